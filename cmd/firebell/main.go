@@ -5,18 +5,22 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"firebell/internal/config"
 	"firebell/internal/daemon"
+	"firebell/internal/detect"
 	"firebell/internal/monitor"
 	"firebell/internal/notify"
 	"firebell/internal/wrap"
@@ -32,11 +36,34 @@ func main() {
 		return
 	}
 
+	// --profile selects the profile for every config load below, as
+	// FIREBELL_PROFILE does
+	if flags.Profile != "" {
+		os.Setenv(config.ProfileEnv, flags.Profile)
+	}
+
+	// Merge agents.yaml into the registry before anything looks agents up
+	registryPath := filepath.Join(config.ResolveConfigDir(flags.ConfigDir), monitor.RegistryFile)
+	if err := monitor.LoadRegistry(registryPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading agent registry: %v\n", err)
+		os.Exit(1)
+	}
+
 	if flags.Setup {
 		runSetup(flags)
 		return
 	}
 
+	if flags.PrintConfigPath || flags.PrintEventPath || flags.PrintSocketPath {
+		runPrintPaths(flags)
+		return
+	}
+
+	if flags.TestMatcher {
+		runTestMatcher(flags)
+		return
+	}
+
 	if flags.Migrate {
 		if err := config.MigrateConfig(); err != nil {
 			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
@@ -55,6 +82,51 @@ func main() {
 		return
 	}
 
+	if flags.Watch {
+		runWatchPath(flags)
+		return
+	}
+
+	if flags.Benchmark {
+		runBenchmark(flags)
+		return
+	}
+
+	if flags.Reasons {
+		runReasons(flags)
+		return
+	}
+
+	if flags.Notify {
+		runNotify(flags)
+		return
+	}
+
+	if flags.Snooze {
+		runSnooze(flags)
+		return
+	}
+
+	if flags.Replay {
+		runReplay(flags)
+		return
+	}
+
+	if flags.ConfigCmd != "" {
+		runConfigEnv(flags)
+		return
+	}
+
+	if flags.PauseCmd != "" {
+		runPause(flags)
+		return
+	}
+
+	if flags.Top {
+		runTop(flags)
+		return
+	}
+
 	// Handle daemon commands
 	if flags.DaemonStart {
 		runDaemonStart(flags)
@@ -62,7 +134,7 @@ func main() {
 	}
 
 	if flags.DaemonStop {
-		runDaemonStop()
+		runDaemonStop(flags)
 		return
 	}
 
@@ -72,7 +144,7 @@ func main() {
 	}
 
 	if flags.DaemonStatus {
-		runDaemonStatus()
+		runDaemonStatus(flags)
 		return
 	}
 
@@ -96,13 +168,21 @@ func main() {
 		return
 	}
 
+	if flags.Stdin {
+		runStdin(flags)
+		return
+	}
+
 	// Load configuration
-	cfg, err := config.Load(flags.ConfigPath)
+	dir := config.ResolveConfigDir(flags.ConfigDir)
+	configPath := config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir)
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		fmt.Fprintln(os.Stderr, "Run 'firebell --setup' to configure")
 		os.Exit(1)
 	}
+	cfg.ApplyConfigDir(dir)
 
 	// Override config with flags
 	if flags.Stdout {
@@ -111,35 +191,38 @@ func main() {
 	if flags.Verbose {
 		cfg.Output.Verbosity = "verbose"
 	}
+	if flags.Backfill > 0 {
+		cfg.Monitor.BackfillSeconds = int(flags.Backfill / time.Second)
+	}
+	if flags.ActiveWindow > 0 {
+		cfg.Monitor.ActiveWindowSeconds = int(flags.ActiveWindow / time.Second)
+	}
 
-	// Determine which agents to monitor
-	var agents []monitor.Agent
-	if flags.Agent != "" {
-		// Single agent specified
-		if agent := monitor.GetAgent(flags.Agent); agent != nil {
-			agents = append(agents, *agent)
-		} else {
-			fmt.Fprintf(os.Stderr, "Unknown agent: %s\n", flags.Agent)
-			fmt.Fprintln(os.Stderr, "Supported agents:", monitor.AllAgentNames())
-			os.Exit(1)
-		}
-	} else if len(cfg.Agents.Enabled) > 0 {
-		// Use agents from config
-		agents = monitor.GetAgents(cfg.Agents.Enabled)
-	} else {
-		// Auto-detect
-		agents = monitor.DetectActiveAgents()
-		if len(agents) == 0 {
-			fmt.Fprintln(os.Stderr, "No active AI agents detected")
-			fmt.Fprintln(os.Stderr, "")
-			fmt.Fprintln(os.Stderr, "Run 'firebell --check' to see status of all supported agents")
-			fmt.Fprintln(os.Stderr, "Or specify an agent: firebell --agent claude")
-			os.Exit(1)
-		}
+	// Determine which agents to monitor (agents.paths applies to all selections)
+	agents, err := monitor.ResolveAgents(flags.Agent, cfg.Agents, cfg.ActiveWindow(), cfg.Advanced.LogExtensions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unknown agent: %s\n", flags.Agent)
+		fmt.Fprintln(os.Stderr, "Supported agents:", monitor.AllAgentNames())
+		os.Exit(1)
 	}
+	autoDetect := flags.Agent == "" && len(cfg.Agents.Enabled) == 0
+	if !autoDetect {
+		// Waiting only applies to auto-detection; explicit selections are fixed
+		cfg.Monitor.WaitForAgents = false
+	}
+	if len(agents) == 0 && autoDetect && !cfg.Monitor.WaitForAgents {
+		fmt.Fprintln(os.Stderr, "No active AI agents detected")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Run 'firebell --check' to see status of all supported agents")
+		fmt.Fprintln(os.Stderr, "Or specify an agent: firebell --agent claude")
+		os.Exit(1)
+	}
+
+	// Apply configured display names so startup output matches notifications
+	agents = monitor.ApplyDisplayNames(agents, cfg.Agents.DisplayNames)
 
 	// Run monitoring
-	if err := runMonitor(cfg, agents); err != nil {
+	if err := runMonitor(cfg, agents, flags.PID, dir, configPath, flags.JSONLogs); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -157,12 +240,15 @@ func runSetup(flags *config.Flags) {
 						Name:        agent.Name,
 						DisplayName: agent.DisplayName,
 						LogPath:     agent.LogPath,
+						AltLogPaths: agent.AltLogPaths,
 					})
 				}
 			}
 			return agents
 		},
 		TestWebhook: config.DefaultTestWebhook,
+		ConfigPath:  config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir),
+		ConfigDir:   config.ResolveConfigDir(flags.ConfigDir),
 	}
 
 	if err := config.SetupWizard(opts); err != nil {
@@ -171,13 +257,48 @@ func runSetup(flags *config.Flags) {
 	}
 }
 
+// runPrintPaths prints the requested paths, one per line (config, event
+// file, then socket), for scripts that need to find firebell's files.
+func runPrintPaths(flags *config.Flags) {
+	paths, err := config.ResolvePaths(flags.ConfigPath, flags.ConfigDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if flags.PrintConfigPath {
+		fmt.Println(paths.Config)
+	}
+	if flags.PrintEventPath {
+		fmt.Println(paths.EventFile)
+	}
+	if flags.PrintSocketPath {
+		fmt.Println(paths.Socket)
+	}
+}
+
 // runHealthCheck shows the status of all supported agents.
 func runHealthCheck(flags *config.Flags) {
 	fmt.Printf("firebell %s - Health Check\n", config.Version)
 	fmt.Println()
 
+	configPath := config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir)
+
+	// Repair what we can before reporting
+	if flags.Fix {
+		fixed, err := daemon.Fix(config.ResolveConfigDir(flags.ConfigDir), configPath)
+		fmt.Println("Fixes:")
+		for _, f := range fixed {
+			fmt.Printf("  ✓ %s\n", f)
+		}
+		if err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+		} else if len(fixed) == 0 {
+			fmt.Println("  Nothing to fix.")
+		}
+		fmt.Println()
+	}
+
 	// Check config
-	configPath := config.DefaultConfigPath()
 	if _, err := os.Stat(configPath); err == nil {
 		fmt.Printf("Config:  %s\n", configPath)
 	} else {
@@ -185,6 +306,15 @@ func runHealthCheck(flags *config.Flags) {
 	}
 	fmt.Println()
 
+	// Log files are counted by the configured extensions, or the defaults
+	var exts monitor.LogExtensions
+	if cfg, err := config.Load(configPath); err == nil {
+		exts = cfg.Advanced.LogExtensions
+	}
+
+	// Last activity recorded by a running firebell, if any
+	lastSeen, _ := monitor.LoadLastSeen(filepath.Join(config.ResolveConfigDir(flags.ConfigDir), monitor.LastSeenFile))
+
 	// Check agents
 	fmt.Println("Agents:")
 	activeCount := 0
@@ -194,7 +324,7 @@ func runHealthCheck(flags *config.Flags) {
 			continue
 		}
 
-		expanded := monitor.ExpandPath(agent.LogPath)
+		expanded := config.ExpandPath(agent.LogPath)
 		info, err := os.Stat(expanded)
 
 		var status, detail string
@@ -208,7 +338,7 @@ func runHealthCheck(flags *config.Flags) {
 			age := formatAge(info.ModTime())
 			if info.IsDir() {
 				// Count log files in directory
-				count := countLogFiles(expanded)
+				count := countLogFiles(expanded, exts)
 				detail = fmt.Sprintf("%d files, %s", count, age)
 			} else {
 				detail = fmt.Sprintf("file, %s", age)
@@ -216,6 +346,9 @@ func runHealthCheck(flags *config.Flags) {
 		}
 
 		fmt.Printf("  %-14s %s %s\n", agent.DisplayName, status, detail)
+		if at, ok := lastSeen[name]; ok && !at.IsZero() {
+			fmt.Printf("  %-14s   last activity %s (detected by firebell)\n", "", formatAge(at))
+		}
 	}
 	fmt.Println()
 
@@ -252,8 +385,9 @@ func formatAge(t time.Time) string {
 	}
 }
 
-// countLogFiles counts log files in a directory recursively (max depth 4).
-func countLogFiles(dir string) int {
+// countLogFiles counts files with one of exts in a directory recursively
+// (max depth 4).
+func countLogFiles(dir string, exts monitor.LogExtensions) int {
 	count := 0
 	maxDepth := 4
 	baseDepth := strings.Count(dir, string(filepath.Separator))
@@ -273,7 +407,7 @@ func countLogFiles(dir string) int {
 		if depth > maxDepth {
 			return nil
 		}
-		if hasLogExtension(info.Name()) {
+		if exts.Match(info.Name()) {
 			count++
 		}
 		return nil
@@ -281,20 +415,11 @@ func countLogFiles(dir string) int {
 	return count
 }
 
-// hasLogExtension checks if a filename has a log-like extension.
-func hasLogExtension(name string) bool {
-	exts := []string{".log", ".jsonl", ".json", ".txt"}
-	for _, ext := range exts {
-		if len(name) > len(ext) && name[len(name)-len(ext):] == ext {
-			return true
-		}
-	}
-	return false
-}
-
 // runMonitor starts the main monitoring loop.
-func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
-	dir := config.DefaultConfigDir()
+// pid, if positive, is tracked instead of auto-detecting the agent process.
+// dir holds the lock and logs; configPath is only reported.
+// jsonLogs writes the daemon log as JSONL.
+func runMonitor(cfg *config.Config, agents []monitor.Agent, pid int, dir, configPath string, jsonLogs bool) error {
 	isDaemon := daemon.IsDaemon()
 	var lock *daemon.Lock
 	var logger *daemon.Logger
@@ -317,9 +442,12 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 			return fmt.Errorf("failed to create logger: %w", err)
 		}
 		defer logger.Close()
+		logger.SetJSON(jsonLogs)
+		monitor.Debugf = logger.Debug
+		monitor.Errorf = logger.Error
 
 		logger.Info("firebell daemon starting")
-		logger.Info("Config: %s", config.DefaultConfigPath())
+		logger.Info("Config: %s", configPath)
 	}
 
 	// Create socket server if enabled
@@ -343,6 +471,37 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 		}
 	}
 
+	// Write notifications to a human-readable log if configured
+	if cfg.Notify.FileLog {
+		fileLogger, err := daemon.NewNamedLogger(filepath.Join(dir, "logs"), daemon.NotificationLogName)
+		if err != nil {
+			if isDaemon {
+				logger.Warn("Failed to open notification log: %v", err)
+			}
+		} else {
+			fileNotifier := daemon.NewFileNotifier(fileLogger)
+			fileNotifier.SetLocation(cfg.TimeLocation())
+			extras = append(extras, fileNotifier)
+		}
+	}
+
+	// Create WebSocket bridge if configured (relays socket broadcasts)
+	var wsServer *daemon.WebSocketServer
+	if socketServer != nil && cfg.Daemon.WSAddr != "" {
+		var err error
+		wsServer, err = daemon.NewWebSocketServer(cfg.Daemon.WSAddr, socketServer)
+		if err != nil {
+			if isDaemon {
+				logger.Warn("Failed to create websocket: %v", err)
+			}
+		} else {
+			wsServer.SetAllowedOrigins(cfg.Daemon.WSAllowedOrigins)
+			if isDaemon {
+				logger.Info("WebSocket: ws://%s/", wsServer.Addr())
+			}
+		}
+	}
+
 	// Create notifier with extras
 	notifier, err := notify.NewNotifierWithExtras(cfg, extras)
 	if err != nil {
@@ -351,11 +510,18 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 
 	// Emit daemon start event if event file is enabled
 	var eventFileNotifier *notify.EventFileNotifier
+	var webhookNotifier *notify.WebhookNotifier
+	primary := notifier
 	if multi, ok := notifier.(*notify.MultiNotifier); ok {
+		primary = multi.Primary()
 		for _, n := range multi.Secondary() {
-			if ef, ok := n.(*notify.EventFileNotifier); ok {
-				eventFileNotifier = ef
-				break
+			switch n := n.(type) {
+			case *notify.EventFileNotifier:
+				if eventFileNotifier == nil {
+					eventFileNotifier = n
+				}
+			case *notify.WebhookNotifier:
+				webhookNotifier = n
 			}
 		}
 	}
@@ -363,15 +529,27 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 		eventFileNotifier.EmitDaemonStart()
 	}
 
+	// Batched Slack sends fail after Send returns; log them like other
+	// failed deliveries (the daemon log's ERROR level, or stderr)
+	if slack, ok := primary.(*notify.SlackNotifier); ok {
+		slack.SetErrorHandler(func(err error) {
+			monitor.Errorf("Failed to send batched notifications via %s: %v", slack.Name(), err)
+		})
+	}
+
 	// Create watcher
 	watcher, err := monitor.NewWatcher(cfg, notifier, agents)
 	if err != nil {
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 	defer watcher.Close()
+	watcher.SetPID(pid)
+	watcher.SetLastSeenPath(filepath.Join(dir, monitor.LastSeenFile))
+	watcher.SetReadyFile(cfg.Daemon.ReadyFile)
+	watcher.SetSnoozeFile(filepath.Join(dir, monitor.SnoozeFile))
 
 	// Identify stale agents (>24h without log updates) for informational output
-	staleAgents := monitor.FindStaleAgents(agents, 24*time.Hour)
+	staleAgents := monitor.FindStaleAgents(agents, 24*time.Hour, cfg.Advanced.LogExtensions)
 
 	// Print startup info
 	if isDaemon {
@@ -382,13 +560,16 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 			}
 			agentNames += agent.DisplayName
 		}
+		if agentNames == "" {
+			agentNames = "none yet, waiting for agents"
+		}
 		logger.Info("Notify: %s", notifier.Name())
 		logger.Info("Agents: %s", agentNames)
 		logger.Info("Stale (>24h): %s", formatAgentList(staleAgents))
 		logger.Info("Monitoring started")
 	} else {
 		fmt.Printf("firebell %s - Starting monitoring...\n", config.Version)
-		fmt.Printf("  Config: %s\n", config.DefaultConfigPath())
+		fmt.Printf("  Config: %s\n", configPath)
 		fmt.Printf("  Notify: %s\n", notifier.Name())
 		fmt.Printf("  Agents: ")
 		for i, agent := range agents {
@@ -397,6 +578,9 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 			}
 			fmt.Print(agent.DisplayName)
 		}
+		if len(agents) == 0 {
+			fmt.Print("none yet, waiting for agents")
+		}
 		fmt.Println()
 		fmt.Printf("  Stale (>24h): %s\n", formatAgentList(staleAgents))
 		fmt.Println()
@@ -406,10 +590,40 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Start socket server
+	// Start socket server; clients can request {"command":"status"} and
+	// pause or resume agents
 	if socketServer != nil {
+		socketServer.SetStatusFunc(func() any { return watcher.DumpStatus() })
+		socketServer.SetPauseFunc(watcher.SetPaused)
 		socketServer.Start(ctx)
 	}
+	if wsServer != nil {
+		wsServer.Start(ctx)
+	}
+
+	// Log webhook retries, which otherwise only show as delayed notifications.
+	// Only the daemon keeps a retry queue: other firebell processes would
+	// each load the file and overwrite the others' entries on save.
+	if webhookNotifier != nil && isDaemon {
+		webhookNotifier.SetLogger(logger)
+		if cfg.Notify.RetryQueue {
+			// An unreadable queue disables retries rather than the webhooks
+			queue, err := notify.NewRetryQueue(cfg.Daemon.RetryQueueFile)
+			if err != nil {
+				logger.Warn("Retry queue disabled: %v", err)
+			} else {
+				webhookNotifier.SetRetryQueue(queue)
+			}
+		}
+	}
+
+	// Resend webhook deliveries queued by this or a previous run
+	if webhookNotifier != nil && webhookNotifier.RetryQueue() != nil {
+		if isDaemon {
+			logger.Info("Retry queue: %d pending", webhookNotifier.RetryQueue().Len())
+		}
+		go webhookNotifier.RunRetries(ctx)
+	}
 
 	// Handle shutdown signals
 	sigCh := make(chan os.Signal, 1)
@@ -424,6 +638,31 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 		cancel()
 	}()
 
+	// Dump a state snapshot on SIGUSR1 without stopping
+	statusCh := make(chan os.Signal, 1)
+	signal.Notify(statusCh, syscall.SIGUSR1)
+	defer signal.Stop(statusCh)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-statusCh:
+				snap := watcher.DumpStatus()
+				for _, line := range snap.Lines() {
+					if isDaemon {
+						logger.Info("%s", line)
+					} else {
+						fmt.Println(line)
+					}
+				}
+				if eventFileNotifier != nil {
+					eventFileNotifier.EmitStatus(snap.Summary(), snap)
+				}
+			}
+		}
+	}()
+
 	// Run watcher (event-driven with polling fallback)
 	var runErr error
 	if cfg.Advanced.ForcePolling {
@@ -437,14 +676,17 @@ func runMonitor(cfg *config.Config, agents []monitor.Agent) error {
 		eventFileNotifier.EmitDaemonStop()
 	}
 
-	// Close socket server
+	// Close socket server and its WebSocket bridge
+	if wsServer != nil {
+		wsServer.Close()
+	}
 	if socketServer != nil {
 		socketServer.Close()
 	}
 
-	// Close multi-notifier if applicable
-	if multi, ok := notifier.(*notify.MultiNotifier); ok {
-		multi.Close()
+	// Close notifiers (flushes batched Slack messages)
+	if closer, ok := notifier.(interface{ Close() error }); ok {
+		closer.Close()
 	}
 
 	if isDaemon {
@@ -463,12 +705,13 @@ func runWrap(flags *config.Flags) {
 	}
 
 	// Load configuration
-	cfg, err := config.Load(flags.ConfigPath)
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		fmt.Fprintln(os.Stderr, "Run 'firebell --setup' to configure")
 		os.Exit(1)
 	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
 
 	// Override config with flags
 	if flags.Stdout {
@@ -487,6 +730,7 @@ func runWrap(flags *config.Flags) {
 
 	// Create runner
 	runner := wrap.NewRunner(cfg, notifier, flags.WrapName)
+	runner.SetCommandAgents(monitor.CommandAgents())
 
 	// Setup context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -510,9 +754,227 @@ func runWrap(flags *config.Flags) {
 	os.Exit(exitCode)
 }
 
+// runWatchPath monitors a single arbitrary log path in the foreground.
+func runWatchPath(flags *config.Flags) {
+	if flags.WatchPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no path specified")
+		fmt.Fprintln(os.Stderr, "Usage: firebell watch <path> [--name NAME]")
+		os.Exit(1)
+	}
+
+	// Load configuration (defaults if none exists)
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Notify.Type = "stdout"
+	if flags.Verbose {
+		cfg.Output.Verbosity = "verbose"
+	}
+
+	stdout := notify.NewStdoutNotifier()
+	stdout.SetTimeFormat(cfg.TimeLayout(), cfg.TimeLocation())
+	stdout.SetTheme(cfg.Output.Theme)
+	watcher, err := monitor.NewPathWatcher(cfg, stdout, flags.WatchPath, flags.WatchPathName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer watcher.Close()
+	watcher.SetSnoozeFile(filepath.Join(config.ResolveConfigDir(flags.ConfigDir), monitor.SnoozeFile))
+
+	// Setup context
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle signals
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Println("\nShutting down...")
+		cancel()
+	}()
+
+	fmt.Printf("firebell %s - Watching %s (Ctrl+C to stop)\n\n", config.Version, flags.WatchPath)
+
+	if err := watcher.Run(ctx); err != nil && err != context.Canceled {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runStdin classifies log lines piped to stdin and sends notifications until
+// the input ends.
+func runStdin(flags *config.Flags) {
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
+	if flags.Stdout {
+		cfg.Notify.Type = "stdout"
+	}
+	if flags.Verbose {
+		cfg.Output.Verbosity = "verbose"
+	}
+
+	name := "stdin"
+	if flags.Agent != "" {
+		agent := monitor.GetAgent(flags.Agent)
+		if agent == nil {
+			fmt.Fprintf(os.Stderr, "Unknown agent: %s\n", flags.Agent)
+			fmt.Fprintln(os.Stderr, "Supported agents:", monitor.AllAgentNames())
+			os.Exit(1)
+		}
+		name = monitor.ApplyDisplayNames([]monitor.Agent{*agent}, cfg.Agents.DisplayNames)[0].DisplayName
+	}
+
+	notifier, err := notify.NewNotifier(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating notifier: %v\n", err)
+		os.Exit(1)
+	}
+	if closer, ok := notifier.(interface{ Close() error }); ok {
+		defer closer.Close()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	watcher := monitor.NewReaderWatcher(cfg, notifier, os.Stdin, flags.Agent, name)
+	defer watcher.Close()
+	watcher.SetSnoozeFile(filepath.Join(config.ResolveConfigDir(flags.ConfigDir), monitor.SnoozeFile))
+	if err := watcher.Run(ctx); err != nil && err != context.Canceled {
+		fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runBenchmark times an agent's matcher over a log file and prints the results.
+func runBenchmark(flags *config.Flags) {
+	if flags.BenchmarkPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no path specified")
+		fmt.Fprintln(os.Stderr, "Usage: firebell benchmark [--agent NAME] <path>")
+		os.Exit(1)
+	}
+
+	// Load all lines up front so file I/O is not part of the timing
+	lines := readLogLines(flags.BenchmarkPath)
+	agent := matcherAgent(flags.Agent)
+	result := detect.RunBenchmark(detect.CreateMatcher(agent), lines)
+
+	fmt.Printf("firebell %s - Matcher benchmark\n\n", config.Version)
+	fmt.Printf("Agent:       %s\n", agent)
+	fmt.Printf("File:        %s\n", flags.BenchmarkPath)
+	fmt.Printf("Lines:       %d (%.1f MB)\n", result.Lines, float64(result.Bytes)/(1024*1024))
+	fmt.Printf("Elapsed:     %s\n", result.Elapsed.Round(time.Microsecond))
+	fmt.Printf("Throughput:  %.0f lines/sec\n", result.LinesPerSec())
+	fmt.Printf("Allocations: %d (%.1f per line, %.1f MB)\n", result.Allocs, result.AllocsPerLine(), float64(result.AllocBytes)/(1024*1024))
+	fmt.Println()
+	fmt.Println("Matches:")
+	for _, t := range []detect.MatchType{detect.MatchActivity, detect.MatchComplete, detect.MatchAwaiting, detect.MatchHolding, detect.MatchCompaction} {
+		fmt.Printf("  %-10s %d\n", t.String()+":", result.Matches[t])
+	}
+	fmt.Printf("  %-10s %d\n", "none:", result.NoMatch)
+}
+
+// runReasons prints how many lines of a log file matched with each Match.Reason.
+func runReasons(flags *config.Flags) {
+	if flags.ReasonsPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no path specified")
+		fmt.Fprintln(os.Stderr, "Usage: firebell reasons [--agent NAME] <path>")
+		os.Exit(1)
+	}
+
+	lines := readLogLines(flags.ReasonsPath)
+	agent := matcherAgent(flags.Agent)
+	counts, noMatch := detect.TallyReasons(detect.CreateMatcher(agent), lines)
+
+	fmt.Printf("firebell %s - Match reasons\n\n", config.Version)
+	fmt.Printf("Agent: %s\n", agent)
+	fmt.Printf("File:  %s\n\n", flags.ReasonsPath)
+	for _, rc := range counts {
+		fmt.Printf("  %8d  %-9s %s\n", rc.Count, rc.Type, rc.Reason)
+	}
+	fmt.Printf("  %8d  %-9s %s\n", noMatch, "-", "(no match)")
+}
+
+// runTestMatcher matches one line with an agent's matcher, built from the
+// config (agents.format and agents.keywords) as the watcher builds it, and
+// prints the match, for reproducing matcher bug reports without a log file.
+func runTestMatcher(flags *config.Flags) {
+	if flags.TestMatcherAgent == "" {
+		fmt.Fprintln(os.Stderr, "Error: expected an agent and a line")
+		fmt.Fprintln(os.Stderr, "Usage: firebell --test-matcher AGENT LINE")
+		os.Exit(1)
+	}
+
+	agent := monitor.GetAgent(flags.TestMatcherAgent)
+	if agent == nil {
+		names := monitor.AllAgentNames()
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Error: unknown agent: %s (agents: %s)\n", flags.TestMatcherAgent, strings.Join(names, ", "))
+		os.Exit(1)
+	}
+
+	// Match as configured when there is a config; built-in keywords otherwise
+	configPath := config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir)
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		if _, statErr := os.Stat(configPath); !os.IsNotExist(statErr) {
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "No config file; using built-in keywords")
+		cfg = config.DefaultConfig()
+	}
+
+	m := monitor.NewAgentMatcher(cfg, agent.Name)
+	fmt.Print(detect.DescribeMatch(detect.MatchLine(m, flags.TestMatcherLine)))
+}
+
+// matcherAgent returns the agent whose matcher to use (generic fallback if unset).
+func matcherAgent(agent string) string {
+	if agent == "" {
+		return "generic"
+	}
+	return agent
+}
+
+// readLogLines reads every line of a log file, exiting on error.
+func readLogLines(path string) []string {
+	f, err := os.Open(config.ExpandPath(path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	return lines
+}
+
 // runDaemonStart starts the daemon in the background.
 func runDaemonStart(flags *config.Flags) {
-	dir := config.DefaultConfigDir()
+	dir := config.ResolveConfigDir(flags.ConfigDir)
 	d := daemon.NewDaemon(dir)
 
 	// Build args for daemon process
@@ -520,9 +982,27 @@ func runDaemonStart(flags *config.Flags) {
 	if flags.ConfigPath != "" {
 		args = append(args, "--config", flags.ConfigPath)
 	}
+	if flags.ConfigDir != "" {
+		args = append(args, "--config-dir", flags.ConfigDir)
+	}
+	if flags.Profile != "" {
+		args = append(args, "--profile", flags.Profile)
+	}
 	if flags.Agent != "" {
 		args = append(args, "--agent", flags.Agent)
 	}
+	if flags.Backfill > 0 {
+		args = append(args, "--backfill", flags.Backfill.String())
+	}
+	if flags.ActiveWindow > 0 {
+		args = append(args, "--active-window", flags.ActiveWindow.String())
+	}
+	if flags.PID > 0 {
+		args = append(args, "--pid", strconv.Itoa(flags.PID))
+	}
+	if flags.JSONLogs {
+		args = append(args, "--json-logs")
+	}
 
 	if err := d.Start(args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -531,8 +1011,8 @@ func runDaemonStart(flags *config.Flags) {
 }
 
 // runDaemonStop stops the running daemon.
-func runDaemonStop() {
-	dir := config.DefaultConfigDir()
+func runDaemonStop(flags *config.Flags) {
+	dir := config.ResolveConfigDir(flags.ConfigDir)
 	d := daemon.NewDaemon(dir)
 
 	if err := d.Stop(); err != nil {
@@ -543,7 +1023,7 @@ func runDaemonStop() {
 
 // runDaemonRestart restarts the daemon.
 func runDaemonRestart(flags *config.Flags) {
-	dir := config.DefaultConfigDir()
+	dir := config.ResolveConfigDir(flags.ConfigDir)
 	d := daemon.NewDaemon(dir)
 
 	// Build args for daemon process
@@ -551,9 +1031,27 @@ func runDaemonRestart(flags *config.Flags) {
 	if flags.ConfigPath != "" {
 		args = append(args, "--config", flags.ConfigPath)
 	}
+	if flags.ConfigDir != "" {
+		args = append(args, "--config-dir", flags.ConfigDir)
+	}
+	if flags.Profile != "" {
+		args = append(args, "--profile", flags.Profile)
+	}
 	if flags.Agent != "" {
 		args = append(args, "--agent", flags.Agent)
 	}
+	if flags.Backfill > 0 {
+		args = append(args, "--backfill", flags.Backfill.String())
+	}
+	if flags.ActiveWindow > 0 {
+		args = append(args, "--active-window", flags.ActiveWindow.String())
+	}
+	if flags.PID > 0 {
+		args = append(args, "--pid", strconv.Itoa(flags.PID))
+	}
+	if flags.JSONLogs {
+		args = append(args, "--json-logs")
+	}
 
 	if err := d.Restart(args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -562,8 +1060,8 @@ func runDaemonRestart(flags *config.Flags) {
 }
 
 // runDaemonStatus shows the daemon status.
-func runDaemonStatus() {
-	dir := config.DefaultConfigDir()
+func runDaemonStatus(flags *config.Flags) {
+	dir := config.ResolveConfigDir(flags.ConfigDir)
 	d := daemon.NewDaemon(dir)
 
 	running, pid, uptime := d.Status()
@@ -592,7 +1090,7 @@ func runDaemonStatus() {
 
 // runDaemonLogs shows or follows the daemon logs.
 func runDaemonLogs(flags *config.Flags) {
-	dir := config.DefaultConfigDir()
+	dir := config.ResolveConfigDir(flags.ConfigDir)
 	logDir := filepath.Join(dir, "logs")
 
 	// Find most recent log file
@@ -727,8 +1225,14 @@ func formatAgentList(agents []monitor.Agent) string {
 
 // runEvents shows or follows the event file.
 func runEvents(flags *config.Flags) {
-	// Get event file path
-	eventPath := filepath.Join(config.DefaultConfigDir(), "events.jsonl")
+	// Get event file path, honoring daemon.event_file_path; fall back to
+	// defaults if the config can't be loaded
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
+	eventPath := cfg.Daemon.EventFilePath
 
 	// Check if file exists
 	info, err := os.Stat(eventPath)
@@ -748,6 +1252,19 @@ func runEvents(flags *config.Flags) {
 		os.Exit(1)
 	}
 
+	if flags.EventsTail > 0 {
+		// Page through events across rotations
+		lines, err := notify.TailEvents(eventPath, flags.EventsTail, flags.EventsOffset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading events: %v\n", err)
+			os.Exit(1)
+		}
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		return
+	}
+
 	if flags.EventsFollow {
 		// Follow mode
 		fmt.Printf("Following %s (Ctrl+C to stop)\n\n", eventPath)
@@ -765,11 +1282,17 @@ func runEvents(flags *config.Flags) {
 		fmt.Println()
 
 		// Count events by type
-		eventCounts := countEventTypes(eventPath)
+		eventCounts, _ := notify.CountEvents(eventPath, flags.EventsAll)
 		if len(eventCounts) > 0 {
+			types := make([]string, 0, len(eventCounts))
+			for eventType := range eventCounts {
+				types = append(types, string(eventType))
+			}
+			sort.Strings(types)
+
 			fmt.Println("Event counts:")
-			for eventType, count := range eventCounts {
-				fmt.Printf("  %-15s %d\n", eventType, count)
+			for _, eventType := range types {
+				fmt.Printf("  %-15s %d\n", eventType, eventCounts[notify.EventType(eventType)])
 			}
 			fmt.Println()
 		}
@@ -814,35 +1337,291 @@ func runWebhookTest(flags *config.Flags) {
 	fmt.Println("      events: [\"all\"]  # or [\"cooling\", \"activity\", \"process_exit\"]")
 }
 
-// countEventTypes counts events by type in the event file.
-func countEventTypes(path string) map[string]int {
-	f, err := os.Open(path)
+// runNotify sends one ad-hoc notification through the configured notifier.
+func runNotify(flags *config.Flags) {
+	if flags.NotifyMessage == "" {
+		fmt.Fprintln(os.Stderr, "Error: no message specified")
+		fmt.Fprintln(os.Stderr, "Usage: firebell notify [--title TEXT] [--agent NAME] <message>")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
 	if err != nil {
-		return nil
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
 	}
-	defer f.Close()
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
 
-	counts := make(map[string]int)
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		// Simple extraction of event type without full JSON parsing
-		// Look for "event":"<type>"
-		if idx := strings.Index(line, `"event":"`); idx != -1 {
-			start := idx + 9
-			end := strings.Index(line[start:], `"`)
-			if end != -1 {
-				eventType := line[start : start+end]
-				counts[eventType]++
+	notifier, err := notify.NewNotifier(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating notifier: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = notify.CriticalOnly(notify.SendManual(ctx, notifier, flags.NotifyTitle, flags.Agent, flags.NotifyMessage))
+	if closer, ok := notifier.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending notification: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSnooze starts, ends, or shows a snooze of outbound notifications.
+func runSnooze(flags *config.Flags) {
+	path := filepath.Join(config.ResolveConfigDir(flags.ConfigDir), monitor.SnoozeFile)
+
+	switch flags.SnoozeArg {
+	case "":
+		until, err := monitor.ReadSnooze(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !until.After(time.Now()) {
+			fmt.Println("Not snoozed")
+			return
+		}
+		fmt.Printf("Snoozed until %s (%s left)\n", until.Format("15:04:05"), time.Until(until).Round(time.Second))
+	case "off":
+		if err := monitor.ClearSnooze(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Snooze off; notifications resumed")
+	default:
+		d, err := time.ParseDuration(flags.SnoozeArg)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid snooze duration %q (e.g. 30m, 2h, or off)\n", flags.SnoozeArg)
+			os.Exit(1)
+		}
+		until := time.Now().Add(d)
+		if err := monitor.WriteSnooze(path, until); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Snoozed until %s; events are still written to the event file\n", until.Format("15:04:05"))
+	}
+}
+
+// runPause asks the running daemon, over its socket, to pause or resume an agent.
+func runPause(flags *config.Flags) {
+	cmd := map[string]string{"command": flags.PauseCmd, "agent": flags.PauseAgent}
+	reply, err := daemonRequest(context.Background(), daemonSocketPath(flags), cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if reply.Type == "paused" {
+		fmt.Printf("Paused %s; run 'firebell resume %s' to resume\n", flags.PauseAgent, flags.PauseAgent)
+	} else {
+		fmt.Printf("Resumed %s\n", flags.PauseAgent)
+	}
+}
+
+// runTop polls the daemon's status over its socket and redraws a per-agent
+// activity meter every interval until interrupted.
+func runTop(flags *config.Flags) {
+	socketPath := daemonSocketPath(flags)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(flags.TopInterval)
+	defer ticker.Stop()
+
+	var prev *monitor.StatusSnapshot
+	for {
+		reply, err := daemonRequest(ctx, socketPath, map[string]string{"command": "status"})
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
+			return
+		}
+		if reply.Status == nil {
+			fmt.Fprintln(os.Stderr, "Error: the daemon sent an empty status")
+			os.Exit(1)
 		}
+
+		// Clear the screen and redraw from the top
+		fmt.Print("\033[H\033[2J")
+		for _, line := range monitor.FormatTop(prev, reply.Status) {
+			fmt.Println(line)
+		}
+		prev = reply.Status
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// daemonSocketPath returns the daemon socket path from the config.
+func daemonSocketPath(flags *config.Flags) string {
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
+	return cfg.Daemon.SocketPath
+}
+
+// daemonReply is the daemon's reply to a socket command.
+type daemonReply struct {
+	Type    string                  `json:"type"` // "status", "paused", "resumed", or "error"
+	Message string                  `json:"message"`
+	Status  *monitor.StatusSnapshot `json:"status"`
+}
+
+// daemonRequest sends cmd to the running daemon over the socket at
+// socketPath and returns its reply, skipping the welcome message and any
+// events sent before it. An "error" reply is returned as an error. Canceling
+// ctx abandons the request.
+func daemonRequest(ctx context.Context, socketPath string, cmd map[string]string) (*daemonReply, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't reach the daemon socket %s (is the daemon running with daemon.socket: true?): %w", socketPath, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	unblock := context.AfterFunc(ctx, func() { conn.Close() }) // Unblock a pending read
+	defer unblock()
+
+	// Skip the welcome message, then send the command
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\n'); err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(cmd)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	// Events may arrive before the reply; they have no type
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("no reply from daemon: %w", err)
+		}
+		var reply daemonReply
+		if json.Unmarshal([]byte(line), &reply) != nil || reply.Type == "" {
+			continue
+		}
+		if reply.Type == "error" {
+			return nil, errors.New(reply.Message)
+		}
+		return &reply, nil
+	}
+}
+
+// runConfigEnv prints the effective config as FIREBELL_* variables.
+func runConfigEnv(flags *config.Flags) {
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
+
+	for _, v := range config.EnvVars(cfg) {
+		fmt.Printf("%s=%s\n", v.Name, v.Value)
 	}
-	return counts
+}
+
+// runReplay re-sends a recorded event file through the configured notifier.
+func runReplay(flags *config.Flags) {
+	if flags.ReplayPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no event file specified")
+		fmt.Fprintln(os.Stderr, "Usage: firebell replay [--speed N] <events.jsonl>")
+		os.Exit(1)
+	}
+	if flags.ReplaySpeed < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --speed must be 0 or more, got %g\n", flags.ReplaySpeed)
+		os.Exit(1)
+	}
+
+	f, err := os.Open(flags.ReplayPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	events, err := notify.ReadEvents(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", flags.ReplayPath, err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
+
+	// Don't append the replay to the recording being replayed
+	if cfg.Daemon.EventFile && sameFile(cfg.Daemon.EventFilePath, flags.ReplayPath) {
+		cfg.Daemon.EventFile = false
+	}
+
+	notifier, err := notify.NewNotifier(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating notifier: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle signals
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Replaying %d events from %s through %s (speed %g)\n", len(events), flags.ReplayPath, notifier.Name(), flags.ReplaySpeed)
+	sent, err := notify.NewReplayer(flags.ReplaySpeed).Run(ctx, notifier, events)
+	if closer, ok := notifier.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+	fmt.Fprintf(os.Stderr, "Sent %d notifications\n", sent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// sameFile reports whether paths a and b name the same existing file.
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
 }
 
 // runListen connects to the daemon socket and displays events.
 func runListen(flags *config.Flags) {
-	socketPath := filepath.Join(config.DefaultConfigDir(), "firebell.sock")
+	// Config supplies the socket path and timestamp formatting; fall back to defaults
+	cfg, err := config.Load(config.ResolveConfigPath(flags.ConfigPath, flags.ConfigDir))
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cfg.ApplyConfigDir(config.ResolveConfigDir(flags.ConfigDir))
+	socketPath := cfg.Daemon.SocketPath
 
 	// Check if socket exists
 	if _, err := os.Stat(socketPath); os.IsNotExist(err) {
@@ -902,13 +1681,13 @@ func runListen(flags *config.Flags) {
 			fmt.Print(line)
 		} else {
 			// Formatted output
-			formatSocketEvent(line)
+			formatSocketEvent(line, cfg)
 		}
 	}
 }
 
 // formatSocketEvent formats a JSON event line for display.
-func formatSocketEvent(line string) {
+func formatSocketEvent(line string, cfg *config.Config) {
 	// Parse the event
 	var event struct {
 		Type      string    `json:"type"`
@@ -930,7 +1709,7 @@ func formatSocketEvent(line string) {
 	}
 
 	// Format: [timestamp] Agent: Event - Message
-	ts := event.Timestamp.Format("15:04:05")
+	ts := cfg.FormatTime(event.Timestamp)
 	if event.Agent != "" && event.Event != "" {
 		fmt.Printf("[%s] %s: %s", ts, event.Agent, event.Event)
 		if event.Message != "" {
@@ -3,84 +3,373 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config is the root configuration structure for firebell v2.0.
 type Config struct {
-	Version  string         `yaml:"version" json:"version"`
-	Notify   NotifyConfig   `yaml:"notify" json:"notify"`
-	Agents   AgentsConfig   `yaml:"agents" json:"agents"`
-	Monitor  MonitorConfig  `yaml:"monitor" json:"monitor"`
-	Output   OutputConfig   `yaml:"output" json:"output"`
-	Daemon   DaemonConfig   `yaml:"daemon" json:"daemon"`
-	Advanced AdvancedConfig `yaml:"advanced" json:"advanced"`
+	Version  string         `yaml:"version" json:"version" toml:"version"`
+	Notify   NotifyConfig   `yaml:"notify" json:"notify" toml:"notify"`
+	Agents   AgentsConfig   `yaml:"agents" json:"agents" toml:"agents"`
+	Monitor  MonitorConfig  `yaml:"monitor" json:"monitor" toml:"monitor"`
+	Output   OutputConfig   `yaml:"output" json:"output" toml:"output"`
+	Daemon   DaemonConfig   `yaml:"daemon" json:"daemon" toml:"daemon"`
+	Advanced AdvancedConfig `yaml:"advanced" json:"advanced" toml:"advanced"`
 }
 
 // DaemonConfig defines daemon mode settings.
 type DaemonConfig struct {
-	LogRetentionDays int `yaml:"log_retention_days" json:"log_retention_days"` // Days to keep logs (0 = forever)
+	LogRetentionDays int `yaml:"log_retention_days" json:"log_retention_days" toml:"log_retention_days"` // Days to keep logs (0 = forever)
 
 	// Event file settings for external integrations
-	EventFile        bool   `yaml:"event_file" json:"event_file"`                 // Enable event file output
-	EventFilePath    string `yaml:"event_file_path" json:"event_file_path"`       // Path to event file (default: ~/.firebell/events.jsonl)
-	EventFileMaxSize int64  `yaml:"event_file_max_size" json:"event_file_max_size"` // Max size in bytes before rotation (default: 10MB)
+	EventFile        bool   `yaml:"event_file" json:"event_file" toml:"event_file"`                            // Enable event file output
+	EventFilePath    string `yaml:"event_file_path" json:"event_file_path" toml:"event_file_path"`             // Path to event file (default: ~/.firebell/events.jsonl)
+	EventFileMaxSize int64  `yaml:"event_file_max_size" json:"event_file_max_size" toml:"event_file_max_size"` // Max size in bytes before rotation (default: 10MB)
+
+	EventMaxLineBytes int `yaml:"event_max_line_bytes,omitempty" json:"event_max_line_bytes,omitempty" toml:"event_max_line_bytes,omitempty"` // Cut a long snippet, raw match, history, then message, to keep each event line within this many bytes (0 = no limit)
 
 	// Unix socket settings for external integrations
-	Socket     bool   `yaml:"socket" json:"socket"`           // Enable Unix socket listener
-	SocketPath string `yaml:"socket_path" json:"socket_path"` // Path to socket (default: ~/.firebell/firebell.sock)
+	Socket     bool   `yaml:"socket" json:"socket" toml:"socket"`                // Enable Unix socket listener
+	SocketPath string `yaml:"socket_path" json:"socket_path" toml:"socket_path"` // Path to socket (default: ~/.firebell/firebell.sock)
+
+	// WebSocket bridge for browser dashboards (requires socket)
+	WSAddr           string   `yaml:"ws_addr,omitempty" json:"ws_addr,omitempty" toml:"ws_addr,omitempty"`                                     // TCP listen address, e.g. "127.0.0.1:8765" (empty = disabled)
+	WSAllowedOrigins []string `yaml:"ws_allowed_origins,omitempty" json:"ws_allowed_origins,omitempty" toml:"ws_allowed_origins,omitempty"` // Browser origins allowed to connect, e.g. "http://localhost:3000" (others are refused)
+
+	// Readiness probe for supervisors and containers
+	ReadyFile string `yaml:"ready_file,omitempty" json:"ready_file,omitempty" toml:"ready_file,omitempty"` // Written once watching starts, removed on exit (default: ~/.firebell/ready)
+
+	// Persisted webhook retry queue (see notify.retry_queue)
+	RetryQueueFile string `yaml:"retry_queue_file,omitempty" json:"retry_queue_file,omitempty" toml:"retry_queue_file,omitempty"` // Default: ~/.firebell/retry-queue.json
 }
 
 // NotifyConfig defines notification destination and settings.
 type NotifyConfig struct {
-	Type     string          `yaml:"type" json:"type"` // "slack" or "stdout"
-	Slack    SlackConfig     `yaml:"slack,omitempty" json:"slack,omitempty"`
-	Webhooks []WebhookConfig `yaml:"webhooks,omitempty" json:"webhooks,omitempty"` // Additional webhook endpoints
+	Type     string          `yaml:"type" json:"type" toml:"type"` // "slack", "stdout", "syslog", or "none"
+	Slack    SlackConfig     `yaml:"slack,omitempty" json:"slack,omitempty" toml:"slack,omitempty"`
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty" json:"webhooks,omitempty" toml:"webhooks,omitempty"` // Additional webhook endpoints
+
+	WebhookConcurrency int `yaml:"webhook_concurrency,omitempty" json:"webhook_concurrency,omitempty" toml:"webhook_concurrency,omitempty"` // Endpoints sent to at once (default: 4, 1 = sequential)
+
+	RetryQueue bool `yaml:"retry_queue,omitempty" json:"retry_queue,omitempty" toml:"retry_queue,omitempty"` // Persist webhook deliveries that fail every retry and resend them in the background
+
+	Syslog SyslogConfig `yaml:"syslog,omitempty" json:"syslog,omitempty" toml:"syslog,omitempty"` // Settings for type "syslog"
+
+	FileLog bool `yaml:"file_log,omitempty" json:"file_log,omitempty" toml:"file_log,omitempty"` // Also write each notification as a readable line to logs/notifications.log, rotated daily
 }
 
 // WebhookConfig defines a webhook endpoint for notifications.
 type WebhookConfig struct {
-	URL     string            `yaml:"url" json:"url"`
-	Events  []string          `yaml:"events,omitempty" json:"events,omitempty"`   // Event types to send (empty = all)
-	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"` // Custom HTTP headers
-	Timeout int               `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Timeout in seconds (default: 10)
+	URL     string            `yaml:"url" json:"url" toml:"url"`
+	Events  []string          `yaml:"events,omitempty" json:"events,omitempty" toml:"events,omitempty"`    // Event types to send (empty = all)
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty" toml:"headers,omitempty"` // Custom HTTP headers
+	Timeout int               `yaml:"timeout,omitempty" json:"timeout,omitempty" toml:"timeout,omitempty"` // Timeout in seconds (default: 10)
+
+	Method       string `yaml:"method,omitempty" json:"method,omitempty" toml:"method,omitempty"`                      // HTTP method (default: POST)
+	BodyTemplate string `yaml:"body_template,omitempty" json:"body_template,omitempty" toml:"body_template,omitempty"` // Go template over the Event (default: Event JSON)
+	Retries      *int   `yaml:"retries,omitempty" json:"retries,omitempty" toml:"retries,omitempty"`                   // Retries after the first attempt (default: 2)
+
+	Format string `yaml:"format,omitempty" json:"format,omitempty" toml:"format,omitempty"` // Body preset: "generic" (Event JSON, default), "slack", "discord", or "teams"
+
+	IncludeHistory int `yaml:"include_history,omitempty" json:"include_history,omitempty" toml:"include_history,omitempty"` // Attach the agent's last N events to the payload's "history" (0 = off, max MaxIncludeHistory)
+
+	Critical bool `yaml:"critical,omitempty" json:"critical,omitempty" toml:"critical,omitempty"` // Log failures as errors, like the primary notifier's (default: best effort, logged at debug)
+}
+
+// Webhook body presets (WebhookConfig.Format).
+const (
+	WebhookFormatGeneric = "generic"
+	WebhookFormatSlack   = "slack"
+	WebhookFormatDiscord = "discord"
+	WebhookFormatTeams   = "teams"
+)
+
+// Normalized severities, lowest first, for output.severity_overrides.
+// Each notifier maps them to its own scale (syslog level, card color).
+const (
+	SeverityInfo    = "info"
+	SeverityNotice  = "notice"
+	SeverityWarning = "warning"
+	SeverityHigh    = "high"
+)
+
+// NotificationEventTypes are the event types a notification can have (see
+// notify.DetermineEventType), the keys of output.severity_overrides.
+var NotificationEventTypes = []string{
+	"activity", "cooling", "awaiting", "holding", "loop", "working", "compaction",
+	"process_start", "process_exit", "idle", "no_logs", "logs_resumed",
+}
+
+// DefaultWebhookConcurrency is how many webhook endpoints are sent to at once
+// when notify.webhook_concurrency is unset.
+const DefaultWebhookConcurrency = 4
+
+// DefaultWebhookRetries is the number of retries when a webhook doesn't set one.
+const DefaultWebhookRetries = 2
+
+// RetryCount returns the configured retries, or DefaultWebhookRetries if unset.
+func (w WebhookConfig) RetryCount() int {
+	if w.Retries == nil {
+		return DefaultWebhookRetries
+	}
+	return *w.Retries
+}
+
+// MaxIncludeHistory is the most recent events a webhook can include
+// (notify.webhooks[].include_history), and so the most kept per agent.
+const MaxIncludeHistory = 100
+
+// HistorySize returns how many recent events to keep per agent: the largest
+// include_history of any webhook (0 = none needed).
+func (n NotifyConfig) HistorySize() int {
+	size := 0
+	for _, wh := range n.Webhooks {
+		size = max(size, wh.IncludeHistory)
+	}
+	return size
 }
 
 // SlackConfig holds Slack-specific notification settings.
 type SlackConfig struct {
-	Webhook string `yaml:"webhook" json:"webhook"`
+	Webhook string `yaml:"webhook" json:"webhook" toml:"webhook"`
+}
+
+// SyslogConfig configures the syslog notifier.
+type SyslogConfig struct {
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty" toml:"tag,omitempty"` // Program name on each message (default: "firebell")
+}
+
+// DefaultSyslogTag is the syslog tag used when notify.syslog.tag is unset.
+const DefaultSyslogTag = "firebell"
+
+// SyslogTag returns notify.syslog.tag, or DefaultSyslogTag if unset.
+func (n NotifyConfig) SyslogTag() string {
+	if n.Syslog.Tag == "" {
+		return DefaultSyslogTag
+	}
+	return n.Syslog.Tag
 }
 
 // AgentsConfig defines which AI agents to monitor and their log paths.
 type AgentsConfig struct {
-	Enabled []string          `yaml:"enabled,omitempty" json:"enabled,omitempty"` // nil = auto-detect
-	Paths   map[string]string `yaml:"paths,omitempty" json:"paths,omitempty"`     // Override default paths
+	Enabled []string          `yaml:"enabled,omitempty" json:"enabled,omitempty" toml:"enabled,omitempty"` // nil = auto-detect
+	Paths   map[string]string `yaml:"paths,omitempty" json:"paths,omitempty" toml:"paths,omitempty"`       // Override default paths
+
+	IgnoreFiles  []string          `yaml:"ignore_files,omitempty" json:"ignore_files,omitempty" toml:"ignore_files,omitempty"`    // Globs of log files never tailed
+	DisplayNames map[string]string `yaml:"display_names,omitempty" json:"display_names,omitempty" toml:"display_names,omitempty"` // Override notification names ("{instance}" = per-instance label)
+
+	Keywords map[string]KeywordsConfig `yaml:"keywords,omitempty" json:"keywords,omitempty" toml:"keywords,omitempty"` // Extra matcher keywords per agent
+
+	MinCompleteLines int `yaml:"min_complete_lines,omitempty" json:"min_complete_lines,omitempty" toml:"min_complete_lines,omitempty"` // Text agents: ignore a completion until this many activity cues since the last one (0 = off)
+
+	Format map[string]string `yaml:"format,omitempty" json:"format,omitempty" toml:"format,omitempty"` // Log format per agent, overriding its matcher: "openai_chat"
+
+	ContinuationPrefix map[string]string `yaml:"continuation_prefix,omitempty" json:"continuation_prefix,omitempty" toml:"continuation_prefix,omitempty"` // Lines starting with this prefix (e.g. indentation) continue the previous line's entry, per agent
+
+	NotifyDisabled []string `yaml:"notify_disabled,omitempty" json:"notify_disabled,omitempty" toml:"notify_disabled,omitempty"` // Agents whose events only go to the event file and socket, never the notifier
+
+	CompletionDetection map[string]bool `yaml:"completion_detection,omitempty" json:"completion_detection,omitempty" toml:"completion_detection,omitempty"` // Per-agent override of monitor.completion_detection, e.g. off for an agent that writes continuously
+}
+
+// NotifyEnabled reports whether agent's events go to the configured notifier
+// (Slack, stdout, webhooks), i.e. it isn't listed in agents.notify_disabled.
+func (a AgentsConfig) NotifyEnabled(agent string) bool {
+	return !slices.Contains(a.NotifyDisabled, agent)
+}
+
+// CompletionDetectionFor reports whether agent gets quiet-period
+// notifications (Cooling, Awaiting, and the like): its entry in
+// agents.completion_detection if it has one, else
+// monitor.completion_detection.
+func (c *Config) CompletionDetectionFor(agent string) bool {
+	if on, ok := c.Agents.CompletionDetection[agent]; ok {
+		return on
+	}
+	return c.Monitor.CompletionDetection
+}
+
+// KeywordsConfig lists extra keywords for an agent's text-based matcher,
+// added to its built-in English keywords (e.g. for localized logs).
+type KeywordsConfig struct {
+	Complete []string `yaml:"complete,omitempty" json:"complete,omitempty" toml:"complete,omitempty"`
+	Holding  []string `yaml:"holding,omitempty" json:"holding,omitempty" toml:"holding,omitempty"`
+	Activity []string `yaml:"activity,omitempty" json:"activity,omitempty" toml:"activity,omitempty"`
 }
 
 // MonitorConfig defines monitoring behavior settings.
 type MonitorConfig struct {
-	ProcessTracking     bool `yaml:"process_tracking" json:"process_tracking"`
-	CompletionDetection bool `yaml:"completion_detection" json:"completion_detection"`
-	QuietSeconds        int  `yaml:"quiet_seconds" json:"quiet_seconds"`
-	PerInstance         bool `yaml:"per_instance" json:"per_instance"` // Track each instance separately (by log file)
+	ProcessTracking     bool `yaml:"process_tracking" json:"process_tracking" toml:"process_tracking"`
+	CompletionDetection bool `yaml:"completion_detection" json:"completion_detection" toml:"completion_detection"`
+	QuietSeconds        int  `yaml:"quiet_seconds" json:"quiet_seconds" toml:"quiet_seconds"`
+	BackfillSeconds     int  `yaml:"backfill_seconds" json:"backfill_seconds" toml:"backfill_seconds"`    // Seed state from this much log history on start (0 = off)
+	LoopThreshold       int  `yaml:"loop_threshold" json:"loop_threshold" toml:"loop_threshold"`          // Alert when the same tool request repeats more than this (0 = off)
+	ImmediateHolding    bool `yaml:"immediate_holding" json:"immediate_holding" toml:"immediate_holding"` // Send "Holding" as soon as a tool is requested instead of after quiet
+
+	PerInstance PerInstanceMode `yaml:"per_instance" json:"per_instance" toml:"per_instance"` // Track each instance separately (by log file): true, false, or "auto"
+
+	WorkingReminderSeconds int `yaml:"working_reminder_seconds,omitempty" json:"working_reminder_seconds,omitempty" toml:"working_reminder_seconds,omitempty"` // Remind on this interval while an agent stays active (0 = off)
+	ActiveWindowSeconds    int `yaml:"active_window_seconds,omitempty" json:"active_window_seconds,omitempty" toml:"active_window_seconds,omitempty"`          // Auto-detect only agents with logs modified this recently (0 = any existing log path)
+
+	Focus bool `yaml:"focus,omitempty" json:"focus,omitempty" toml:"focus,omitempty"` // Only the most recently active agent/instance sends quiet notifications
+
+	WaitForAgents bool `yaml:"wait_for_agents,omitempty" json:"wait_for_agents,omitempty" toml:"wait_for_agents,omitempty"` // Keep running when auto-detect finds no agents and pick them up as they appear
+
+	SensitiveTools []string `yaml:"sensitive_tools,omitempty" json:"sensitive_tools,omitempty" toml:"sensitive_tools,omitempty"` // Tools or commands (e.g. "write_file", "git push") whose Holding is sent at once at high priority
+
+	NotifyFirstHolding bool `yaml:"notify_first_holding,omitempty" json:"notify_first_holding,omitempty" toml:"notify_first_holding,omitempty"` // Send a turn's first "Holding" at once; later ones in the turn wait for quiet
+
+	IdleAlertSeconds int     `yaml:"idle_alert_seconds,omitempty" json:"idle_alert_seconds,omitempty" toml:"idle_alert_seconds,omitempty"` // Send "Idle" when the tracked process idles this long mid-turn with silent logs (0 = off)
+	IdleCPUPercent   float64 `yaml:"idle_cpu_percent,omitempty" json:"idle_cpu_percent,omitempty" toml:"idle_cpu_percent,omitempty"`       // CPU below which the process counts as idle (0 = DefaultIdleCPUPercent)
+
+	MinProcessLifetimeSec int `yaml:"min_process_lifetime_sec,omitempty" json:"min_process_lifetime_sec,omitempty" toml:"min_process_lifetime_sec,omitempty"` // Only track agent processes that have run this long, and hold back "Process Exit" as long in case one replaces it (0 = off)
+
+	CollapseCuesMS int `yaml:"collapse_cues_ms,omitempty" json:"collapse_cues_ms,omitempty" toml:"collapse_cues_ms,omitempty"` // Activity cues this close after another from the same log only refresh its time: no history entry or verbose notification (0 = off)
+}
+
+// PerInstanceMode selects per-instance tracking. In config files it is a
+// bool or the string "auto".
+type PerInstanceMode string
+
+const (
+	PerInstanceOff  PerInstanceMode = "false" // One state per agent
+	PerInstanceOn   PerInstanceMode = "true"  // One state per log file
+	PerInstanceAuto PerInstanceMode = "auto"  // Per log file only for agents with one log per session
+)
+
+// IsOn reports whether every agent is tracked per instance.
+func (m PerInstanceMode) IsOn() bool {
+	return m == PerInstanceOn
+}
+
+// IsAuto reports whether the mode is chosen per agent.
+func (m PerInstanceMode) IsAuto() bool {
+	return m == PerInstanceAuto
+}
+
+// parsePerInstanceMode accepts "true", "false", or "auto" (any case).
+func parsePerInstanceMode(s string) (PerInstanceMode, error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return PerInstanceOn, nil
+	case "false":
+		return PerInstanceOff, nil
+	case "auto":
+		return PerInstanceAuto, nil
+	}
+	return "", fmt.Errorf("per_instance must be true, false, or \"auto\", got %q", s)
+}
+
+// UnmarshalYAML accepts a bool or "auto".
+func (m *PerInstanceMode) UnmarshalYAML(node *yaml.Node) error {
+	mode, err := parsePerInstanceMode(node.Value)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// MarshalYAML writes on/off as a bool so saved configs stay unchanged.
+func (m PerInstanceMode) MarshalYAML() (any, error) {
+	if m.IsAuto() {
+		return string(m), nil
+	}
+	return m.IsOn(), nil
+}
+
+// UnmarshalJSON accepts a bool or "auto".
+func (m *PerInstanceMode) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return m.UnmarshalTOML(v)
+}
+
+// MarshalJSON writes on/off as a bool.
+func (m PerInstanceMode) MarshalJSON() ([]byte, error) {
+	if m.IsAuto() {
+		return json.Marshal(string(m))
+	}
+	return json.Marshal(m.IsOn())
+}
+
+// UnmarshalTOML accepts a bool or "auto".
+func (m *PerInstanceMode) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case bool:
+		*m = PerInstanceOff
+		if v {
+			*m = PerInstanceOn
+		}
+		return nil
+	case string:
+		mode, err := parsePerInstanceMode(v)
+		if err != nil {
+			return err
+		}
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("per_instance must be true, false, or \"auto\", got %v", v)
 }
 
 // OutputConfig defines notification output formatting.
 type OutputConfig struct {
-	Verbosity       string `yaml:"verbosity" json:"verbosity"` // "minimal" | "normal" | "verbose"
-	IncludeSnippets bool   `yaml:"include_snippets" json:"include_snippets"`
-	SnippetLines    int    `yaml:"snippet_lines" json:"snippet_lines"`
+	Verbosity       string `yaml:"verbosity" json:"verbosity" toml:"verbosity"` // "minimal" | "normal" | "verbose"
+	IncludeSnippets bool   `yaml:"include_snippets" json:"include_snippets" toml:"include_snippets"`
+	SnippetLines    int    `yaml:"snippet_lines" json:"snippet_lines" toml:"snippet_lines"`
+	TimeFormat      string `yaml:"time_format,omitempty" json:"time_format,omitempty" toml:"time_format,omitempty"` // Go time layout for displayed timestamps (default: 15:04:05)
+	Timezone        string `yaml:"timezone,omitempty" json:"timezone,omitempty" toml:"timezone,omitempty"`          // IANA name or "UTC" (default: local)
+	Theme           string `yaml:"theme,omitempty" json:"theme,omitempty" toml:"theme,omitempty"`                   // stdout format: "plain" (default), "emoji", "compact", or "json"
+
+	SuppressReasons []string `yaml:"suppress_reasons,omitempty" json:"suppress_reasons,omitempty" toml:"suppress_reasons,omitempty"` // Match reasons never sent as activity notifications (e.g. "section marker")
+
+	MaxPerMinute int `yaml:"max_per_minute,omitempty" json:"max_per_minute,omitempty" toml:"max_per_minute,omitempty"` // Cap on notifications a minute to the primary notifier and webhooks (token bucket), the rest dropped there (0 = no cap)
+
+	IncludeRawMatch bool `yaml:"include_raw_match,omitempty" json:"include_raw_match,omitempty" toml:"include_raw_match,omitempty"` // Attach the matched log line to verbose activity notifications, for debugging matchers
+
+	SeverityOverrides map[string]string `yaml:"severity_overrides,omitempty" json:"severity_overrides,omitempty" toml:"severity_overrides,omitempty"` // Event type -> severity ("info", "notice", "warning", "high"), e.g. cooling: high
+}
+
+// SuppressesReason reports whether activity notifications with the matcher
+// reason are dropped, i.e. it's listed in output.suppress_reasons. Cues are
+// still recorded, so quiet-period notifications are unaffected.
+func (o OutputConfig) SuppressesReason(reason string) bool {
+	return slices.Contains(o.SuppressReasons, reason)
 }
 
+// DefaultTimeFormat is the time layout used when output.time_format is unset.
+const DefaultTimeFormat = "15:04:05"
+
 // AdvancedConfig holds advanced/power-user settings.
 // These are typically not changed from defaults.
 type AdvancedConfig struct {
-	PollIntervalMS int  `yaml:"poll_interval_ms" json:"poll_interval_ms"`
-	MaxRecentFiles int  `yaml:"max_recent_files" json:"max_recent_files"`
-	WatchDepth     int  `yaml:"watch_depth" json:"watch_depth"`
-	ForcePolling   bool `yaml:"force_polling" json:"force_polling"` // Use polling instead of fsnotify
+	PollIntervalMS int  `yaml:"poll_interval_ms" json:"poll_interval_ms" toml:"poll_interval_ms"`
+	MaxRecentFiles int  `yaml:"max_recent_files" json:"max_recent_files" toml:"max_recent_files"`
+	WatchDepth     int  `yaml:"watch_depth" json:"watch_depth" toml:"watch_depth"`
+	ForcePolling   bool `yaml:"force_polling" json:"force_polling" toml:"force_polling"` // Use polling instead of fsnotify
+
+	MaxLineBytes int `yaml:"max_line_bytes,omitempty" json:"max_line_bytes,omitempty" toml:"max_line_bytes,omitempty"` // Longest log line buffered; longer lines are skipped (0 = default)
+
+	AllowedRoots []string `yaml:"allowed_roots,omitempty" json:"allowed_roots,omitempty" toml:"allowed_roots,omitempty"` // Only watch paths under these directories (empty = anywhere)
+
+	LogExtensions []string `yaml:"log_extensions,omitempty" json:"log_extensions,omitempty" toml:"log_extensions,omitempty"` // Extensions of log files to discover, replacing .log, .txt, .json, .jsonl (empty = those; list any to keep)
 }
 
+// DefaultMaxLineBytes is the line length cap used when advanced.max_line_bytes
+// is unset. It is generous because agent transcripts can embed whole files.
+const DefaultMaxLineBytes = 16 * 1024 * 1024
+
 // DefaultConfig returns a Config with sensible defaults for v2.0.
 func DefaultConfig() *Config {
 	return &Config{
@@ -95,7 +384,8 @@ func DefaultConfig() *Config {
 			ProcessTracking:     true,
 			CompletionDetection: true,
 			QuietSeconds:        15,
-			PerInstance:         true, // Track each instance separately by default
+			PerInstance:         PerInstanceOn, // Track each instance separately by default
+			LoopThreshold:       5,
 		},
 		Output: OutputConfig{
 			Verbosity:       "normal",
@@ -126,22 +416,155 @@ func (c *Config) QuietDuration() time.Duration {
 	return time.Duration(c.Monitor.QuietSeconds) * time.Second
 }
 
+// CollapseCuesWindow returns the window within which repeated activity
+// cues are collapsed (0 = off).
+func (c *Config) CollapseCuesWindow() time.Duration {
+	return time.Duration(c.Monitor.CollapseCuesMS) * time.Millisecond
+}
+
+// WorkingReminderInterval returns the still-working reminder interval (0 = off).
+func (c *Config) WorkingReminderInterval() time.Duration {
+	return time.Duration(c.Monitor.WorkingReminderSeconds) * time.Second
+}
+
+// DefaultIdleCPUPercent is the idle CPU threshold used when
+// monitor.idle_cpu_percent is unset.
+const DefaultIdleCPUPercent = 1.0
+
+// IdleAlertDuration returns how long the tracked process must idle before
+// an "Idle" notification (0 = off).
+func (c *Config) IdleAlertDuration() time.Duration {
+	return time.Duration(c.Monitor.IdleAlertSeconds) * time.Second
+}
+
+// IdleCPUThreshold returns the CPU percentage below which the tracked
+// process is idle.
+func (c *Config) IdleCPUThreshold() float64 {
+	if c.Monitor.IdleCPUPercent <= 0 {
+		return DefaultIdleCPUPercent
+	}
+	return c.Monitor.IdleCPUPercent
+}
+
+// MinProcessLifetime returns how long an agent process must have run before
+// it is tracked (0 = any).
+func (c *Config) MinProcessLifetime() time.Duration {
+	return time.Duration(c.Monitor.MinProcessLifetimeSec) * time.Second
+}
+
+// ActiveWindow returns the auto-detection recency window (0 = no limit).
+func (c *Config) ActiveWindow() time.Duration {
+	return time.Duration(c.Monitor.ActiveWindowSeconds) * time.Second
+}
+
+// BackfillDuration returns the startup backfill window as a time.Duration.
+func (c *Config) BackfillDuration() time.Duration {
+	return time.Duration(c.Monitor.BackfillSeconds) * time.Second
+}
+
+// TimeLayout returns the layout used to render timestamps.
+func (c *Config) TimeLayout() string {
+	if c.Output.TimeFormat == "" {
+		return DefaultTimeFormat
+	}
+	return c.Output.TimeFormat
+}
+
+// LineLimit returns the longest log line the tailers will buffer.
+func (c *Config) LineLimit() int {
+	if c.Advanced.MaxLineBytes == 0 {
+		return DefaultMaxLineBytes
+	}
+	return c.Advanced.MaxLineBytes
+}
+
+// TimeLocation returns the timezone used to render timestamps.
+// Falls back to local time if the timezone is unset or unknown.
+func (c *Config) TimeLocation() *time.Location {
+	if c.Output.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Output.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// FormatTime renders t with the configured time format and timezone.
+func (c *Config) FormatTime(t time.Time) string {
+	return t.In(c.TimeLocation()).Format(c.TimeLayout())
+}
+
 // Validate checks that the configuration is valid and returns an error if not.
 func (c *Config) Validate() error {
 	// Notification validation
-	if c.Notify.Type != "slack" && c.Notify.Type != "stdout" {
-		return &ValidationError{Field: "notify.type", Message: "must be 'slack' or 'stdout'"}
+	validNotify := map[string]bool{"slack": true, "stdout": true, "syslog": true, "none": true}
+	if !validNotify[c.Notify.Type] {
+		return &ValidationError{Field: "notify.type", Message: "must be 'slack', 'stdout', 'syslog', or 'none'"}
 	}
 
 	if c.Notify.Type == "slack" && c.Notify.Slack.Webhook == "" {
 		return &ValidationError{Field: "notify.slack.webhook", Message: "Slack webhook URL is required when type is 'slack'"}
 	}
 
+	// Webhook validation
+	for i, wh := range c.Notify.Webhooks {
+		field := fmt.Sprintf("notify.webhooks[%d]", i)
+		switch strings.ToUpper(wh.Method) {
+		case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return &ValidationError{Field: field + ".method", Message: "must be 'POST', 'PUT', or 'PATCH'"}
+		}
+		if wh.Retries != nil && (*wh.Retries < 0 || *wh.Retries > 10) {
+			return &ValidationError{Field: field + ".retries", Message: "must be between 0 and 10"}
+		}
+		if wh.IncludeHistory < 0 || wh.IncludeHistory > MaxIncludeHistory {
+			return &ValidationError{Field: field + ".include_history", Message: fmt.Sprintf("must be between 0 and %d", MaxIncludeHistory)}
+		}
+		if wh.BodyTemplate != "" {
+			if _, err := template.New("body").Parse(wh.BodyTemplate); err != nil {
+				return &ValidationError{Field: field + ".body_template", Message: err.Error()}
+			}
+		}
+		switch wh.Format {
+		case "", WebhookFormatGeneric:
+		case WebhookFormatSlack, WebhookFormatDiscord, WebhookFormatTeams:
+			if wh.BodyTemplate != "" {
+				return &ValidationError{Field: field + ".format", Message: "body_template can only be used with the 'generic' format"}
+			}
+		default:
+			return &ValidationError{Field: field + ".format", Message: "must be 'generic', 'slack', 'discord', or 'teams'"}
+		}
+	}
+	if c.Notify.WebhookConcurrency < 0 {
+		return &ValidationError{Field: "notify.webhook_concurrency", Message: "cannot be negative"}
+	}
+
 	// Output verbosity validation
 	validVerbosity := map[string]bool{"minimal": true, "normal": true, "verbose": true}
 	if !validVerbosity[c.Output.Verbosity] {
 		return &ValidationError{Field: "output.verbosity", Message: "must be 'minimal', 'normal', or 'verbose'"}
 	}
+	validTheme := map[string]bool{"": true, "plain": true, "emoji": true, "compact": true, "json": true}
+	if !validTheme[c.Output.Theme] {
+		return &ValidationError{Field: "output.theme", Message: "must be 'plain', 'emoji', 'compact', or 'json'"}
+	}
+
+	if c.Output.MaxPerMinute < 0 {
+		return &ValidationError{Field: "output.max_per_minute", Message: "cannot be negative"}
+	}
+
+	for event, severity := range c.Output.SeverityOverrides {
+		if !slices.Contains(NotificationEventTypes, event) {
+			return &ValidationError{Field: "output.severity_overrides." + event, Message: "unknown event type; must be one of " + strings.Join(NotificationEventTypes, ", ")}
+		}
+		switch severity {
+		case SeverityInfo, SeverityNotice, SeverityWarning, SeverityHigh:
+		default:
+			return &ValidationError{Field: "output.severity_overrides." + event, Message: "must be 'info', 'notice', 'warning', or 'high'"}
+		}
+	}
 
 	// Advanced config validation
 	if c.Advanced.PollIntervalMS < 100 {
@@ -152,10 +575,102 @@ func (c *Config) Validate() error {
 		return &ValidationError{Field: "advanced.max_recent_files", Message: "must be at least 1"}
 	}
 
+	if c.Advanced.MaxLineBytes < 0 {
+		return &ValidationError{Field: "advanced.max_line_bytes", Message: "cannot be negative"}
+	}
+
+	for _, root := range c.Advanced.AllowedRoots {
+		if !filepath.IsAbs(ExpandPath(root)) {
+			return &ValidationError{Field: "advanced.allowed_roots", Message: fmt.Sprintf("%q must be an absolute path (or start with ~)", root)}
+		}
+	}
+
+	for _, ext := range c.Advanced.LogExtensions {
+		if strings.TrimPrefix(ext, ".") == "" || strings.ContainsAny(ext, `/\*?`) {
+			return &ValidationError{Field: "advanced.log_extensions", Message: fmt.Sprintf("%q is not a file extension", ext)}
+		}
+	}
+
 	if c.Monitor.QuietSeconds < 0 {
 		return &ValidationError{Field: "monitor.quiet_seconds", Message: "cannot be negative"}
 	}
 
+	if c.Monitor.BackfillSeconds < 0 {
+		return &ValidationError{Field: "monitor.backfill_seconds", Message: "cannot be negative"}
+	}
+	if c.Monitor.CollapseCuesMS < 0 {
+		return &ValidationError{Field: "monitor.collapse_cues_ms", Message: "cannot be negative"}
+	}
+	if c.Monitor.ActiveWindowSeconds < 0 {
+		return &ValidationError{Field: "monitor.active_window_seconds", Message: "cannot be negative"}
+	}
+	for i, pattern := range c.Agents.IgnoreFiles {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return &ValidationError{Field: fmt.Sprintf("agents.ignore_files[%d]", i), Message: "invalid glob pattern"}
+		}
+	}
+	for agent, kw := range c.Agents.Keywords {
+		lists := []struct {
+			kind  string
+			words []string
+		}{{"complete", kw.Complete}, {"holding", kw.Holding}, {"activity", kw.Activity}}
+		for _, list := range lists {
+			for i, w := range list.words {
+				// An empty keyword would match every line
+				if strings.TrimSpace(w) == "" {
+					return &ValidationError{Field: fmt.Sprintf("agents.keywords.%s.%s[%d]", agent, list.kind, i), Message: "cannot be empty"}
+				}
+			}
+		}
+	}
+	if c.Agents.MinCompleteLines < 0 {
+		return &ValidationError{Field: "agents.min_complete_lines", Message: "cannot be negative"}
+	}
+	for agent, prefix := range c.Agents.ContinuationPrefix {
+		// An empty prefix would join every line into one
+		if prefix == "" {
+			return &ValidationError{Field: "agents.continuation_prefix." + agent, Message: "cannot be empty"}
+		}
+	}
+	for agent, format := range c.Agents.Format {
+		if format != "openai_chat" {
+			return &ValidationError{Field: "agents.format." + agent, Message: "must be 'openai_chat'"}
+		}
+	}
+	switch c.Monitor.PerInstance {
+	case "", PerInstanceOn, PerInstanceOff, PerInstanceAuto:
+	default:
+		return &ValidationError{Field: "monitor.per_instance", Message: "must be true, false, or \"auto\""}
+	}
+	if c.Monitor.LoopThreshold < 0 {
+		return &ValidationError{Field: "monitor.loop_threshold", Message: "cannot be negative"}
+	}
+	if c.Monitor.WorkingReminderSeconds < 0 {
+		return &ValidationError{Field: "monitor.working_reminder_seconds", Message: "cannot be negative"}
+	}
+	if c.Monitor.IdleAlertSeconds < 0 {
+		return &ValidationError{Field: "monitor.idle_alert_seconds", Message: "cannot be negative"}
+	}
+	if c.Monitor.IdleCPUPercent < 0 {
+		return &ValidationError{Field: "monitor.idle_cpu_percent", Message: "cannot be negative"}
+	}
+	if c.Monitor.MinProcessLifetimeSec < 0 {
+		return &ValidationError{Field: "monitor.min_process_lifetime_sec", Message: "cannot be negative"}
+	}
+
+	if c.Output.Timezone != "" {
+		if _, err := time.LoadLocation(c.Output.Timezone); err != nil {
+			return &ValidationError{Field: "output.timezone", Message: "unknown timezone " + c.Output.Timezone}
+		}
+	}
+
+	if c.Daemon.WSAddr != "" && !c.Daemon.Socket {
+		return &ValidationError{Field: "daemon.ws_addr", Message: "requires daemon.socket to be enabled"}
+	}
+	if c.Daemon.EventMaxLineBytes < 0 {
+		return &ValidationError{Field: "daemon.event_max_line_bytes", Message: "cannot be negative"}
+	}
+
 	return nil
 }
 
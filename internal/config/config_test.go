@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bufio"
 	"flag"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -64,6 +69,182 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "none notify type",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "none"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: false,
+		},
+		{
+			name: "syslog notify type",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "syslog", Syslog: SyslogConfig{Tag: "fb-prod"}},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhook with method and body template",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", Method: "PUT", BodyTemplate: `{"text":"{{.Message}}"}`},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhook with invalid body template",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", BodyTemplate: `{"text":"{{.Message"}`},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "body_template",
+		},
+		{
+			name: "webhook with discord format",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", Format: "discord"},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhook with unknown format",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", Format: "mattermost"},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "format",
+		},
+		{
+			name: "webhook with format and body template",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", Format: "teams", BodyTemplate: `{"text":"{{.Message}}"}`},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "format",
+		},
+		{
+			name: "webhook with invalid method",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", Method: "DELETE"},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "method",
+		},
+		{
+			name: "webhook with negative retries",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", Retries: intPtr(-1)},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "retries",
+		},
+		{
+			name: "webhook include_history too large",
+			cfg: &Config{
+				Notify: NotifyConfig{
+					Type: "stdout",
+					Webhooks: []WebhookConfig{
+						{URL: "http://example.com", IncludeHistory: MaxIncludeHistory + 1},
+					},
+				},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "include_history",
+		},
 		{
 			name: "invalid notify type",
 			cfg: &Config{
@@ -138,314 +319,1141 @@ func TestConfigValidate(t *testing.T) {
 			errMsg:  "max_recent_files",
 		},
 		{
-			name: "negative quiet_seconds",
+			name: "negative max_line_bytes",
 			cfg: &Config{
 				Notify: NotifyConfig{Type: "stdout"},
 				Output: OutputConfig{Verbosity: "normal"},
 				Advanced: AdvancedConfig{
 					PollIntervalMS: 800,
 					MaxRecentFiles: 3,
+					MaxLineBytes:   -1,
 				},
-				Monitor: MonitorConfig{QuietSeconds: -1},
+				Monitor: MonitorConfig{QuietSeconds: 20},
 			},
 			wantErr: true,
-			errMsg:  "quiet_seconds",
+			errMsg:  "max_line_bytes",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := tt.cfg.Validate()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if err != nil && tt.errMsg != "" {
-				if verr, ok := err.(*ValidationError); ok {
-					if !contains(verr.Field, tt.errMsg) {
-						t.Errorf("Expected error field to contain '%s', got '%s'", tt.errMsg, verr.Field)
-					}
-				}
-			}
-		})
-	}
-}
-
-func TestPollInterval(t *testing.T) {
-	cfg := DefaultConfig()
-	cfg.Advanced.PollIntervalMS = 1000
-
-	interval := cfg.PollInterval()
-	if interval.Milliseconds() != 1000 {
-		t.Errorf("Expected 1000ms, got %dms", interval.Milliseconds())
-	}
-}
-
-func TestQuietDuration(t *testing.T) {
-	cfg := DefaultConfig()
-	cfg.Monitor.QuietSeconds = 30
-
-	duration := cfg.QuietDuration()
-	if duration.Seconds() != 30 {
-		t.Errorf("Expected 30s, got %fs", duration.Seconds())
-	}
-}
-
-func contains(s, substr string) bool {
-	// Simple substring check
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
-func TestParseFlags(t *testing.T) {
-	// Save original args and restore after test
-	origArgs := os.Args
-	defer func() { os.Args = origArgs }()
-
-	tests := []struct {
-		name     string
-		args     []string
-		setupFn  func() *Flags
-		verifyFn func(t *testing.T, f *Flags)
-	}{
 		{
-			name: "default flags",
-			args: []string{"firebell"},
-			setupFn: func() *Flags {
-				return ParseFlags()
-			},
-			verifyFn: func(t *testing.T, f *Flags) {
-				if f.ConfigPath != "" {
-					t.Errorf("Expected empty ConfigPath, got %q", f.ConfigPath)
-				}
-				if f.Setup || f.Check || f.Stdout || f.Verbose || f.Version {
-					t.Error("Expected default bool flags to be false")
-				}
+			name: "relative allowed_roots",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+					AllowedRoots:   []string{"~/.claude", "logs"},
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
 			},
+			wantErr: true,
+			errMsg:  "allowed_roots",
 		},
 		{
-			name: "with config flag",
-			args: []string{"firebell", "--config", "/path/to/config.yaml"},
-			setupFn: func() *Flags {
-				return ParseFlags()
-			},
-			verifyFn: func(t *testing.T, f *Flags) {
-				if f.ConfigPath != "/path/to/config.yaml" {
-					t.Errorf("Expected ConfigPath=/path/to/config.yaml, got %q", f.ConfigPath)
-				}
+			name: "negative quiet_seconds",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: -1},
 			},
+			wantErr: true,
+			errMsg:  "quiet_seconds",
 		},
 		{
-			name: "with stdout flag",
-			args: []string{"firebell", "--stdout"},
-			setupFn: func() *Flags {
-				return ParseFlags()
-			},
-			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Stdout {
-					t.Error("Expected Stdout to be true")
-				}
+			name: "negative backfill_seconds",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20, BackfillSeconds: -1},
 			},
+			wantErr: true,
+			errMsg:  "backfill_seconds",
 		},
 		{
-			name: "with verbose flag",
-			args: []string{"firebell", "--verbose"},
-			setupFn: func() *Flags {
-				return ParseFlags()
-			},
-			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Verbose {
+			name: "negative active_window_seconds",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20, ActiveWindowSeconds: -1},
+			},
+			wantErr: true,
+			errMsg:  "active_window_seconds",
+		},
+		{
+			name: "negative collapse_cues_ms",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20, CollapseCuesMS: -1},
+			},
+			wantErr: true,
+			errMsg:  "collapse_cues_ms",
+		},
+		{
+			name: "invalid output theme",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal", Theme: "rainbow"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "output.theme",
+		},
+		{
+			name: "negative output max_per_minute",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal", MaxPerMinute: -1},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "output.max_per_minute",
+		},
+		{
+			name: "unknown output severity override",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal", SeverityOverrides: map[string]string{"cooling": "urgent"}},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "output.severity_overrides.cooling",
+		},
+		{
+			name: "unknown output severity override event",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal", SeverityOverrides: map[string]string{"coolng": "high"}},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "output.severity_overrides.coolng",
+		},
+		{
+			name: "empty log extension",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+					LogExtensions:  []string{".ndjson", "."},
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "advanced.log_extensions",
+		},
+		{
+			name: "log extension glob",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+					LogExtensions:  []string{"*.out"},
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "advanced.log_extensions",
+		},
+		{
+			name: "negative idle_alert_seconds",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20, IdleAlertSeconds: -1},
+			},
+			wantErr: true,
+			errMsg:  "monitor.idle_alert_seconds",
+		},
+		{
+			name: "negative min_process_lifetime_sec",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20, MinProcessLifetimeSec: -1},
+			},
+			wantErr: true,
+			errMsg:  "monitor.min_process_lifetime_sec",
+		},
+		{
+			name: "negative min_complete_lines",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+				Agents:  AgentsConfig{MinCompleteLines: -1},
+			},
+			wantErr: true,
+			errMsg:  "min_complete_lines",
+		},
+		{
+			name: "empty continuation prefix",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+				Agents:  AgentsConfig{ContinuationPrefix: map[string]string{"opencode": ""}},
+			},
+			wantErr: true,
+			errMsg:  "agents.continuation_prefix.opencode",
+		},
+		{
+			name: "unknown agent format",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Agents: AgentsConfig{Format: map[string]string{"myproxy": "anthropic"}},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "agents.format.myproxy",
+		},
+		{
+			name: "invalid ignore_files glob",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Agents: AgentsConfig{IgnoreFiles: []string{"[debug.log"}},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "ignore_files",
+		},
+		{
+			name: "empty agent keyword",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Agents: AgentsConfig{Keywords: map[string]KeywordsConfig{
+					"opencode": {Complete: []string{"terminé", " "}},
+				}},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "agents.keywords.opencode.complete[1]",
+		},
+		{
+			name: "negative webhook_concurrency",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout", WebhookConcurrency: -1},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "webhook_concurrency",
+		},
+		{
+			name: "negative loop_threshold",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20, LoopThreshold: -1},
+			},
+			wantErr: true,
+			errMsg:  "loop_threshold",
+		},
+		{
+			name: "ws_addr without socket",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+				Daemon:  DaemonConfig{WSAddr: "127.0.0.1:8765"},
+			},
+			wantErr: true,
+			errMsg:  "ws_addr",
+		},
+		{
+			name: "negative event_max_line_bytes",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+				Daemon:  DaemonConfig{EventMaxLineBytes: -1},
+			},
+			wantErr: true,
+			errMsg:  "event_max_line_bytes",
+		},
+		{
+			name: "unknown timezone",
+			cfg: &Config{
+				Notify: NotifyConfig{Type: "stdout"},
+				Output: OutputConfig{Verbosity: "normal", Timezone: "Mars/Olympus_Mons"},
+				Advanced: AdvancedConfig{
+					PollIntervalMS: 800,
+					MaxRecentFiles: 3,
+				},
+				Monitor: MonitorConfig{QuietSeconds: 20},
+			},
+			wantErr: true,
+			errMsg:  "timezone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && tt.errMsg != "" {
+				if verr, ok := err.(*ValidationError); ok {
+					if !contains(verr.Field, tt.errMsg) {
+						t.Errorf("Expected error field to contain '%s', got '%s'", tt.errMsg, verr.Field)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Advanced.PollIntervalMS = 1000
+
+	interval := cfg.PollInterval()
+	if interval.Milliseconds() != 1000 {
+		t.Errorf("Expected 1000ms, got %dms", interval.Milliseconds())
+	}
+}
+
+func TestQuietDuration(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Monitor.QuietSeconds = 30
+
+	duration := cfg.QuietDuration()
+	if duration.Seconds() != 30 {
+		t.Errorf("Expected 30s, got %fs", duration.Seconds())
+	}
+}
+
+func TestSyslogTag(t *testing.T) {
+	var n NotifyConfig
+	if got := n.SyslogTag(); got != "firebell" {
+		t.Errorf("Default tag = %q, want firebell", got)
+	}
+	n.Syslog.Tag = "fb-prod"
+	if got := n.SyslogTag(); got != "fb-prod" {
+		t.Errorf("Tag = %q, want fb-prod", got)
+	}
+}
+
+func TestNotifyEnabled(t *testing.T) {
+	cfg := DefaultConfig()
+	if !cfg.Agents.NotifyEnabled("claude") {
+		t.Error("Expected agents to notify by default")
+	}
+	cfg.Agents.NotifyDisabled = []string{"aider", "crush"}
+	if cfg.Agents.NotifyEnabled("crush") || !cfg.Agents.NotifyEnabled("claude") {
+		t.Errorf("NotifyEnabled wrong with notify_disabled %v", cfg.Agents.NotifyDisabled)
+	}
+}
+
+func TestSuppressesReason(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Output.SuppressesReason("section marker") {
+		t.Error("Expected no reasons suppressed by default")
+	}
+	cfg.Output.SuppressReasons = []string{"section marker", "response chunk"}
+	if !cfg.Output.SuppressesReason("response chunk") || cfg.Output.SuppressesReason("end turn") {
+		t.Errorf("SuppressesReason wrong with suppress_reasons %v", cfg.Output.SuppressReasons)
+	}
+}
+
+func contains(s, substr string) bool {
+	// Simple substring check
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConfigDirOverride(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fb1")
+
+	if got := ResolveConfigDir(dir); got != dir {
+		t.Errorf("ResolveConfigDir = %q, want %q", got, dir)
+	}
+	if got := ResolveConfigDir(""); got != DefaultConfigDir() {
+		t.Errorf("ResolveConfigDir(\"\") = %q, want default %q", got, DefaultConfigDir())
+	}
+
+	if got := ResolveConfigPath("", dir); got != filepath.Join(dir, "config.yaml") {
+		t.Errorf("ResolveConfigPath = %q, want config.yaml under %q", got, dir)
+	}
+	if got := ResolveConfigPath("/etc/fb.yaml", dir); got != "/etc/fb.yaml" {
+		t.Errorf("Explicit --config should win, got %q", got)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ApplyConfigDir(dir)
+	if cfg.Daemon.EventFilePath != filepath.Join(dir, "events.jsonl") {
+		t.Errorf("EventFilePath = %q, want under %q", cfg.Daemon.EventFilePath, dir)
+	}
+	if cfg.Daemon.SocketPath != filepath.Join(dir, "firebell.sock") {
+		t.Errorf("SocketPath = %q, want under %q", cfg.Daemon.SocketPath, dir)
+	}
+	if cfg.Daemon.ReadyFile != filepath.Join(dir, "ready") {
+		t.Errorf("ReadyFile = %q, want under %q", cfg.Daemon.ReadyFile, dir)
+	}
+	if cfg.Daemon.RetryQueueFile != filepath.Join(dir, "retry-queue.json") {
+		t.Errorf("RetryQueueFile = %q, want under %q", cfg.Daemon.RetryQueueFile, dir)
+	}
+
+	// Explicit paths are preserved
+	cfg = DefaultConfig()
+	cfg.Daemon.EventFilePath = "/var/tmp/events.jsonl"
+	cfg.ApplyConfigDir(dir)
+	if cfg.Daemon.EventFilePath != "/var/tmp/events.jsonl" {
+		t.Errorf("Explicit EventFilePath overwritten: %q", cfg.Daemon.EventFilePath)
+	}
+}
+
+func TestConfigDirExpandsPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FIREBELL_TEST_RUN", "run")
+
+	cfg := DefaultConfig()
+	cfg.Daemon.EventFilePath = "~/logs/events.jsonl"
+	cfg.Daemon.SocketPath = "$HOME/${FIREBELL_TEST_RUN}/fb.sock"
+	cfg.ApplyConfigDir(t.TempDir())
+
+	if want := filepath.Join(home, "logs", "events.jsonl"); cfg.Daemon.EventFilePath != want {
+		t.Errorf("EventFilePath = %q, want %q", cfg.Daemon.EventFilePath, want)
+	}
+	if want := filepath.Join(home, "run", "fb.sock"); cfg.Daemon.SocketPath != want {
+		t.Errorf("SocketPath = %q, want %q", cfg.Daemon.SocketPath, want)
+	}
+
+	tests := map[string]string{
+		"~":            home,
+		"~/a/b":        filepath.Join(home, "a", "b"),
+		"/abs/path":    "/abs/path",
+		"~other/x":     "~other/x",
+		"rel/$UNSET_X": "rel/",
+	}
+	for in, want := range tests {
+		if got := ExpandPath(in); got != want {
+			t.Errorf("ExpandPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Defaults live under ~/.firebell
+	paths, err := ResolvePaths("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(home, ".firebell")
+	want := Paths{Config: filepath.Join(base, "config.yaml"), EventFile: filepath.Join(base, "events.jsonl"), Socket: filepath.Join(base, "firebell.sock")}
+	if paths != want {
+		t.Errorf("Default paths = %+v, want %+v", paths, want)
+	}
+
+	// --config-dir moves them all
+	dir := t.TempDir()
+	paths, err = ResolvePaths("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Paths{Config: filepath.Join(dir, "config.yaml"), EventFile: filepath.Join(dir, "events.jsonl"), Socket: filepath.Join(dir, "firebell.sock")}
+	if paths != want {
+		t.Errorf("--config-dir paths = %+v, want %+v", paths, want)
+	}
+
+	// --config, paths set in it, and FIREBELL_* overrides win
+	configPath := filepath.Join(t.TempDir(), "custom.yaml")
+	cfg := DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	cfg.Daemon.EventFilePath = "~/events/firebell.jsonl"
+	if err := Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FIREBELL_DAEMON_SOCKET_PATH", "/run/firebell.sock")
+	paths, err = ResolvePaths(configPath, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Paths{Config: configPath, EventFile: filepath.Join(home, "events", "firebell.jsonl"), Socket: "/run/firebell.sock"}
+	if paths != want {
+		t.Errorf("Overridden paths = %+v, want %+v", paths, want)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	// Save original args and restore after test
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
+	tests := []struct {
+		name     string
+		args     []string
+		setupFn  func() *Flags
+		verifyFn func(t *testing.T, f *Flags)
+	}{
+		{
+			name: "default flags",
+			args: []string{"firebell"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.ConfigPath != "" {
+					t.Errorf("Expected empty ConfigPath, got %q", f.ConfigPath)
+				}
+				if f.Setup || f.Check || f.Stdout || f.Verbose || f.Version {
+					t.Error("Expected default bool flags to be false")
+				}
+			},
+		},
+		{
+			name: "print path flags",
+			args: []string{"firebell", "--print-event-path", "--print-socket-path", "--config-dir", "/tmp/fb"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.PrintConfigPath || !f.PrintEventPath || !f.PrintSocketPath || f.ConfigDir != "/tmp/fb" {
+					t.Errorf("Flags = %+v, want the event and socket paths printed for /tmp/fb", f)
+				}
+			},
+		},
+		{
+			name: "test matcher",
+			args: []string{"firebell", "--test-matcher", "claude", `{"type":"assistant"}`},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.TestMatcher || f.TestMatcherAgent != "claude" || f.TestMatcherLine != `{"type":"assistant"}` {
+					t.Errorf("Flags = %+v, want the claude matcher run on the line", f)
+				}
+			},
+		},
+		{
+			name: "with config flag",
+			args: []string{"firebell", "--config", "/path/to/config.yaml"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.ConfigPath != "/path/to/config.yaml" {
+					t.Errorf("Expected ConfigPath=/path/to/config.yaml, got %q", f.ConfigPath)
+				}
+			},
+		},
+		{
+			name: "with stdout flag",
+			args: []string{"firebell", "--stdout"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Stdout {
+					t.Error("Expected Stdout to be true")
+				}
+			},
+		},
+		{
+			name: "with pid flag",
+			args: []string{"firebell", "--pid", "12345"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.PID != 12345 {
+					t.Errorf("Expected PID=12345, got %d", f.PID)
+				}
+			},
+		},
+		{
+			name: "start with pid flag",
+			args: []string{"firebell", "start", "--pid", "4242"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStart || f.PID != 4242 {
+					t.Errorf("Expected DaemonStart with PID=4242, got start=%v pid=%d", f.DaemonStart, f.PID)
+				}
+			},
+		},
+		{
+			name: "with verbose flag",
+			args: []string{"firebell", "--verbose"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Verbose {
 					t.Error("Expected Verbose to be true")
 				}
 			},
 		},
 		{
-			name: "with agent flag",
-			args: []string{"firebell", "--agent", "claude"},
+			name: "with agent flag",
+			args: []string{"firebell", "--agent", "claude"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.Agent != "claude" {
+					t.Errorf("Expected Agent=claude, got %q", f.Agent)
+				}
+			},
+		},
+		{
+			name: "with backfill flag",
+			args: []string{"firebell", "--backfill", "2m"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.Backfill != 2*time.Minute {
+					t.Errorf("Expected Backfill=2m, got %v", f.Backfill)
+				}
+			},
+		},
+		{
+			name: "with stdin flag",
+			args: []string{"firebell", "--stdin", "--agent", "claude"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Stdin || f.Agent != "claude" {
+					t.Errorf("Expected stdin for claude, got stdin=%v agent=%q", f.Stdin, f.Agent)
+				}
+			},
+		},
+		{
+			name: "start with active-window flag",
+			args: []string{"firebell", "start", "--active-window", "24h"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStart || f.ActiveWindow != 24*time.Hour {
+					t.Errorf("Expected DaemonStart with ActiveWindow=24h, got %v/%v", f.DaemonStart, f.ActiveWindow)
+				}
+			},
+		},
+		{
+			name: "with profile flag",
+			args: []string{"firebell", "--profile", "work"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.Profile != "work" {
+					t.Errorf("Expected Profile 'work', got %q", f.Profile)
+				}
+			},
+		},
+		{
+			name: "start with profile flag",
+			args: []string{"firebell", "start", "--profile", "personal"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStart || f.Profile != "personal" {
+					t.Errorf("Expected DaemonStart with Profile 'personal', got %v/%q", f.DaemonStart, f.Profile)
+				}
+			},
+		},
+		{
+			name: "start with json-logs flag",
+			args: []string{"firebell", "start", "--json-logs"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStart || !f.JSONLogs {
+					t.Errorf("Expected DaemonStart with JSONLogs, got %v/%v", f.DaemonStart, f.JSONLogs)
+				}
+			},
+		},
+		{
+			name: "start subcommand",
+			args: []string{"firebell", "start"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStart {
+					t.Error("Expected DaemonStart to be true")
+				}
+			},
+		},
+		{
+			name: "stop subcommand",
+			args: []string{"firebell", "stop"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStop {
+					t.Error("Expected DaemonStop to be true")
+				}
+			},
+		},
+		{
+			name: "restart subcommand",
+			args: []string{"firebell", "restart"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonRestart {
+					t.Error("Expected DaemonRestart to be true")
+				}
+			},
+		},
+		{
+			name: "status subcommand",
+			args: []string{"firebell", "status"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStatus {
+					t.Error("Expected DaemonStatus to be true")
+				}
+			},
+		},
+		{
+			name: "logs subcommand",
+			args: []string{"firebell", "logs"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonLogs {
+					t.Error("Expected DaemonLogs to be true")
+				}
+			},
+		},
+		{
+			name: "logs subcommand with follow",
+			args: []string{"firebell", "logs", "-f"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonLogs {
+					t.Error("Expected DaemonLogs to be true")
+				}
+				if !f.DaemonFollow {
+					t.Error("Expected DaemonFollow to be true")
+				}
+			},
+		},
+		{
+			name: "wrap subcommand with command",
+			args: []string{"firebell", "wrap", "--", "claude"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Wrap {
+					t.Error("Expected Wrap to be true")
+				}
+				if len(f.WrapArgs) != 1 || f.WrapArgs[0] != "claude" {
+					t.Errorf("Expected WrapArgs=[claude], got %v", f.WrapArgs)
+				}
+				if f.WrapName != "claude" {
+					t.Errorf("Expected default WrapName=claude, got %q", f.WrapName)
+				}
+			},
+		},
+		{
+			name: "wrap subcommand with name flag",
+			args: []string{"firebell", "wrap", "--name", "MyAI", "--", "claude"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Wrap {
+					t.Error("Expected Wrap to be true")
+				}
+				if f.WrapName != "MyAI" {
+					t.Errorf("Expected WrapName=MyAI, got %q", f.WrapName)
+				}
+			},
+		},
+		{
+			name: "wrap subcommand without -- separator",
+			args: []string{"firebell", "wrap", "claude"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Wrap {
+					t.Error("Expected Wrap to be true")
+				}
+			},
+		},
+		{
+			name: "events subcommand",
+			args: []string{"firebell", "events"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Events {
+					t.Error("Expected Events to be true")
+				}
+			},
+		},
+		{
+			name: "events subcommand with follow",
+			args: []string{"firebell", "events", "-f"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Events {
+					t.Error("Expected Events to be true")
+				}
+				if !f.EventsFollow {
+					t.Error("Expected EventsFollow to be true")
+				}
+			},
+		},
+		{
+			name: "listen subcommand",
+			args: []string{"firebell", "listen"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Listen {
+					t.Error("Expected Listen to be true")
+				}
+			},
+		},
+		{
+			name: "listen subcommand with json",
+			args: []string{"firebell", "listen", "--json"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Listen {
+					t.Error("Expected Listen to be true")
+				}
+				if !f.ListenJSON {
+					t.Error("Expected ListenJSON to be true")
+				}
+			},
+		},
+		{
+			name: "with config-dir flag",
+			args: []string{"firebell", "--config-dir", "/tmp/fb1"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if f.ConfigDir != "/tmp/fb1" {
+					t.Errorf("Expected ConfigDir=/tmp/fb1, got %q", f.ConfigDir)
+				}
+			},
+		},
+		{
+			name: "start with config-dir flag",
+			args: []string{"firebell", "start", "--config-dir", "/tmp/fb2"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.DaemonStart || f.ConfigDir != "/tmp/fb2" {
+					t.Errorf("Expected DaemonStart with ConfigDir=/tmp/fb2, got start=%v dir=%q", f.DaemonStart, f.ConfigDir)
+				}
+			},
+		},
+		{
+			name: "events with tail and offset",
+			args: []string{"firebell", "events", "--tail", "100", "--offset", "200"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Events || f.EventsTail != 100 || f.EventsOffset != 200 {
+					t.Errorf("Expected events tail=100 offset=200, got events=%v tail=%d offset=%d", f.Events, f.EventsTail, f.EventsOffset)
+				}
+			},
+		},
+		{
+			name: "watch subcommand",
+			args: []string{"firebell", "watch", "/var/log/myagent.log", "--name", "My Agent"},
+			setupFn: func() *Flags {
+				return ParseFlags()
+			},
+			verifyFn: func(t *testing.T, f *Flags) {
+				if !f.Watch {
+					t.Error("Expected Watch to be true")
+				}
+				if f.WatchPath != "/var/log/myagent.log" {
+					t.Errorf("Expected WatchPath=/var/log/myagent.log, got %q", f.WatchPath)
+				}
+				if f.WatchPathName != "My Agent" {
+					t.Errorf("Expected WatchPathName=My Agent, got %q", f.WatchPathName)
+				}
+			},
+		},
+		{
+			name: "watch subcommand with flags first",
+			args: []string{"firebell", "watch", "--verbose", "/tmp/logs"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if f.Agent != "claude" {
-					t.Errorf("Expected Agent=claude, got %q", f.Agent)
+				if f.WatchPath != "/tmp/logs" || !f.Verbose {
+					t.Errorf("Expected WatchPath=/tmp/logs with Verbose, got %q verbose=%v", f.WatchPath, f.Verbose)
 				}
 			},
 		},
 		{
-			name: "start subcommand",
-			args: []string{"firebell", "start"},
+			name: "benchmark subcommand",
+			args: []string{"firebell", "benchmark", "--agent", "claude", "/tmp/big.jsonl"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.DaemonStart {
-					t.Error("Expected DaemonStart to be true")
+				if !f.Benchmark || f.Agent != "claude" || f.BenchmarkPath != "/tmp/big.jsonl" {
+					t.Errorf("Expected benchmark of /tmp/big.jsonl with agent claude, got benchmark=%v agent=%q path=%q", f.Benchmark, f.Agent, f.BenchmarkPath)
 				}
 			},
 		},
 		{
-			name: "stop subcommand",
-			args: []string{"firebell", "stop"},
+			name: "reasons subcommand",
+			args: []string{"firebell", "reasons", "/tmp/big.jsonl", "--agent", "codex"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.DaemonStop {
-					t.Error("Expected DaemonStop to be true")
+				if !f.Reasons || f.Agent != "codex" || f.ReasonsPath != "/tmp/big.jsonl" {
+					t.Errorf("Expected reasons of /tmp/big.jsonl with agent codex, got reasons=%v agent=%q path=%q", f.Reasons, f.Agent, f.ReasonsPath)
 				}
 			},
 		},
 		{
-			name: "restart subcommand",
-			args: []string{"firebell", "restart"},
+			name: "notify subcommand with flags",
+			args: []string{"firebell", "notify", "--title", "Build", "--message", "done", "--agent", "CI"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.DaemonRestart {
-					t.Error("Expected DaemonRestart to be true")
+				if !f.Notify || f.NotifyTitle != "Build" || f.NotifyMessage != "done" || f.Agent != "CI" {
+					t.Errorf("Expected notify Build/done/CI, got notify=%v title=%q message=%q agent=%q", f.Notify, f.NotifyTitle, f.NotifyMessage, f.Agent)
 				}
 			},
 		},
 		{
-			name: "status subcommand",
-			args: []string{"firebell", "status"},
+			name: "notify subcommand with positional message",
+			args: []string{"firebell", "notify", "Deploy finished", "--title", "Deploy"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.DaemonStatus {
-					t.Error("Expected DaemonStatus to be true")
+				if !f.Notify || f.NotifyMessage != "Deploy finished" || f.NotifyTitle != "Deploy" {
+					t.Errorf("Expected notify message %q titled Deploy, got message=%q title=%q", "Deploy finished", f.NotifyMessage, f.NotifyTitle)
 				}
 			},
 		},
 		{
-			name: "logs subcommand",
-			args: []string{"firebell", "logs"},
+			name: "snooze subcommand",
+			args: []string{"firebell", "snooze", "30m", "--config-dir", "/tmp/fb"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.DaemonLogs {
-					t.Error("Expected DaemonLogs to be true")
+				if !f.Snooze || f.SnoozeArg != "30m" || f.ConfigDir != "/tmp/fb" {
+					t.Errorf("Expected snooze 30m in /tmp/fb, got snooze=%v arg=%q dir=%q", f.Snooze, f.SnoozeArg, f.ConfigDir)
 				}
 			},
 		},
 		{
-			name: "logs subcommand with follow",
-			args: []string{"firebell", "logs", "-f"},
+			name: "config env subcommand",
+			args: []string{"firebell", "config", "env", "--config-dir", "/tmp/fb"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.DaemonLogs {
-					t.Error("Expected DaemonLogs to be true")
-				}
-				if !f.DaemonFollow {
-					t.Error("Expected DaemonFollow to be true")
+				if f.ConfigCmd != "env" || f.ConfigDir != "/tmp/fb" {
+					t.Errorf("Expected config env in /tmp/fb, got cmd=%q dir=%q", f.ConfigCmd, f.ConfigDir)
 				}
 			},
 		},
 		{
-			name: "wrap subcommand with command",
-			args: []string{"firebell", "wrap", "--", "claude"},
+			name: "pause subcommand",
+			args: []string{"firebell", "pause", "claude", "--config-dir", "/tmp/fb"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Wrap {
-					t.Error("Expected Wrap to be true")
-				}
-				if len(f.WrapArgs) != 1 || f.WrapArgs[0] != "claude" {
-					t.Errorf("Expected WrapArgs=[claude], got %v", f.WrapArgs)
-				}
-				if f.WrapName != "claude" {
-					t.Errorf("Expected default WrapName=claude, got %q", f.WrapName)
+				if f.PauseCmd != "pause" || f.PauseAgent != "claude" || f.ConfigDir != "/tmp/fb" {
+					t.Errorf("Expected pause claude in /tmp/fb, got cmd=%q agent=%q dir=%q", f.PauseCmd, f.PauseAgent, f.ConfigDir)
 				}
 			},
 		},
 		{
-			name: "wrap subcommand with name flag",
-			args: []string{"firebell", "wrap", "--name", "MyAI", "--", "claude"},
+			name: "resume subcommand",
+			args: []string{"firebell", "resume", "codex"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Wrap {
-					t.Error("Expected Wrap to be true")
-				}
-				if f.WrapName != "MyAI" {
-					t.Errorf("Expected WrapName=MyAI, got %q", f.WrapName)
+				if f.PauseCmd != "resume" || f.PauseAgent != "codex" {
+					t.Errorf("Expected resume codex, got cmd=%q agent=%q", f.PauseCmd, f.PauseAgent)
 				}
 			},
 		},
 		{
-			name: "wrap subcommand without -- separator",
-			args: []string{"firebell", "wrap", "claude"},
+			name: "top subcommand",
+			args: []string{"firebell", "top"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Wrap {
-					t.Error("Expected Wrap to be true")
+				if !f.Top || f.TopInterval != time.Second {
+					t.Errorf("Expected top every 1s, got top=%v interval=%v", f.Top, f.TopInterval)
 				}
 			},
 		},
 		{
-			name: "events subcommand",
-			args: []string{"firebell", "events"},
+			name: "top subcommand with interval",
+			args: []string{"firebell", "top", "--interval", "5s"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Events {
-					t.Error("Expected Events to be true")
+				if !f.Top || f.TopInterval != 5*time.Second {
+					t.Errorf("Expected top every 5s, got top=%v interval=%v", f.Top, f.TopInterval)
 				}
 			},
 		},
 		{
-			name: "events subcommand with follow",
-			args: []string{"firebell", "events", "-f"},
+			name: "doctor fix subcommand",
+			args: []string{"firebell", "doctor", "--fix", "--config-dir", "/tmp/fb"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Events {
-					t.Error("Expected Events to be true")
-				}
-				if !f.EventsFollow {
-					t.Error("Expected EventsFollow to be true")
+				if !f.Check || !f.Fix || f.ConfigDir != "/tmp/fb" {
+					t.Errorf("Expected check+fix in /tmp/fb, got check=%v fix=%v dir=%q", f.Check, f.Fix, f.ConfigDir)
 				}
 			},
 		},
 		{
-			name: "listen subcommand",
-			args: []string{"firebell", "listen"},
+			name: "replay subcommand",
+			args: []string{"firebell", "replay", "events.jsonl", "--speed", "10"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Listen {
-					t.Error("Expected Listen to be true")
+				if !f.Replay || f.ReplayPath != "events.jsonl" || f.ReplaySpeed != 10 {
+					t.Errorf("Expected replay of events.jsonl at 10x, got replay=%v path=%q speed=%g", f.Replay, f.ReplayPath, f.ReplaySpeed)
 				}
 			},
 		},
 		{
-			name: "listen subcommand with json",
-			args: []string{"firebell", "listen", "--json"},
+			name: "replay default speed",
+			args: []string{"firebell", "replay", "--config-dir", "/tmp/fb", "events.jsonl"},
 			setupFn: func() *Flags {
 				return ParseFlags()
 			},
 			verifyFn: func(t *testing.T, f *Flags) {
-				if !f.Listen {
-					t.Error("Expected Listen to be true")
-				}
-				if !f.ListenJSON {
-					t.Error("Expected ListenJSON to be true")
+				if f.ReplayPath != "events.jsonl" || f.ReplaySpeed != 1 || f.ConfigDir != "/tmp/fb" {
+					t.Errorf("Expected events.jsonl at 1x in /tmp/fb, got path=%q speed=%g dir=%q", f.ReplayPath, f.ReplaySpeed, f.ConfigDir)
 				}
 			},
 		},
@@ -463,6 +1471,222 @@ func TestParseFlags(t *testing.T) {
 	}
 }
 
+func TestLoadFormats(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `version: "2"
+notify:
+  type: slack
+  slack:
+    webhook: https://hooks.slack.com/services/T/B/X
+  webhooks:
+    - url: https://example.com/hook
+      events: [cooling, holding]
+      headers:
+        Authorization: Bearer abc
+agents:
+  enabled: [claude, codex]
+  paths:
+    claude: ~/custom/claude
+monitor:
+  process_tracking: true
+  completion_detection: true
+  quiet_seconds: 30
+  per_instance: true
+output:
+  verbosity: verbose
+  include_snippets: true
+  snippet_lines: 4
+daemon:
+  event_file: true
+advanced:
+  poll_interval_ms: 500
+  max_recent_files: 5
+  watch_depth: 3
+`,
+		"config.json": `{
+  "version": "2",
+  "notify": {
+    "type": "slack",
+    "slack": {"webhook": "https://hooks.slack.com/services/T/B/X"},
+    "webhooks": [
+      {"url": "https://example.com/hook", "events": ["cooling", "holding"], "headers": {"Authorization": "Bearer abc"}}
+    ]
+  },
+  "agents": {"enabled": ["claude", "codex"], "paths": {"claude": "~/custom/claude"}},
+  "monitor": {"process_tracking": true, "completion_detection": true, "quiet_seconds": 30, "per_instance": true},
+  "output": {"verbosity": "verbose", "include_snippets": true, "snippet_lines": 4},
+  "daemon": {"event_file": true},
+  "advanced": {"poll_interval_ms": 500, "max_recent_files": 5, "watch_depth": 3}
+}`,
+		"config.toml": `version = "2"
+
+[notify]
+type = "slack"
+
+[notify.slack]
+webhook = "https://hooks.slack.com/services/T/B/X"
+
+[[notify.webhooks]]
+url = "https://example.com/hook"
+events = ["cooling", "holding"]
+headers = { Authorization = "Bearer abc" }
+
+[agents]
+enabled = ["claude", "codex"]
+paths = { claude = "~/custom/claude" }
+
+[monitor]
+process_tracking = true
+completion_detection = true
+quiet_seconds = 30
+per_instance = true
+
+[output]
+verbosity = "verbose"
+include_snippets = true
+snippet_lines = 4
+
+[daemon]
+event_file = true
+
+[advanced]
+poll_interval_ms = 500
+max_recent_files = 5
+watch_depth = 3
+`,
+	}
+
+	dir := t.TempDir()
+	loaded := map[string]*Config{}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(%s) failed: %v", name, err)
+		}
+		loaded[name] = cfg
+	}
+
+	want := loaded["config.yaml"]
+	if want.Monitor.QuietSeconds != 30 || len(want.Notify.Webhooks) != 1 || want.Agents.Paths["claude"] != "~/custom/claude" {
+		t.Fatalf("YAML config not loaded as expected: %+v", want)
+	}
+	for _, name := range []string{"config.json", "config.toml"} {
+		if !reflect.DeepEqual(loaded[name], want) {
+			t.Errorf("%s loaded as %+v, want %+v", name, loaded[name], want)
+		}
+	}
+}
+
+func TestPerInstanceMode(t *testing.T) {
+	tests := []struct {
+		value   string // as written in the config
+		want    PerInstanceMode
+		wantErr bool
+	}{
+		{"true", PerInstanceOn, false},
+		{"false", PerInstanceOff, false},
+		{`"auto"`, PerInstanceAuto, false},
+		{`"sometimes"`, "", true},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		files := map[string]string{
+			"config.yaml": "monitor:\n  per_instance: " + tt.value + "\n",
+			"config.json": `{"monitor": {"per_instance": ` + tt.value + `}}`,
+			"config.toml": "[monitor]\nper_instance = " + tt.value + "\n",
+		}
+		for name, content := range files {
+			t.Run(name+"/"+tt.value, func(t *testing.T) {
+				var (
+					cfg *Config
+					err error
+				)
+				switch filepath.Ext(name) {
+				case ".yaml":
+					cfg, err = parseV2YAML([]byte(content))
+				case ".json":
+					cfg, err = parseV2JSON([]byte(content))
+				case ".toml":
+					cfg, err = parseV2TOML([]byte(content))
+				}
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("expected error for per_instance %s", tt.value)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("parse failed: %v", err)
+				}
+				if cfg.Monitor.PerInstance != tt.want {
+					t.Errorf("PerInstance = %q, want %q", cfg.Monitor.PerInstance, tt.want)
+				}
+			})
+		}
+	}
+
+	t.Run("save keeps bools", func(t *testing.T) {
+		path := filepath.Join(dir, "saved.yaml")
+		cfg := DefaultConfig()
+		cfg.Notify.Type = "stdout"
+		if err := Save(cfg, path); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(path)
+		if !strings.Contains(string(data), "per_instance: true") {
+			t.Errorf("saved config should write per_instance as a bool:\n%s", data)
+		}
+
+		cfg.Monitor.PerInstance = PerInstanceAuto
+		if err := Save(cfg, path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Monitor.PerInstance != PerInstanceAuto {
+			t.Errorf("round-tripped PerInstance = %q, want auto", loaded.Monitor.PerInstance)
+		}
+	})
+}
+
+func TestLoadFormatsValidate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bad.yaml": "notify:\n  type: email\n",
+		"bad.json": `{"notify": {"type": "email"}}`,
+		"bad.toml": "[notify]\ntype = \"email\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0600)
+		_, err := Load(path)
+		verr, ok := err.(*ValidationError)
+		if !ok || verr.Field != "notify.type" {
+			t.Errorf("Load(%s) error = %v, want notify.type validation error", name, err)
+		}
+	}
+}
+
+func TestLoadV1JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"webhook": "https://hooks.slack.com/services/T/B/OLD"}`), 0600)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Notify.Type != "slack" || cfg.Notify.Slack.Webhook != "https://hooks.slack.com/services/T/B/OLD" {
+		t.Errorf("Expected migrated v1 webhook, got %+v", cfg.Notify)
+	}
+}
+
 func TestValidationError(t *testing.T) {
 	err := &ValidationError{
 		Field:   "test.field",
@@ -481,3 +1705,401 @@ func TestValidationError(t *testing.T) {
 		t.Errorf("Expected Message=test error message, got %q", err.Message)
 	}
 }
+
+func intPtr(n int) *int { return &n }
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   string
+		timezone string
+		want     string
+	}{
+		{"default format in UTC", "", "UTC", "23:30:00"},
+		{"custom format in UTC", "2006-01-02 15:04", "UTC", "2025-01-15 23:30"},
+		{"crosses date in Tokyo", "2006-01-02 15:04 MST", "Asia/Tokyo", "2025-01-16 08:30 JST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Output.TimeFormat = tt.format
+			cfg.Output.Timezone = tt.timezone
+			if got := cfg.FormatTime(ts); got != tt.want {
+				t.Errorf("FormatTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unset timezone is local", func(t *testing.T) {
+		if got := DefaultConfig().TimeLocation(); got != time.Local {
+			t.Errorf("TimeLocation() = %v, want Local", got)
+		}
+	})
+}
+
+func TestProbeLogPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CODEX_HOME", "")
+
+	codex := AgentInfo{Name: "codex", LogPath: "~/.codex/sessions", AltLogPaths: []string{"$CODEX_HOME/sessions"}}
+	if got := ProbeLogPath(codex); got != "" {
+		t.Errorf("Nothing installed: ProbeLogPath = %q, want empty", got)
+	}
+
+	// XDG config variant of the default
+	if err := os.MkdirAll(filepath.Join(home, ".config", "codex", "sessions"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ProbeLogPath(codex); got != "~/.config/codex/sessions" {
+		t.Errorf("ProbeLogPath = %q, want ~/.config/codex/sessions", got)
+	}
+
+	// A known alternate wins, recorded with its variable expanded
+	custom := filepath.Join(t.TempDir(), "codex-home")
+	if err := os.MkdirAll(filepath.Join(custom, "sessions"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CODEX_HOME", custom)
+	if got := ProbeLogPath(codex); got != filepath.Join(custom, "sessions") {
+		t.Errorf("ProbeLogPath = %q, want %q", got, filepath.Join(custom, "sessions"))
+	}
+
+	// A default under ~/.local/share falls back to a dot directory
+	if err := os.MkdirAll(filepath.Join(home, ".crush"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ProbeLogPath(AgentInfo{Name: "crush", LogPath: "~/.local/share/crush"}); got != "~/.crush" {
+		t.Errorf("ProbeLogPath = %q, want ~/.crush", got)
+	}
+}
+
+func TestSetupAgentsRecordsProbedPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".config", "codex", "sessions"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	agents := func() []AgentInfo {
+		return []AgentInfo{{Name: "codex", DisplayName: "Codex", LogPath: "~/.codex/sessions"}}
+	}
+	// Accept the probed path, then monitor detected agents only
+	reader := bufio.NewReader(strings.NewReader("y\n\n"))
+	if err := setupAgents(reader, cfg, agents); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Agents.Paths["codex"]; got != "~/.config/codex/sessions" {
+		t.Errorf("agents.paths.codex = %q, want ~/.config/codex/sessions", got)
+	}
+	if cfg.Agents.Enabled != nil {
+		t.Errorf("Enabled = %v, want auto-detect", cfg.Agents.Enabled)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("monitor.quiet_seconds"); got != "FIREBELL_MONITOR_QUIET_SECONDS" {
+		t.Errorf("EnvName = %q", got)
+	}
+
+	// Every key maps to a distinct variable
+	seen := make(map[string]bool)
+	for _, v := range EnvVars(DefaultConfig()) {
+		if seen[v.Name] {
+			t.Errorf("Duplicate env var %s", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	if !seen["FIREBELL_NOTIFY_SLACK_WEBHOOK"] || seen["FIREBELL_VERSION"] {
+		t.Errorf("Unexpected variable set: %v", seen)
+	}
+}
+
+func TestEnvRoundTrip(t *testing.T) {
+	retries := 5
+	cfg := DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	cfg.Notify.Webhooks = []WebhookConfig{{URL: "https://example.com/hook", Events: []string{"cooling"}, Retries: &retries}}
+	cfg.Agents.Enabled = []string{"claude", "codex"}
+	cfg.Agents.Paths = map[string]string{"claude": "/srv/claude"}
+	cfg.Monitor.QuietSeconds = 45
+	cfg.Monitor.PerInstance = PerInstanceAuto
+	cfg.Monitor.ProcessTracking = false
+	cfg.Daemon.EventFileMaxSize = 1 << 30
+	cfg.Output.TimeFormat = "2006-01-02 15:04"
+
+	var environ []string
+	for _, v := range EnvVars(cfg) {
+		environ = append(environ, v.Name+"="+v.Value)
+	}
+	if !containsLine(environ, "FIREBELL_AGENTS_ENABLED=claude,codex") || !containsLine(environ, "FIREBELL_MONITOR_PER_INSTANCE=auto") {
+		t.Errorf("Unexpected encoding: %v", environ)
+	}
+
+	got := DefaultConfig()
+	n, err := ApplyEnv(got, append(environ, "FIREBELL_DAEMON=1", "PATH=/bin"))
+	if err != nil {
+		t.Fatalf("ApplyEnv failed: %v", err)
+	}
+	if n != len(environ) {
+		t.Errorf("Applied %d keys, want %d", n, len(environ))
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", got, cfg)
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	for _, kv := range []string{
+		"FIREBELL_MONITOR_QUIET_SECONDS=soon",
+		"FIREBELL_MONITOR_FOCUS=maybe",
+		"FIREBELL_AGENTS_PATHS={not json",
+		"FIREBELL_MONITOR_PER_INSTANCE=sometimes",
+	} {
+		if _, err := ApplyEnv(DefaultConfig(), []string{kv}); err == nil {
+			t.Errorf("ApplyEnv(%s) succeeded, want an error", kv)
+		}
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	// A fresh install has no file: the defaults load as is, and --stdout
+	// (applied by the caller) makes them valid
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load without a file or env failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("Load without a file = %+v, want the defaults", cfg)
+	}
+	cfg.Notify.Type = "stdout"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Defaults with --stdout invalid: %v", err)
+	}
+
+	// Overrides of other settings don't need the webhook either
+	t.Setenv("FIREBELL_OUTPUT_VERBOSITY", "verbose")
+	if cfg, err = Load(filepath.Join(t.TempDir(), "missing.yaml")); err != nil || cfg.Output.Verbosity != "verbose" {
+		t.Errorf("Load without a file = %+v, %v; want FIREBELL_OUTPUT_VERBOSITY applied", cfg, err)
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("notify:\n  type: stdout\noutput:\n  verbosity: normal\nmonitor:\n  quiet_seconds: 20\nadvanced:\n  poll_interval_ms: 800\n  max_recent_files: 3\n"), 0600)
+
+	t.Setenv("FIREBELL_MONITOR_QUIET_SECONDS", "60")
+	t.Setenv("FIREBELL_AGENTS_ENABLED", "claude")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Monitor.QuietSeconds != 60 || !reflect.DeepEqual(cfg.Agents.Enabled, []string{"claude"}) {
+		t.Errorf("Env not applied: quiet=%d enabled=%v", cfg.Monitor.QuietSeconds, cfg.Agents.Enabled)
+	}
+	if cfg.Notify.Type != "stdout" {
+		t.Errorf("File value lost: notify.type = %q", cfg.Notify.Type)
+	}
+
+	// Overrides are validated like the file
+	t.Setenv("FIREBELL_NOTIFY_TYPE", "email")
+	if _, err := Load(path); err == nil {
+		t.Error("Expected a validation error for FIREBELL_NOTIFY_TYPE=email")
+	}
+
+	// And apply without a config file
+	t.Setenv("FIREBELL_NOTIFY_TYPE", "none")
+	cfg, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || cfg.Notify.Type != "none" || cfg.Monitor.QuietSeconds != 60 {
+		t.Errorf("Load without a file = %+v, %v; want env overrides applied", cfg, err)
+	}
+	t.Setenv("FIREBELL_NOTIFY_TYPE", "email")
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "notify.type") {
+		t.Errorf("Load without a file error = %v, want a notify.type validation error", err)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `notify:
+  type: stdout
+agents:
+  enabled: [claude, codex]
+  display_names:
+    claude: Claude
+monitor:
+  quiet_seconds: 20
+output:
+  verbosity: normal
+advanced:
+  poll_interval_ms: 800
+  max_recent_files: 3
+profiles:
+  work:
+    notify:
+      type: slack
+      slack:
+        webhook: https://hooks.slack.com/services/T/B/X
+    agents:
+      enabled: [claude]
+      display_names:
+        codex: Codex
+  personal:
+    output:
+      verbosity: verbose
+`,
+		"config.json": `{
+  "notify": {"type": "stdout"},
+  "agents": {"enabled": ["claude", "codex"], "display_names": {"claude": "Claude"}},
+  "monitor": {"quiet_seconds": 20},
+  "output": {"verbosity": "normal"},
+  "advanced": {"poll_interval_ms": 800, "max_recent_files": 3},
+  "profiles": {
+    "work": {
+      "notify": {"type": "slack", "slack": {"webhook": "https://hooks.slack.com/services/T/B/X"}},
+      "agents": {"enabled": ["claude"], "display_names": {"codex": "Codex"}}
+    },
+    "personal": {"output": {"verbosity": "verbose"}}
+  }
+}`,
+		"config.toml": `[notify]
+type = "stdout"
+
+[agents]
+enabled = ["claude", "codex"]
+display_names = { claude = "Claude" }
+
+[monitor]
+quiet_seconds = 20
+
+[output]
+verbosity = "normal"
+
+[advanced]
+poll_interval_ms = 800
+max_recent_files = 3
+
+[profiles.work.notify]
+type = "slack"
+slack = { webhook = "https://hooks.slack.com/services/T/B/X" }
+
+[profiles.work.agents]
+enabled = ["claude"]
+display_names = { codex = "Codex" }
+
+[profiles.personal.output]
+verbosity = "verbose"
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			// No profile: the base config alone
+			t.Setenv(ProfileEnv, "")
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Notify.Type != "stdout" || len(cfg.Agents.Enabled) != 2 {
+				t.Errorf("base config = %s %v, want stdout with two agents", cfg.Notify.Type, cfg.Agents.Enabled)
+			}
+
+			t.Setenv(ProfileEnv, "work")
+			cfg, err = Load(path)
+			if err != nil {
+				t.Fatalf("Load() with profile error = %v", err)
+			}
+			if cfg.Notify.Type != "slack" || cfg.Notify.Slack.Webhook == "" {
+				t.Errorf("notify = %+v, want the profile's Slack", cfg.Notify)
+			}
+			if !reflect.DeepEqual(cfg.Agents.Enabled, []string{"claude"}) {
+				t.Errorf("agents.enabled = %v, want the profile's list", cfg.Agents.Enabled)
+			}
+			if want := map[string]string{"claude": "Claude", "codex": "Codex"}; !reflect.DeepEqual(cfg.Agents.DisplayNames, want) {
+				t.Errorf("agents.display_names = %v, want %v (merged)", cfg.Agents.DisplayNames, want)
+			}
+			if cfg.Monitor.QuietSeconds != 20 {
+				t.Errorf("monitor.quiet_seconds = %d, want the base's 20", cfg.Monitor.QuietSeconds)
+			}
+
+			t.Setenv(ProfileEnv, "personal")
+			cfg, err = Load(path)
+			if err != nil {
+				t.Fatalf("Load() with profile error = %v", err)
+			}
+			if cfg.Notify.Type != "stdout" || cfg.Output.Verbosity != "verbose" {
+				t.Errorf("personal profile = %s/%s, want stdout/verbose", cfg.Notify.Type, cfg.Output.Verbosity)
+			}
+
+			// Environment overrides apply over the profile
+			t.Setenv(ProfileEnv, "work")
+			t.Setenv("FIREBELL_NOTIFY_TYPE", "none")
+			if cfg, err = Load(path); err != nil || cfg.Notify.Type != "none" {
+				t.Errorf("Load() = %v, %v; want FIREBELL_NOTIFY_TYPE over the profile", cfg, err)
+			}
+		})
+	}
+}
+
+func TestLoadUnknownProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("notify:\n  type: stdout\nprofiles:\n  work:\n    notify:\n      type: none\n  home: {}\n"), 0600)
+
+	t.Setenv(ProfileEnv, "wrok")
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "wrok" (profiles: home, work)`) {
+		t.Errorf("Load() error = %v, want an unknown profile error listing the profiles", err)
+	}
+
+	// Without profiles, or without a config file, any profile is unknown
+	os.WriteFile(path, []byte("notify:\n  type: stdout\n"), 0600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "defines no profiles") {
+		t.Errorf("Load() error = %v, want no profiles defined", err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("Load() of a missing file error = %v, want unknown profile", err)
+	}
+
+	// Profiles are validated like the file
+	base := "notify:\n  type: stdout\noutput:\n  verbosity: normal\nmonitor:\n  quiet_seconds: 20\nadvanced:\n  poll_interval_ms: 800\n  max_recent_files: 3\n"
+	os.WriteFile(path, []byte(base+"profiles:\n  bad:\n    notify:\n      type: email\n"), 0600)
+	t.Setenv(ProfileEnv, "bad")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "notify.type") {
+		t.Errorf("Load() with an invalid profile error = %v, want a notify.type validation error", err)
+	}
+}
+
+func TestCompletionDetectionFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.CompletionDetection = map[string]bool{"aider": false, "codex": true}
+
+	if !cfg.CompletionDetectionFor("claude") || cfg.CompletionDetectionFor("aider") {
+		t.Errorf("with monitor.completion_detection on: claude=%v aider=%v, want true/false",
+			cfg.CompletionDetectionFor("claude"), cfg.CompletionDetectionFor("aider"))
+	}
+
+	cfg.Monitor.CompletionDetection = false
+	if cfg.CompletionDetectionFor("claude") || !cfg.CompletionDetectionFor("codex") {
+		t.Errorf("with monitor.completion_detection off: claude=%v codex=%v, want false/true",
+			cfg.CompletionDetectionFor("claude"), cfg.CompletionDetectionFor("codex"))
+	}
+}
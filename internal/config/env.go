@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts every environment variable that overrides a config key.
+// The rest of the name is the key's path, upper-cased and joined with
+// underscores: monitor.quiet_seconds is FIREBELL_MONITOR_QUIET_SECONDS.
+const EnvPrefix = "FIREBELL_"
+
+// EnvVar is one config key as an environment variable.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvName returns the environment variable for a dotted config key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// envField is a settable config field and its variable name.
+type envField struct {
+	name  string
+	value reflect.Value
+}
+
+// envFields lists every config key of cfg in declaration order. Structs are
+// flattened; everything else (scalars, lists, maps, webhooks) is one key.
+// The top-level version is not a setting and is left out.
+func envFields(cfg *Config) []envField {
+	var fields []envField
+	var walk func(v reflect.Value, path []string)
+	walk = func(v reflect.Value, path []string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" || (len(path) == 0 && name == "version") {
+				continue
+			}
+			field := v.Field(i)
+			key := append(append([]string(nil), path...), name)
+			if field.Kind() == reflect.Struct && !isJSONCoded(field) {
+				walk(field, key)
+				continue
+			}
+			fields = append(fields, envField{name: EnvName(strings.Join(key, ".")), value: field})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), nil)
+	return fields
+}
+
+// isJSONCoded reports whether a field's env value is JSON: maps, lists of
+// structs, pointers, and types with their own JSON form (e.g. per_instance).
+func isJSONCoded(v reflect.Value) bool {
+	if _, ok := v.Addr().Interface().(json.Unmarshaler); ok {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+		return false
+	case reflect.Slice:
+		return v.Type().Elem().Kind() != reflect.String
+	case reflect.Struct:
+		return false
+	}
+	return true
+}
+
+// EnvVars returns cfg as environment variables, one per config key, in a
+// fixed order. Lists of strings are comma-separated; maps, webhooks, and
+// similar values are JSON. ApplyEnv reads them back.
+func EnvVars(cfg *Config) []EnvVar {
+	var vars []EnvVar
+	for _, f := range envFields(cfg) {
+		vars = append(vars, EnvVar{Name: f.name, Value: formatEnvValue(f.value)})
+	}
+	return vars
+}
+
+// formatEnvValue renders one field as an environment variable value.
+func formatEnvValue(v reflect.Value) string {
+	if isJSONCoded(v) {
+		if (v.Kind() == reflect.Map || v.Kind() == reflect.Slice || v.Kind() == reflect.Pointer) && v.IsNil() {
+			return ""
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+		// A bare JSON string (e.g. per_instance "auto") reads better unquoted
+		var s string
+		if json.Unmarshal(data, &s) == nil {
+			return s
+		}
+		return string(data)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return v.String()
+}
+
+// ApplyEnv overrides cfg with the FIREBELL_* variables in environ (as from
+// os.Environ) that name a config key. Other variables, including unrelated
+// FIREBELL_* ones, are ignored. It returns how many keys were overridden.
+func ApplyEnv(cfg *Config, environ []string) (int, error) {
+	values := make(map[string]string)
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(name, EnvPrefix) {
+			values[name] = value
+		}
+	}
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	applied := 0
+	for _, f := range envFields(cfg) {
+		value, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		if err := parseEnvValue(f.value, value); err != nil {
+			return applied, fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// parseEnvValue sets one field from an environment variable value. An empty
+// value resets the field to its zero value.
+func parseEnvValue(v reflect.Value, value string) error {
+	if value == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if isJSONCoded(v) {
+		target := v.Addr().Interface()
+		if err := json.Unmarshal([]byte(value), target); err != nil {
+			// Allow bare strings, e.g. FIREBELL_MONITOR_PER_INSTANCE=auto
+			quoted, _ := json.Marshal(value)
+			if json.Unmarshal(quoted, target) != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		v.SetString(value)
+	}
+	return nil
+}
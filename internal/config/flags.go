@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
 // Version is set at build time via -ldflags.
@@ -12,16 +14,24 @@ var Version = "dev"
 // Flags holds parsed command-line flags.
 type Flags struct {
 	ConfigPath string
+	ConfigDir  string // Directory for config, logs, lock, socket, and event file
+	Profile    string // Config profile to merge over the base config (see ProfileEnv)
 	Setup      bool
 	Check      bool
+	Fix        bool // With Check: repair stale locks, missing dirs, and a missing config
 	Agent      string
 	Stdout     bool
 	Verbose    bool // Enable verbose output (show all activity)
 	Version    bool
 	Migrate    bool
-	Wrap       bool     // Wrap a command
-	WrapArgs   []string // Command and arguments to wrap
-	WrapName   string   // Display name for wrapped command
+	Backfill   time.Duration // Process this much log history on start
+	Stdin      bool          // Read log lines from stdin instead of log files
+	PID        int           // Track this process instead of auto-detecting (0 = detect)
+	Wrap       bool          // Wrap a command
+	WrapArgs   []string      // Command and arguments to wrap
+	WrapName   string        // Display name for wrapped command
+
+	ActiveWindow time.Duration // Auto-detect only agents with logs modified this recently (0 = any)
 
 	// Daemon subcommands
 	DaemonStart   bool // Start daemon
@@ -30,10 +40,14 @@ type Flags struct {
 	DaemonStatus  bool // Show daemon status
 	DaemonLogs    bool // Show/tail logs
 	DaemonFollow  bool // Follow log output (-f)
+	JSONLogs      bool // Write the daemon log as pure JSONL (--json-logs)
 
 	// Events subcommand
 	Events       bool // Show event file info
 	EventsFollow bool // Follow event file (-f)
+	EventsTail   int  // Print this many events across rotations (--tail)
+	EventsOffset int  // Skip this many newest events when paging (--offset)
+	EventsAll    bool // Count daemon/status events too, not just agent events (--all)
 
 	// Webhook subcommand
 	WebhookTest bool   // Test a webhook URL
@@ -42,6 +56,54 @@ type Flags struct {
 	// Listen subcommand
 	Listen     bool // Listen to socket events
 	ListenJSON bool // Output raw JSON
+
+	// Watch subcommand
+	Watch         bool   // Watch an arbitrary log path
+	WatchPath     string // File or directory to watch
+	WatchPathName string // Display name for notifications
+
+	// Benchmark subcommand
+	Benchmark     bool   // Time a matcher over a log file
+	BenchmarkPath string // Log file to match against
+
+	// Reasons subcommand
+	Reasons     bool   // Tally Match.Reason over a log file
+	ReasonsPath string // Log file to match against
+
+	// Notify subcommand
+	Notify        bool   // Send one ad-hoc notification and exit
+	NotifyTitle   string // Notification title
+	NotifyMessage string // Notification body
+
+	// Snooze subcommand
+	Snooze    bool   // Silence alerts for a while
+	SnoozeArg string // Duration (e.g. "30m"), "off", or "" to show the current snooze
+
+	// Replay subcommand
+	Replay      bool    // Re-send a recorded event file through the configured notifier
+	ReplayPath  string  // Event file to replay
+	ReplaySpeed float64 // Playback speed (1 = original timing, 0 = no delay)
+
+	// Config subcommand
+	ConfigCmd string // Action: "env" prints the effective config as FIREBELL_* variables
+
+	// Pause and resume subcommands
+	PauseCmd   string // "pause" or "resume" ("" = neither)
+	PauseAgent string // Agent to pause or resume
+
+	// Top subcommand
+	Top         bool          // Show a live per-agent activity meter
+	TopInterval time.Duration // Refresh interval
+
+	// Path helpers: print a resolved path and exit
+	PrintConfigPath bool // --print-config-path
+	PrintEventPath  bool // --print-event-path
+	PrintSocketPath bool // --print-socket-path
+
+	// Matcher debugging: --test-matcher AGENT LINE
+	TestMatcher      bool   // Match one line and print the result
+	TestMatcherAgent string // Agent whose matcher to use
+	TestMatcherLine  string // Log line to match
 }
 
 // ParseFlags parses command-line flags and returns the result.
@@ -70,21 +132,58 @@ func ParseFlags() *Flags {
 			return parseWebhookFlags(flags)
 		case "listen":
 			return parseListenFlags(flags)
+		case "watch":
+			return parseWatchFlags(flags, os.Args[2:])
+		case "benchmark":
+			return parseBenchmarkFlags(flags, os.Args[2:])
+		case "reasons":
+			return parseReasonsFlags(flags, os.Args[2:])
+		case "notify":
+			return parseNotifyFlags(flags, os.Args[2:])
+		case "snooze":
+			return parseSnoozeFlags(flags, os.Args[2:])
+		case "replay":
+			return parseReplayFlags(flags, os.Args[2:])
+		case "config":
+			return parseConfigFlags(flags, os.Args[2:])
+		case "doctor":
+			return parseDoctorFlags(flags, os.Args[2:])
+		case "pause", "resume":
+			return parsePauseFlags(flags, os.Args[1], os.Args[2:])
+		case "top":
+			return parseTopFlags(flags, os.Args[2:])
 		}
 	}
 
 	flag.StringVar(&flags.ConfigPath, "config", "", "Config file path (default: ~/.firebell/config.yaml)")
+	flag.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files (default: ~/.firebell)")
+	flag.StringVar(&flags.Profile, "profile", "", "Config profile to use (default: $FIREBELL_PROFILE)")
 	flag.BoolVar(&flags.Setup, "setup", false, "Run interactive configuration wizard")
 	flag.BoolVar(&flags.Check, "check", false, "Run health check and exit")
+	flag.BoolVar(&flags.Fix, "fix", false, "With --check, repair common problems first")
 	flag.StringVar(&flags.Agent, "agent", "", "Filter to specific agent (codex|copilot|claude|gemini|opencode)")
 	flag.BoolVar(&flags.Stdout, "stdout", false, "Output to stdout instead of Slack (for testing)")
 	flag.BoolVar(&flags.Verbose, "verbose", false, "Show all activity notifications (default: only 'cooling')")
 	flag.BoolVar(&flags.Version, "version", false, "Print version and exit")
 	flag.BoolVar(&flags.Migrate, "migrate", false, "Migrate v1 config to v2 YAML format")
+	flag.DurationVar(&flags.Backfill, "backfill", 0, "Seed state from recent log history on start (e.g. 2m)")
+	flag.BoolVar(&flags.Stdin, "stdin", false, "Read log lines from stdin (use --agent to pick the matcher)")
+	flag.DurationVar(&flags.ActiveWindow, "active-window", 0, "Auto-detect only agents with log activity within this window (e.g. 24h)")
+	flag.IntVar(&flags.PID, "pid", 0, "Track this process ID instead of auto-detecting")
+	flag.BoolVar(&flags.JSONLogs, "json-logs", false, "Write the daemon log as one JSON object per line")
+	flag.BoolVar(&flags.PrintConfigPath, "print-config-path", false, "Print the config file path and exit")
+	flag.BoolVar(&flags.PrintEventPath, "print-event-path", false, "Print the event file path and exit")
+	flag.BoolVar(&flags.PrintSocketPath, "print-socket-path", false, "Print the daemon socket path and exit")
+	flag.BoolVar(&flags.TestMatcher, "test-matcher", false, "Match one line (args: AGENT LINE), print the result, and exit")
 
 	flag.Usage = customUsage
 	flag.Parse()
 
+	if flags.TestMatcher && flag.NArg() == 2 {
+		flags.TestMatcherAgent = flag.Arg(0)
+		flags.TestMatcherLine = flag.Arg(1)
+	}
+
 	return flags
 }
 
@@ -95,6 +194,7 @@ func parseWrapFlags(flags *Flags) *Flags {
 	// Create a new flagset for wrap subcommand
 	wrapFlags := flag.NewFlagSet("wrap", flag.ExitOnError)
 	wrapFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	wrapFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
 	wrapFlags.StringVar(&flags.WrapName, "name", "", "Display name for the wrapped command")
 	wrapFlags.BoolVar(&flags.Stdout, "stdout", false, "Output notifications to stdout")
 	wrapFlags.BoolVar(&flags.Verbose, "verbose", false, "Show all activity notifications")
@@ -107,6 +207,7 @@ USAGE:
 
 FLAGS:
   --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
   --name NAME      Display name for notifications (default: command name)
   --stdout         Output notifications to stdout instead of Slack
   --verbose        Show all activity notifications (default: only 'cooling')
@@ -172,13 +273,19 @@ func parseDaemonFlags(flags *Flags, cmd string) *Flags {
 
 	daemonFlags := flag.NewFlagSet(cmd, flag.ExitOnError)
 	daemonFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	daemonFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
 
 	if cmd == "logs" {
 		daemonFlags.BoolVar(&flags.DaemonFollow, "f", false, "Follow log output")
 	}
 
 	if cmd == "start" || cmd == "restart" {
+		daemonFlags.StringVar(&flags.Profile, "profile", "", "Config profile to use")
 		daemonFlags.StringVar(&flags.Agent, "agent", "", "Filter to specific agent")
+		daemonFlags.DurationVar(&flags.Backfill, "backfill", 0, "Seed state from recent log history on start")
+		daemonFlags.DurationVar(&flags.ActiveWindow, "active-window", 0, "Auto-detect only agents with recent log activity")
+		daemonFlags.IntVar(&flags.PID, "pid", 0, "Track this process ID instead of auto-detecting")
+		daemonFlags.BoolVar(&flags.JSONLogs, "json-logs", false, "Write the daemon log as one JSON object per line")
 	}
 
 	daemonFlags.Usage = func() {
@@ -191,18 +298,27 @@ USAGE:
 
 FLAGS:
   --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
+  --profile NAME   Config profile to use (default: $FIREBELL_PROFILE)
   --agent NAME     Filter to specific agent
+  --backfill DUR   Seed state from recent log history (e.g. 2m)
+  --active-window DUR
+                   Auto-detect only agents with log activity within DUR (e.g. 24h)
+  --pid PID        Track this process instead of auto-detecting
+  --json-logs      Write the daemon log as JSONL for log shippers
 
 EXAMPLES:
   firebell start
   firebell start --agent claude
+  firebell start --backfill 2m
+  firebell start --pid 12345
 
 `)
 		case "stop":
 			fmt.Fprintf(os.Stderr, `firebell stop - Stop the running daemon
 
 USAGE:
-  firebell stop
+  firebell stop [--config-dir DIR]
 
 `)
 		case "restart":
@@ -213,14 +329,21 @@ USAGE:
 
 FLAGS:
   --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
+  --profile NAME   Config profile to use (default: $FIREBELL_PROFILE)
   --agent NAME     Filter to specific agent
+  --backfill DUR   Seed state from recent log history (e.g. 2m)
+  --active-window DUR
+                   Auto-detect only agents with log activity within DUR (e.g. 24h)
+  --pid PID        Track this process instead of auto-detecting
+  --json-logs      Write the daemon log as JSONL for log shippers
 
 `)
 		case "status":
 			fmt.Fprintf(os.Stderr, `firebell status - Show daemon status
 
 USAGE:
-  firebell status
+  firebell status [--config-dir DIR]
 
 `)
 		case "logs":
@@ -231,6 +354,7 @@ USAGE:
 
 FLAGS:
   -f               Follow log output (like tail -f)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
 
 EXAMPLES:
   firebell logs
@@ -250,6 +374,10 @@ func parseEventsFlags(flags *Flags) *Flags {
 
 	eventsFlags := flag.NewFlagSet("events", flag.ExitOnError)
 	eventsFlags.BoolVar(&flags.EventsFollow, "f", false, "Follow event output")
+	eventsFlags.IntVar(&flags.EventsTail, "tail", 0, "Print the last N events (across rotated files)")
+	eventsFlags.IntVar(&flags.EventsOffset, "offset", 0, "Skip the newest N events (use with --tail to page back)")
+	eventsFlags.BoolVar(&flags.EventsAll, "all", false, "Include daemon and status events in counts")
+	eventsFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
 
 	eventsFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, `firebell events - View event file for external integrations
@@ -259,10 +387,14 @@ USAGE:
 
 FLAGS:
   -f               Follow event output (like tail -f)
+  --tail N         Print the last N events as JSON lines, including rotated files
+  --offset N       Skip the newest N events (page back with --tail)
+  --all            Count daemon and status events too (default: agent events only)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
 
 DESCRIPTION:
   The event file contains JSON events that external applications can consume.
-  Events include: activity, cooling, process_exit, daemon_start, daemon_stop.
+  Events include: activity, cooling, process_start, process_exit, daemon_start, daemon_stop.
 
   Location: ~/.firebell/events.jsonl
 
@@ -277,6 +409,10 @@ EXAMPLES:
   # Follow events in real-time
   firebell events -f
 
+  # Page back through history, 100 at a time
+  firebell events --tail 100
+  firebell events --tail 100 --offset 100
+
   # Process events with jq
   tail -f ~/.firebell/events.jsonl | jq -r '.agent + ": " + .event'
 
@@ -341,6 +477,7 @@ func parseListenFlags(flags *Flags) *Flags {
 
 	listenFlags := flag.NewFlagSet("listen", flag.ExitOnError)
 	listenFlags.BoolVar(&flags.ListenJSON, "json", false, "Output raw JSON")
+	listenFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
 
 	listenFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, `firebell listen - Connect to daemon socket and receive events
@@ -350,6 +487,7 @@ USAGE:
 
 FLAGS:
   --json             Output raw JSON (default: formatted)
+  --config-dir DIR   Config and runtime directory (default: ~/.firebell)
 
 DESCRIPTION:
   Connects to the firebell daemon's Unix socket and displays events in real-time.
@@ -374,6 +512,424 @@ EXAMPLES:
 	return flags
 }
 
+// parseWatchFlags parses flags for the watch subcommand.
+// The path may appear before or after the flags.
+func parseWatchFlags(flags *Flags, args []string) *Flags {
+	flags.Watch = true
+
+	watchFlags := flag.NewFlagSet("watch", flag.ExitOnError)
+	watchFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	watchFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+	watchFlags.StringVar(&flags.WatchPathName, "name", "", "Display name for notifications")
+	watchFlags.BoolVar(&flags.Verbose, "verbose", false, "Show all activity notifications")
+
+	watchFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell watch - Monitor an arbitrary log file or directory
+
+USAGE:
+  firebell watch <path> [flags]
+
+FLAGS:
+  --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
+  --name NAME      Display name for notifications (default: file name)
+  --verbose        Show all activity notifications
+
+DESCRIPTION:
+  Tails the path with the generic matcher and prints notifications to stdout.
+  No agent registry entry or daemon is needed. Directories are scanned for
+  .log, .jsonl, .json, and .txt files.
+
+EXAMPLES:
+  firebell watch /var/log/myagent.log --name "My Agent"
+  firebell watch ~/.myagent/logs --verbose
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.WatchPath = args[0]
+		args = args[1:]
+	}
+	watchFlags.Parse(args)
+	if flags.WatchPath == "" && watchFlags.NArg() > 0 {
+		flags.WatchPath = watchFlags.Arg(0)
+	}
+
+	return flags
+}
+
+// parseBenchmarkFlags parses flags for the benchmark subcommand.
+func parseBenchmarkFlags(flags *Flags, args []string) *Flags {
+	flags.Benchmark = true
+
+	benchFlags := flag.NewFlagSet("benchmark", flag.ExitOnError)
+	benchFlags.StringVar(&flags.Agent, "agent", "", "Agent whose matcher to run (default: generic fallback)")
+
+	benchFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell benchmark - Measure matcher performance on a log file
+
+USAGE:
+  firebell benchmark [--agent NAME] <path>
+
+FLAGS:
+  --agent NAME     Agent whose matcher to run (default: generic fallback)
+
+DESCRIPTION:
+  Runs the agent's matcher over every line of the file and reports lines/sec,
+  allocations, and how many lines matched each type. Include the output when
+  reporting performance issues.
+
+EXAMPLES:
+  firebell benchmark --agent claude ~/.claude/projects/myproj/session.jsonl
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.BenchmarkPath = args[0]
+		args = args[1:]
+	}
+	benchFlags.Parse(args)
+	if flags.BenchmarkPath == "" && benchFlags.NArg() > 0 {
+		flags.BenchmarkPath = benchFlags.Arg(0)
+	}
+
+	return flags
+}
+
+// parseReasonsFlags parses flags for the reasons subcommand.
+func parseReasonsFlags(flags *Flags, args []string) *Flags {
+	flags.Reasons = true
+
+	reasonsFlags := flag.NewFlagSet("reasons", flag.ExitOnError)
+	reasonsFlags.StringVar(&flags.Agent, "agent", "", "Agent whose matcher to run (default: generic fallback)")
+
+	reasonsFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell reasons - Count which matcher heuristics fire on a log file
+
+USAGE:
+  firebell reasons [--agent NAME] <path>
+
+FLAGS:
+  --agent NAME     Agent whose matcher to run (default: generic fallback)
+
+DESCRIPTION:
+  Runs the agent's matcher over every line of the file and prints how many
+  lines matched with each reason, most frequent first. Runs locally only.
+
+EXAMPLES:
+  firebell reasons --agent claude ~/.claude/projects/myproj/session.jsonl
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.ReasonsPath = args[0]
+		args = args[1:]
+	}
+	reasonsFlags.Parse(args)
+	if flags.ReasonsPath == "" && reasonsFlags.NArg() > 0 {
+		flags.ReasonsPath = reasonsFlags.Arg(0)
+	}
+
+	return flags
+}
+
+// parseNotifyFlags parses flags for the notify subcommand.
+func parseNotifyFlags(flags *Flags, args []string) *Flags {
+	flags.Notify = true
+
+	notifyFlags := flag.NewFlagSet("notify", flag.ExitOnError)
+	notifyFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	notifyFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+	notifyFlags.StringVar(&flags.NotifyTitle, "title", "", "Notification title")
+	notifyFlags.StringVar(&flags.NotifyMessage, "message", "", "Notification body")
+	notifyFlags.StringVar(&flags.Agent, "agent", "", "Agent name shown with the notification")
+
+	notifyFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell notify - Send a one-off notification
+
+USAGE:
+  firebell notify [flags] [message]
+
+FLAGS:
+  --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
+  --title TEXT     Notification title (default: "Notification")
+  --message TEXT   Notification body (or pass it as an argument)
+  --agent NAME     Agent name shown with the notification
+
+DESCRIPTION:
+  Loads the config, builds the configured notifier (Slack, stdout, event
+  file, webhooks), sends one notification, and exits.
+
+EXAMPLES:
+  firebell notify "Deploy finished"
+  make build && firebell notify --title "Build" --message "done" --agent "CI"
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.NotifyMessage = args[0]
+		args = args[1:]
+	}
+	notifyFlags.Parse(args)
+	if flags.NotifyMessage == "" && notifyFlags.NArg() > 0 {
+		flags.NotifyMessage = strings.Join(notifyFlags.Args(), " ")
+	}
+
+	return flags
+}
+
+// parseSnoozeFlags parses flags for the snooze subcommand.
+func parseSnoozeFlags(flags *Flags, args []string) *Flags {
+	flags.Snooze = true
+
+	snoozeFlags := flag.NewFlagSet("snooze", flag.ExitOnError)
+	snoozeFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+
+	snoozeFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell snooze - Silence alerts for a while
+
+USAGE:
+  firebell snooze [DURATION|off] [--config-dir DIR]
+
+DESCRIPTION:
+  Suppresses Slack, stdout, webhook, and socket notifications from the
+  running monitor or daemon until DURATION has passed. Events are still
+  written to the event file. With no argument, shows the current snooze.
+
+EXAMPLES:
+  firebell snooze 30m
+  firebell snooze off
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.SnoozeArg = args[0]
+		args = args[1:]
+	}
+	snoozeFlags.Parse(args)
+	if flags.SnoozeArg == "" && snoozeFlags.NArg() > 0 {
+		flags.SnoozeArg = snoozeFlags.Arg(0)
+	}
+
+	return flags
+}
+
+// parsePauseFlags parses flags for the pause and resume subcommands.
+func parsePauseFlags(flags *Flags, cmd string, args []string) *Flags {
+	flags.PauseCmd = cmd
+
+	pauseFlags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	pauseFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	pauseFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+
+	pauseFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell pause/resume - Pause or resume one agent
+
+USAGE:
+  firebell pause AGENT [--config PATH] [--config-dir DIR]
+  firebell resume AGENT [--config PATH] [--config-dir DIR]
+
+DESCRIPTION:
+  Tells the running daemon, over its socket (daemon.socket: true), to
+  stop processing AGENT's log lines and notifications, or to start again.
+  Other agents are unaffected. A pause lasts until resumed or the daemon
+  restarts.
+
+EXAMPLES:
+  firebell pause claude
+  firebell resume claude
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.PauseAgent = args[0]
+		args = args[1:]
+	}
+	pauseFlags.Parse(args)
+	if flags.PauseAgent == "" && pauseFlags.NArg() > 0 {
+		flags.PauseAgent = pauseFlags.Arg(0)
+	}
+	if flags.PauseAgent == "" {
+		pauseFlags.Usage()
+		os.Exit(2)
+	}
+
+	return flags
+}
+
+// parseTopFlags parses flags for the top subcommand.
+func parseTopFlags(flags *Flags, args []string) *Flags {
+	flags.Top = true
+
+	topFlags := flag.NewFlagSet("top", flag.ExitOnError)
+	topFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	topFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+	topFlags.DurationVar(&flags.TopInterval, "interval", time.Second, "Refresh interval")
+
+	topFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell top - Live per-agent activity meter
+
+USAGE:
+  firebell top [flags]
+
+FLAGS:
+  --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
+  --interval DUR   Refresh interval (default: 1s)
+
+DESCRIPTION:
+  Polls the running daemon's status over its socket (daemon.socket: true)
+  and redraws one screen: each agent's state, log lines per second, and
+  time since its last cue, plus the tracked process's CPU and memory.
+  Press Ctrl+C to exit.
+
+EXAMPLES:
+  firebell top
+  firebell top --interval 5s
+
+`)
+	}
+
+	topFlags.Parse(args)
+	if flags.TopInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --interval must be positive")
+		os.Exit(2)
+	}
+
+	return flags
+}
+
+// parseReplayFlags parses flags for the replay subcommand.
+func parseReplayFlags(flags *Flags, args []string) *Flags {
+	flags.Replay = true
+
+	replayFlags := flag.NewFlagSet("replay", flag.ExitOnError)
+	replayFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	replayFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+	replayFlags.Float64Var(&flags.ReplaySpeed, "speed", 1, "Playback speed (1 = original timing, 0 = no delay)")
+
+	replayFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell replay - Re-send recorded events through the configured notifier
+
+USAGE:
+  firebell replay [flags] <events.jsonl>
+
+FLAGS:
+  --config PATH    Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR Config and runtime directory (default: ~/.firebell)
+  --speed N        Playback speed: 1 = original timing, 10 = ten times
+                   faster, 0 = no delay (default: 1)
+
+DESCRIPTION:
+  Reads an event file, rebuilds each agent event's notification, and sends
+  it through the configured notifier (Slack, stdout, webhooks), keeping the
+  recorded spacing. Daemon start/stop and status events are skipped. Useful
+  for demos and for checking notifier changes against real data.
+
+EXAMPLES:
+  firebell replay ~/.firebell/events.jsonl --speed 10
+  firebell replay --config ./demo.yaml --speed 0 recorded.jsonl
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.ReplayPath = args[0]
+		args = args[1:]
+	}
+	replayFlags.Parse(args)
+	if flags.ReplayPath == "" && replayFlags.NArg() > 0 {
+		flags.ReplayPath = replayFlags.Arg(0)
+	}
+
+	return flags
+}
+
+// parseConfigFlags parses flags for the config subcommand.
+func parseConfigFlags(flags *Flags, args []string) *Flags {
+	configFlags := flag.NewFlagSet("config", flag.ExitOnError)
+	configFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	configFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+
+	configFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell config - Inspect the effective configuration
+
+USAGE:
+  firebell config env [--config PATH] [--config-dir DIR]
+
+DESCRIPTION:
+  env  Prints every config key of the effective config (file plus any
+       FIREBELL_* overrides) as a FIREBELL_* variable, one per line, in a
+       form usable as a Docker --env-file. The name is the key's path in
+       upper case: monitor.quiet_seconds is FIREBELL_MONITOR_QUIET_SECONDS.
+       Lists are comma-separated; maps and webhooks are JSON. Set any of
+       these variables to override the config file.
+
+EXAMPLES:
+  firebell config env > firebell.env
+  FIREBELL_NOTIFY_TYPE=stdout firebell
+
+`)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		flags.ConfigCmd = args[0]
+		args = args[1:]
+	}
+	configFlags.Parse(args)
+	if flags.ConfigCmd == "" {
+		flags.ConfigCmd = configFlags.Arg(0)
+	}
+	if flags.ConfigCmd != "env" {
+		configFlags.Usage()
+		os.Exit(2)
+	}
+
+	return flags
+}
+
+// parseDoctorFlags parses flags for the doctor subcommand.
+func parseDoctorFlags(flags *Flags, args []string) *Flags {
+	doctorFlags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	doctorFlags.StringVar(&flags.ConfigPath, "config", "", "Config file path")
+	doctorFlags.StringVar(&flags.ConfigDir, "config-dir", "", "Directory for config and runtime files")
+	doctorFlags.BoolVar(&flags.Fix, "fix", false, "Repair common problems before checking")
+
+	doctorFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `firebell doctor - Check (and repair) the firebell setup
+
+USAGE:
+  firebell doctor [--fix] [--config PATH] [--config-dir DIR]
+
+DESCRIPTION:
+  Runs the health check, like 'firebell --check'. With --fix, first
+  repairs what it safely can, reporting each change:
+    - removes a lock file left by a daemon that is no longer running
+    - creates missing config, event file, and socket directories
+    - writes a default config (stdout notifications) if none exists
+  An existing config is never overwritten. Running --fix again when
+  nothing is wrong changes nothing.
+
+EXAMPLES:
+  firebell doctor
+  firebell doctor --fix
+  firebell doctor --fix --config-dir /tmp/firebell
+
+`)
+	}
+
+	doctorFlags.Parse(args)
+	flags.Check = true
+	return flags
+}
+
 // customUsage provides user-friendly help text.
 func customUsage() {
 	fmt.Fprintf(os.Stderr, `firebell %s - Real-time AI CLI activity monitor`, Version)
@@ -388,10 +944,12 @@ USAGE:
   firebell logs [-f]                            View daemon logs
   firebell events [-f]                          View/follow event file
   firebell wrap [flags] -- <command> [args...]  Wrap a command
+  firebell watch <path> [--name NAME]           Watch an arbitrary log path
 
 GETTING STARTED:
   firebell --setup     Run interactive configuration wizard
   firebell --check     Verify log paths and show status
+  firebell doctor --fix  Repair a stale lock, missing dirs, or missing config
   firebell --stdout    Test without Slack (prints to terminal)
 
 DAEMON COMMANDS:
@@ -408,16 +966,37 @@ INTEGRATION COMMANDS:
 
 OTHER COMMANDS:
   wrap                Wrap a command and monitor its output
+  watch <path>        Monitor any log file or directory (stdout, no daemon)
+  benchmark <path>    Measure matcher speed on a log file (--agent NAME)
+  reasons <path>      Count lines per matcher reason in a log file (--agent NAME)
+  notify <message>    Send one notification via the configured notifier
+  snooze <dur|off>    Silence alerts (event file still written) for a while
+  doctor [--fix]      Health check; --fix repairs common setup problems
+  pause <agent>       Stop a running daemon processing one agent (resume <agent> undoes)
+  top                 Live per-agent activity meter for a running daemon
 
 FLAGS:
   --config PATH       Config file (default: ~/.firebell/config.yaml)
+  --config-dir DIR    Directory for config, logs, lock, socket, and events
+  --profile NAME      Merge this profile from the config's profiles section (or FIREBELL_PROFILE)
   --setup             Interactive configuration wizard
   --check             Health check and exit
+  --fix               With --check, repair common problems first
   --agent NAME        Filter to specific agent: codex, copilot, claude, gemini, opencode
   --stdout            Output to stdout instead of Slack (for testing)
   --verbose           Show all activity notifications (default: only 'cooling')
   --version           Print version and exit
   --migrate           Migrate v1 config to v2 YAML format
+  --backfill DUR      Seed state from recent log history on start (e.g. 2m)
+  --stdin             Read log lines from stdin, e.g. some-agent | firebell --stdin --agent claude
+  --active-window DUR Auto-detect only agents with log activity within DUR (e.g. 24h)
+  --pid PID           Track this process for CPU/idle/exit instead of auto-detecting
+  --json-logs         Write the daemon log as JSONL (each entry tagged "source": "firebell")
+  --print-config-path Print the config file path and exit
+  --print-event-path  Print the event file path and exit
+  --print-socket-path Print the daemon socket path and exit
+  --test-matcher AGENT LINE
+                      Match LINE with AGENT's matcher, print the match, and exit
 
 EXAMPLES:
   # First-time setup
@@ -444,6 +1023,9 @@ EXAMPLES:
   firebell wrap -- claude
   firebell wrap --name "My AI" -- python ai_script.py
 
+  # Find firebell's files from a script
+  tail -f "$(firebell --print-event-path --config-dir /tmp/firebell)"
+
 CONFIGURATION:
   Config file: ~/.firebell/config.yaml
   Edit this file to customize monitoring behavior, output verbosity, and advanced settings.
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
@@ -27,51 +29,203 @@ func DefaultConfigDir() string {
 	return filepath.Join(home, ".firebell")
 }
 
+// ResolveConfigDir returns dir if set, otherwise DefaultConfigDir.
+func ResolveConfigDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	return DefaultConfigDir()
+}
+
+// ResolveConfigPath returns the config file to load for the given --config
+// and --config-dir values. An explicit path wins over the directory.
+func ResolveConfigPath(path, dir string) string {
+	if path != "" {
+		return path
+	}
+	if dir != "" {
+		return filepath.Join(dir, "config.yaml")
+	}
+	return DefaultConfigPath()
+}
+
+// Paths are where firebell keeps the files scripts and hooks look for.
+type Paths struct {
+	Config    string // Config file (which may not exist yet)
+	EventFile string // Event file (daemon.event_file_path)
+	Socket    string // Daemon socket (daemon.socket_path)
+}
+
+// ResolvePaths resolves the files firebell uses for the given --config and
+// --config-dir values, honoring paths set in the config file and FIREBELL_*
+// overrides, as `firebell --print-config-path` and friends print them.
+// The config isn't validated, so the paths resolve before setup (the
+// defaults alone don't validate without a Slack webhook).
+func ResolvePaths(path, dir string) (Paths, error) {
+	configPath := ResolveConfigPath(path, dir)
+	cfg, _, err := load(configPath)
+	if err != nil {
+		return Paths{}, err
+	}
+	cfg.ApplyConfigDir(ResolveConfigDir(dir))
+	return Paths{Config: configPath, EventFile: cfg.Daemon.EventFilePath, Socket: cfg.Daemon.SocketPath}, nil
+}
+
+// ApplyConfigDir places the event file, socket, ready file, and retry queue under dir
+// unless the config sets explicit paths, so all runtime artifacts live together.
+// Explicit paths have ~ and environment variables expanded.
+func (c *Config) ApplyConfigDir(dir string) {
+	if c.Daemon.EventFilePath == "" {
+		c.Daemon.EventFilePath = filepath.Join(dir, "events.jsonl")
+	} else {
+		c.Daemon.EventFilePath = ExpandPath(c.Daemon.EventFilePath)
+	}
+	if c.Daemon.SocketPath == "" {
+		c.Daemon.SocketPath = filepath.Join(dir, "firebell.sock")
+	} else {
+		c.Daemon.SocketPath = ExpandPath(c.Daemon.SocketPath)
+	}
+	if c.Daemon.ReadyFile == "" {
+		c.Daemon.ReadyFile = filepath.Join(dir, "ready")
+	} else {
+		c.Daemon.ReadyFile = ExpandPath(c.Daemon.ReadyFile)
+	}
+	if c.Daemon.RetryQueueFile == "" {
+		c.Daemon.RetryQueueFile = filepath.Join(dir, "retry-queue.json")
+	} else {
+		c.Daemon.RetryQueueFile = ExpandPath(c.Daemon.RetryQueueFile)
+	}
+}
+
+// ExpandPath expands environment variables ($VAR, ${VAR}) and a leading ~
+// to the user's home directory.
+func ExpandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
 // Load loads configuration from the specified path, with auto-detection of format.
 // If path doesn't exist, returns default config.
-// Supports both v2 YAML and v1 JSON (with migration warnings).
+// The decoder is chosen by extension: .json and .toml decode into Config
+// directly; anything else is v2 YAML, falling back to v1 JSON (with migration
+// warnings). A .json file holding a v1 config is also migrated. The profile
+// named by FIREBELL_PROFILE (see ProfileEnv) is merged over the file.
+// The result is validated, with or without a file. Without one, the Slack
+// webhook the default notifier needs isn't required: setup provides it, or
+// the caller overrides the notifier (e.g. --stdout) after loading.
 func Load(path string) (*Config, error) {
+	cfg, fromFile, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	check := cfg
+	if !fromFile && cfg.Notify.Type == "slack" && cfg.Notify.Slack.Webhook == "" {
+		unset := *cfg
+		unset.Notify.Type = "none"
+		check = &unset
+	}
+	if verr := check.Validate(); verr != nil {
+		return nil, verr
+	}
+	return cfg, nil
+}
+
+// load is Load without validation. It also reports whether the config came
+// from a file rather than the defaults.
+func load(path string) (*Config, bool, error) {
 	// If no path specified, use default
 	if path == "" {
 		path = DefaultConfigPath()
 	}
 
-	// If file doesn't exist, return default config
+	// If file doesn't exist, use the default config with env overrides
+	// (any profile is unknown)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return DefaultConfig(), nil
+		cfg, err := finishLoad(DefaultConfig(), nil, "yaml")
+		return cfg, false, err
 	}
 
+	cfg, err := loadFile(path)
+	return cfg, true, err
+}
+
+// loadFile loads the config file at path (see Load).
+func loadFile(path string) (*Config, error) {
 	// Read file
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if isV1JSON(data) {
+			return loadV1JSON(path, data)
+		}
+		cfg, err := parseV2JSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON config: %w", err)
+		}
+		return finishLoad(cfg, data, "json")
+
+	case ".toml":
+		cfg, err := parseV2TOML(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOML config: %w", err)
+		}
+		return finishLoad(cfg, data, "toml")
+	}
+
 	// Try v2 YAML first
 	cfg, err := parseV2YAML(data)
 	if err == nil {
-		if verr := cfg.Validate(); verr != nil {
-			return nil, verr
-		}
-		return cfg, nil
+		return finishLoad(cfg, data, "yaml")
 	}
 
 	// Fallback to v1 JSON
-	cfg, err = parseV1JSON(data)
-	if err == nil {
-		fmt.Fprintln(os.Stderr, "WARNING: v1 config detected at", path)
-		fmt.Fprintln(os.Stderr, "Run 'firebell --setup' to migrate to v2 YAML format")
-		fmt.Fprintln(os.Stderr, "")
-
-		if verr := cfg.Validate(); verr != nil {
-			return nil, verr
-		}
-		return cfg, nil
+	if _, err = parseV1JSON(data); err == nil {
+		return loadV1JSON(path, data)
 	}
 
 	return nil, fmt.Errorf("invalid config format (not v2 YAML or v1 JSON): %w", err)
 }
 
+// loadV1JSON parses a v1 JSON config, warning that it should be migrated.
+func loadV1JSON(path string, data []byte) (*Config, error) {
+	cfg, err := parseV1JSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(os.Stderr, "WARNING: v1 config detected at", path)
+	fmt.Fprintln(os.Stderr, "Run 'firebell --setup' to migrate to v2 YAML format")
+	fmt.Fprintln(os.Stderr, "")
+
+	return finishLoad(cfg, data, "json")
+}
+
+// finishLoad merges the selected profile from the file data (in format, as
+// for applyProfile) and then FIREBELL_* environment overrides (see ApplyEnv)
+// over a parsed config, or the defaults when there is no file.
+func finishLoad(cfg *Config, data []byte, format string) (*Config, error) {
+	if err := applyProfile(cfg, data, format, selectedProfile()); err != nil {
+		return nil, err
+	}
+	if _, err := ApplyEnv(cfg, os.Environ()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 // Save writes the configuration to the specified path in YAML format.
 func Save(cfg *Config, path string) error {
 	if path == "" {
@@ -113,6 +267,43 @@ func parseV2YAML(data []byte) (*Config, error) {
 	return &cfg, nil
 }
 
+// parseV2JSON parses data as a v2 config in JSON form.
+func parseV2JSON(data []byte) (*Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Version == "" {
+		cfg.Version = "2"
+	}
+	return &cfg, nil
+}
+
+// parseV2TOML parses data as a v2 config in TOML form.
+func parseV2TOML(data []byte) (*Config, error) {
+	var cfg Config
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Version == "" {
+		cfg.Version = "2"
+	}
+	return &cfg, nil
+}
+
+// isV1JSON reports whether data is a v1 JSON config (a top-level "webhook"
+// string and none of the v2 sections).
+func isV1JSON(data []byte) bool {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return false
+	}
+	_, hasWebhook := top["webhook"]
+	_, hasNotify := top["notify"]
+	_, hasVersion := top["version"]
+	return hasWebhook && !hasNotify && !hasVersion
+}
+
 // parseV1JSON attempts to parse data as v1 JSON format and migrate to v2.
 func parseV1JSON(data []byte) (*Config, error) {
 	var v1 struct {
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv selects a profile from the config file's profiles section, as
+// --profile does (main sets it from the flag so every load sees it).
+//
+//	notify:
+//	  type: stdout
+//	profiles:
+//	  work:
+//	    notify:
+//	      type: slack
+//	      slack: {webhook: https://hooks.slack.com/services/...}
+//
+// A profile overrides only the keys it sets: maps gain its entries and
+// lists are replaced. FIREBELL_* overrides still apply on top.
+const ProfileEnv = "FIREBELL_PROFILE"
+
+// applyProfile merges profile name from the config file data over cfg.
+// format is the file's format as Load decoded it: "yaml", "json", or "toml".
+func applyProfile(cfg *Config, data []byte, format, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	var names []string
+	var apply func(*Config) error
+	switch format {
+	case "json":
+		var file struct {
+			Profiles map[string]json.RawMessage `json:"profiles"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return err
+		}
+		names = slices.Sorted(maps.Keys(file.Profiles))
+		if raw, ok := file.Profiles[name]; ok {
+			apply = func(c *Config) error { return json.Unmarshal(raw, c) }
+		}
+
+	case "toml":
+		var file struct {
+			Profiles map[string]toml.Primitive `toml:"profiles"`
+		}
+		md, err := toml.Decode(string(data), &file)
+		if err != nil {
+			return err
+		}
+		names = slices.Sorted(maps.Keys(file.Profiles))
+		if prim, ok := file.Profiles[name]; ok {
+			apply = func(c *Config) error { return md.PrimitiveDecode(prim, c) }
+		}
+
+	default:
+		var file struct {
+			Profiles map[string]yaml.Node `yaml:"profiles"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return err
+		}
+		names = slices.Sorted(maps.Keys(file.Profiles))
+		if node, ok := file.Profiles[name]; ok {
+			apply = func(c *Config) error { return node.Decode(c) }
+		}
+	}
+
+	if apply == nil {
+		return unknownProfileError(name, names)
+	}
+	if err := apply(cfg); err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// unknownProfileError reports a profile that is not among names.
+func unknownProfileError(name string, names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("unknown profile %q: the config file defines no profiles", name)
+	}
+	return fmt.Errorf("unknown profile %q (profiles: %s)", name, strings.Join(names, ", "))
+}
+
+// selectedProfile returns the profile chosen by FIREBELL_PROFILE ("" = none).
+func selectedProfile() string {
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
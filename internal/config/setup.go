@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +18,7 @@ type AgentInfo struct {
 	Name        string
 	DisplayName string
 	LogPath     string
+	AltLogPaths []string // Other known log locations (may use $VARS)
 }
 
 // SetupAgentProvider is a function type for getting agent information.
@@ -32,6 +32,8 @@ type SetupWebhookTester func(webhook string) error
 type SetupOptions struct {
 	GetAgents     SetupAgentProvider
 	TestWebhook   SetupWebhookTester
+	ConfigPath    string // Where to save the config (empty = DefaultConfigPath)
+	ConfigDir     string // Directory for firebell's files (empty = DefaultConfigDir)
 }
 
 // SetupWizard runs the interactive configuration wizard.
@@ -64,8 +66,8 @@ func SetupWizard(opts SetupOptions) error {
 	}
 
 	// Save configuration
-	configPath := DefaultConfigPath()
-	if err := ensureConfigDir(); err != nil {
+	configPath := ResolveConfigPath(opts.ConfigPath, opts.ConfigDir)
+	if err := ensureConfigDir(ResolveConfigDir(opts.ConfigDir)); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
@@ -140,11 +142,18 @@ func setupAgents(reader *bufio.Reader, cfg *Config, getAgents SetupAgentProvider
 	var activeNames []string
 	fmt.Println("  Detected agents:")
 	for _, agent := range agents {
-		expanded := expandPath(agent.LogPath)
+		expanded := ExpandPath(agent.LogPath)
 		info, err := os.Stat(expanded)
 
 		if err != nil {
 			fmt.Printf("    ✗ %s (not found)\n", agent.DisplayName)
+			if found := ProbeLogPath(agent); found != "" && confirmLogPath(reader, found) {
+				if cfg.Agents.Paths == nil {
+					cfg.Agents.Paths = make(map[string]string)
+				}
+				cfg.Agents.Paths[agent.Name] = found
+				activeNames = append(activeNames, agent.Name)
+			}
 		} else {
 			activeNames = append(activeNames, agent.Name)
 			age := ""
@@ -184,6 +193,63 @@ func setupAgents(reader *bufio.Reader, cfg *Config, getAgents SetupAgentProvider
 	return nil
 }
 
+// confirmLogPath asks whether to record a discovered log path.
+func confirmLogPath(reader *bufio.Reader, path string) bool {
+	fmt.Printf("      Found logs at %s. Use this path? [Y/n]: ", path)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "" || response == "y" || response == "yes"
+}
+
+// ProbeLogPath looks for an agent's logs outside its default location and
+// returns the first candidate that exists, or "" if none does. Candidates
+// are the agent's known alternates, then XDG-style variants of the default
+// (see logPathCandidates). Paths are returned as written, with any $VARS
+// expanded so the recorded path doesn't depend on the environment.
+func ProbeLogPath(agent AgentInfo) string {
+	for _, candidate := range logPathCandidates(agent) {
+		if _, err := os.Stat(ExpandPath(candidate)); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// logPathCandidates lists alternate log locations for an agent. A default of
+// ~/.tool/logs yields ~/.config/tool/logs and ~/.local/share/tool/logs; a
+// default under ~/.local/share or ~/.local/state yields ~/.tool/...
+// Candidates referring to unset environment variables are skipped.
+func logPathCandidates(agent AgentInfo) []string {
+	var candidates []string
+	for _, path := range agent.AltLogPaths {
+		if expanded, ok := expandSetEnv(path); ok {
+			candidates = append(candidates, expanded)
+		}
+	}
+
+	switch path := agent.LogPath; {
+	case strings.HasPrefix(path, "~/.local/share/"), strings.HasPrefix(path, "~/.local/state/"):
+		candidates = append(candidates, "~/."+path[len("~/.local/share/"):])
+	case strings.HasPrefix(path, "~/."):
+		rest := path[len("~/."):]
+		candidates = append(candidates, "~/.config/"+rest, "~/.local/share/"+rest)
+	}
+	return candidates
+}
+
+// expandSetEnv expands $VARS in path, reporting false if any is unset.
+func expandSetEnv(path string) (string, bool) {
+	ok := true
+	expanded := os.Expand(path, func(key string) string {
+		value := os.Getenv(key)
+		if value == "" {
+			ok = false
+		}
+		return value
+	})
+	return expanded, ok
+}
+
 // selectAgents prompts user to select agents from a list.
 func selectAgents(reader *bufio.Reader, agents []AgentInfo) []string {
 	fmt.Println()
@@ -286,27 +352,8 @@ func promptString(reader *bufio.Reader, prompt string) string {
 	return strings.TrimSpace(input)
 }
 
-// expandPath expands ~ to home directory.
-func expandPath(path string) string {
-	if len(path) == 0 {
-		return path
-	}
-	if path[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return path
-		}
-		if len(path) == 1 {
-			return home
-		}
-		return filepath.Join(home, path[1:])
-	}
-	return path
-}
-
 // ensureConfigDir creates the config directory if it doesn't exist.
-func ensureConfigDir() error {
-	dir := filepath.Dir(DefaultConfigPath())
+func ensureConfigDir(dir string) error {
 	return os.MkdirAll(dir, 0755)
 }
 
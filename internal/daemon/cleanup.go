@@ -33,23 +33,9 @@ func CleanupLogs(logDir string, retentionDays int) (int, error) {
 
 		name := entry.Name()
 
-		// Skip symlinks and non-log files
-		if name == "firebell.log" {
-			continue
-		}
-
-		// Only process firebell-*.log files
-		if !strings.HasPrefix(name, "firebell-") || !strings.HasSuffix(name, ".log") {
-			continue
-		}
-
-		// Parse date from filename: firebell-2006-01-02.log
-		dateStr := strings.TrimPrefix(name, "firebell-")
-		dateStr = strings.TrimSuffix(dateStr, ".log")
-
-		logDate, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			// Can't parse date, skip
+		// Only process daily logs (skips the symlinks and other files)
+		logDate, ok := dailyLogDate(name)
+		if !ok {
 			continue
 		}
 
@@ -68,6 +54,29 @@ func CleanupLogs(logDir string, retentionDays int) (int, error) {
 	return deleted, nil
 }
 
+// dailyLogs are the names of the logs rotated daily in the log directory:
+// the daemon log and the notification log.
+var dailyLogs = []string{LogSource, NotificationLogName}
+
+// dailyLogDate returns the date of a daily log file, named like
+// firebell-2006-01-02.log, and whether filename is one.
+func dailyLogDate(filename string) (time.Time, bool) {
+	for _, name := range dailyLogs {
+		dateStr, ok := strings.CutPrefix(filename, name+"-")
+		if !ok {
+			continue
+		}
+		dateStr, ok = strings.CutSuffix(dateStr, ".log")
+		if !ok {
+			continue
+		}
+		if date, err := time.Parse("2006-01-02", dateStr); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // CleanupOnStart runs cleanup when daemon starts.
 func CleanupOnStart(dir string, retentionDays int) {
 	logDir := filepath.Join(dir, "logs")
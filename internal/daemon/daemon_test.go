@@ -1,10 +1,15 @@
 package daemon
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/notify"
 )
 
 func TestNewLock(t *testing.T) {
@@ -184,6 +189,8 @@ func TestCleanupLogs(t *testing.T) {
 	oldDate := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
 	oldLog := filepath.Join(logDir, "firebell-"+oldDate+".log")
 	os.WriteFile(oldLog, []byte("old log"), 0644)
+	oldNotifications := filepath.Join(logDir, NotificationLogName+"-"+oldDate+".log")
+	os.WriteFile(oldNotifications, []byte("old notifications"), 0644)
 
 	// Create recent log file
 	recentDate := time.Now().Format("2006-01-02")
@@ -196,14 +203,17 @@ func TestCleanupLogs(t *testing.T) {
 		t.Fatalf("CleanupLogs failed: %v", err)
 	}
 
-	if deleted != 1 {
-		t.Errorf("Deleted = %d, want 1", deleted)
+	if deleted != 2 {
+		t.Errorf("Deleted = %d, want 2", deleted)
 	}
 
-	// Old log should be deleted
+	// Old logs should be deleted
 	if _, err := os.Stat(oldLog); !os.IsNotExist(err) {
 		t.Error("Old log file was not deleted")
 	}
+	if _, err := os.Stat(oldNotifications); !os.IsNotExist(err) {
+		t.Error("Old notification log was not deleted")
+	}
 
 	// Recent log should exist
 	if _, err := os.Stat(recentLog); os.IsNotExist(err) {
@@ -299,3 +309,185 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestArtifactsUnderConfigDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fb1")
+
+	cfg := config.DefaultConfig()
+	cfg.ApplyConfigDir(dir)
+
+	lock := NewLock(dir)
+	if err := lock.TryLock(); err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	defer lock.Unlock()
+
+	logger, err := NewLogger(dir)
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	defer logger.Close()
+	logger.Info("test")
+
+	server, err := NewSocketServer(cfg.Daemon.SocketPath)
+	if err != nil {
+		t.Fatalf("NewSocketServer failed: %v", err)
+	}
+	defer server.Close()
+
+	ef, err := notify.NewEventFileNotifier(cfg.Daemon.EventFilePath, cfg.Daemon.EventFileMaxSize)
+	if err != nil {
+		t.Fatalf("NewEventFileNotifier failed: %v", err)
+	}
+	defer ef.Close()
+	ef.EmitDaemonStart()
+
+	for _, name := range []string{"firebell.lock", "firebell.sock", "events.jsonl", "logs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s under config dir: %v", name, err)
+		}
+	}
+}
+
+func TestLoggerJSON(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(dir)
+	if err != nil {
+		t.Fatalf("NewLogger failed: %v", err)
+	}
+	logger.SetJSON(true)
+
+	logger.Info("Monitoring started")
+	logger.LogEvent(LevelWarn, "claude", "cooling", "Agent idle", "")
+	logger.Close()
+
+	data, err := os.ReadFile(logger.LogPath())
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 JSON lines, got %d: %q", len(lines), data)
+	}
+	for _, line := range lines {
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Line is not JSON: %q", line)
+		}
+		if entry.Source != LogSource {
+			t.Errorf("source = %q, want %q", entry.Source, LogSource)
+		}
+	}
+
+	var entry LogEntry
+	json.Unmarshal([]byte(lines[1]), &entry)
+	if entry.Agent != "claude" || entry.Event != "cooling" || entry.Level != "WARN" {
+		t.Errorf("Unexpected event entry: %+v", entry)
+	}
+}
+
+func TestRemoveStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	lock := NewLock(dir)
+
+	// No lock file: nothing to do
+	if done, err := RemoveStaleLock(dir); err != nil || done != "" {
+		t.Errorf("RemoveStaleLock(no file) = %q, %v; want nothing", done, err)
+	}
+
+	// A held lock is left alone
+	if err := lock.TryLock(); err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	if done, err := RemoveStaleLock(dir); err != nil || done != "" {
+		t.Errorf("RemoveStaleLock(held) = %q, %v; want nothing", done, err)
+	}
+	if _, err := os.Stat(lock.Path()); err != nil {
+		t.Errorf("Held lock file was removed: %v", err)
+	}
+	lock.Unlock()
+
+	// A lock file left by a killed daemon is removed, once
+	if err := os.WriteFile(lock.Path(), []byte("4242\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	done, err := RemoveStaleLock(dir)
+	if err != nil {
+		t.Fatalf("RemoveStaleLock(stale) failed: %v", err)
+	}
+	if !strings.Contains(done, "PID 4242") {
+		t.Errorf("RemoveStaleLock(stale) = %q, want mention of PID 4242", done)
+	}
+	if _, err := os.Stat(lock.Path()); !os.IsNotExist(err) {
+		t.Errorf("Stale lock file still exists: %v", err)
+	}
+	if done, err := RemoveStaleLock(dir); err != nil || done != "" {
+		t.Errorf("RemoveStaleLock(again) = %q, %v; want nothing", done, err)
+	}
+}
+
+func TestFix(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fb")
+	configPath := filepath.Join(dir, "config.yaml")
+
+	// Fresh install: config dir and a loadable default config
+	fixed, err := Fix(dir, configPath)
+	if err != nil {
+		t.Fatalf("Fix failed: %v", err)
+	}
+	if len(fixed) != 2 {
+		t.Errorf("Fix = %q, want config dir and config", fixed)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Default config doesn't load: %v", err)
+	}
+	if cfg.Notify.Type != "stdout" {
+		t.Errorf("Default config notify type = %q, want stdout", cfg.Notify.Type)
+	}
+
+	// An event file outside the config dir gets its directory; a stale lock
+	// is removed
+	cfg.Daemon.EventFile = true
+	cfg.Daemon.EventFilePath = filepath.Join(dir, "events", "firebell.jsonl")
+	if err := config.Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(NewLock(dir).Path(), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fixed, err = Fix(dir, configPath)
+	if err != nil {
+		t.Fatalf("Fix failed: %v", err)
+	}
+	want := []string{
+		"Created directory " + filepath.Join(dir, "events"),
+		"Removed stale lock " + NewLock(dir).Path(),
+	}
+	if strings.Join(fixed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Fix = %q, want %q", fixed, want)
+	}
+
+	// Nothing left to fix
+	if fixed, err := Fix(dir, configPath); err != nil || len(fixed) != 0 {
+		t.Errorf("Fix(again) = %q, %v; want nothing", fixed, err)
+	}
+}
+
+func TestFixKeepsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	bad := []byte("notify: [not valid\n")
+	if err := os.WriteFile(configPath, bad, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Fix(dir, configPath); err != nil {
+		t.Fatalf("Fix failed: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || string(data) != string(bad) {
+		t.Errorf("Config was rewritten: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"firebell/internal/config"
+)
+
+// The fixes below are what `firebell doctor --fix` repairs. Each is
+// idempotent: it returns what it changed, or "" if there was nothing to fix.
+
+// RemoveStaleLock removes the lock file in dir if no process holds it, as
+// left behind by a daemon that was killed.
+func RemoveStaleLock(dir string) (string, error) {
+	l := NewLock(dir)
+	f, err := os.Open(l.Path())
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	pid := l.readPID(f)
+	f.Close()
+
+	if running, _ := l.IsRunning(); running {
+		return "", nil
+	}
+	if err := os.Remove(l.Path()); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove stale lock: %w", err)
+	}
+	if pid > 0 {
+		return fmt.Sprintf("Removed stale lock %s (PID %d is not running)", l.Path(), pid), nil
+	}
+	return "Removed stale lock " + l.Path(), nil
+}
+
+// EnsureDir creates dir, and any missing parents, if it doesn't exist.
+func EnsureDir(dir string) (string, error) {
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return "", fmt.Errorf("%s exists but is not a directory", dir)
+		}
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return "Created directory " + dir, nil
+}
+
+// WriteDefaultConfig writes the default config to path if no file is there.
+// It notifies on stdout, as setup does without a Slack webhook, so firebell
+// starts until `firebell --setup` configures Slack.
+func WriteDefaultConfig(path string) (string, error) {
+	if _, err := os.Stat(path); err == nil {
+		return "", nil
+	}
+	cfg := config.DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	if err := config.Save(cfg, path); err != nil {
+		return "", err
+	}
+	return "Wrote default config " + path, nil
+}
+
+// Fix repairs what it can for the config directory dir and config file
+// configPath: missing directories, a missing config, and a stale lock. It
+// carries on past failures, returning what it fixed and every error.
+func Fix(dir, configPath string) ([]string, error) {
+	var fixed []string
+	var errs []error
+	apply := func(fix func() (string, error)) {
+		done, err := fix()
+		if done != "" {
+			fixed = append(fixed, done)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	apply(func() (string, error) { return EnsureDir(dir) })
+	apply(func() (string, error) { return WriteDefaultConfig(configPath) })
+
+	// The event file and socket may live elsewhere; an invalid config is
+	// reported by the check, not overwritten, so fall back to the defaults
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	cfg.ApplyConfigDir(dir)
+	if cfg.Daemon.EventFile {
+		apply(func() (string, error) { return EnsureDir(filepath.Dir(cfg.Daemon.EventFilePath)) })
+	}
+	if cfg.Daemon.Socket {
+		apply(func() (string, error) { return EnsureDir(filepath.Dir(cfg.Daemon.SocketPath)) })
+	}
+
+	apply(func() (string, error) { return RemoveStaleLock(dir) })
+	return fixed, errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"firebell/internal/notify"
+)
+
+// NotificationLogName names the human-readable notification log:
+// notifications-YYYY-MM-DD.log in the log directory, linked from
+// notifications.log.
+const NotificationLogName = "notifications"
+
+// FileNotifier writes each notification as one human-readable line, e.g.
+// "2025-01-09 14:03 Claude Code — Cooling — No activity for 20s", to a
+// Logger, which rotates the file daily.
+type FileNotifier struct {
+	logger *Logger
+	loc    *time.Location
+}
+
+// NewFileNotifier creates a notifier that writes to logger.
+func NewFileNotifier(logger *Logger) *FileNotifier {
+	return &FileNotifier{logger: logger, loc: time.Local}
+}
+
+// SetLocation sets the timezone of the timestamps (output.timezone).
+func (f *FileNotifier) SetLocation(loc *time.Location) {
+	if loc != nil {
+		f.loc = loc
+	}
+}
+
+// Name returns the notifier type.
+func (f *FileNotifier) Name() string {
+	return "file"
+}
+
+// Send writes the notification's line.
+func (f *FileNotifier) Send(ctx context.Context, n *notify.Notification) error {
+	return f.logger.WriteLine(f.format(n))
+}
+
+// format renders n as "time agent — title — message", leaving out an empty
+// agent or message and joining a multi-line message onto one line.
+func (f *FileNotifier) format(n *notify.Notification) string {
+	t := n.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	parts := []string{t.In(f.loc).Format("2006-01-02 15:04")}
+	if n.Agent != "" {
+		parts = append(parts, n.Agent+" —")
+	}
+	parts = append(parts, n.Title)
+	if msg := strings.Join(strings.Fields(n.Message), " "); msg != "" {
+		parts = append(parts, "— "+msg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Close closes the underlying log.
+func (f *FileNotifier) Close() error {
+	return f.logger.Close()
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"firebell/internal/notify"
+)
+
+func TestFileNotifierFormat(t *testing.T) {
+	f := NewFileNotifier(nil)
+	f.SetLocation(time.UTC)
+	at := time.Date(2025, 1, 9, 14, 3, 27, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		n    *notify.Notification
+		want string
+	}{
+		{
+			name: "agent, title, and message",
+			n:    &notify.Notification{Title: "Cooling", Agent: "Claude Code", Message: "No activity for 20s", Time: at},
+			want: "2025-01-09 14:03 Claude Code — Cooling — No activity for 20s",
+		},
+		{
+			name: "multi-line message joined",
+			n:    &notify.Notification{Title: "Holding", Agent: "Codex", Message: "Waiting for approval:\n  shell ls", Time: at},
+			want: "2025-01-09 14:03 Codex — Holding — Waiting for approval: shell ls",
+		},
+		{
+			name: "no agent or message",
+			n:    &notify.Notification{Title: "Daemon Started", Time: at},
+			want: "2025-01-09 14:03 Daemon Started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.format(tt.n); got != tt.want {
+				t.Errorf("format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileNotifierRotatesByDate(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewNamedLogger(dir, NotificationLogName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 9, 23, 59, 0, 0, time.Local)
+	logger.now = func() time.Time { return now }
+
+	f := NewFileNotifier(logger)
+	defer f.Close()
+	ctx := context.Background()
+
+	if err := f.Send(ctx, &notify.Notification{Title: "Cooling", Agent: "Claude Code", Message: "No activity for 20s", Time: now}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if err := f.Send(ctx, &notify.Notification{Title: "Holding", Agent: "Claude Code", Message: "Waiting for Bash", Time: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if got := read("notifications-2025-01-09.log"); got != "2025-01-09 23:59 Claude Code — Cooling — No activity for 20s\n" {
+		t.Errorf("First day = %q", got)
+	}
+	if got := read("notifications-2025-01-10.log"); got != "2025-01-10 00:01 Claude Code — Holding — Waiting for Bash\n" {
+		t.Errorf("Second day = %q", got)
+	}
+	if got := read("notifications.log"); !strings.Contains(got, "Holding") {
+		t.Errorf("notifications.log = %q, want it to link to the current day", got)
+	}
+}
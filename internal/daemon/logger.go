@@ -35,8 +35,14 @@ func (l LogLevel) String() string {
 	}
 }
 
+// LogSource tags every daemon log entry, so a log shipper can tell firebell's
+// own operational logs apart from agent events (which carry an "event" field
+// and live in the event file instead).
+const LogSource = "firebell"
+
 // LogEntry represents a structured log entry.
 type LogEntry struct {
+	Source    string    `json:"source"`
 	Timestamp time.Time `json:"timestamp"`
 	Level     string    `json:"level"`
 	Message   string    `json:"message"`
@@ -49,21 +55,31 @@ type LogEntry struct {
 type Logger struct {
 	mu          sync.Mutex
 	dir         string
+	name        string // Files are <name>-YYYY-MM-DD.log, linked from <name>.log
 	file        *os.File
 	currentDate string
 	minLevel    LogLevel
+	jsonOnly    bool             // Write one JSON object per line (--json-logs)
+	now         func() time.Time // Time source for entries and rotation
 }
 
 // NewLogger creates a new logger.
 func NewLogger(dir string) (*Logger, error) {
-	logDir := filepath.Join(dir, "logs")
+	return NewNamedLogger(filepath.Join(dir, "logs"), LogSource)
+}
+
+// NewNamedLogger creates a logger writing to name-YYYY-MM-DD.log files in
+// logDir, rotated daily like the daemon log.
+func NewNamedLogger(logDir, name string) (*Logger, error) {
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
 	l := &Logger{
 		dir:      logDir,
+		name:     name,
 		minLevel: LevelInfo,
+		now:      time.Now,
 	}
 
 	if err := l.openLogFile(); err != nil {
@@ -75,7 +91,7 @@ func NewLogger(dir string) (*Logger, error) {
 
 // openLogFile opens or rotates the log file based on date.
 func (l *Logger) openLogFile() error {
-	today := time.Now().Format("2006-01-02")
+	today := l.now().Format("2006-01-02")
 
 	if l.file != nil && l.currentDate == today {
 		return nil // Already have correct file open
@@ -87,7 +103,7 @@ func (l *Logger) openLogFile() error {
 	}
 
 	// Open new log file
-	logPath := filepath.Join(l.dir, fmt.Sprintf("firebell-%s.log", today))
+	logPath := filepath.Join(l.dir, fmt.Sprintf("%s-%s.log", l.name, today))
 	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %w", err)
@@ -97,7 +113,7 @@ func (l *Logger) openLogFile() error {
 	l.currentDate = today
 
 	// Update symlink to current log
-	symlink := filepath.Join(l.dir, "firebell.log")
+	symlink := filepath.Join(l.dir, l.name+".log")
 	os.Remove(symlink)
 	os.Symlink(filepath.Base(logPath), symlink)
 
@@ -111,6 +127,14 @@ func (l *Logger) SetLevel(level LogLevel) {
 	l.minLevel = level
 }
 
+// SetJSON switches the log to pure JSONL: one LogEntry object per line with
+// no human-readable line alongside it.
+func (l *Logger) SetJSON(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jsonOnly = enabled
+}
+
 // Log writes a log entry.
 func (l *Logger) Log(level LogLevel, msg string, args ...interface{}) {
 	if level < l.minLevel {
@@ -132,7 +156,8 @@ func (l *Logger) Log(level LogLevel, msg string, args ...interface{}) {
 	}
 
 	entry := LogEntry{
-		Timestamp: time.Now(),
+		Source:    LogSource,
+		Timestamp: l.now(),
 		Level:     level.String(),
 		Message:   msg,
 	}
@@ -157,7 +182,8 @@ func (l *Logger) LogEvent(level LogLevel, agent, event, msg string, details stri
 	}
 
 	entry := LogEntry{
-		Timestamp: time.Now(),
+		Source:    LogSource,
+		Timestamp: l.now(),
 		Level:     level.String(),
 		Message:   msg,
 		Agent:     agent,
@@ -168,12 +194,33 @@ func (l *Logger) LogEvent(level LogLevel, agent, event, msg string, details stri
 	l.writeEntry(entry)
 }
 
-// writeEntry writes a log entry in both human-readable and JSON format.
+// WriteLine writes line to the log as is, without a level or JSON copy.
+func (l *Logger) WriteLine(line string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Check for date rotation
+	if err := l.openLogFile(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(l.file, line)
+	return err
+}
+
+// writeEntry writes a log entry in both human-readable and JSON format,
+// or as a bare JSON line in JSON mode.
 func (l *Logger) writeEntry(entry LogEntry) {
 	if l.file == nil {
 		return
 	}
 
+	if l.jsonOnly {
+		if jsonData, err := json.Marshal(entry); err == nil {
+			fmt.Fprintf(l.file, "%s\n", jsonData)
+		}
+		return
+	}
+
 	// Human-readable format
 	ts := entry.Timestamp.Format("2006-01-02 15:04:05")
 	var line string
@@ -249,7 +296,7 @@ func (l *Logger) LogPath() string {
 	if l.file != nil {
 		return l.file.Name()
 	}
-	return filepath.Join(l.dir, "firebell.log")
+	return filepath.Join(l.dir, l.name+".log")
 }
 
 // Dir returns the log directory.
@@ -2,8 +2,10 @@
 package daemon
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -16,11 +18,27 @@ import (
 
 // SocketServer manages a Unix domain socket for external integrations.
 type SocketServer struct {
-	path     string
-	listener net.Listener
-	clients  map[net.Conn]bool
-	mu       sync.RWMutex
-	done     chan struct{}
+	path        string
+	listener    net.Listener
+	clients     map[net.Conn]bool
+	subscribers map[chan *notify.Event]bool // In-process consumers (e.g. WebSocket bridge)
+	status      func() any                  // Answers {"command":"status"} (nil = unavailable)
+	pause       func(string, bool) error    // Answers {"command":"pause"/"resume","agent":...} (nil = unavailable)
+	mu          sync.RWMutex
+	done        chan struct{}
+}
+
+// Broadcast gives a client write clientWriteTimeout per attempt, and this
+// many attempts in all, before dropping the client.
+const (
+	clientWriteAttempts = 3
+	clientWriteTimeout  = 2 * time.Second
+)
+
+// socketCommand is a request sent by a client, one JSON object per line.
+type socketCommand struct {
+	Command string `json:"command"`
+	Agent   string `json:"agent,omitempty"` // For pause and resume
 }
 
 // NewSocketServer creates a new socket server.
@@ -37,7 +55,7 @@ func NewSocketServer(path string) (*SocketServer, error) {
 	// Ensure parent directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create directory: %w", err)
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
 	// Remove existing socket file
@@ -56,10 +74,11 @@ func NewSocketServer(path string) (*SocketServer, error) {
 	}
 
 	return &SocketServer{
-		path:     path,
-		listener: listener,
-		clients:  make(map[net.Conn]bool),
-		done:     make(chan struct{}),
+		path:        path,
+		listener:    listener,
+		clients:     make(map[net.Conn]bool),
+		subscribers: make(map[chan *notify.Event]bool),
+		done:        make(chan struct{}),
 	}, nil
 }
 
@@ -68,6 +87,22 @@ func (s *SocketServer) Path() string {
 	return s.path
 }
 
+// SetStatusFunc sets the function whose result (marshaled as JSON) answers
+// a client's {"command":"status"} request.
+func (s *SocketServer) SetStatusFunc(fn func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = fn
+}
+
+// SetPauseFunc sets the function that pauses (true) or resumes (false) an
+// agent for a client's {"command":"pause","agent":NAME} or "resume" request.
+func (s *SocketServer) SetPauseFunc(fn func(agent string, paused bool) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pause = fn
+}
+
 // Start begins accepting connections in a goroutine.
 func (s *SocketServer) Start(ctx context.Context) {
 	go s.acceptLoop(ctx)
@@ -128,14 +163,56 @@ func (s *SocketServer) handleClient(conn net.Conn) {
 	data, _ := json.Marshal(welcome)
 	conn.Write(append(data, '\n'))
 
-	// Keep connection alive until closed
-	buf := make([]byte, 1024)
+	// Answer commands until the connection closes
+	scanner := bufio.NewScanner(conn)
 	for {
 		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
-		_, err := conn.Read(buf)
-		if err != nil {
+		if !scanner.Scan() {
 			return
 		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		reply, _ := json.Marshal(s.handleCommand(scanner.Bytes()))
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write(append(reply, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+// handleCommand returns the reply to one command line.
+func (s *SocketServer) handleCommand(line []byte) map[string]any {
+	var cmd socketCommand
+	if err := json.Unmarshal(line, &cmd); err != nil {
+		return map[string]any{"type": "error", "message": "invalid command: " + err.Error()}
+	}
+
+	switch cmd.Command {
+	case "status":
+		s.mu.RLock()
+		status := s.status
+		s.mu.RUnlock()
+		if status == nil {
+			return map[string]any{"type": "error", "message": "status unavailable"}
+		}
+		return map[string]any{"type": "status", "status": status()}
+	case "pause", "resume":
+		s.mu.RLock()
+		pause := s.pause
+		s.mu.RUnlock()
+		if pause == nil {
+			return map[string]any{"type": "error", "message": cmd.Command + " unavailable"}
+		}
+		if cmd.Agent == "" {
+			return map[string]any{"type": "error", "message": cmd.Command + " needs an agent"}
+		}
+		if err := pause(cmd.Agent, cmd.Command == "pause"); err != nil {
+			return map[string]any{"type": "error", "message": err.Error()}
+		}
+		return map[string]any{"type": cmd.Command + "d", "agent": cmd.Agent}
+	default:
+		return map[string]any{"type": "error", "message": fmt.Sprintf("unknown command: %q", cmd.Command)}
 	}
 }
 
@@ -152,12 +229,17 @@ func (s *SocketServer) Broadcast(event *notify.Event) {
 	for conn := range s.clients {
 		clients = append(clients, conn)
 	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber - drop rather than block the broadcast
+		}
+	}
 	s.mu.RUnlock()
 
 	for _, conn := range clients {
-		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-		_, err := conn.Write(data)
-		if err != nil {
+		if err := writeClient(conn, data, clientWriteTimeout); err != nil {
 			// Remove failed client
 			s.mu.Lock()
 			delete(s.clients, conn)
@@ -167,6 +249,47 @@ func (s *SocketServer) Broadcast(event *notify.Event) {
 	}
 }
 
+// writeClient writes data to a client within timeout per attempt. A client
+// that stops reading fills its socket buffer and the write times out; it is
+// tried again, clientWriteAttempts times in all, so a client that stalls
+// briefly isn't dropped. A partial write resumes where it stopped, so the
+// client never sees a line twice. Other errors (broken pipes, resets,
+// closed connections) are final.
+func writeClient(conn net.Conn, data []byte, timeout time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= clientWriteAttempts; attempt++ {
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+		var n int
+		n, err = conn.Write(data)
+		data = data[n:]
+		if err == nil || !errors.Is(err, os.ErrDeadlineExceeded) {
+			return err
+		}
+	}
+	return err
+}
+
+// Subscribe returns a channel that receives every broadcast event, and a
+// function to unsubscribe. Events are dropped if the buffer is full.
+// The channel is closed on unsubscribe or when the server closes.
+func (s *SocketServer) Subscribe(buffer int) (<-chan *notify.Event, func()) {
+	ch := make(chan *notify.Event, buffer)
+
+	s.mu.Lock()
+	s.subscribers[ch] = true
+	s.mu.Unlock()
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.subscribers[ch] {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
 // ClientCount returns the number of connected clients.
 func (s *SocketServer) ClientCount() int {
 	s.mu.RLock()
@@ -176,6 +299,11 @@ func (s *SocketServer) ClientCount() int {
 
 // Close shuts down the socket server.
 func (s *SocketServer) Close() error {
+	select {
+	case <-s.done:
+		return nil // Already closed
+	default:
+	}
 	close(s.done)
 
 	// Close all client connections
@@ -184,6 +312,10 @@ func (s *SocketServer) Close() error {
 		conn.Close()
 	}
 	s.clients = make(map[net.Conn]bool)
+	for ch := range s.subscribers {
+		close(ch)
+	}
+	s.subscribers = make(map[chan *notify.Event]bool)
 	s.mu.Unlock()
 
 	// Close listener
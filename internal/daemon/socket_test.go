@@ -2,14 +2,19 @@ package daemon
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"firebell/internal/config"
 	"firebell/internal/notify"
 )
 
@@ -43,6 +48,20 @@ func TestSocketServer_CreateAndClose(t *testing.T) {
 	}
 }
 
+func TestSocketServer_CreatesParentDirs(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "a", "b", "fb.sock")
+
+	server, err := NewSocketServer(sockPath)
+	if err != nil {
+		t.Fatalf("NewSocketServer failed: %v", err)
+	}
+	defer server.Close()
+
+	if _, err := os.Stat(sockPath); err != nil {
+		t.Errorf("Socket file not created: %v", err)
+	}
+}
+
 func TestSocketServer_DefaultPath(t *testing.T) {
 	// Test with empty path (should use default)
 	server, err := NewSocketServer("")
@@ -103,6 +122,114 @@ func TestSocketServer_AcceptConnection(t *testing.T) {
 	}
 }
 
+func TestSocketServer_StatusCommand(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "test.sock")
+	server, err := NewSocketServer(sockPath)
+	if err != nil {
+		t.Fatalf("NewSocketServer failed: %v", err)
+	}
+	defer server.Close()
+	server.SetStatusFunc(func() any {
+		return map[string]any{
+			"agents":  []map[string]string{{"name": "claude", "last_cue_type": "complete"}},
+			"process": map[string]any{"pid": 4242, "cpu_percent": 12.5},
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.Start(ctx)
+
+	conn, err := net.Dial("unix", sockPath)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatalf("Failed to read welcome: %v", err)
+	}
+
+	request := func(cmd string) map[string]any {
+		t.Helper()
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		if _, err := conn.Write([]byte(cmd + "\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read reply: %v", err)
+		}
+		var reply map[string]any
+		if err := json.Unmarshal([]byte(line), &reply); err != nil {
+			t.Fatalf("Reply is not JSON: %q", line)
+		}
+		return reply
+	}
+
+	reply := request(`{"command":"status"}`)
+	if reply["type"] != "status" {
+		t.Fatalf("Reply type = %v, want status", reply["type"])
+	}
+	status, ok := reply["status"].(map[string]any)
+	if !ok {
+		t.Fatalf("status = %#v, want object", reply["status"])
+	}
+	proc, _ := status["process"].(map[string]any)
+	if proc["pid"] != float64(4242) || proc["cpu_percent"] != 12.5 {
+		t.Errorf("process = %v", proc)
+	}
+	if agents, _ := status["agents"].([]any); len(agents) != 1 {
+		t.Errorf("agents = %v, want 1 entry", status["agents"])
+	}
+
+	if reply := request(`{"command":"reboot"}`); reply["type"] != "error" {
+		t.Errorf("Unknown command reply = %v, want error", reply)
+	}
+	if reply := request(`not json`); reply["type"] != "error" {
+		t.Errorf("Invalid command reply = %v, want error", reply)
+	}
+}
+
+func TestSocketServer_StatusUnavailable(t *testing.T) {
+	server := &SocketServer{}
+	reply := server.handleCommand([]byte(`{"command":"status"}`))
+	if reply["type"] != "error" || reply["message"] != "status unavailable" {
+		t.Errorf("Reply = %v, want status unavailable error", reply)
+	}
+}
+
+func TestSocketServer_PauseCommand(t *testing.T) {
+	server := &SocketServer{}
+	if reply := server.handleCommand([]byte(`{"command":"pause","agent":"claude"}`)); reply["type"] != "error" {
+		t.Errorf("Reply without a pause func = %v, want error", reply)
+	}
+
+	paused := map[string]bool{}
+	server.SetPauseFunc(func(agent string, p bool) error {
+		if agent != "claude" {
+			return fmt.Errorf("agent %q is not being monitored", agent)
+		}
+		paused[agent] = p
+		return nil
+	})
+
+	reply := server.handleCommand([]byte(`{"command":"pause","agent":"claude"}`))
+	if reply["type"] != "paused" || reply["agent"] != "claude" || !paused["claude"] {
+		t.Errorf("Pause reply = %v, paused = %v", reply, paused)
+	}
+	reply = server.handleCommand([]byte(`{"command":"resume","agent":"claude"}`))
+	if reply["type"] != "resumed" || paused["claude"] {
+		t.Errorf("Resume reply = %v, paused = %v", reply, paused)
+	}
+	if reply := server.handleCommand([]byte(`{"command":"pause"}`)); reply["type"] != "error" {
+		t.Errorf("Pause without agent reply = %v, want error", reply)
+	}
+	if reply := server.handleCommand([]byte(`{"command":"pause","agent":"codex"}`)); reply["type"] != "error" {
+		t.Errorf("Pause of unknown agent reply = %v, want error", reply)
+	}
+}
+
 func TestSocketServer_Broadcast(t *testing.T) {
 	tmpDir := t.TempDir()
 	sockPath := filepath.Join(tmpDir, "test.sock")
@@ -214,6 +341,57 @@ func TestSocketServer_ClientDisconnect(t *testing.T) {
 	}
 }
 
+func TestWriteClientRetriesTimeouts(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	data := bytes.Repeat([]byte("event line "), 1000)
+
+	tests := []struct {
+		name    string
+		readIn  time.Duration // When the client starts reading (0 = never)
+		close   bool          // The client hangs up first
+		wantErr error
+	}{
+		{"stalled client catches up", timeout * 3 / 2, false, nil},
+		{"client never reads", 0, false, os.ErrDeadlineExceeded},
+		{"closed client fails at once", 0, true, io.ErrClosedPipe},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer server.Close()
+
+			received := make(chan []byte, 1)
+			switch {
+			case tt.close:
+				client.Close()
+			case tt.readIn > 0:
+				go func() {
+					time.Sleep(tt.readIn)
+					got, _ := io.ReadAll(client)
+					received <- got
+				}()
+			default:
+				defer client.Close()
+			}
+
+			start := time.Now()
+			err := writeClient(server, data, timeout)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("writeClient() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.close && time.Since(start) >= timeout {
+				t.Errorf("writeClient took %v on a closed client, want no retries", time.Since(start))
+			}
+			if tt.wantErr == nil {
+				server.Close()
+				if got := <-received; !bytes.Equal(got, data) {
+					t.Errorf("Client received %d bytes, want the %d written once", len(got), len(data))
+				}
+			}
+		})
+	}
+}
+
 func TestSocketNotifier_Send(t *testing.T) {
 	tmpDir := t.TempDir()
 	sockPath := filepath.Join(tmpDir, "test.sock")
@@ -276,4 +454,8 @@ func TestSocketNotifier_Send(t *testing.T) {
 	if event.Agent != "Test Agent" {
 		t.Errorf("Agent = %q, want 'Test Agent'", event.Agent)
 	}
+	host, _ := os.Hostname()
+	if event.Host != host || event.Version != config.Version {
+		t.Errorf("Host/Version = %q/%q, want %q/%q", event.Host, event.Version, host, config.Version)
+	}
 }
@@ -0,0 +1,358 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is the fixed key suffix from RFC 6455 section 1.3.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket frame opcodes (RFC 6455 section 5.2).
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// maxWebSocketFrame bounds client frames; clients only send control frames.
+const maxWebSocketFrame = 64 * 1024
+
+// WebSocketServer bridges SocketServer broadcasts to browser clients.
+// Clients may pass ?events=cooling,holding to receive only those event types.
+//
+// Browsers let any page open a WebSocket to localhost, so requests carrying
+// an Origin are refused unless it is allowed with SetAllowedOrigins, or is
+// the host the request was sent to when that host is loopback (a rebound
+// DNS name matches its own Origin, so other hosts must be allowed by name).
+// Clients that send no Origin (scripts, CLI tools) are not browsers and are
+// accepted.
+type WebSocketServer struct {
+	source   *SocketServer
+	listener net.Listener
+	server   *http.Server
+	done     chan struct{}
+	once     sync.Once
+	origins  map[string]bool // Allowed browser origins, lowercase (daemon.ws_allowed_origins)
+	hosts    map[string]bool // Hosts of the allowed origins, lowercase
+}
+
+// NewWebSocketServer listens on addr (e.g. "127.0.0.1:8765") and relays
+// events broadcast by source.
+func NewWebSocketServer(addr string, source *SocketServer) (*WebSocketServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &WebSocketServer{
+		source:   source,
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	s.server = &http.Server{
+		Handler:           http.HandlerFunc(s.handle),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// SetAllowedOrigins allows browser pages from origins (e.g.
+// "http://localhost:3000") to connect. Call before Start.
+func (s *WebSocketServer) SetAllowedOrigins(origins []string) {
+	s.origins = make(map[string]bool, len(origins))
+	s.hosts = make(map[string]bool, len(origins))
+	for _, o := range origins {
+		o = normalizeOrigin(o)
+		s.origins[o] = true
+		if u, err := url.Parse(o); err == nil && u.Host != "" {
+			s.hosts[u.Host] = true
+		}
+	}
+}
+
+// originAllowed reports whether r may connect: it has no Origin, its Origin
+// is allowed, or its Origin's host is the one it connected to and that host
+// is loopback or allowed.
+func (s *WebSocketServer) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if s.origins[normalizeOrigin(origin)] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || !strings.EqualFold(u.Host, r.Host) {
+		return false
+	}
+	return isLoopbackHost(u.Hostname()) || s.hosts[strings.ToLower(r.Host)]
+}
+
+// isLoopbackHost reports whether host (without port) is localhost or a
+// loopback IP.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// normalizeOrigin lowercases an origin and drops a trailing slash, so
+// configured origins match what browsers send.
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
+
+// Addr returns the address the server is listening on.
+func (s *WebSocketServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Start begins serving in a goroutine until ctx is cancelled or Close is called.
+func (s *WebSocketServer) Start(ctx context.Context) {
+	go s.server.Serve(s.listener)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+}
+
+// Close stops the server and disconnects all clients.
+func (s *WebSocketServer) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.server.Close()
+	})
+	return err
+}
+
+// handle upgrades a request and streams events until either side closes.
+func (s *WebSocketServer) handle(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	if !s.originAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", websocketAccept(key))
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	c := &wsConn{conn: conn, reader: rw.Reader}
+	filter := parseEventFilter(r.URL.Query().Get("events"))
+
+	events, cancel := s.source.Subscribe(32)
+	defer cancel()
+
+	// Send welcome message, matching the Unix socket
+	welcome, _ := json.Marshal(map[string]string{
+		"type":    "welcome",
+		"message": "Connected to firebell websocket",
+	})
+	if err := c.writeFrame(wsOpText, welcome); err != nil {
+		return
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		c.readLoop()
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			c.writeFrame(wsOpClose, nil)
+			return
+		case event, ok := <-events:
+			if !ok {
+				c.writeFrame(wsOpClose, nil)
+				return
+			}
+			if filter != nil && !filter[string(event.Event)] {
+				continue
+			}
+			data, err := event.JSON()
+			if err != nil {
+				continue
+			}
+			if err := c.writeFrame(wsOpText, data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseEventFilter parses a comma-separated event list.
+// Returns nil (all events) for an empty list or one containing "all".
+func parseEventFilter(list string) map[string]bool {
+	if list == "" {
+		return nil
+	}
+	filter := make(map[string]bool)
+	for _, e := range strings.Split(list, ",") {
+		e = strings.TrimSpace(e)
+		if e == "all" {
+			return nil
+		}
+		if e != "" {
+			filter[e] = true
+		}
+	}
+	return filter
+}
+
+// headerContains reports whether a comma-separated header contains token.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// websocketAccept computes the Sec-WebSocket-Accept value for a client key.
+func websocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// wsConn is a server-side WebSocket connection.
+type wsConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex // Serializes writes
+}
+
+// writeFrame writes a single unmasked frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | opcode} // FIN set
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readLoop handles client control frames until the connection closes.
+func (c *wsConn) readLoop() {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsOpClose:
+			c.writeFrame(wsOpClose, nil)
+			return
+		case wsOpPing:
+			c.writeFrame(wsOpPong, payload)
+		}
+	}
+}
+
+// readFrame reads a single client frame, unmasking its payload.
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxWebSocketFrame {
+		return 0, nil, errors.New("websocket frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
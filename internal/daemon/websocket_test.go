@@ -0,0 +1,212 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"firebell/internal/notify"
+)
+
+// dialWebSocket performs a client handshake against addr and returns the
+// connection with its reader.
+func dialWebSocket(t *testing.T, addr, query string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	req := "GET /" + query + " HTTP/1.1\r\n" +
+		"Host: " + addr + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatalf("Failed to write handshake: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Status = %d, want 101", resp.StatusCode)
+	}
+	// RFC 6455 section 1.3 example
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q", got)
+	}
+	return conn, reader
+}
+
+// readTextFrame reads one unmasked server frame and returns its payload.
+func readTextFrame(t *testing.T, r *bufio.Reader) map[string]interface{} {
+	t.Helper()
+
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	if head[0] != 0x81 {
+		t.Fatalf("Frame header = %#x, want final text frame", head[0])
+	}
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("Failed to read payload: %v", err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", payload, err)
+	}
+	return msg
+}
+
+func newTestWebSocketServer(t *testing.T) (*SocketServer, *WebSocketServer) {
+	t.Helper()
+
+	server, err := NewSocketServer(filepath.Join(t.TempDir(), "test.sock"))
+	if err != nil {
+		t.Fatalf("NewSocketServer failed: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	ws, err := NewWebSocketServer("127.0.0.1:0", server)
+	if err != nil {
+		t.Fatalf("NewWebSocketServer failed: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ws.Start(ctx)
+	return server, ws
+}
+
+func TestWebSocketServer_Broadcast(t *testing.T) {
+	server, ws := newTestWebSocketServer(t)
+
+	_, reader := dialWebSocket(t, ws.Addr(), "")
+
+	welcome := readTextFrame(t, reader)
+	if welcome["type"] != "welcome" {
+		t.Errorf("Welcome type = %v, want welcome", welcome["type"])
+	}
+
+	server.Broadcast(notify.NewEvent(notify.EventCooling).
+		WithAgent("Claude Code").
+		WithMessage("No activity for 20 seconds"))
+
+	msg := readTextFrame(t, reader)
+	if msg["event"] != "cooling" {
+		t.Errorf("Event = %v, want cooling", msg["event"])
+	}
+	if msg["agent"] != "Claude Code" {
+		t.Errorf("Agent = %v, want Claude Code", msg["agent"])
+	}
+}
+
+func TestWebSocketServer_EventFilter(t *testing.T) {
+	server, ws := newTestWebSocketServer(t)
+
+	_, reader := dialWebSocket(t, ws.Addr(), "?events=holding")
+	readTextFrame(t, reader) // Welcome
+
+	server.Broadcast(notify.NewEvent(notify.EventActivity).WithAgent("Codex"))
+	server.Broadcast(notify.NewEvent(notify.EventHolding).WithAgent("Codex"))
+
+	msg := readTextFrame(t, reader)
+	if msg["event"] != "holding" {
+		t.Errorf("Event = %v, want holding (activity should be filtered)", msg["event"])
+	}
+}
+
+func TestWebSocketServer_RejectsPlainHTTP(t *testing.T) {
+	_, ws := newTestWebSocketServer(t)
+
+	resp, err := http.Get("http://" + ws.Addr() + "/")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestWebSocketServer_CheckOrigin(t *testing.T) {
+	_, ws := newTestWebSocketServer(t)
+	ws.SetAllowedOrigins([]string{"http://localhost:3000/"})
+
+	_, port, _ := net.SplitHostPort(ws.Addr())
+	tests := []struct {
+		origin string
+		host   string // Host header, if not the server's address
+		want   int
+	}{
+		{"", "", http.StatusSwitchingProtocols}, // Not a browser
+		{"http://" + ws.Addr(), "", http.StatusSwitchingProtocols},
+		{"http://localhost:" + port, "localhost:" + port, http.StatusSwitchingProtocols},
+		{"HTTP://LOCALHOST:3000", "", http.StatusSwitchingProtocols},
+		{"https://evil.example", "", http.StatusForbidden},
+		{"http://localhost:3001", "", http.StatusForbidden},
+		{"null", "", http.StatusForbidden},
+		// DNS rebinding: the page's host resolves to the server, so Host
+		// and Origin match but the host isn't loopback
+		{"http://evil.example:" + port, "evil.example:" + port, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "http://"+ws.Addr()+"/", nil)
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Origin %q: request failed: %v", tt.origin, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("Origin %q: Status = %d, want %d", tt.origin, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestParseEventFilter(t *testing.T) {
+	if f := parseEventFilter(""); f != nil {
+		t.Errorf("empty filter = %v, want nil", f)
+	}
+	if f := parseEventFilter("cooling,all"); f != nil {
+		t.Errorf("filter with all = %v, want nil", f)
+	}
+	f := parseEventFilter("cooling, holding")
+	if !f["cooling"] || !f["holding"] || f["activity"] {
+		t.Errorf("filter = %v", f)
+	}
+}
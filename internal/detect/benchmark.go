@@ -0,0 +1,65 @@
+package detect
+
+import (
+	"runtime"
+	"time"
+)
+
+// BenchmarkResult summarizes a matcher run over a set of log lines.
+type BenchmarkResult struct {
+	Lines      int               // Lines matched against
+	Bytes      int64             // Total bytes in those lines
+	Elapsed    time.Duration     // Wall time spent in Match
+	Allocs     uint64            // Heap allocations during the run
+	AllocBytes uint64            // Heap bytes allocated during the run
+	Matches    map[MatchType]int // Count of matches by type
+	NoMatch    int               // Lines that produced no match
+}
+
+// LinesPerSec returns the matching throughput.
+func (r *BenchmarkResult) LinesPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Lines) / r.Elapsed.Seconds()
+}
+
+// AllocsPerLine returns the average number of heap allocations per line.
+func (r *BenchmarkResult) AllocsPerLine() float64 {
+	if r.Lines == 0 {
+		return 0
+	}
+	return float64(r.Allocs) / float64(r.Lines)
+}
+
+// RunBenchmark times m.Match over every line and records allocations and the
+// match-type distribution. Empty lines are skipped, as the watcher does.
+func RunBenchmark(m Matcher, lines []string) *BenchmarkResult {
+	result := &BenchmarkResult{Matches: make(map[MatchType]int)}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		result.Lines++
+		result.Bytes += int64(len(line))
+
+		if match := m.Match(line); match != nil {
+			result.Matches[match.Type]++
+		} else {
+			result.NoMatch++
+		}
+	}
+
+	result.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	result.Allocs = after.Mallocs - before.Mallocs
+	result.AllocBytes = after.TotalAlloc - before.TotalAlloc
+
+	return result
+}
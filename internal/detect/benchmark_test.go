@@ -0,0 +1,70 @@
+package detect
+
+import (
+	"fmt"
+	"testing"
+)
+
+// syntheticClaudeLines generates a Claude-style session log of n lines that
+// cycles through tool requests, tool results, completed turns, and noise.
+func syntheticClaudeLines(n int) []string {
+	lines := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		switch i % 4 {
+		case 0:
+			lines = append(lines, fmt.Sprintf(`{"type":"assistant","message":{"stop_reason":"tool_use","content":[{"type":"tool_use","name":"Bash","id":"toolu_%d","input":{"command":"ls"}}]}}`, i))
+		case 1:
+			lines = append(lines, fmt.Sprintf(`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_%d"}]}}`, i-1))
+		case 2:
+			lines = append(lines, `{"type":"assistant","message":{"stop_reason":"end_turn","content":[{"type":"text","text":"done"}]}}`)
+		default:
+			lines = append(lines, "not json")
+		}
+	}
+	return lines
+}
+
+func TestRunBenchmark(t *testing.T) {
+	lines := syntheticClaudeLines(400)
+	lines = append(lines, "") // Empty lines are skipped
+
+	result := RunBenchmark(NewClaudeMatcher(), lines)
+
+	if result.Lines != 400 {
+		t.Errorf("Lines = %d, want 400", result.Lines)
+	}
+	if result.Matches[MatchHolding] != 100 {
+		t.Errorf("Holding matches = %d, want 100", result.Matches[MatchHolding])
+	}
+	if result.Matches[MatchComplete] != 100 {
+		t.Errorf("Complete matches = %d, want 100", result.Matches[MatchComplete])
+	}
+
+	total := result.NoMatch
+	for _, count := range result.Matches {
+		total += count
+	}
+	if total != result.Lines {
+		t.Errorf("Matches + NoMatch = %d, want %d", total, result.Lines)
+	}
+	if result.Bytes == 0 || result.Elapsed <= 0 || result.LinesPerSec() <= 0 {
+		t.Errorf("Expected nonzero bytes, elapsed, and throughput, got %+v", result)
+	}
+}
+
+func TestRunBenchmarkEmpty(t *testing.T) {
+	result := RunBenchmark(NewClaudeMatcher(), nil)
+	if result.Lines != 0 || result.NoMatch != 0 || result.AllocsPerLine() != 0 {
+		t.Errorf("Expected empty result, got %+v", result)
+	}
+}
+
+func BenchmarkClaudeMatcher(b *testing.B) {
+	lines := syntheticClaudeLines(1000)
+	m := NewClaudeMatcher()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Match(lines[i%len(lines)])
+	}
+}
@@ -0,0 +1,51 @@
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DescribeMatch renders the result of matching one line, as printed by
+// `firebell --test-matcher`: the match type, reason, and metadata sorted by
+// key, or "no match". err is a parse error from MatchLine, if any.
+func DescribeMatch(m *Match, err error) string {
+	var b strings.Builder
+	if err != nil {
+		fmt.Fprintf(&b, "error:  %v\n", err)
+	}
+	if m == nil {
+		b.WriteString("no match\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "type:   %s\n", m.Type)
+	fmt.Fprintf(&b, "reason: %s\n", m.Reason)
+	if len(m.Meta) == 0 {
+		return b.String()
+	}
+
+	keys := make([]string, 0, len(m.Meta))
+	for k := range m.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b.WriteString("meta:\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "  %s: %s\n", k, metaValue(m.Meta[k]))
+	}
+	return b.String()
+}
+
+// metaValue formats a metadata value: strings as is, anything else as JSON.
+func metaValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
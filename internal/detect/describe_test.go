@@ -0,0 +1,49 @@
+package detect
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDescribeMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Matcher
+		line string
+		want string
+	}{
+		{
+			name: "completion",
+			m:    NewClaudeMatcher(),
+			line: `{"type":"assistant","message":{"stop_reason":"end_turn"}}`,
+			want: "type:   complete\nreason: end turn\nmeta:\n  message: {\"stop_reason\":\"end_turn\"}\n  type: assistant\n",
+		},
+		{
+			name: "tool request with metadata",
+			m:    NewCodyMatcher(),
+			line: `{"type":"tool_request","tool":{"name":"Bash","input":{"command":"ls"}}}`,
+			want: "type:   holding\nreason: tool request\nmeta:\n  tool: Bash\n  tool_args: {\"command\":\"ls\"}\n  type: tool_request\n",
+		},
+		{
+			name: "no match",
+			m:    NewClaudeMatcher(),
+			line: `{"type":"summary"}`,
+			want: "no match\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeMatch(MatchLine(tt.m, tt.line)); got != tt.want {
+				t.Errorf("DescribeMatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeMatchError(t *testing.T) {
+	got := DescribeMatch(nil, errors.New("claude: invalid JSON"))
+	if want := "error:  claude: invalid JSON\nno match\n"; got != want {
+		t.Errorf("DescribeMatch() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package detect
+
+import "testing"
+
+// fuzzSeeds are representative log lines plus malformed-but-valid JSON that
+// puts unexpected types where the matchers expect objects, arrays, or strings.
+var fuzzSeeds = []string{
+	"",
+	"null",
+	"[]",
+	`"assistant"`,
+	"plain text: task complete",
+	`{"type":"assistant","message":{"stop_reason":"end_turn"}}`,
+	`{"type":"assistant","message":{"stop_reason":"tool_use","content":[{"type":"tool_use","name":"Bash","id":"t1","input":{"command":"ls"}}]}}`,
+	`{"type":"assistant","message":{"stop_reason":"tool_use","content":"not an array"}}`,
+	`{"type":"assistant","message":{"stop_reason":"tool_use","content":[null,1,"x",{"type":"tool_use","name":7,"id":[],"input":null}]}}`,
+	`{"type":"assistant","message":"string message"}`,
+	`{"type":"assistant","message":null}`,
+	`{"type":"response_item","payload":{"type":"function_call","name":"shell","call_id":"c1","arguments":"{}"}}`,
+	`{"type":"response_item","payload":{"type":"function_call","name":1,"call_id":{},"arguments":[1]}}`,
+	`{"type":"response_item","payload":{"type":"message","role":"assistant","content":"text"}}`,
+	`{"type":"response_item","payload":"nope"}`,
+	`{"type":"assistant.turn_end","data":{}}`,
+	`{"type":"assistant.message","data":{"toolRequests":[{"name":"bash","arguments":{"cmd":"ls"}}]}}`,
+	`{"type":"assistant.message","data":{"toolRequests":"bash"}}`,
+	`{"type":"assistant.message","data":{"toolRequests":[null]}}`,
+	`{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":"done"}}]}`,
+	`{"choices":[{"finish_reason":"tool_calls","message":{"tool_calls":[{"function":{"name":"read","arguments":"{}"}}]}}]}`,
+	`{"choices":[{"finish_reason":"tool_calls","message":{"tool_calls":[{"function":"read"}]}}]}`,
+	`{"choices":"none"}`,
+	`{"choices":[null]}`,
+	`{"type":"transcript","speaker":"assistant","status":"complete","text":"done"}`,
+	`{"type":"tool_request","tool":{"name":"bash","input":{"command":"ls"}}}`,
+	`{"type":"tool_request","tool":"bash"}`,
+	`{"type":"gemini","content":"hello"}`,
+	`{"toolCalls":[{"name":"x","status":"pending"}]}`,
+	`{"toolCalls":{"name":"x"}}`,
+	`{"level":"INFO","msg":"agent finished","time":"2025-01-01T00:00:00Z"}`,
+	`{"msg":{"nested":true},"level":[]}`,
+	`{"role":"assistant","status":"completed","event":"done","type":"message"}`,
+	`#### user prompt`,
+	`> Applied edit to main.go`,
+	`{"name": "`,
+	`{"name":"unterminated`,
+}
+
+// fuzzMatcher checks that a matcher never panics and that any match it returns
+// carries the line it was given.
+func fuzzMatcher(f *testing.F, newMatcher func() Matcher) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		match := newMatcher().Match(line)
+		if match != nil && match.Line != line {
+			t.Errorf("Match.Line = %q, want input line %q", match.Line, line)
+		}
+	})
+}
+
+func FuzzClaudeMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewClaudeMatcher() })
+}
+
+func FuzzCodexMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewCodexMatcher() })
+}
+
+func FuzzGeminiMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewGeminiMatcher() })
+}
+
+func FuzzCopilotMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewCopilotMatcher() })
+}
+
+func FuzzQwenMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewQwenMatcher() })
+}
+
+func FuzzCodyMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewCodyMatcher() })
+}
+
+func FuzzOpenCodeMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewOpenCodeMatcher() })
+}
+
+func FuzzCrushMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewCrushMatcher() })
+}
+
+func FuzzAmazonQMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewAmazonQMatcher() })
+}
+
+func FuzzPlandexMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewPlandexMatcher() })
+}
+
+func FuzzAiderMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewAiderMatcher() })
+}
+
+func FuzzFallbackMatcher(f *testing.F) {
+	fuzzMatcher(f, func() Matcher { return NewFallbackMatcher("unknown") })
+}
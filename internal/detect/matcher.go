@@ -3,6 +3,8 @@ package detect
 
 import (
 	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -11,12 +13,29 @@ import (
 type MatchType int
 
 const (
-	MatchActivity MatchType = iota // Normal activity detection (no completion signal)
-	MatchComplete                  // Turn complete, response finished (triggers Cooling after quiet)
-	MatchAwaiting                  // Explicit waiting for user input (immediate notification)
-	MatchHolding                   // Waiting for tool approval (immediate notification)
+	MatchActivity   MatchType = iota // Normal activity detection (no completion signal)
+	MatchComplete                    // Turn complete, response finished (triggers Cooling after quiet)
+	MatchAwaiting                    // Explicit waiting for user input (immediate notification)
+	MatchHolding                     // Waiting for tool approval (immediate notification)
+	MatchCompaction                  // Context was compacted mid-session (immediate notification)
 )
 
+// String returns the lowercase name of the match type.
+func (t MatchType) String() string {
+	switch t {
+	case MatchComplete:
+		return "complete"
+	case MatchAwaiting:
+		return "awaiting"
+	case MatchHolding:
+		return "holding"
+	case MatchCompaction:
+		return "compaction"
+	default:
+		return "activity"
+	}
+}
+
 // Match represents a detected activity match.
 type Match struct {
 	Agent  string                 // Agent name (e.g., "claude", "codex")
@@ -33,6 +52,52 @@ type Matcher interface {
 	Match(line string) *Match
 }
 
+// ErrorMatcher is a Matcher that can also tell a malformed line apart from
+// one that is simply not for it. Matchers for JSONL logs implement it.
+type ErrorMatcher interface {
+	Matcher
+	// MatchErr is Match, but returns a *ParseError instead of nil for a line
+	// that looks like JSON yet fails to parse.
+	MatchErr(line string) (*Match, error)
+}
+
+// ParseError reports a log line that looks like JSON but fails to parse.
+type ParseError struct {
+	Agent string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: malformed JSON line: %v", e.Agent, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// MatchLine runs m on line, reporting parse errors if m is an ErrorMatcher.
+func MatchLine(m Matcher, line string) (*Match, error) {
+	if em, ok := m.(ErrorMatcher); ok {
+		return em.MatchErr(line)
+	}
+	return m.Match(line), nil
+}
+
+// decodeJSONLine decodes line as a JSON object, for agent's matcher. obj is
+// nil if it isn't one; err is a *ParseError if it starts like a JSON object
+// but fails to parse. Other lines (plain text, blank) are not errors.
+func decodeJSONLine(agent, line string) (obj map[string]interface{}, err error) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+		if strings.HasPrefix(trimmed, "{") {
+			return nil, &ParseError{Agent: agent, Err: err}
+		}
+		return nil, nil
+	}
+	return obj, nil
+}
+
 // RegexMatcher matches lines using a regular expression.
 type RegexMatcher struct {
 	pattern *regexp.Regexp
@@ -90,16 +155,21 @@ func NewCodexMatcher() *CodexMatcher {
 
 // Match implements Matcher for CodexMatcher.
 func (m *CodexMatcher) Match(line string) *Match {
-	// Skip empty lines
-	if len(strings.TrimSpace(line)) == 0 {
-		return nil
-	}
+	match, _ := m.MatchErr(line)
+	return match
+}
 
-	var obj map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &obj); err != nil {
-		return nil
+// MatchErr implements ErrorMatcher for CodexMatcher.
+func (m *CodexMatcher) MatchErr(line string) (*Match, error) {
+	obj, err := decodeJSONLine(m.agent, line)
+	if obj == nil {
+		return nil, err
 	}
+	return m.match(line, obj), nil
+}
 
+// match matches a line decoded as a JSON object.
+func (m *CodexMatcher) match(line string, obj map[string]interface{}) *Match {
 	// Check for response_item type
 	typ, ok := obj["type"].(string)
 	if !ok || typ != "response_item" {
@@ -115,17 +185,17 @@ func (m *CodexMatcher) Match(line string) *Match {
 
 	// Check for function_call = awaiting permission
 	if payloadType == "function_call" {
-		meta := obj
+		meta := metaFrom(obj)
 		// Extract function name
 		if name, ok := payload["name"].(string); ok {
-			if meta == nil {
-				meta = make(map[string]interface{})
-			}
 			meta["tool"] = name
 		}
 		if callID, ok := payload["call_id"].(string); ok {
 			meta["tool_id"] = callID
 		}
+		if args := toolArgs(payload["arguments"]); args != "" {
+			meta["tool_args"] = args
+		}
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchHolding,
@@ -184,18 +254,26 @@ func NewClaudeMatcher() *ClaudeMatcher {
 
 // Match implements Matcher for ClaudeMatcher.
 func (m *ClaudeMatcher) Match(line string) *Match {
-	// Skip empty lines
-	if len(strings.TrimSpace(line)) == 0 {
-		return nil
-	}
+	match, _ := m.MatchErr(line)
+	return match
+}
 
-	var obj map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &obj); err != nil {
-		return nil
+// MatchErr implements ErrorMatcher for ClaudeMatcher.
+func (m *ClaudeMatcher) MatchErr(line string) (*Match, error) {
+	obj, err := decodeJSONLine(m.agent, line)
+	if obj == nil {
+		return nil, err
 	}
+	return m.match(line, obj), nil
+}
 
-	// Must be an assistant type entry
+// match matches a line decoded as a JSON object.
+func (m *ClaudeMatcher) match(line string, obj map[string]interface{}) *Match {
+	// Must be an assistant type entry, or a system compaction marker
 	typ, ok := obj["type"].(string)
+	if ok && typ == "system" {
+		return m.matchSystem(line, obj)
+	}
 	if !ok || typ != "assistant" {
 		return nil
 	}
@@ -230,21 +308,21 @@ func (m *ClaudeMatcher) Match(line string) *Match {
 
 	case "tool_use":
 		// Claude wants to run a tool, waiting for approval
-		meta := obj
+		meta := metaFrom(obj)
 		// Extract tool name from content
 		if content, ok := message["content"].([]interface{}); ok {
 			for _, item := range content {
 				if itemMap, ok := item.(map[string]interface{}); ok {
 					if itemMap["type"] == "tool_use" {
 						if toolName, ok := itemMap["name"].(string); ok {
-							if meta == nil {
-								meta = make(map[string]interface{})
-							}
 							meta["tool"] = toolName
 						}
 						if toolID, ok := itemMap["id"].(string); ok {
 							meta["tool_id"] = toolID
 						}
+						if args := toolArgs(itemMap["input"]); args != "" {
+							meta["tool_args"] = args
+						}
 						break
 					}
 				}
@@ -270,6 +348,33 @@ func (m *ClaudeMatcher) Match(line string) *Match {
 	}
 }
 
+// matchSystem matches a type:"system" entry. Only compaction boundaries
+// (subtype "compact_boundary") are of interest; their trigger ("auto" or
+// "manual") and token count before compaction are copied into Meta.
+func (m *ClaudeMatcher) matchSystem(line string, obj map[string]interface{}) *Match {
+	subtype, _ := obj["subtype"].(string)
+	if !strings.Contains(subtype, "compact") {
+		return nil
+	}
+
+	meta := metaFrom(obj)
+	if cm, ok := obj["compactMetadata"].(map[string]interface{}); ok {
+		if trigger, ok := cm["trigger"].(string); ok {
+			meta["trigger"] = trigger
+		}
+		if tokens, ok := cm["preTokens"].(float64); ok {
+			meta["pre_tokens"] = int(tokens)
+		}
+	}
+	return &Match{
+		Agent:  m.agent,
+		Type:   MatchCompaction,
+		Reason: "context compacted",
+		Line:   line,
+		Meta:   meta,
+	}
+}
+
 // GeminiMatcher detects Gemini CLI activity and awaiting states.
 // Gemini uses single JSON files (not JSONL) with a messages array.
 // When the file is rewritten, lines containing message data are detected.
@@ -340,6 +445,32 @@ func (m *GeminiMatcher) Match(line string) *Match {
 	return nil
 }
 
+// metaFrom returns obj for use as match metadata, or an empty map if obj is
+// nil (e.g. the line was JSON null), so tool fields can always be added.
+func metaFrom(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return make(map[string]interface{})
+	}
+	return obj
+}
+
+// toolArgs returns tool call arguments as a string for the "tool_args" metadata.
+// Strings are returned as-is; other values are JSON-encoded.
+func toolArgs(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
 // extractToolName attempts to extract a tool name from a JSON line
 func extractToolName(line string) string {
 	// Simple extraction - look for "name": "value"
@@ -374,24 +505,30 @@ func NewCopilotMatcher() *CopilotMatcher {
 
 // Match implements Matcher for CopilotMatcher.
 func (m *CopilotMatcher) Match(line string) *Match {
-	// Skip empty lines
-	if len(strings.TrimSpace(line)) == 0 {
-		return nil
-	}
+	match, _ := m.MatchErr(line)
+	return match
+}
 
-	var obj map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &obj); err != nil {
-		// Fallback: check for old log format
-		if strings.Contains(line, "chat/completions succeeded") {
-			return &Match{
-				Agent:  m.agent,
-				Type:   MatchComplete,
-				Reason: "completion success",
-				Line:   line,
-			}
-		}
-		return nil
+// MatchErr implements ErrorMatcher for CopilotMatcher.
+func (m *CopilotMatcher) MatchErr(line string) (*Match, error) {
+	obj, err := decodeJSONLine(m.agent, line)
+	if obj != nil {
+		return m.match(line, obj), nil
+	}
+	// Fallback: check for old log format
+	if strings.Contains(line, "chat/completions succeeded") {
+		return &Match{
+			Agent:  m.agent,
+			Type:   MatchComplete,
+			Reason: "completion success",
+			Line:   line,
+		}, nil
 	}
+	return nil, err
+}
+
+// match matches a line decoded as a JSON object.
+func (m *CopilotMatcher) match(line string, obj map[string]interface{}) *Match {
 
 	typ, ok := obj["type"].(string)
 	if !ok {
@@ -414,16 +551,16 @@ func (m *CopilotMatcher) Match(line string) *Match {
 		if data, ok := obj["data"].(map[string]interface{}); ok {
 			if toolRequests, ok := data["toolRequests"].([]interface{}); ok && len(toolRequests) > 0 {
 				// Has tool requests - this is a potential holding point
-				meta := obj
+				meta := metaFrom(obj)
 				// Extract first tool name
 				if len(toolRequests) > 0 {
 					if req, ok := toolRequests[0].(map[string]interface{}); ok {
 						if name, ok := req["name"].(string); ok {
-							if meta == nil {
-								meta = make(map[string]interface{})
-							}
 							meta["tool"] = name
 						}
+						if args := toolArgs(req["arguments"]); args != "" {
+							meta["tool_args"] = args
+						}
 					}
 				}
 				return &Match{
@@ -468,30 +605,135 @@ func (m *CopilotMatcher) Match(line string) *Match {
 	return nil
 }
 
-// QwenMatcher detects Qwen Code activity from OpenAI API logs.
-// Qwen Code is a fork of Gemini CLI that logs OpenAI-compatible API calls.
-// Logs are JSONL format with request/response data.
-type QwenMatcher struct {
+// CodyMatcher detects Sourcegraph Cody CLI activity from its JSONL logs.
+// Parses type:"transcript" messages, where an assistant message with
+// status:"complete" ends the turn, and type:"tool_request" events, which
+// wait for the user to approve a tool.
+type CodyMatcher struct {
 	agent string
 }
 
-// NewQwenMatcher creates a new Qwen Code-specific matcher.
-func NewQwenMatcher() *QwenMatcher {
-	return &QwenMatcher{agent: "qwen"}
+// NewCodyMatcher creates a new Cody-specific matcher.
+func NewCodyMatcher() *CodyMatcher {
+	return &CodyMatcher{agent: "cody"}
 }
 
-// Match implements Matcher for QwenMatcher.
-func (m *QwenMatcher) Match(line string) *Match {
-	// Skip empty lines
-	if len(strings.TrimSpace(line)) == 0 {
-		return nil
+// Match implements Matcher for CodyMatcher.
+func (m *CodyMatcher) Match(line string) *Match {
+	match, _ := m.MatchErr(line)
+	return match
+}
+
+// MatchErr implements ErrorMatcher for CodyMatcher.
+func (m *CodyMatcher) MatchErr(line string) (*Match, error) {
+	obj, err := decodeJSONLine(m.agent, line)
+	if obj == nil {
+		return nil, err
 	}
+	return m.match(line, obj), nil
+}
 
-	var obj map[string]interface{}
-	if err := json.Unmarshal([]byte(line), &obj); err != nil {
-		return nil
+// match matches a line decoded as a JSON object.
+func (m *CodyMatcher) match(line string, obj map[string]interface{}) *Match {
+	typ, _ := obj["type"].(string)
+	switch typ {
+	case "transcript":
+		speaker, _ := obj["speaker"].(string)
+		if speaker != "assistant" {
+			// User input - activity
+			return &Match{
+				Agent:  m.agent,
+				Type:   MatchActivity,
+				Reason: "user message",
+				Line:   line,
+				Meta:   obj,
+			}
+		}
+		if status, _ := obj["status"].(string); status == "complete" {
+			// Assistant finished its reply
+			return &Match{
+				Agent:  m.agent,
+				Type:   MatchComplete,
+				Reason: "transcript complete",
+				Line:   line,
+				Meta:   obj,
+			}
+		}
+		// Assistant reply still streaming
+		return &Match{
+			Agent:  m.agent,
+			Type:   MatchActivity,
+			Reason: "assistant streaming",
+			Line:   line,
+			Meta:   obj,
+		}
+
+	case "tool_request":
+		// Tool waiting for approval - potential holding point
+		meta := metaFrom(obj)
+		if tool, ok := obj["tool"].(map[string]interface{}); ok {
+			if name, ok := tool["name"].(string); ok {
+				meta["tool"] = name
+			}
+			if args := toolArgs(tool["input"]); args != "" {
+				meta["tool_args"] = args
+			}
+		}
+		return &Match{
+			Agent:  m.agent,
+			Type:   MatchHolding,
+			Reason: "tool request",
+			Line:   line,
+			Meta:   meta,
+		}
+
+	case "tool_result":
+		// Tool ran - activity
+		return &Match{
+			Agent:  m.agent,
+			Type:   MatchActivity,
+			Reason: "tool result",
+			Line:   line,
+			Meta:   obj,
+		}
 	}
 
+	return nil
+}
+
+// FormatOpenAIChat is the agents.format value that selects OpenAIChatMatcher.
+const FormatOpenAIChat = "openai_chat"
+
+// OpenAIChatMatcher detects activity from raw OpenAI chat/completions logs:
+// JSONL with a request (messages array) or response (choices array) per
+// line. finish_reason "stop" is a completion and "tool_calls" or
+// "function_call" a tool request; a choice without one is still streaming.
+type OpenAIChatMatcher struct {
+	agent string
+}
+
+// NewOpenAIChatMatcher creates an OpenAI chat log matcher for agent.
+func NewOpenAIChatMatcher(agent string) *OpenAIChatMatcher {
+	return &OpenAIChatMatcher{agent: agent}
+}
+
+// Match implements Matcher for OpenAIChatMatcher.
+func (m *OpenAIChatMatcher) Match(line string) *Match {
+	match, _ := m.MatchErr(line)
+	return match
+}
+
+// MatchErr implements ErrorMatcher for OpenAIChatMatcher.
+func (m *OpenAIChatMatcher) MatchErr(line string) (*Match, error) {
+	obj, err := decodeJSONLine(m.agent, line)
+	if obj == nil {
+		return nil, err
+	}
+	return m.match(line, obj), nil
+}
+
+// match matches a line decoded as a JSON object.
+func (m *OpenAIChatMatcher) match(line string, obj map[string]interface{}) *Match {
 	// Check for response object with choices
 	if choices, ok := obj["choices"].([]interface{}); ok && len(choices) > 0 {
 		if choice, ok := choices[0].(map[string]interface{}); ok {
@@ -508,17 +750,17 @@ func (m *QwenMatcher) Match(line string) *Match {
 					}
 				case "tool_calls", "function_call":
 					// Extract tool name if available
-					meta := obj
+					meta := metaFrom(obj)
 					if message, ok := choice["message"].(map[string]interface{}); ok {
 						if toolCalls, ok := message["tool_calls"].([]interface{}); ok && len(toolCalls) > 0 {
 							if tc, ok := toolCalls[0].(map[string]interface{}); ok {
 								if fn, ok := tc["function"].(map[string]interface{}); ok {
 									if name, ok := fn["name"].(string); ok {
-										if meta == nil {
-											meta = make(map[string]interface{})
-										}
 										meta["tool"] = name
 									}
+									if args := toolArgs(fn["arguments"]); args != "" {
+										meta["tool_args"] = args
+									}
 								}
 							}
 						}
@@ -557,15 +799,33 @@ func (m *QwenMatcher) Match(line string) *Match {
 	return nil
 }
 
+// QwenMatcher detects Qwen Code activity from OpenAI API logs.
+// Qwen Code is a fork of Gemini CLI that logs OpenAI-compatible API calls.
+type QwenMatcher struct {
+	OpenAIChatMatcher
+}
+
+// NewQwenMatcher creates a new Qwen Code-specific matcher.
+func NewQwenMatcher() *QwenMatcher {
+	return &QwenMatcher{OpenAIChatMatcher{agent: "qwen"}}
+}
+
 // OpenCodeMatcher detects SST OpenCode activity from log files.
 // OpenCode logs are timestamped text files with structured messages.
 type OpenCodeMatcher struct {
-	agent string
+	agent    string
+	keywords Keywords
 }
 
 // NewOpenCodeMatcher creates a new OpenCode-specific matcher.
 func NewOpenCodeMatcher() *OpenCodeMatcher {
-	return &OpenCodeMatcher{agent: "opencode"}
+	return NewOpenCodeMatcherWithKeywords(Keywords{})
+}
+
+// NewOpenCodeMatcherWithKeywords creates an OpenCode matcher that also
+// recognizes the given keywords.
+func NewOpenCodeMatcherWithKeywords(kw Keywords) *OpenCodeMatcher {
+	return &OpenCodeMatcher{agent: "opencode", keywords: kw.lower()}
 }
 
 // Match implements Matcher for OpenCodeMatcher.
@@ -588,7 +848,7 @@ func (m *OpenCodeMatcher) Match(line string) *Match {
 
 	// Tool permission/confirmation patterns
 	if strings.Contains(line, "tool.confirm") || strings.Contains(line, "awaiting confirmation") ||
-		strings.Contains(line, "permission") {
+		strings.Contains(line, "permission") || hasKeyword(line, m.keywords.Holding) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchHolding,
@@ -599,7 +859,7 @@ func (m *OpenCodeMatcher) Match(line string) *Match {
 
 	// Turn complete patterns
 	if strings.Contains(line, "turn.complete") || strings.Contains(line, "response.complete") ||
-		strings.Contains(line, "assistant.done") {
+		strings.Contains(line, "assistant.done") || hasKeyword(line, m.keywords.Complete) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchComplete,
@@ -610,7 +870,7 @@ func (m *OpenCodeMatcher) Match(line string) *Match {
 
 	// General assistant activity
 	if strings.Contains(line, "assistant") || strings.Contains(line, "response") ||
-		strings.Contains(line, "message") {
+		strings.Contains(line, "message") || hasKeyword(line, m.keywords.Activity) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchActivity,
@@ -625,12 +885,19 @@ func (m *OpenCodeMatcher) Match(line string) *Match {
 // CrushMatcher detects Charmbracelet Crush activity from log files.
 // Crush uses slog for structured logging.
 type CrushMatcher struct {
-	agent string
+	agent    string
+	keywords Keywords
 }
 
 // NewCrushMatcher creates a new Crush-specific matcher.
 func NewCrushMatcher() *CrushMatcher {
-	return &CrushMatcher{agent: "crush"}
+	return NewCrushMatcherWithKeywords(Keywords{})
+}
+
+// NewCrushMatcherWithKeywords creates a Crush matcher that also recognizes
+// the given keywords.
+func NewCrushMatcherWithKeywords(kw Keywords) *CrushMatcher {
+	return &CrushMatcher{agent: "crush", keywords: kw.lower()}
 }
 
 // Match implements Matcher for CrushMatcher.
@@ -645,7 +912,7 @@ func (m *CrushMatcher) Match(line string) *Match {
 	if err := json.Unmarshal([]byte(line), &obj); err == nil {
 		// Check for message or msg field
 		if msg, ok := obj["msg"].(string); ok {
-			if strings.Contains(msg, "tool") && strings.Contains(msg, "confirm") {
+			if (strings.Contains(msg, "tool") && strings.Contains(msg, "confirm")) || hasKeyword(msg, m.keywords.Holding) {
 				return &Match{
 					Agent:  m.agent,
 					Type:   MatchHolding,
@@ -654,7 +921,7 @@ func (m *CrushMatcher) Match(line string) *Match {
 					Meta:   obj,
 				}
 			}
-			if strings.Contains(msg, "complete") || strings.Contains(msg, "done") {
+			if strings.Contains(msg, "complete") || strings.Contains(msg, "done") || hasKeyword(msg, m.keywords.Complete) {
 				return &Match{
 					Agent:  m.agent,
 					Type:   MatchComplete,
@@ -675,7 +942,8 @@ func (m *CrushMatcher) Match(line string) *Match {
 	}
 
 	// Fallback to text pattern matching
-	if strings.Contains(line, "tool") && (strings.Contains(line, "confirm") || strings.Contains(line, "permission")) {
+	if (strings.Contains(line, "tool") && (strings.Contains(line, "confirm") || strings.Contains(line, "permission"))) ||
+		hasKeyword(line, m.keywords.Holding) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchHolding,
@@ -684,7 +952,7 @@ func (m *CrushMatcher) Match(line string) *Match {
 		}
 	}
 
-	if strings.Contains(line, "complete") || strings.Contains(line, "finished") {
+	if strings.Contains(line, "complete") || strings.Contains(line, "finished") || hasKeyword(line, m.keywords.Complete) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchComplete,
@@ -693,7 +961,7 @@ func (m *CrushMatcher) Match(line string) *Match {
 		}
 	}
 
-	if strings.Contains(line, "assistant") || strings.Contains(line, "response") {
+	if strings.Contains(line, "assistant") || strings.Contains(line, "response") || hasKeyword(line, m.keywords.Activity) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchActivity,
@@ -708,12 +976,19 @@ func (m *CrushMatcher) Match(line string) *Match {
 // AmazonQMatcher detects Amazon Q CLI activity from log files.
 // Amazon Q logs to chat.log and qchat.log files.
 type AmazonQMatcher struct {
-	agent string
+	agent    string
+	keywords Keywords
 }
 
 // NewAmazonQMatcher creates a new Amazon Q-specific matcher.
 func NewAmazonQMatcher() *AmazonQMatcher {
-	return &AmazonQMatcher{agent: "amazonq"}
+	return NewAmazonQMatcherWithKeywords(Keywords{})
+}
+
+// NewAmazonQMatcherWithKeywords creates an Amazon Q matcher that also
+// recognizes the given keywords in text lines.
+func NewAmazonQMatcherWithKeywords(kw Keywords) *AmazonQMatcher {
+	return &AmazonQMatcher{agent: "amazonq", keywords: kw.lower()}
 }
 
 // Match implements Matcher for AmazonQMatcher.
@@ -730,11 +1005,8 @@ func (m *AmazonQMatcher) Match(line string) *Match {
 		if eventType, ok := obj["type"].(string); ok {
 			switch eventType {
 			case "tool_use", "tool_call":
-				meta := obj
+				meta := metaFrom(obj)
 				if name, ok := obj["name"].(string); ok {
-					if meta == nil {
-						meta = make(map[string]interface{})
-					}
 					meta["tool"] = name
 				}
 				return &Match{
@@ -765,7 +1037,8 @@ func (m *AmazonQMatcher) Match(line string) *Match {
 	}
 
 	// Fallback to text pattern matching
-	if strings.Contains(line, "tool") && (strings.Contains(line, "permission") || strings.Contains(line, "confirm")) {
+	if (strings.Contains(line, "tool") && (strings.Contains(line, "permission") || strings.Contains(line, "confirm"))) ||
+		hasKeyword(line, m.keywords.Holding) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchHolding,
@@ -774,7 +1047,8 @@ func (m *AmazonQMatcher) Match(line string) *Match {
 		}
 	}
 
-	if strings.Contains(line, "complete") || strings.Contains(line, "finished") || strings.Contains(line, "done") {
+	if strings.Contains(line, "complete") || strings.Contains(line, "finished") || strings.Contains(line, "done") ||
+		hasKeyword(line, m.keywords.Complete) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchComplete,
@@ -783,7 +1057,8 @@ func (m *AmazonQMatcher) Match(line string) *Match {
 		}
 	}
 
-	if strings.Contains(line, "response") || strings.Contains(line, "message") || strings.Contains(line, "chat") {
+	if strings.Contains(line, "response") || strings.Contains(line, "message") || strings.Contains(line, "chat") ||
+		hasKeyword(line, m.keywords.Activity) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchActivity,
@@ -798,12 +1073,19 @@ func (m *AmazonQMatcher) Match(line string) *Match {
 // PlandexMatcher detects Plandex activity from log files.
 // Plandex is a Go-based AI coding agent with server-client architecture.
 type PlandexMatcher struct {
-	agent string
+	agent    string
+	keywords Keywords
 }
 
 // NewPlandexMatcher creates a new Plandex-specific matcher.
 func NewPlandexMatcher() *PlandexMatcher {
-	return &PlandexMatcher{agent: "plandex"}
+	return NewPlandexMatcherWithKeywords(Keywords{})
+}
+
+// NewPlandexMatcherWithKeywords creates a Plandex matcher that also
+// recognizes the given keywords in text lines.
+func NewPlandexMatcherWithKeywords(kw Keywords) *PlandexMatcher {
+	return &PlandexMatcher{agent: "plandex", keywords: kw.lower()}
 }
 
 // Match implements Matcher for PlandexMatcher.
@@ -860,7 +1142,8 @@ func (m *PlandexMatcher) Match(line string) *Match {
 
 	// Completion patterns
 	if strings.Contains(lineLower, "plan complete") || strings.Contains(lineLower, "changes applied") ||
-		strings.Contains(lineLower, "finished") || strings.Contains(lineLower, "done building") {
+		strings.Contains(lineLower, "finished") || strings.Contains(lineLower, "done building") ||
+		hasKeyword(lineLower, m.keywords.Complete) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchComplete,
@@ -871,7 +1154,8 @@ func (m *PlandexMatcher) Match(line string) *Match {
 
 	// Waiting/blocked patterns
 	if strings.Contains(lineLower, "waiting for") || strings.Contains(lineLower, "confirm") ||
-		strings.Contains(lineLower, "review changes") || strings.Contains(lineLower, "pending approval") {
+		strings.Contains(lineLower, "review changes") || strings.Contains(lineLower, "pending approval") ||
+		hasKeyword(lineLower, m.keywords.Holding) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchHolding,
@@ -883,7 +1167,8 @@ func (m *PlandexMatcher) Match(line string) *Match {
 	// Activity patterns
 	if strings.Contains(lineLower, "building") || strings.Contains(lineLower, "planning") ||
 		strings.Contains(lineLower, "streaming") || strings.Contains(lineLower, "processing") ||
-		strings.Contains(lineLower, "loading") || strings.Contains(lineLower, "running") {
+		strings.Contains(lineLower, "loading") || strings.Contains(lineLower, "running") ||
+		hasKeyword(lineLower, m.keywords.Activity) {
 		return &Match{
 			Agent:  m.agent,
 			Type:   MatchActivity,
@@ -1037,12 +1322,51 @@ const DefaultPattern = `"type":\s*"assistant"|"type":\s*"gemini"|assistant_messa
 // It combines JSON parsing with common text patterns to detect activity,
 // completion, and tool permission states across various AI CLI tools.
 type FallbackMatcher struct {
-	agent string
+	agent    string
+	holding  []string
+	complete []string
+	activity []string
 }
 
+// Text patterns checked by FallbackMatcher, in priority order.
+var (
+	// Holding patterns - waiting for user input/permission
+	fallbackHoldingPatterns = []string{
+		"waiting for", "confirm", "permission", "approve", "allow",
+		"y/n", "yes/no", "proceed?", "continue?", "accept?",
+		"review changes", "pending approval", "requires confirmation",
+	}
+
+	// Completion patterns
+	fallbackCompletePatterns = []string{
+		"complete", "completed", "finished", "done", "success",
+		"applied edit", "changes applied", "wrote file", "created file",
+		"task complete", "turn complete", "response complete",
+	}
+
+	// Activity patterns
+	fallbackActivityPatterns = []string{
+		"thinking", "generating", "processing", "analyzing", "searching",
+		"loading", "streaming", "running", "executing", "building",
+		"assistant", "response", "message", "output",
+	}
+)
+
 // NewFallbackMatcher creates a new fallback matcher for unknown agents.
 func NewFallbackMatcher(agentName string) *FallbackMatcher {
-	return &FallbackMatcher{agent: agentName}
+	return NewFallbackMatcherWithKeywords(agentName, Keywords{})
+}
+
+// NewFallbackMatcherWithKeywords creates a fallback matcher whose text
+// patterns include the given keywords after the built-in ones.
+func NewFallbackMatcherWithKeywords(agentName string, kw Keywords) *FallbackMatcher {
+	kw = kw.lower()
+	return &FallbackMatcher{
+		agent:    agentName,
+		holding:  mergeKeywords(fallbackHoldingPatterns, kw.Holding),
+		complete: mergeKeywords(fallbackCompletePatterns, kw.Complete),
+		activity: mergeKeywords(fallbackActivityPatterns, kw.Activity),
+	}
 }
 
 // Match implements Matcher for FallbackMatcher.
@@ -1165,37 +1489,19 @@ func (m *FallbackMatcher) matchJSON(line string, obj map[string]interface{}) *Ma
 func (m *FallbackMatcher) matchText(line, trimmed string) *Match {
 	lineLower := strings.ToLower(trimmed)
 
-	// Holding patterns - waiting for user input/permission
-	holdingPatterns := []string{
-		"waiting for", "confirm", "permission", "approve", "allow",
-		"y/n", "yes/no", "proceed?", "continue?", "accept?",
-		"review changes", "pending approval", "requires confirmation",
-	}
-	for _, pattern := range holdingPatterns {
+	for _, pattern := range m.holding {
 		if strings.Contains(lineLower, pattern) {
 			return &Match{Agent: m.agent, Type: MatchHolding, Reason: "text: " + pattern, Line: line}
 		}
 	}
 
-	// Completion patterns
-	completePatterns := []string{
-		"complete", "completed", "finished", "done", "success",
-		"applied edit", "changes applied", "wrote file", "created file",
-		"task complete", "turn complete", "response complete",
-	}
-	for _, pattern := range completePatterns {
+	for _, pattern := range m.complete {
 		if strings.Contains(lineLower, pattern) {
 			return &Match{Agent: m.agent, Type: MatchComplete, Reason: "text: " + pattern, Line: line}
 		}
 	}
 
-	// Activity patterns
-	activityPatterns := []string{
-		"thinking", "generating", "processing", "analyzing", "searching",
-		"loading", "streaming", "running", "executing", "building",
-		"assistant", "response", "message", "output",
-	}
-	for _, pattern := range activityPatterns {
+	for _, pattern := range m.activity {
 		if strings.Contains(lineLower, pattern) {
 			return &Match{Agent: m.agent, Type: MatchActivity, Reason: "text: " + pattern, Line: line}
 		}
@@ -1204,8 +1510,70 @@ func (m *FallbackMatcher) matchText(line, trimmed string) *Match {
 	return nil
 }
 
+// Keywords are extra substrings that text-based matchers (OpenCode, Crush,
+// Amazon Q, Plandex, and the fallback) accept as completion, holding, or
+// activity cues in addition to their built-in English keywords. Keywords
+// match case-insensitively.
+type Keywords struct {
+	Complete []string
+	Holding  []string
+	Activity []string
+}
+
+// lower returns a copy of k with every keyword lowercased.
+func (k Keywords) lower() Keywords {
+	return Keywords{
+		Complete: lowerAll(k.Complete),
+		Holding:  lowerAll(k.Holding),
+		Activity: lowerAll(k.Activity),
+	}
+}
+
+func lowerAll(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = strings.ToLower(w)
+	}
+	return out
+}
+
+// mergeKeywords returns base followed by extra, without modifying base.
+func mergeKeywords(base, extra []string) []string {
+	if len(extra) == 0 {
+		return base
+	}
+	merged := make([]string, 0, len(base)+len(extra))
+	merged = append(merged, base...)
+	return append(merged, extra...)
+}
+
+// hasKeyword reports whether line contains any of the (lowercased) keywords,
+// ignoring case.
+func hasKeyword(line string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+	lineLower := strings.ToLower(line)
+	for _, kw := range keywords {
+		if strings.Contains(lineLower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateMatcher creates the appropriate matcher for an agent.
 func CreateMatcher(agentName string) Matcher {
+	return CreateMatcherWithKeywords(agentName, Keywords{})
+}
+
+// CreateMatcherWithKeywords creates the matcher for an agent, merging kw into
+// its keyword lists. Structured matchers (Claude, Codex, Gemini, Copilot,
+// Qwen, Cody, Aider) ignore kw.
+func CreateMatcherWithKeywords(agentName string, kw Keywords) Matcher {
 	switch agentName {
 	case "claude":
 		// Claude Code uses structured JSONL with stop_reason for awaiting detection
@@ -1222,23 +1590,70 @@ func CreateMatcher(agentName string) Matcher {
 	case "qwen":
 		// Qwen Code logs OpenAI-compatible API calls in JSONL format
 		return NewQwenMatcher()
+	case "cody":
+		// Sourcegraph Cody CLI logs transcript and tool events in JSONL format
+		return NewCodyMatcher()
 	case "opencode":
 		// SST OpenCode uses timestamped log files
-		return NewOpenCodeMatcher()
+		return NewOpenCodeMatcherWithKeywords(kw)
 	case "crush":
 		// Charmbracelet Crush uses slog for structured logging
-		return NewCrushMatcher()
+		return NewCrushMatcherWithKeywords(kw)
 	case "amazonq":
 		// Amazon Q CLI logs to chat.log and qchat.log
-		return NewAmazonQMatcher()
+		return NewAmazonQMatcherWithKeywords(kw)
 	case "plandex":
 		// Plandex uses JSON status and text patterns
-		return NewPlandexMatcher()
+		return NewPlandexMatcherWithKeywords(kw)
 	case "aider":
 		// Aider uses markdown history and JSON LLM logs
 		return NewAiderMatcher()
 	default:
 		// Unknown agents use intelligent fallback matching
-		return NewFallbackMatcher(agentName)
+		return NewFallbackMatcherWithKeywords(agentName, kw)
+	}
+}
+
+// CreateMatcherForFormat creates the matcher for an agent whose logs are in
+// format (an agents.format value). An empty or unknown format uses the
+// agent's own matcher, as CreateMatcherWithKeywords.
+func CreateMatcherForFormat(agentName, format string, kw Keywords) Matcher {
+	switch format {
+	case FormatOpenAIChat:
+		return NewOpenAIChatMatcher(agentName)
+	default:
+		return CreateMatcherWithKeywords(agentName, kw)
+	}
+}
+
+// TextBased reports whether agentName's matcher classifies lines by keywords
+// in free text rather than structured fields, so a stray line (e.g. one
+// containing "done") can be mistaken for a completion.
+func TextBased(agentName string) bool {
+	switch agentName {
+	case "claude", "codex", "gemini", "copilot", "qwen", "cody":
+		return false
+	default:
+		return true
+	}
+}
+
+// AgentForCommand returns the agent name for a command path or name
+// (e.g. "/usr/local/bin/claude" -> "claude"), or "" if it is not a known agent.
+// commands maps lowercase command names to agent names, such as the agent
+// registry's process names.
+func AgentForCommand(cmd string, commands map[string]string) string {
+	base := strings.ToLower(filepath.Base(cmd))
+	base = strings.TrimSuffix(base, ".exe")
+	return commands[base]
+}
+
+// MatcherForCommand returns the agent-specific matcher for a wrapped command,
+// or nil if the command is not a known agent (see AgentForCommand).
+func MatcherForCommand(cmd string, commands map[string]string) Matcher {
+	agent := AgentForCommand(cmd, commands)
+	if agent == "" {
+		return nil
 	}
+	return CreateMatcher(agent)
 }
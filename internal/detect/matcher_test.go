@@ -1,6 +1,8 @@
 package detect
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -316,6 +318,95 @@ func TestCopilotMatcher(t *testing.T) {
 	}
 }
 
+func TestCodyMatcher(t *testing.T) {
+	m := NewCodyMatcher()
+
+	tests := []struct {
+		name      string
+		line      string
+		wantMatch bool
+		wantType  MatchType
+		wantTool  string
+	}{
+		{
+			name:      "assistant transcript complete - complete",
+			line:      `{"type":"transcript","speaker":"assistant","status":"complete","text":"Done."}`,
+			wantMatch: true,
+			wantType:  MatchComplete,
+		},
+		{
+			name:      "assistant transcript streaming - activity",
+			line:      `{"type":"transcript","speaker":"assistant","status":"streaming","text":"Let me"}`,
+			wantMatch: true,
+			wantType:  MatchActivity,
+		},
+		{
+			name:      "human transcript - activity",
+			line:      `{"type":"transcript","speaker":"human","text":"fix the tests"}`,
+			wantMatch: true,
+			wantType:  MatchActivity,
+		},
+		{
+			name:      "tool request - holding",
+			line:      `{"type":"tool_request","tool":{"name":"bash","input":{"command":"go test ./..."}}}`,
+			wantMatch: true,
+			wantType:  MatchHolding,
+			wantTool:  "bash",
+		},
+		{
+			name:      "tool result - activity",
+			line:      `{"type":"tool_result","tool":{"name":"bash"},"output":"ok"}`,
+			wantMatch: true,
+			wantType:  MatchActivity,
+		},
+		{
+			name:      "other event - no match",
+			line:      `{"type":"auth","endpoint":"https://sourcegraph.com"}`,
+			wantMatch: false,
+		},
+		{
+			name:      "plain text - no match",
+			line:      "status complete",
+			wantMatch: false,
+		},
+		{
+			name:      "empty line - no match",
+			line:      "",
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := m.Match(tt.line)
+
+			if (result != nil) != tt.wantMatch {
+				t.Errorf("Match() returned %v, want match=%v", result != nil, tt.wantMatch)
+				return
+			}
+
+			if result == nil {
+				return
+			}
+
+			if result.Agent != "cody" {
+				t.Errorf("Agent = %q, want 'cody'", result.Agent)
+			}
+
+			if result.Type != tt.wantType {
+				t.Errorf("Type = %v, want %v", result.Type, tt.wantType)
+			}
+
+			if tt.wantTool != "" {
+				tool, ok := result.Meta["tool"].(string)
+				if !ok || tool != tt.wantTool {
+					t.Errorf("Meta[tool] = %q, want %q", tool, tt.wantTool)
+				}
+			}
+		})
+	}
+}
+
 func TestComboMatcher(t *testing.T) {
 	m := NewComboMatcher(
 		NewCodexMatcher(),
@@ -403,6 +494,17 @@ func TestClaudeMatcher(t *testing.T) {
 			line:      `{"type":"system","content":"compacted"}`,
 			wantMatch: false,
 		},
+		{
+			name:      "system compact_boundary - compaction",
+			line:      `{"type":"system","subtype":"compact_boundary","content":"Conversation compacted","compactMetadata":{"trigger":"auto","preTokens":155000}}`,
+			wantMatch: true,
+			wantType:  MatchCompaction,
+		},
+		{
+			name:      "other system subtype - no match",
+			line:      `{"type":"system","subtype":"informational","content":"hook ran"}`,
+			wantMatch: false,
+		},
 		{
 			name:      "invalid json - no match",
 			line:      `not valid json`,
@@ -452,6 +554,47 @@ func TestClaudeMatcher(t *testing.T) {
 	}
 }
 
+func TestToolArgsMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher Matcher
+		line    string
+		want    string
+	}{
+		{
+			name:    "claude input object",
+			matcher: NewClaudeMatcher(),
+			line:    `{"type":"assistant","message":{"stop_reason":"tool_use","content":[{"type":"tool_use","name":"Bash","id":"toolu_1","input":{"command":"ls"}}]}}`,
+			want:    `{"command":"ls"}`,
+		},
+		{
+			name:    "codex arguments string",
+			matcher: NewCodexMatcher(),
+			line:    `{"type":"response_item","payload":{"type":"function_call","name":"shell","call_id":"call_1","arguments":"{\"cmd\":\"ls\"}"}}`,
+			want:    `{"cmd":"ls"}`,
+		},
+		{
+			name:    "claude without input",
+			matcher: NewClaudeMatcher(),
+			line:    `{"type":"assistant","message":{"stop_reason":"tool_use","content":[{"type":"tool_use","name":"Bash","id":"toolu_1"}]}}`,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.matcher.Match(tt.line)
+			if result == nil {
+				t.Fatal("Expected match")
+			}
+			args, _ := result.Meta["tool_args"].(string)
+			if args != tt.want {
+				t.Errorf("Meta[tool_args] = %q, want %q", args, tt.want)
+			}
+		})
+	}
+}
+
 func TestQwenMatcher(t *testing.T) {
 	m := NewQwenMatcher()
 
@@ -530,6 +673,55 @@ func TestQwenMatcher(t *testing.T) {
 	}
 }
 
+func TestOpenAIChatMatcher(t *testing.T) {
+	m := NewOpenAIChatMatcher("myproxy")
+
+	tests := []struct {
+		name     string
+		line     string
+		wantType MatchType
+		reason   string
+	}{
+		{"stop", `{"id":"chatcmpl-1","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Done"}}]}`, MatchComplete, "response complete"},
+		{"tool_calls", `{"choices":[{"finish_reason":"tool_calls","message":{"tool_calls":[{"function":{"name":"read_file","arguments":"{\"path\":\"a.go\"}"}}]}}]}`, MatchHolding, "tool call"},
+		{"function_call", `{"choices":[{"finish_reason":"function_call","message":{}}]}`, MatchHolding, "tool call"},
+		{"length", `{"choices":[{"finish_reason":"length","message":{}}]}`, MatchActivity, "response chunk"},
+		{"stream chunk", `{"choices":[{"delta":{"content":"Hel"}}]}`, MatchActivity, "response chunk"},
+		{"request", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`, MatchActivity, "request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := m.Match(tt.line)
+			if result == nil {
+				t.Fatalf("Match(%q) = nil", tt.line)
+			}
+			if result.Agent != "myproxy" || result.Type != tt.wantType || result.Reason != tt.reason {
+				t.Errorf("Match = %s/%v/%q, want myproxy/%v/%q", result.Agent, result.Type, result.Reason, tt.wantType, tt.reason)
+			}
+		})
+	}
+
+	if got := m.Match(`{"choices":[{"finish_reason":"tool_calls","message":{"tool_calls":[{"function":{"name":"read_file"}}]}}]}`); got.Meta["tool"] != "read_file" {
+		t.Errorf("Meta[tool] = %v, want read_file", got.Meta["tool"])
+	}
+	if got := m.Match(`{"usage":{"total_tokens":10}}`); got != nil {
+		t.Errorf("Unrelated object matched: %+v", got)
+	}
+	if _, err := m.MatchErr(`{"choices":[`); err == nil {
+		t.Error("Expected parse error for truncated JSON")
+	}
+}
+
+func TestCreateMatcherForFormat(t *testing.T) {
+	if m, ok := CreateMatcherForFormat("myproxy", FormatOpenAIChat, Keywords{}).(*OpenAIChatMatcher); !ok || m.agent != "myproxy" {
+		t.Errorf("openai_chat format = %T, want *OpenAIChatMatcher for myproxy", m)
+	}
+	if _, ok := CreateMatcherForFormat("claude", "", Keywords{}).(*ClaudeMatcher); !ok {
+		t.Error("Empty format should keep the agent's own matcher")
+	}
+}
+
 func TestOpenCodeMatcher(t *testing.T) {
 	m := NewOpenCodeMatcher()
 
@@ -1080,8 +1272,48 @@ func TestFallbackMatcher(t *testing.T) {
 	}
 }
 
+func TestMatchLineParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		matcher   Matcher
+		line      string
+		wantMatch bool
+		wantErr   bool
+	}{
+		{"claude valid", NewClaudeMatcher(), `{"type":"assistant","message":{"stop_reason":"end_turn"}}`, true, false},
+		{"claude not for it", NewClaudeMatcher(), `{"type":"user"}`, false, false},
+		{"claude truncated", NewClaudeMatcher(), `{"type":"assistant","message":{"stop_rea`, false, true},
+		{"claude plain text", NewClaudeMatcher(), `not json at all`, false, false},
+		{"claude blank", NewClaudeMatcher(), "   ", false, false},
+		{"codex malformed", NewCodexMatcher(), `{"type":"response_item",}`, false, true},
+		{"copilot malformed", NewCopilotMatcher(), `{"type": assistant.turn_end}`, false, true},
+		{"copilot old text format", NewCopilotMatcher(), `[INFO] chat/completions succeeded`, true, false},
+		{"qwen malformed", NewQwenMatcher(), `{"choices":[`, false, true},
+		{"cody malformed", NewCodyMatcher(), `{"type":"transcript",`, false, true},
+		{"regex has no parse errors", MustRegexMatcher("x", "assistant"), `{"assistant"`, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := MatchLine(tt.matcher, tt.line)
+			if (match != nil) != tt.wantMatch {
+				t.Errorf("match = %v, want match %v", match, tt.wantMatch)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var perr *ParseError
+				if !errors.As(err, &perr) || perr.Agent == "" {
+					t.Errorf("err = %#v, want *ParseError with agent", err)
+				}
+			}
+		})
+	}
+}
+
 func TestCreateMatcher(t *testing.T) {
-	tests := []string{"claude", "codex", "copilot", "gemini", "opencode", "crush", "qwen", "amazonq", "plandex", "aider"}
+	tests := []string{"claude", "codex", "copilot", "gemini", "opencode", "crush", "qwen", "cody", "amazonq", "plandex", "aider"}
 
 	for _, agent := range tests {
 		t.Run(agent, func(t *testing.T) {
@@ -1104,3 +1336,112 @@ func TestCreateMatcher(t *testing.T) {
 		}
 	})
 }
+
+func TestCreateMatcherWithKeywords(t *testing.T) {
+	kw := Keywords{
+		Complete: []string{"Terminé"},
+		Holding:  []string{"bitte bestätigen"},
+		Activity: []string{"denke nach"},
+	}
+
+	tests := []struct {
+		agent string
+		line  string
+		want  MatchType
+	}{
+		{"opencode", "2025-01-09T10:00:00 Terminé en 5s", MatchComplete},
+		{"opencode", "2025-01-09T10:00:00 Bitte bestätigen: bash", MatchHolding},
+		{"opencode", "2025-01-09T10:00:00 denke nach", MatchActivity},
+		{"crush", `{"level":"INFO","msg":"terminé"}`, MatchComplete},
+		{"crush", "INFO bitte bestätigen", MatchHolding},
+		{"crush", "INFO denke nach", MatchActivity},
+		{"amazonq", "[INFO] terminé", MatchComplete},
+		{"amazonq", "[INFO] bitte bestätigen", MatchHolding},
+		{"amazonq", "[INFO] denke nach", MatchActivity},
+		{"plandex", "TERMINÉ", MatchComplete},
+		{"plandex", "bitte bestätigen", MatchHolding},
+		{"plandex", "denke nach", MatchActivity},
+		{"myagent", "terminé", MatchComplete},
+		{"myagent", "bitte bestätigen", MatchHolding},
+		{"myagent", "denke nach", MatchActivity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agent+"/"+tt.line, func(t *testing.T) {
+			if m := CreateMatcher(tt.agent).Match(tt.line); m != nil && m.Type == tt.want {
+				t.Fatalf("built-in matcher already matches %q as %v; pick another keyword", tt.line, m.Type)
+			}
+
+			m := CreateMatcherWithKeywords(tt.agent, kw).Match(tt.line)
+			if m == nil {
+				t.Fatalf("Match(%q) = nil, want %v", tt.line, tt.want)
+			}
+			if m.Type != tt.want {
+				t.Errorf("Match(%q).Type = %v, want %v", tt.line, m.Type, tt.want)
+			}
+		})
+	}
+
+	t.Run("built-in keywords still match", func(t *testing.T) {
+		m := CreateMatcherWithKeywords("opencode", kw).Match("2025-01-09T10:00:00 turn.complete duration=5s")
+		if m == nil || m.Type != MatchComplete {
+			t.Errorf("turn.complete should still be MatchComplete, got %v", m)
+		}
+	})
+}
+
+func TestMatcherForCommand(t *testing.T) {
+	commands := map[string]string{
+		"claude": "claude", "codex": "codex", "gemini": "gemini", "copilot": "copilot",
+		"qwen": "qwen", "cody": "cody", "opencode": "opencode", "crush": "crush",
+		"q": "amazonq", "qchat": "amazonq", "pdx": "plandex", "aider": "aider",
+	}
+	tests := []struct {
+		cmd  string
+		want Matcher
+	}{
+		{"claude", &ClaudeMatcher{}},
+		{"/usr/local/bin/claude", &ClaudeMatcher{}},
+		{"codex", &CodexMatcher{}},
+		{"gemini", &GeminiMatcher{}},
+		{"copilot", &CopilotMatcher{}},
+		{"qwen", &QwenMatcher{}},
+		{"cody", &CodyMatcher{}},
+		{"opencode", &OpenCodeMatcher{}},
+		{"crush", &CrushMatcher{}},
+		{"q", &AmazonQMatcher{}},
+		{"qchat", &AmazonQMatcher{}},
+		{"pdx", &PlandexMatcher{}},
+		{"aider", &AiderMatcher{}},
+		{"Claude.exe", &ClaudeMatcher{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			m := MatcherForCommand(tt.cmd, commands)
+			if fmt.Sprintf("%T", m) != fmt.Sprintf("%T", tt.want) {
+				t.Errorf("MatcherForCommand(%q) = %T, want %T", tt.cmd, m, tt.want)
+			}
+		})
+	}
+
+	for _, cmd := range []string{"python", "./my-ai-script.sh", ""} {
+		if m := MatcherForCommand(cmd, commands); m != nil {
+			t.Errorf("MatcherForCommand(%q) = %T, want nil", cmd, m)
+		}
+	}
+}
+
+func TestClaudeMatcherCompactionMeta(t *testing.T) {
+	m := NewClaudeMatcher()
+	match := m.Match(`{"type":"system","subtype":"compact_boundary","compactMetadata":{"trigger":"manual","preTokens":98765}}`)
+	if match == nil || match.Type != MatchCompaction {
+		t.Fatalf("Match = %+v, want compaction", match)
+	}
+	if match.Meta["trigger"] != "manual" || match.Meta["pre_tokens"] != 98765 {
+		t.Errorf("Meta = %v, want trigger manual and pre_tokens 98765", match.Meta)
+	}
+	if MatchCompaction.String() != "compaction" {
+		t.Errorf("String() = %q, want compaction", MatchCompaction.String())
+	}
+}
@@ -0,0 +1,47 @@
+package detect
+
+import "sort"
+
+// ReasonCount is how many lines matched with a given Match.Reason.
+type ReasonCount struct {
+	Reason string
+	Type   MatchType // Match type of the first line seen with this reason
+	Count  int
+}
+
+// TallyReasons runs m over lines and counts matches by Match.Reason, most
+// frequent first (ties by reason). Empty lines are skipped. The second
+// return value is the number of non-empty lines that did not match.
+func TallyReasons(m Matcher, lines []string) ([]ReasonCount, int) {
+	counts := make(map[string]*ReasonCount)
+	noMatch := 0
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		match := m.Match(line)
+		if match == nil {
+			noMatch++
+			continue
+		}
+		rc, ok := counts[match.Reason]
+		if !ok {
+			rc = &ReasonCount{Reason: match.Reason, Type: match.Type}
+			counts[match.Reason] = rc
+		}
+		rc.Count++
+	}
+
+	result := make([]ReasonCount, 0, len(counts))
+	for _, rc := range counts {
+		result = append(result, *rc)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Reason < result[j].Reason
+	})
+	return result, noMatch
+}
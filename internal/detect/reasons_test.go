@@ -0,0 +1,49 @@
+package detect
+
+import "testing"
+
+func TestTallyReasons(t *testing.T) {
+	lines := append(syntheticClaudeLines(8), "", "")
+
+	counts, noMatch := TallyReasons(NewClaudeMatcher(), lines)
+
+	total := noMatch
+	for _, rc := range counts {
+		total += rc.Count
+		if rc.Reason == "" {
+			t.Errorf("Unexpected empty reason in %+v", counts)
+		}
+	}
+	if total != 8 {
+		t.Errorf("Counted %d lines, want 8", total)
+	}
+
+	// Sorted by count, descending
+	for i := 1; i < len(counts); i++ {
+		if counts[i].Count > counts[i-1].Count {
+			t.Errorf("Counts not sorted: %+v", counts)
+		}
+	}
+
+	var holding int
+	for _, rc := range counts {
+		if rc.Type == MatchHolding {
+			holding += rc.Count
+		}
+	}
+	if holding != 2 {
+		t.Errorf("Holding lines = %d, want 2", holding)
+	}
+}
+
+func TestTallyReasonsRegex(t *testing.T) {
+	m := MustRegexMatcher("test", `done|finished`)
+	counts, noMatch := TallyReasons(m, []string{"done", "finished", "nothing", "done again"})
+
+	if noMatch != 1 {
+		t.Errorf("noMatch = %d, want 1", noMatch)
+	}
+	if len(counts) != 1 || counts[0].Count != 3 {
+		t.Errorf("Expected a single reason with 3 lines, got %+v", counts)
+	}
+}
@@ -2,10 +2,15 @@
 package monitor
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/detect"
 )
 
 // Agent represents a supported AI CLI tool with its configuration.
@@ -15,6 +20,10 @@ type Agent struct {
 	LogPath      string   // Default log path (with ~ for home)
 	LogPatterns  []string // Glob patterns for log files
 	ProcessNames []string // Process names for PID detection
+	SessionFiles bool     // Writes one log file per session (per_instance "auto" tracks these separately)
+	AltLogPaths  []string // Other known log locations, probed by setup when LogPath is missing
+	WholeFile    bool     // Rewrites its whole log file on each update instead of appending
+	Format       string   // Log format selecting another matcher, as agents.format (set by the registry file)
 	// Matcher will be added in Phase 2 (detect package)
 }
 
@@ -27,6 +36,8 @@ var Registry = map[string]Agent{
 		LogPath:      "~/.claude/projects",
 		LogPatterns:  []string{"*.jsonl"},
 		ProcessNames: []string{"claude", "claude-code"},
+		SessionFiles: true,
+		AltLogPaths:  []string{"$CLAUDE_CONFIG_DIR/projects"},
 	},
 	"codex": {
 		Name:         "codex",
@@ -34,6 +45,8 @@ var Registry = map[string]Agent{
 		LogPath:      "~/.codex/sessions",
 		LogPatterns:  []string{"*.jsonl", "*.json"},
 		ProcessNames: []string{"codex"},
+		SessionFiles: true,
+		AltLogPaths:  []string{"$CODEX_HOME/sessions"},
 	},
 	"copilot": {
 		Name:         "copilot",
@@ -41,6 +54,7 @@ var Registry = map[string]Agent{
 		LogPath:      "~/.copilot/session-state",
 		LogPatterns:  []string{"*.jsonl"},
 		ProcessNames: []string{"copilot"},
+		SessionFiles: true,
 	},
 	"gemini": {
 		Name:         "gemini",
@@ -48,6 +62,8 @@ var Registry = map[string]Agent{
 		LogPath:      "~/.gemini/tmp",
 		LogPatterns:  []string{"*.json"},
 		ProcessNames: []string{"gemini"},
+		SessionFiles: true,
+		WholeFile:    true,
 	},
 	"opencode": {
 		Name:         "opencode",
@@ -55,11 +71,14 @@ var Registry = map[string]Agent{
 		LogPath:      "~/.local/share/opencode/log",
 		LogPatterns:  []string{"*.log"},
 		ProcessNames: []string{"opencode"},
+		SessionFiles: true,
+		AltLogPaths:  []string{"$XDG_DATA_HOME/opencode/log"},
 	},
 	"crush": {
 		Name:         "crush",
 		DisplayName:  "Crush",
 		LogPath:      "~/.local/share/crush",
+		AltLogPaths:  []string{"$XDG_DATA_HOME/crush"},
 		LogPatterns:  []string{"*.log", "*.jsonl"},
 		ProcessNames: []string{"crush"},
 	},
@@ -69,20 +88,30 @@ var Registry = map[string]Agent{
 		LogPath:      "~/.qwen/logs/openai",
 		LogPatterns:  []string{"*.jsonl", "*.json"},
 		ProcessNames: []string{"qwen", "qwen-code"},
+		SessionFiles: true,
+	},
+	"cody": {
+		Name:         "cody",
+		DisplayName:  "Sourcegraph Cody",
+		LogPath:      "~/.local/share/cody/logs",
+		AltLogPaths:  []string{"$XDG_DATA_HOME/cody/logs"},
+		LogPatterns:  []string{"*.jsonl"},
+		ProcessNames: []string{"cody"},
 	},
 	"amazonq": {
 		Name:         "amazonq",
 		DisplayName:  "Amazon Q",
 		LogPath:      "~/.local/state/amazonq/logs",
+		AltLogPaths:  []string{"$XDG_STATE_HOME/amazonq/logs"},
 		LogPatterns:  []string{"*.log"},
-		ProcessNames: []string{"q", "amazonq"},
+		ProcessNames: []string{"q", "qchat", "amazonq"},
 	},
 	"plandex": {
 		Name:         "plandex",
 		DisplayName:  "Plandex",
 		LogPath:      "~/.plandex-home",
 		LogPatterns:  []string{"*.log", "*.json"},
-		ProcessNames: []string{"plandex"},
+		ProcessNames: []string{"plandex", "pdx"},
 	},
 	"aider": {
 		Name:         "aider",
@@ -102,6 +131,42 @@ func GetAgent(name string) *Agent {
 	return nil
 }
 
+// LogFormat returns the log format of the named agent: agents.format if
+// configured, otherwise its registry entry's.
+func LogFormat(name string, formats map[string]string) string {
+	if format := formats[name]; format != "" {
+		return format
+	}
+	if agent := GetAgent(name); agent != nil {
+		return agent.Format
+	}
+	return ""
+}
+
+// NewAgentMatcher creates the matcher for the named agent's lines, in its
+// agents.format with its agents.keywords, as the watcher matches them.
+func NewAgentMatcher(cfg *config.Config, name string) detect.Matcher {
+	kw := cfg.Agents.Keywords[name]
+	return detect.CreateMatcherForFormat(name, LogFormat(name, cfg.Agents.Format), detect.Keywords{
+		Complete: kw.Complete,
+		Holding:  kw.Holding,
+		Activity: kw.Activity,
+	})
+}
+
+// AutoPerInstance reports whether per_instance "auto" tracks the agent's log
+// files separately: only agents that write one log file per session do, and
+// only when their log path is a directory rather than a single file.
+func AutoPerInstance(agent Agent) bool {
+	if !agent.SessionFiles || IsStreamPath(agent.LogPath) {
+		return false
+	}
+	if info, err := os.Stat(config.ExpandPath(agent.LogPath)); err == nil && !info.IsDir() {
+		return false
+	}
+	return true
+}
+
 // GetAgents returns agents based on the filter list.
 // If filter is empty or nil, returns auto-detected active agents.
 // If filter contains specific names, returns only those agents.
@@ -119,13 +184,96 @@ func GetAgents(filter []string) []Agent {
 	return agents
 }
 
+// instancePlaceholder marks where the instance label goes in a display name.
+const instancePlaceholder = "{instance}"
+
+// ApplyDisplayNames returns agents with display names overridden from names
+// (agent name -> display name). The {instance} placeholder is only filled in
+// per-instance mode, so it is dropped from agent-level names.
+func ApplyDisplayNames(agents []Agent, names map[string]string) []Agent {
+	if len(names) == 0 {
+		return agents
+	}
+
+	out := make([]Agent, len(agents))
+	for i, agent := range agents {
+		if name := names[agent.Name]; name != "" {
+			name = strings.ReplaceAll(name, "("+instancePlaceholder+")", "")
+			name = strings.ReplaceAll(name, instancePlaceholder, "")
+			if name = strings.TrimSpace(name); name != "" {
+				agent.DisplayName = name
+			}
+		}
+		out[i] = agent
+	}
+	return out
+}
+
+// ApplyPathOverrides returns agents with log paths overridden from paths
+// (agent name -> log path), as configured by agents.paths.
+func ApplyPathOverrides(agents []Agent, paths map[string]string) []Agent {
+	if len(paths) == 0 {
+		return agents
+	}
+
+	out := make([]Agent, len(agents))
+	for i, agent := range agents {
+		if path := paths[agent.Name]; path != "" {
+			agent.LogPath = path
+		}
+		out[i] = agent
+	}
+	return out
+}
+
+// ResolveAgents selects the agents to monitor: the named agent if name is set,
+// otherwise agents.enabled, otherwise auto-detected agents. agents.paths
+// overrides apply however the agents were selected, including to detection.
+// activeWindow limits auto-detection to recently written logs (0 = no limit),
+// and exts (advanced.log_extensions) is what detection counts as a log.
+// Returns an error only for an unknown agent name.
+func ResolveAgents(name string, cfg config.AgentsConfig, activeWindow time.Duration, exts LogExtensions) ([]Agent, error) {
+	if name != "" {
+		agent := GetAgent(name)
+		if agent == nil {
+			return nil, fmt.Errorf("unknown agent: %s", name)
+		}
+		return ApplyPathOverrides([]Agent{*agent}, cfg.Paths), nil
+	}
+	if len(cfg.Enabled) > 0 {
+		return ApplyPathOverrides(GetAgents(cfg.Enabled), cfg.Paths), nil
+	}
+	return DetectActiveAgentsWith(cfg.Paths, activeWindow, exts), nil
+}
+
 // DetectActiveAgents scans the filesystem for agents with recent log activity.
 // An agent is considered "active" if its log path exists (regardless of recency).
 func DetectActiveAgents() []Agent {
+	return DetectActiveAgentsWith(nil, 0, nil)
+}
+
+// DetectActiveAgentsWith is DetectActiveAgents with log path overrides applied
+// before checking each agent's path. If within is positive, an agent is only
+// active if a log file under its path, one matching exts, was modified within
+// that window.
+// Agents are ordered by their newest log file, most recent first; ties (and
+// journald units and containers, which have no files) are ordered by name.
+func DetectActiveAgentsWith(paths map[string]string, within time.Duration, exts LogExtensions) []Agent {
 	var active []Agent
+	lastMod := make(map[string]time.Time)
 
 	for _, agent := range Registry {
-		expanded := ExpandPath(agent.LogPath)
+		if path := paths[agent.Name]; path != "" {
+			agent.LogPath = path
+		}
+		expanded := config.ExpandPath(agent.LogPath)
+
+		// A configured journald unit or container can't be checked for
+		// activity here; having been pointed at one is enough
+		if IsStreamPath(expanded) {
+			active = append(active, agent)
+			continue
+		}
 
 		// Check if path exists
 		info, err := os.Stat(expanded)
@@ -135,25 +283,40 @@ func DetectActiveAgents() []Agent {
 
 		// If it's a directory, check for recent modifications
 		if info.IsDir() {
-			active = append(active, agent)
+			latest := latestLogMod(expanded, exts)
+			if within <= 0 || time.Since(latest) < within {
+				active = append(active, agent)
+				lastMod[agent.Name] = latest
+			}
 		} else {
 			// If it's a file, check its modification time
-			if hasLogExtension(expanded) {
+			if exts.Match(expanded) && (within <= 0 || time.Since(info.ModTime()) < within) {
 				active = append(active, agent)
+				lastMod[agent.Name] = info.ModTime()
 			}
 		}
 	}
 
+	sort.Slice(active, func(i, j int) bool {
+		a, b := lastMod[active[i].Name], lastMod[active[j].Name]
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return active[i].Name < active[j].Name
+	})
 	return active
 }
 
 // FindStaleAgents returns agents whose log paths exist but have no activity within the duration.
-// Paths that fail to stat or have no log files are treated as stale for reporting.
-func FindStaleAgents(agents []Agent, within time.Duration) []Agent {
+// Paths that fail to stat or have no log files (those matching exts) are treated as stale for reporting.
+func FindStaleAgents(agents []Agent, within time.Duration, exts LogExtensions) []Agent {
 	var stale []Agent
 
 	for _, agent := range agents {
-		expanded := ExpandPath(agent.LogPath)
+		expanded := config.ExpandPath(agent.LogPath)
+		if IsStreamPath(expanded) {
+			continue
+		}
 		info, err := os.Stat(expanded)
 		if err != nil {
 			stale = append(stale, agent)
@@ -161,7 +324,7 @@ func FindStaleAgents(agents []Agent, within time.Duration) []Agent {
 		}
 
 		if info.IsDir() {
-			if !hasRecentActivity(expanded, within) {
+			if !hasRecentActivity(expanded, within, exts) {
 				stale = append(stale, agent)
 			}
 		} else {
@@ -174,9 +337,9 @@ func FindStaleAgents(agents []Agent, within time.Duration) []Agent {
 	return stale
 }
 
-// hasRecentActivity checks if a directory has files modified within the duration.
-func hasRecentActivity(dir string, within time.Duration) bool {
-	var mostRecent time.Time
+// hasRecentActivity checks if a directory has log files modified within the duration.
+func hasRecentActivity(dir string, within time.Duration, exts LogExtensions) bool {
+	found := false
 
 	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
@@ -184,52 +347,68 @@ func hasRecentActivity(dir string, within time.Duration) bool {
 		}
 
 		// Check file extension
-		if !hasLogExtension(path) {
+		if !exts.Match(path) {
 			return nil
 		}
 
-		if info.ModTime().After(mostRecent) {
-			mostRecent = info.ModTime()
-		}
-
 		// Early exit if we found recent activity
-		if time.Since(mostRecent) < within {
+		if time.Since(info.ModTime()) < within {
+			found = true
 			return filepath.SkipAll
 		}
-
 		return nil
 	})
 
-	return time.Since(mostRecent) < within
+	return found
 }
 
-// hasLogExtension checks if a file has a log-related extension.
-func hasLogExtension(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".log", ".txt", ".json", ".jsonl":
-		return true
-	default:
-		return false
-	}
+// latestLogMod returns the modification time of the newest log file (one
+// matching exts) under dir, or the zero time if there is none. Unlike
+// hasRecentActivity it always walks the whole tree.
+func latestLogMod(dir string, exts LogExtensions) time.Time {
+	var mostRecent time.Time
+
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		// Check file extension
+		if !exts.Match(path) {
+			return nil
+		}
+
+		if info.ModTime().After(mostRecent) {
+			mostRecent = info.ModTime()
+		}
+		return nil
+	})
+
+	return mostRecent
 }
 
-// ExpandPath expands ~ to the user's home directory.
-func ExpandPath(path string) string {
-	if !strings.HasPrefix(path, "~") {
-		return path
-	}
+// DefaultLogExtensions are the extensions of log files when
+// advanced.log_extensions is unset.
+var DefaultLogExtensions = []string{".log", ".txt", ".json", ".jsonl"}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return path
-	}
+// LogExtensions is the set of file extensions discovered as logs, e.g.
+// ".ndjson" (the leading dot is optional). Empty means DefaultLogExtensions;
+// otherwise the set replaces them, so it must list any defaults to keep.
+type LogExtensions []string
 
-	if path == "~" {
-		return home
+// Match reports whether path ends in one of the extensions, ignoring case.
+func (e LogExtensions) Match(path string) bool {
+	if len(e) == 0 {
+		e = DefaultLogExtensions
 	}
-
-	return filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	name := strings.ToLower(filepath.Base(path))
+	for _, ext := range e {
+		ext = "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+		if len(name) > len(ext) && strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
 }
 
 // AllAgentNames returns a list of all supported agent names.
@@ -240,3 +419,23 @@ func AllAgentNames() []string {
 	}
 	return names
 }
+
+// CommandAgents maps each registry agent's process names, lowercased, to the
+// agent's name, for recognizing a wrapped command (see
+// detect.AgentForCommand). A name claimed by several agents goes to the
+// first by agent name.
+func CommandAgents() map[string]string {
+	names := AllAgentNames()
+	sort.Strings(names)
+
+	commands := make(map[string]string)
+	for _, name := range names {
+		for _, proc := range Registry[name].ProcessNames {
+			proc = strings.ToLower(proc)
+			if _, ok := commands[proc]; !ok {
+				commands[proc] = name
+			}
+		}
+	}
+	return commands
+}
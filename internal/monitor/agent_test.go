@@ -3,8 +3,13 @@ package monitor
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/detect"
 )
 
 func TestGetAgent(t *testing.T) {
@@ -74,31 +79,6 @@ func TestRegistryCompleteness(t *testing.T) {
 	}
 }
 
-func TestExpandPath(t *testing.T) {
-	home, _ := os.UserHomeDir()
-
-	tests := []struct {
-		name string
-		path string
-		want string
-	}{
-		{"tilde only", "~", home},
-		{"tilde with slash", "~/", home},
-		{"tilde with path", "~/.firebell", filepath.Join(home, ".firebell")},
-		{"absolute path", "/tmp/test", "/tmp/test"},
-		{"relative path", "test", "test"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := ExpandPath(tt.path)
-			if got != tt.want {
-				t.Errorf("ExpandPath(%s) = %s, want %s", tt.path, got, tt.want)
-			}
-		})
-	}
-}
-
 func TestHasLogExtension(t *testing.T) {
 	tests := []struct {
 		path string
@@ -118,9 +98,9 @@ func TestHasLogExtension(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			got := hasLogExtension(tt.path)
+			got := LogExtensions(nil).Match(tt.path)
 			if got != tt.want {
-				t.Errorf("hasLogExtension(%s) = %v, want %v", tt.path, got, tt.want)
+				t.Errorf("LogExtensions(nil).Match(%s) = %v, want %v", tt.path, got, tt.want)
 			}
 		})
 	}
@@ -186,6 +166,28 @@ func TestAllAgentNames(t *testing.T) {
 	}
 }
 
+func TestCommandAgents(t *testing.T) {
+	restoreRegistry(t)
+	Registry["mytool"] = Agent{Name: "mytool", ProcessNames: []string{"MyTool", "mt"}}
+
+	commands := CommandAgents()
+	for cmd, want := range map[string]string{
+		"claude":      "claude",
+		"claude-code": "claude",
+		"qchat":       "amazonq",
+		"pdx":         "plandex",
+		"mytool":      "mytool",
+		"mt":          "mytool",
+	} {
+		if got := commands[cmd]; got != want {
+			t.Errorf("CommandAgents()[%q] = %q, want %q", cmd, got, want)
+		}
+	}
+	if got := detect.AgentForCommand("/opt/bin/mytool", commands); got != "mytool" {
+		t.Errorf("AgentForCommand(mytool) = %q, want the agents.yaml agent", got)
+	}
+}
+
 func TestDetectActiveAgents(t *testing.T) {
 	// Create a temporary directory with a test log file
 	tmpDir := t.TempDir()
@@ -221,6 +223,120 @@ func TestDetectActiveAgents(t *testing.T) {
 	}
 }
 
+func TestDetectActiveAgentsWindow(t *testing.T) {
+	inside := t.TempDir()
+	outside := t.TempDir()
+	single := filepath.Join(t.TempDir(), "session.log")
+	for path, age := range map[string]time.Duration{
+		filepath.Join(inside, "a.log"):  50 * time.Minute,
+		filepath.Join(outside, "b.log"): 70 * time.Minute,
+		single:                          70 * time.Minute,
+	} {
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldRegistry := Registry
+	Registry = map[string]Agent{
+		"inside":  {Name: "inside", LogPath: inside},
+		"outside": {Name: "outside", LogPath: outside},
+		"single":  {Name: "single", LogPath: single},
+	}
+	defer func() { Registry = oldRegistry }()
+
+	names := func(agents []Agent) []string {
+		var out []string
+		for _, a := range agents {
+			out = append(out, a.Name)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	if got := names(DetectActiveAgentsWith(nil, time.Hour, nil)); !reflect.DeepEqual(got, []string{"inside"}) {
+		t.Errorf("1h window detected %v, want [inside]", got)
+	}
+	if got := names(DetectActiveAgentsWith(nil, 2*time.Hour, nil)); !reflect.DeepEqual(got, []string{"inside", "outside", "single"}) {
+		t.Errorf("2h window detected %v, want all", got)
+	}
+	// No window keeps the existence-only behavior
+	if got := names(DetectActiveAgentsWith(nil, 0, nil)); len(got) != 3 {
+		t.Errorf("No window detected %v, want all", got)
+	}
+
+	agents, err := ResolveAgents("", config.AgentsConfig{}, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(agents); !reflect.DeepEqual(got, []string{"inside"}) {
+		t.Errorf("ResolveAgents with 1h window = %v, want [inside]", got)
+	}
+
+	// Only logs with the configured extensions count as activity
+	recent := filepath.Join(outside, "c.ndjson")
+	if err := os.WriteFile(recent, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(DetectActiveAgentsWith(nil, time.Hour, nil)); !reflect.DeepEqual(got, []string{"inside"}) {
+		t.Errorf("Default extensions detected %v, want [inside]", got)
+	}
+	if got := names(DetectActiveAgentsWith(nil, time.Hour, LogExtensions{".ndjson"})); !reflect.DeepEqual(got, []string{"outside"}) {
+		t.Errorf("log_extensions [.ndjson] detected %v, want [outside]", got)
+	}
+}
+
+func TestDetectActiveAgentsOrder(t *testing.T) {
+	now := time.Now()
+	older := t.TempDir()
+	nested := t.TempDir()
+	tieA := t.TempDir()
+	tieB := t.TempDir()
+	single := filepath.Join(t.TempDir(), "session.jsonl")
+	for path, age := range map[string]time.Duration{
+		filepath.Join(older, "a.log"):                     3 * time.Hour,
+		filepath.Join(nested, "old.log"):                  5 * time.Hour,
+		filepath.Join(nested, "proj", "sub", "new.jsonl"): time.Minute, // Newest file is deep down
+		filepath.Join(tieA, "x.log"):                      time.Hour,
+		filepath.Join(tieB, "y.log"):                      time.Hour,
+		single:                                            10 * time.Minute,
+	} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldRegistry := Registry
+	Registry = map[string]Agent{
+		"older":  {Name: "older", LogPath: older},
+		"nested": {Name: "nested", LogPath: nested},
+		"tie-b":  {Name: "tie-b", LogPath: tieB},
+		"tie-a":  {Name: "tie-a", LogPath: tieA},
+		"single": {Name: "single", LogPath: single},
+	}
+	defer func() { Registry = oldRegistry }()
+
+	want := []string{"nested", "single", "tie-a", "tie-b", "older"}
+	for i := 0; i < 5; i++ { // Map iteration varies between runs
+		var got []string
+		for _, a := range DetectActiveAgents() {
+			got = append(got, a.Name)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Order = %v, want newest first %v", got, want)
+		}
+	}
+}
+
 func TestHasRecentActivity(t *testing.T) {
 	// Create temp directory with files
 	tmpDir := t.TempDir()
@@ -232,17 +348,17 @@ func TestHasRecentActivity(t *testing.T) {
 	}
 
 	// Test recent activity
-	if !hasRecentActivity(tmpDir, 1*time.Hour) {
+	if !hasRecentActivity(tmpDir, 1*time.Hour, nil) {
 		t.Error("Expected recent activity to be detected")
 	}
 
 	// Test with very short duration
-	if hasRecentActivity(tmpDir, 1*time.Nanosecond) {
+	if hasRecentActivity(tmpDir, 1*time.Nanosecond, nil) {
 		t.Error("Expected no recent activity with nanosecond duration")
 	}
 
 	// Test non-existent directory
-	if hasRecentActivity("/nonexistent", 1*time.Hour) {
+	if hasRecentActivity("/nonexistent", 1*time.Hour, nil) {
 		t.Error("Expected no activity for nonexistent directory")
 	}
 }
@@ -276,7 +392,7 @@ func TestFindStaleAgents(t *testing.T) {
 		t.Fatalf("Expected 1 agent from DetectActiveAgents, got %d", len(agents))
 	}
 
-	stale := FindStaleAgents(agents, 24*time.Hour)
+	stale := FindStaleAgents(agents, 24*time.Hour, nil)
 	if len(stale) != 1 || stale[0].Name != "stale" {
 		t.Fatalf("Expected stale agent to be reported, got %v", stale)
 	}
@@ -286,8 +402,69 @@ func TestFindStaleAgents(t *testing.T) {
 	if err := os.Chtimes(testLogPath, newTime, newTime); err != nil {
 		t.Fatal(err)
 	}
-	stale = FindStaleAgents(agents, 24*time.Hour)
+	stale = FindStaleAgents(agents, 24*time.Hour, nil)
 	if len(stale) != 0 {
 		t.Fatalf("Expected no stale agents after recent update, got %v", stale)
 	}
 }
+
+func TestResolveAgentsPathOverride(t *testing.T) {
+	override := t.TempDir()
+	if err := os.WriteFile(filepath.Join(override, "session.jsonl"), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldRegistry := Registry
+	Registry = map[string]Agent{
+		"claude": {Name: "claude", DisplayName: "Claude Code", LogPath: filepath.Join(override, "missing-default")},
+	}
+	defer func() { Registry = oldRegistry }()
+
+	cfg := config.AgentsConfig{Paths: map[string]string{"claude": override}}
+
+	tests := []struct {
+		name      string
+		agentFlag string
+		enabled   []string
+	}{
+		{"--agent", "claude", nil},
+		{"agents.enabled", "", []string{"claude"}},
+		{"auto-detect", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Enabled = tt.enabled
+			agents, err := ResolveAgents(tt.agentFlag, cfg, 0, nil)
+			if err != nil {
+				t.Fatalf("ResolveAgents failed: %v", err)
+			}
+			// Auto-detect only finds the agent through the overridden path
+			if len(agents) != 1 {
+				t.Fatalf("Expected 1 agent, got %d", len(agents))
+			}
+			if agents[0].LogPath != override {
+				t.Errorf("LogPath = %q, want override %q", agents[0].LogPath, override)
+			}
+		})
+	}
+
+	t.Run("unknown agent", func(t *testing.T) {
+		if _, err := ResolveAgents("nope", config.AgentsConfig{}, 0, nil); err == nil {
+			t.Error("expected error for unknown agent")
+		}
+	})
+}
+
+func TestNewAgentMatcher(t *testing.T) {
+	cfg := config.DefaultConfig()
+	line := "all checks green"
+	if m, _ := detect.MatchLine(NewAgentMatcher(cfg, "plandex"), line); m != nil && m.Type == detect.MatchComplete {
+		t.Fatalf("Default plandex matcher treats %q as complete", line)
+	}
+
+	cfg.Agents.Keywords = map[string]config.KeywordsConfig{"plandex": {Complete: []string{"checks green"}}}
+	if m, _ := detect.MatchLine(NewAgentMatcher(cfg, "plandex"), line); m == nil || m.Type != detect.MatchComplete {
+		t.Errorf("Match(%q) = %+v, want complete from agents.keywords", line, m)
+	}
+}
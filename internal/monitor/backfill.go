@@ -0,0 +1,283 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"firebell/internal/detect"
+)
+
+// maxBackfillBytes bounds how much of each file is read during backfill.
+const maxBackfillBytes = 1024 * 1024
+
+// HistoryLine is a log line read during backfill with its best-known timestamp.
+type HistoryLine struct {
+	Text string
+	Time time.Time
+}
+
+// ReadHistory reads lines written at or after since, then leaves the tailer
+// positioned after the last complete line so live tailing resumes from there.
+// Line timestamps are used where available; otherwise the file's mod time is
+// used for every line. At most maxBytes from the end of the file are examined.
+func (t *Tailer) ReadHistory(since time.Time, maxBytes int64) ([]HistoryLine, error) {
+	t.Reset()
+
+	f, err := os.Open(t.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	t.file = f
+	t.started = true
+	t.offset = info.Size()
+
+	// Nothing written inside the window
+	if info.ModTime().Before(since) || info.Size() == 0 {
+		return nil, nil
+	}
+
+	start := int64(0)
+	if maxBytes > 0 && info.Size() > maxBytes {
+		start = info.Size() - maxBytes
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(io.LimitReader(f, info.Size()-start))
+	if err != nil {
+		return nil, err
+	}
+
+	data := string(content)
+
+	// Drop a leading partial line when starting mid-file
+	if start > 0 {
+		if idx := strings.IndexByte(data, '\n'); idx >= 0 {
+			data = data[idx+1:]
+		} else {
+			data = ""
+		}
+	}
+
+	// Leave any trailing partial line for live tailing
+	end := strings.LastIndexByte(data, '\n')
+	if end < 0 {
+		t.offset = info.Size() - int64(len(data))
+		return nil, nil
+	}
+	t.offset = info.Size() - int64(len(data)-end-1)
+	lines := strings.Split(data[:end], "\n")
+
+	return selectHistory(lines, since, info.ModTime()), nil
+}
+
+// selectHistory stamps lines with their timestamps and keeps those at or after since.
+// Lines without a timestamp inherit the previous line's; if no line carries a
+// timestamp, every line is stamped with modTime.
+func selectHistory(lines []string, since, modTime time.Time) []HistoryLine {
+	history := make([]HistoryLine, 0, len(lines))
+	var current time.Time
+	found := false
+
+	for _, line := range lines {
+		if ts, ok := ParseLineTime(line); ok {
+			current = ts
+			found = true
+		}
+		history = append(history, HistoryLine{Text: line, Time: current})
+	}
+
+	if !found {
+		for i := range history {
+			history[i].Time = modTime
+		}
+		return history
+	}
+
+	for i, h := range history {
+		if !h.Time.IsZero() && !h.Time.Before(since) {
+			return history[i:]
+		}
+	}
+	return nil
+}
+
+// lineTimeKeys are JSON fields checked for a line timestamp, in order.
+var lineTimeKeys = []string{"timestamp", "ts", "time", "created_at"}
+
+// lineTimeLayouts are date-time layouts recognized at the start of text lines.
+// Fractional seconds are accepted when parsing even though the layouts omit them.
+var lineTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+// ParseLineTime extracts a timestamp from a log line.
+// Supports JSON lines with a timestamp/ts/time/created_at field (RFC 3339 or
+// Unix seconds/milliseconds) and text lines starting with a date-time.
+func ParseLineTime(line string) (time.Time, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return time.Time{}, false
+	}
+
+	if trimmed[0] == '{' {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+			return time.Time{}, false
+		}
+		for _, key := range lineTimeKeys {
+			if ts, ok := parseTimeValue(obj[key]); ok {
+				return ts, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	// Text line, e.g. "2025-01-15T10:30:00Z ..." or "[2025-01-15 10:30:00] ..."
+	head := strings.TrimPrefix(trimmed, "[")
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	fields := strings.FieldsFunc(head, func(r rune) bool { return r == ' ' || r == ']' })
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		return ts, true
+	}
+	if len(fields) > 1 {
+		dateTime := fields[0] + " " + fields[1]
+		for _, layout := range lineTimeLayouts {
+			if ts, err := time.ParseInLocation(layout, dateTime, time.Local); err == nil {
+				return ts, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimeValue converts a JSON value to a time.
+func parseTimeValue(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case string:
+		if ts, err := time.Parse(time.RFC3339Nano, val); err == nil {
+			return ts, true
+		}
+		if n, err := strconv.ParseFloat(val, 64); err == nil {
+			return unixTime(n), true
+		}
+	case float64:
+		return unixTime(val), true
+	}
+	return time.Time{}, false
+}
+
+// unixTime converts Unix seconds or milliseconds to a time.
+func unixTime(n float64) time.Time {
+	if n > 1e12 {
+		return time.UnixMilli(int64(n))
+	}
+	sec := int64(n)
+	return time.Unix(sec, int64((n-float64(sec))*1e9))
+}
+
+// ReadHistory reads backfill history from all managed tailers.
+// Returns a map of path -> lines.
+func (m *TailerManager) ReadHistory(since time.Time) map[string][]HistoryLine {
+	result := make(map[string][]HistoryLine)
+
+	for path, reader := range m.tailers {
+		// Only files have history to read back; journald units and containers start live
+		tailer, ok := reader.(*Tailer)
+		if !ok {
+			continue
+		}
+		lines, err := tailer.ReadHistory(since, maxBackfillBytes)
+		if err != nil {
+			tailer.Reset()
+			continue
+		}
+		if len(lines) > 0 {
+			result[path] = lines
+		}
+	}
+
+	return result
+}
+
+// Backfill processes log history written since the given time to seed cue
+// state without sending notifications. Cues whose quiet period already
+// elapsed are marked as notified so old turns don't fire on startup.
+func (w *Watcher) Backfill(since time.Time) {
+	quietDuration := w.cfg.QuietDuration()
+
+	for name, mgr := range w.managers {
+		paths := mgr.RefreshFiles()
+		w.state.UpdateWatchedPaths(name, paths)
+
+		for path, lines := range mgr.ReadHistory(since) {
+			w.seedLines(name, path, lines)
+
+			if w.state.IsPerInstanceAgent(name) {
+				if w.state.ShouldSendInstanceQuiet(path, quietDuration) {
+					w.state.MarkInstanceQuietNotified(path)
+				}
+			}
+		}
+
+		if !w.state.IsPerInstanceAgent(name) && w.state.ShouldSendQuiet(name, quietDuration) {
+			w.state.MarkQuietNotified(name)
+		}
+	}
+}
+
+// seedLines records cues from history lines without sending notifications.
+func (w *Watcher) seedLines(agentName, path string, lines []HistoryLine) {
+	matcher := w.matchers[agentName]
+	if matcher == nil {
+		return
+	}
+
+	if w.state.IsPerInstanceAgent(agentName) {
+		w.state.GetOrCreateInstance(agentName, path)
+	}
+
+	// History is complete, so its last entry is seeded too, not held
+	entries, last := joinContinuations(nil, lines, w.cfg.Agents.ContinuationPrefix[agentName])
+	if last != nil {
+		entries = append(entries, *last)
+	}
+	for _, line := range entries {
+		if line.Text == "" {
+			continue
+		}
+
+		match, _ := matchEntry(matcher, line.Text)
+		if match == nil {
+			continue
+		}
+
+		w.recordCueAt(agentName, path, match.Type, line.Time)
+	}
+}
+
+// recordCueAt records a cue at a specific time, using per-instance or per-agent mode.
+func (w *Watcher) recordCueAt(agentName, path string, cueType detect.MatchType, at time.Time) {
+	if w.state.IsPerInstanceAgent(agentName) {
+		w.state.RecordInstanceCueAt(path, cueType, at)
+	} else {
+		w.state.RecordCueAt(agentName, cueType, at)
+	}
+}
@@ -0,0 +1,210 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"firebell/internal/detect"
+)
+
+func claudeLine(ts time.Time, stopReason string) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"message":{"stop_reason":%q}}`,
+		ts.UTC().Format(time.RFC3339Nano), stopReason)
+}
+
+func TestParseLineTime(t *testing.T) {
+	want := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		line string
+		ok   bool
+	}{
+		{"json timestamp", `{"timestamp":"2025-01-15T10:30:00Z","type":"assistant"}`, true},
+		{"json ts seconds", fmt.Sprintf(`{"ts":%d}`, want.Unix()), true},
+		{"json time millis", fmt.Sprintf(`{"time":%d}`, want.UnixMilli()), true},
+		{"text rfc3339", "2025-01-15T10:30:00Z INFO response complete", true},
+		{"json without timestamp", `{"type":"assistant"}`, false},
+		{"text without timestamp", "thinking about the problem", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseLineTime(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ParseLineTime(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			}
+			if ok && !got.Equal(want) {
+				t.Errorf("ParseLineTime(%q) = %v, want %v", tt.line, got, want)
+			}
+		})
+	}
+
+	t.Run("text local date-time", func(t *testing.T) {
+		got, ok := ParseLineTime("[2025-01-15 10:30:00.250] tool.execute bash")
+		if !ok {
+			t.Fatal("expected timestamp to parse")
+		}
+		local := time.Date(2025, 1, 15, 10, 30, 0, 250*int(time.Millisecond), time.Local)
+		if !got.Equal(local) {
+			t.Errorf("got %v, want %v", got, local)
+		}
+	})
+}
+
+func TestTailerReadHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "session.jsonl")
+
+	now := time.Now()
+	lines := []string{
+		claudeLine(now.Add(-10*time.Minute), "end_turn"),
+		claudeLine(now.Add(-5*time.Minute), "tool_use"),
+		claudeLine(now.Add(-30*time.Second), ""),
+		claudeLine(now.Add(-20*time.Second), "end_turn"),
+	}
+	content := strings.Join(lines, "\n") + "\n" + `{"partial":`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, false)
+	defer tailer.Close()
+
+	history, err := tailer.ReadHistory(now.Add(-2*time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history lines within window, got %d", len(history))
+	}
+	if history[0].Text != lines[2] {
+		t.Errorf("First history line = %q, want %q", history[0].Text, lines[2])
+	}
+
+	// Live tailing resumes with the buffered partial line
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`1}` + "\n")
+	f.Close()
+
+	newLines, err := tailer.ReadNewLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newLines) == 0 || newLines[0] != `{"partial":1}` {
+		t.Errorf("Expected completed partial line after history, got %v", newLines)
+	}
+}
+
+func TestTailerReadHistoryStaleFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "old.log")
+
+	if err := os.WriteFile(testFile, []byte("thinking\ndone\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(testFile, old, old)
+
+	tailer := NewTailer(testFile, false)
+	defer tailer.Close()
+
+	history, err := tailer.ReadHistory(time.Now().Add(-time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected no history for file untouched in window, got %d lines", len(history))
+	}
+}
+
+func TestTailerReadHistoryNoTimestamps(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "plain.log")
+
+	if err := os.WriteFile(testFile, []byte("thinking\ndone\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, false)
+	defer tailer.Close()
+
+	history, err := tailer.ReadHistory(time.Now().Add(-time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected all lines stamped with mod time, got %d", len(history))
+	}
+	if history[0].Time.IsZero() {
+		t.Error("Expected lines to carry the file mod time")
+	}
+}
+
+func TestWatcherBackfillSeedsState(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "session.jsonl")
+
+	now := time.Now()
+	turnEnd := now.Add(-5 * time.Second)
+	content := claudeLine(now.Add(-30*time.Second), "") + "\n" + claudeLine(turnEnd, "end_turn") + "\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, rec := newTestWatcher(t, tmpDir, true)
+	w.Backfill(now.Add(-2 * time.Minute))
+
+	inst := w.state.GetInstance(logFile)
+	if inst == nil {
+		t.Fatal("Expected instance to be created from history")
+	}
+	if inst.LastCueType != detect.MatchComplete {
+		t.Errorf("LastCueType = %v, want MatchComplete", inst.LastCueType)
+	}
+	if !inst.LastCue.Equal(turnEnd.UTC()) {
+		t.Errorf("LastCue = %v, want %v", inst.LastCue, turnEnd)
+	}
+	if inst.QuietNotified {
+		t.Error("Recent turn should still be pending its quiet notification")
+	}
+	if rec.count() != 0 {
+		t.Errorf("Backfill should not send notifications, sent %d", rec.count())
+	}
+}
+
+func TestWatcherBackfillSuppressesOldTurns(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "session.jsonl")
+
+	now := time.Now()
+	content := claudeLine(now.Add(-90*time.Second), "end_turn") + "\n"
+	if err := os.WriteFile(logFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, rec := newTestWatcher(t, tmpDir, false)
+	w.Backfill(now.Add(-2 * time.Minute))
+
+	agentState := w.state.GetAgent("claude")
+	if agentState.LastCueType != detect.MatchComplete {
+		t.Errorf("LastCueType = %v, want MatchComplete", agentState.LastCueType)
+	}
+	if !agentState.QuietNotified {
+		t.Error("Turn older than quiet period should be marked notified")
+	}
+
+	w.checkQuietPeriods(context.Background())
+	if rec.count() != 0 {
+		t.Errorf("Old turn should not fire a notification, sent %d", rec.count())
+	}
+}
@@ -0,0 +1,40 @@
+package monitor
+
+import "time"
+
+// Clock supplies the current time. State, Watcher, and ProcessMonitor use it
+// for quiet periods, idle detection, and reminders so tests can control time.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the Clock backed by time.Now.
+type realClock struct{}
+
+// Now returns the current wall-clock time.
+func (realClock) Now() time.Time { return time.Now() }
+
+// Times from time.Now carry a monotonic clock reading, so the difference
+// between two of them ignores wall-clock changes such as NTP adjustments.
+// Times parsed from logs, or from a Clock without that reading, compare by
+// wall clock and end up after "now" when the clock is set back; the helpers
+// below keep those from stalling quiet periods until the clock catches up.
+
+// anchorTime returns t as an offset from now, so it carries now's monotonic
+// reading and later differences from it ignore wall-clock jumps. A t after
+// now (e.g. a log written by a clock ahead of ours) becomes now.
+func anchorTime(now, t time.Time) time.Time {
+	if t.After(now) {
+		return now
+	}
+	return now.Add(-now.Sub(t))
+}
+
+// rewind moves *t back to now if the clock was set back past it, restarting
+// whatever period it began, and returns how long before now *t is.
+func rewind(t *time.Time, now time.Time) time.Duration {
+	if t.After(now) {
+		*t = now
+	}
+	return now.Sub(*t)
+}
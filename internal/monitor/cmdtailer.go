@@ -0,0 +1,142 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandTailer follows the output of a command that streams log lines,
+// such as `journalctl -f` or `docker logs -f`. Output arrives in the
+// background and is buffered until the next ReadNewLines. When the command
+// exits, reads report why until Reset; the next read after RetryInterval
+// runs it again with fresh arguments, so it can resume where it stopped.
+type commandTailer struct {
+	// MaxLineBytes caps one line of output (0 = unbounded); longer lines
+	// are skipped.
+	MaxLineBytes int
+
+	// RetryInterval is how long to wait after Reset before running the
+	// command again (0 = on the next read).
+	RetryInterval time.Duration
+
+	name   string          // Command, e.g. "journalctl"
+	desc   string          // What is followed, for errors, e.g. "journalctl -u claude.service"
+	args   func() []string // Arguments for the next run; called with mu held
+	parse  outputParser    // Called with mu held
+	stderr bool            // Read the command's stderr along with its stdout
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	done    chan struct{} // Closed when the reader goroutine finishes
+	lines   []string      // Lines not yet returned
+	err     error         // Why the command stopped, once it has
+	retryAt time.Time     // Don't run the command again before this
+}
+
+// outputParser turns one line of a command's output into the log lines it
+// holds, if any. A line that is the command's own message rather than log
+// output (such as "No such container") is returned as note instead, and
+// added to the error when the command exits.
+type outputParser func(output string) (lines []string, note string)
+
+// start runs the command.
+func (t *commandTailer) start() error {
+	t.mu.Lock()
+	cmd := exec.Command(t.name, t.args()...)
+	t.mu.Unlock()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if t.stderr {
+		cmd.Stderr = cmd.Stdout
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", t.name, err)
+	}
+	Debugf("%s: following %s (pid %d)", t.name, t.desc, cmd.Process.Pid)
+
+	t.cmd = cmd
+	t.done = make(chan struct{})
+	t.err = nil
+	go t.read(stdout, cmd, t.done)
+	return nil
+}
+
+// read buffers lines from the command's output until it exits.
+func (t *commandTailer) read(stdout io.Reader, cmd *exec.Cmd, done chan struct{}) {
+	defer close(done)
+	var notes []string
+	err := readStreamLines(stdout, t.MaxLineBytes, func(output string) bool {
+		t.mu.Lock()
+		lines, note := t.parse(output)
+		t.lines = append(t.lines, lines...)
+		t.mu.Unlock()
+		if note != "" {
+			notes = append(notes, note)
+		}
+		return true
+	})
+	if err != nil {
+		// Still running with output unread; Wait would block on it
+		cmd.Process.Kill()
+	}
+	if waitErr := cmd.Wait(); err == nil {
+		err = waitErr
+	}
+	if err == nil {
+		err = fmt.Errorf("exited")
+	}
+	if len(notes) > 0 {
+		err = fmt.Errorf("%w: %s", err, strings.Join(notes, "; "))
+	}
+	t.mu.Lock()
+	t.err = fmt.Errorf("%s: %w", t.desc, err)
+	t.mu.Unlock()
+}
+
+// ReadNewLines returns the lines received since the last read, starting the
+// command on the first call. Once it has exited and its output is drained,
+// it returns the reason; after Reset, the next read past RetryInterval runs
+// it again.
+func (t *commandTailer) ReadNewLines() ([]string, error) {
+	if t.cmd == nil {
+		if time.Now().Before(t.retryAt) {
+			return nil, nil
+		}
+		if err := t.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := t.lines
+	t.lines = nil
+	if len(lines) == 0 && t.err != nil {
+		return nil, t.err
+	}
+	return lines, nil
+}
+
+// Reset stops the command; it is run again once RetryInterval has passed.
+func (t *commandTailer) Reset() {
+	t.Close()
+	t.cmd = nil
+	t.retryAt = time.Now().Add(t.RetryInterval)
+}
+
+// Close stops the command.
+func (t *commandTailer) Close() error {
+	if t.cmd == nil {
+		return nil
+	}
+	t.cmd.Process.Kill()
+	<-t.done
+	return nil
+}
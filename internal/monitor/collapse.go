@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"time"
+
+	"firebell/internal/detect"
+)
+
+// cueCollapser spots runs of activity cues (monitor.collapse_cues_ms): an
+// activity cue within the window of the previous cue from the same log file,
+// itself activity, is not a state transition, so the watcher only refreshes
+// the cue time instead of recording it in full and notifying. Strong cues
+// always pass, as they change state and feed loop detection.
+type cueCollapser struct {
+	window time.Duration
+	last   map[cueSource]lastCue
+}
+
+// cueSource identifies the log file a cue came from.
+type cueSource struct {
+	agentName, path string
+}
+
+// lastCue is the previous cue seen from one log file.
+type lastCue struct {
+	cueType detect.MatchType
+	at      time.Time
+}
+
+// newCueCollapser returns a collapser for window (0 = off).
+func newCueCollapser(window time.Duration) *cueCollapser {
+	return &cueCollapser{window: window, last: make(map[cueSource]lastCue)}
+}
+
+// collapse records a cue from agentName's log at path and reports whether
+// it only continues a run of activity cues.
+func (c *cueCollapser) collapse(agentName, path string, cueType detect.MatchType, now time.Time) bool {
+	if c.window <= 0 {
+		return false
+	}
+	key := cueSource{agentName, path}
+	prev, ok := c.last[key]
+	c.last[key] = lastCue{cueType: cueType, at: now}
+	return ok && cueType == detect.MatchActivity && prev.cueType == detect.MatchActivity &&
+		now.Sub(prev.at) < c.window
+}
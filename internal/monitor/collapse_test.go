@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"firebell/internal/detect"
+)
+
+func TestCueCollapser(t *testing.T) {
+	c := newCueCollapser(time.Second)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		path    string
+		cueType detect.MatchType
+		after   time.Duration
+		want    bool
+	}{
+		{"a.jsonl", detect.MatchActivity, 0, false},                     // First cue
+		{"a.jsonl", detect.MatchActivity, 500 * time.Millisecond, true}, // Continues the run
+		{"b.jsonl", detect.MatchActivity, 0, false},                     // Another log
+		{"a.jsonl", detect.MatchActivity, 900 * time.Millisecond, true}, // Window runs from the previous cue
+		{"a.jsonl", detect.MatchComplete, 0, false},                     // Transition
+		{"a.jsonl", detect.MatchComplete, 0, false},                     // Strong cues always pass
+		{"a.jsonl", detect.MatchActivity, 0, false},                     // Transition back
+		{"a.jsonl", detect.MatchActivity, time.Second, false},           // Window passed
+		{"a.jsonl", detect.MatchHolding, 0, false},
+		{"a.jsonl", detect.MatchHolding, 0, false},
+	}
+	for i, step := range steps {
+		now = now.Add(step.after)
+		if got := c.collapse("claude", step.path, step.cueType, now); got != step.want {
+			t.Errorf("step %d: collapse(%s, %s) = %v, want %v", i, step.path, step.cueType, got, step.want)
+		}
+	}
+
+	off := newCueCollapser(0)
+	for i := 0; i < 3; i++ {
+		if off.collapse("claude", "a.jsonl", detect.MatchActivity, now) {
+			t.Fatal("collapse() = true with collapsing off")
+		}
+	}
+}
+
+func TestWatcherCollapsesActivityCues(t *testing.T) {
+	for _, perInstance := range []bool{false, true} {
+		t.Run(map[bool]string{false: "agent", true: "instance"}[perInstance], func(t *testing.T) {
+			w, rec := newTestWatcher(t, t.TempDir(), perInstance)
+			w.cfg.Notify.Type = "stdout"
+			w.cfg.Output.Verbosity = "verbose"
+			w.collapser = newCueCollapser(time.Second)
+			w.state.SetHistorySize(10)
+			clock := newFakeClock()
+			w.SetClock(clock)
+			ctx := context.Background()
+
+			lastCue := func() time.Time {
+				if perInstance {
+					return w.state.GetInstance("session.jsonl").LastCue
+				}
+				return w.state.GetAgent("claude").LastCue
+			}
+			activity := func() int {
+				if perInstance {
+					return w.state.InstanceActivitySinceComplete("session.jsonl")
+				}
+				return w.state.ActivitySinceComplete("claude")
+			}
+
+			// A streaming turn: only its first activity cue notifies, but
+			// every cue counts and refreshes the quiet timer
+			w.processLines(ctx, "claude", "session.jsonl", []string{
+				claudeLine(clock.Now(), ""), claudeLine(clock.Now(), ""), claudeLine(clock.Now(), ""),
+			})
+			clock.Advance(500 * time.Millisecond)
+			w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), ""), claudeLine(clock.Now(), "")})
+			if rec.count() != 1 {
+				t.Errorf("got %d notifications for one run of activity, want 1", rec.count())
+			}
+			if got := activity(); got != 5 {
+				t.Errorf("activity since complete = %d, want 5", got)
+			}
+			if got := lastCue(); !got.Equal(clock.Now()) {
+				t.Errorf("LastCue = %v, want refreshed to %v", got, clock.Now())
+			}
+
+			// Transitions do the full work
+			clock.Advance(100 * time.Millisecond)
+			w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "end_turn"), claudeLine(clock.Now(), "")})
+			if rec.count() != 3 {
+				t.Errorf("got %d notifications after Complete and new activity, want 3", rec.count())
+			}
+			if got := w.state.GetLastCueType("claude"); !perInstance && got != detect.MatchComplete {
+				t.Errorf("LastCueType = %s, want complete", got)
+			}
+
+			// A pause longer than the window starts a new run
+			clock.Advance(2 * time.Second)
+			w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "")})
+			if rec.count() != 4 {
+				t.Errorf("got %d notifications after a pause, want 4", rec.count())
+			}
+
+			var types []string
+			for _, e := range w.state.History("claude", 10) {
+				types = append(types, e.Type)
+			}
+			if want := []string{"activity", "complete", "activity", "activity"}; !slices.Equal(types, want) {
+				t.Errorf("history = %v, want only uncollapsed cues %v", types, want)
+			}
+		})
+	}
+}
+
+func BenchmarkProcessLinesActivity(b *testing.B) {
+	lines := make([]string, 200)
+	for i := range lines {
+		lines[i] = claudeLine(time.Now(), "")
+	}
+
+	for _, window := range []time.Duration{0, time.Second} {
+		name := "off"
+		if window > 0 {
+			name = "collapse"
+		}
+		b.Run(name, func(b *testing.B) {
+			w, rec := newTestWatcher(b, b.TempDir(), false)
+			w.cfg.Notify.Type = "stdout"
+			w.cfg.Output.Verbosity = "verbose"
+			w.cfg.Output.IncludeSnippets = false
+			w.collapser = newCueCollapser(window)
+			w.state.SetHistorySize(10)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w.processLines(ctx, "claude", "session.jsonl", lines)
+				rec.mu.Lock()
+				rec.sent = rec.sent[:0]
+				rec.mu.Unlock()
+			}
+		})
+	}
+}
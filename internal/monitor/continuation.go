@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"firebell/internal/detect"
+)
+
+// continuationWait is how long the last entry of a log is held back for
+// continuation lines before it is matched without them.
+const continuationWait = 1 * time.Second
+
+// joinContinuations joins each line starting with prefix (agents.
+// continuation_prefix, e.g. indentation) onto the entry before it, separated
+// by a newline, so a multi-line entry such as a stack trace is matched as
+// one logical line. An entry keeps the time of its first line.
+//
+// carried is the last entry of the previous read of the same log (nil if
+// none), which continuation lines at the start of lines extend; without it,
+// they belong to an entry already matched and are dropped. The last entry is
+// returned apart from the others, as its continuation lines may come in the
+// next read. An empty prefix returns carried and lines unchanged.
+func joinContinuations(carried *HistoryLine, lines []HistoryLine, prefix string) (entries []HistoryLine, last *HistoryLine) {
+	joined := make([]HistoryLine, 0, len(lines)+1)
+	if carried != nil {
+		joined = append(joined, *carried)
+	}
+	if prefix == "" {
+		return append(joined, lines...), nil
+	}
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line.Text, prefix) {
+			joined = append(joined, line)
+			continue
+		}
+		if len(joined) > 0 {
+			joined[len(joined)-1].Text += "\n" + line.Text
+		}
+	}
+	if len(joined) == 0 {
+		return nil, nil
+	}
+	tail := joined[len(joined)-1]
+	return joined[:len(joined)-1], &tail
+}
+
+// joinEntries joins continuation lines read from path (see
+// joinContinuations). The last entry is held until the next read of path,
+// or until flushEntries matches it after continuationWait.
+func (w *Watcher) joinEntries(agentName, path string, lines []string, prefix string) []string {
+	src := cueSource{agentName, path}
+	var carried *HistoryLine
+	if entry, ok := w.entries[src]; ok {
+		carried = &entry
+		delete(w.entries, src)
+	}
+
+	now := w.clock.Now()
+	read := make([]HistoryLine, len(lines))
+	for i, line := range lines {
+		read[i] = HistoryLine{Text: line, Time: now}
+	}
+	joined, last := joinContinuations(carried, read, prefix)
+	if last != nil {
+		w.entries[src] = *last
+	}
+
+	entries := make([]string, len(joined))
+	for i, entry := range joined {
+		entries[i] = entry.Text
+	}
+	return entries
+}
+
+// flushEntries processes the entries held by joinEntries that have waited
+// continuationWait without further lines.
+func (w *Watcher) flushEntries(ctx context.Context) {
+	now := w.clock.Now()
+	for src, entry := range w.entries {
+		if now.Sub(entry.Time) >= continuationWait {
+			delete(w.entries, src)
+			w.processEntries(ctx, src.agentName, src.path, []string{entry.Text})
+		}
+	}
+}
+
+// matchEntry matches a logical line by its first physical line, so text in
+// a continuation, such as a stack frame mentioning "finished", can't change
+// how the entry is classified. The match carries the whole entry.
+func matchEntry(m detect.Matcher, entry string) (*detect.Match, error) {
+	first, _, multiLine := strings.Cut(entry, "\n")
+	match, err := detect.MatchLine(m, first)
+	if match != nil && multiLine {
+		match.Line = entry
+	}
+	return match, err
+}
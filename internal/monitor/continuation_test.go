@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/detect"
+)
+
+func TestJoinContinuations(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		carried string // Last entry of the previous read ("" = none)
+		lines   []string
+		want    []string // Entries, then the last one
+	}{
+		{
+			name:   "no prefix",
+			prefix: "",
+			lines:  []string{"Error: build failed", "    at main.go:12"},
+			want:   []string{"Error: build failed", "    at main.go:12"},
+		},
+		{
+			name:   "indented stack trace",
+			prefix: "  ",
+			lines:  []string{"Error: build failed", "    at run (main.go:12)", "    at main (main.go:3)", "Retrying"},
+			want:   []string{"Error: build failed\n    at run (main.go:12)\n    at main (main.go:3)", "Retrying"},
+		},
+		{
+			name:   "tab prefix",
+			prefix: "\t",
+			lines:  []string{"panic: nil map", "\tgoroutine 1", "  not a continuation"},
+			want:   []string{"panic: nil map\n\tgoroutine 1", "  not a continuation"},
+		},
+		{
+			name:   "leading continuation of a matched entry dropped",
+			prefix: "  ",
+			lines:  []string{"  at main.go:12", "Retrying"},
+			want:   []string{"Retrying"},
+		},
+		{
+			name:    "leading continuation of the carried entry",
+			prefix:  "  ",
+			carried: "Error: build failed",
+			lines:   []string{"  at main.go:12", "Retrying"},
+			want:    []string{"Error: build failed\n  at main.go:12", "Retrying"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var carried *HistoryLine
+			if tt.carried != "" {
+				carried = &HistoryLine{Text: tt.carried}
+			}
+			lines := make([]HistoryLine, len(tt.lines))
+			for i, line := range tt.lines {
+				lines[i] = HistoryLine{Text: line}
+			}
+
+			entries, last := joinContinuations(carried, lines, tt.prefix)
+			if tt.prefix == "" && last != nil {
+				t.Errorf("last = %+v without a prefix, want nil", last)
+			}
+			if last != nil {
+				entries = append(entries, *last)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("joinContinuations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// An entry keeps the time of its first line
+	start := time.Date(2025, 1, 9, 14, 3, 0, 0, time.UTC)
+	entries, last := joinContinuations(nil, []HistoryLine{
+		{Text: "Error: build failed", Time: start},
+		{Text: "  at main.go:12", Time: start.Add(time.Second)},
+		{Text: "Retrying", Time: start.Add(2 * time.Second)},
+	}, "  ")
+	want := []HistoryLine{{Text: "Error: build failed\n  at main.go:12", Time: start}}
+	if !reflect.DeepEqual(entries, want) || last == nil || !last.Time.Equal(start.Add(2*time.Second)) {
+		t.Errorf("joinContinuations() = %+v, %+v; want %+v and Retrying last", entries, last, want)
+	}
+}
+
+func TestWatcherContinuationPrefix(t *testing.T) {
+	// A stack frame mentioning "finished" reads as a completion on its own
+	lines := []string{"Running tests", "Error: test failed", "    at finished (runner.go:12)"}
+
+	for _, tt := range []struct {
+		name   string
+		prefix string
+		want   detect.MatchType
+		count  int // Logical lines read
+	}{
+		{"lines matched separately", "", detect.MatchComplete, 3},
+		{"entry matched by its first line", "  ", detect.MatchActivity, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Monitor.ProcessTracking = false
+			cfg.Monitor.PerInstance = perInstanceMode(false)
+			if tt.prefix != "" {
+				cfg.Agents.ContinuationPrefix = map[string]string{"custom": tt.prefix}
+			}
+			agent := Agent{Name: "custom", DisplayName: "Custom", LogPath: t.TempDir()}
+			w, err := NewWatcher(cfg, &recordingNotifier{}, []Agent{agent})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+
+			clock := newFakeClock()
+			w.SetClock(clock)
+			w.processLines(context.Background(), "custom", "agent.log", lines)
+			clock.Advance(continuationWait)
+			w.flushEntries(context.Background())
+			state := w.state.GetAgent("custom")
+			if state.LastCueType != tt.want {
+				t.Errorf("LastCueType = %v, want %v", state.LastCueType, tt.want)
+			}
+			if state.LinesRead != tt.count {
+				t.Errorf("LinesRead = %d, want %d", state.LinesRead, tt.count)
+			}
+		})
+	}
+}
+
+func TestWatcherContinuationAcrossReads(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	w.cfg.Notify.Type = "stdout"
+	w.cfg.Output.Verbosity = "verbose"
+	w.cfg.Output.IncludeRawMatch = true
+	w.cfg.Agents.ContinuationPrefix = map[string]string{"claude": "  "}
+	clock := newFakeClock()
+	w.SetClock(clock)
+	ctx := context.Background()
+
+	// The entry is held until its continuation arrives in the next read
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "")})
+	if rec.count() != 0 {
+		t.Fatalf("Sent %v before the entry was complete", rec.titles())
+	}
+	clock.Advance(100 * time.Millisecond)
+	w.processLines(ctx, "claude", "session.jsonl", []string{"  continued"})
+	w.flushEntries(ctx)
+	if rec.count() != 0 {
+		t.Fatalf("Sent %v before continuationWait", rec.titles())
+	}
+
+	clock.Advance(continuationWait)
+	w.flushEntries(ctx)
+	if rec.count() != 1 {
+		t.Fatalf("Sent %v, want one activity for the joined entry", rec.titles())
+	}
+	if raw, _ := rec.sent[0].Metadata["raw_match"].(string); !strings.Contains(raw, "continued") {
+		t.Errorf("raw_match = %q, want the continuation from the second read", raw)
+	}
+	if got := w.state.GetAgent("claude").LinesRead; got != 1 {
+		t.Errorf("LinesRead = %d, want 1 logical line", got)
+	}
+}
@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+
+	"firebell/internal/notify"
+)
+
+// Debugf receives low-level diagnostics such as tailer truncation handling.
+// It discards them by default; the daemon routes them to its debug log.
+var Debugf = func(format string, args ...any) {}
+
+// Errorf receives failures that need attention, such as a critical notifier
+// failing to deliver. It prints them to stderr by default; the daemon routes
+// them to its log at ERROR.
+var Errorf = func(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// logSendError logs notifier's failure to deliver what (e.g. "awaiting
+// notification"), route by route: critical routes at ERROR (see Errorf) and
+// best-effort ones at DEBUG, so a full disk under the event file doesn't
+// read like a revoked Slack token.
+func logSendError(notifier notify.Notifier, what string, err error) {
+	for _, f := range notify.DeliveryFailures(err, notifier.Name()) {
+		if f.Critical {
+			Errorf("Failed to send %s via %s: %v", what, f.Route, f.Err)
+		} else {
+			Debugf("failed to send %s via %s (best effort): %v", what, f.Route, f.Err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"strings"
+	"time"
+)
+
+// DockerScheme prefixes an agent log path that names a Docker container
+// instead of a file: docker://claude-agent follows that container's output.
+const DockerScheme = "docker://"
+
+// DefaultDockerRetry is how long a DockerTailer waits before running
+// `docker logs` again after it exits, e.g. because the container doesn't
+// exist yet (its RetryInterval).
+const DefaultDockerRetry = 5 * time.Second
+
+// DockerContainer returns the container named by a docker:// path, and
+// whether path is one.
+func DockerContainer(path string) (string, bool) {
+	container, ok := strings.CutPrefix(path, DockerScheme)
+	if !ok || container == "" {
+		return "", false
+	}
+	return container, true
+}
+
+// IsDockerPath reports whether path names a Docker container.
+func IsDockerPath(path string) bool {
+	_, ok := DockerContainer(path)
+	return ok
+}
+
+// IsStreamPath reports whether path names a stream read through a command,
+// a journald unit or a Docker container, rather than files. Streams have
+// nothing to watch or scan, so they are polled.
+func IsStreamPath(path string) bool {
+	return IsJournaldPath(path) || IsDockerPath(path)
+}
+
+// DockerTailer follows a container's output by running
+// `docker logs -f --timestamps CONTAINER`, returning each line without its
+// timestamp for the matcher. The container's stdout and stderr are both
+// followed. After Reset, docker is run again once RetryInterval has passed,
+// from the last line read.
+type DockerTailer struct {
+	commandTailer
+	Path      string // docker:// path, used as the instance path
+	Container string // Container being followed
+
+	since time.Time // Timestamp of the last line read, to resume after a restart
+}
+
+// NewDockerTailer creates a tailer for the container named by a docker://
+// path. Only lines logged after the first read are returned, including
+// those of a container that doesn't exist yet when it starts.
+func NewDockerTailer(path string) *DockerTailer {
+	container, _ := DockerContainer(path)
+	t := &DockerTailer{Path: path, Container: container}
+	t.commandTailer = commandTailer{
+		RetryInterval: DefaultDockerRetry,
+		name:          "docker",
+		desc:          "docker logs " + container,
+		args:          t.args,
+		parse:         t.parse,
+		stderr:        true, // The container's stderr, and docker's own errors
+	}
+	return t
+}
+
+// args returns docker's arguments, following from the last line read, or
+// from now on the first run.
+func (t *DockerTailer) args() []string {
+	if t.since.IsZero() {
+		t.since = time.Now()
+	}
+	return []string{"logs", "-f", "--timestamps", "--since", t.since.Format(time.RFC3339Nano), t.Container}
+}
+
+// parse returns the container's line from one line of docker's output.
+// Lines without a timestamp are docker's own messages, such as "No such
+// container", and become notes for the error.
+func (t *DockerTailer) parse(output string) ([]string, string) {
+	ts, line, ok := parseDockerLine(output)
+	if !ok {
+		return nil, strings.TrimSpace(output)
+	}
+	// --since includes the last line read before a restart
+	if !ts.After(t.since) {
+		return nil, ""
+	}
+	t.since = ts
+	if line == "" {
+		return nil, ""
+	}
+	return []string{line}, ""
+}
+
+// parseDockerLine splits a `docker logs --timestamps` line into its
+// timestamp and the line the container wrote.
+func parseDockerLine(text string) (time.Time, string, bool) {
+	stamp, line, _ := strings.Cut(text, " ")
+	ts, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return ts, strings.TrimRight(line, "\r"), true
+}
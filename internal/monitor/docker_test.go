@@ -0,0 +1,191 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+)
+
+// fakeDocker puts a docker script on PATH that records its arguments. Until
+// the returned container file exists it fails like a missing container;
+// then it prints that file's lines, each with the current timestamp as
+// --timestamps does, and waits like -f would. It returns the args and
+// container file paths.
+func fakeDocker(t *testing.T) (argsPath, containerPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsPath = filepath.Join(dir, "args")
+	containerPath = filepath.Join(dir, "container")
+
+	script := `#!/bin/sh
+echo "$@" >> ` + argsPath + `
+if [ ! -f ` + containerPath + ` ]; then
+	echo "Error response from daemon: No such container: agent" >&2
+	exit 1
+fi
+while IFS= read -r line; do
+	echo "$(date -u +%Y-%m-%dT%H:%M:%S.%NZ) $line"
+done < ` + containerPath + `
+exec sleep 30
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsPath, containerPath
+}
+
+// readDocker reads from tailer until want lines have arrived, resetting it
+// on errors as TailerManager does.
+func readDocker(t *testing.T, tailer *DockerTailer, want int) []string {
+	t.Helper()
+	var lines []string
+	deadline := time.Now().Add(5 * time.Second)
+	for len(lines) < want && time.Now().Before(deadline) {
+		got, err := tailer.ReadNewLines()
+		if err != nil {
+			tailer.Reset()
+		}
+		lines = append(lines, got...)
+		time.Sleep(10 * time.Millisecond)
+	}
+	return lines
+}
+
+func TestDockerContainer(t *testing.T) {
+	if container, ok := DockerContainer("docker://claude-agent"); !ok || container != "claude-agent" {
+		t.Errorf("DockerContainer = %q, %v; want claude-agent", container, ok)
+	}
+	for _, path := range []string{"docker://", "~/.claude/projects", "/var/lib/docker"} {
+		if IsDockerPath(path) {
+			t.Errorf("IsDockerPath(%q) = true", path)
+		}
+	}
+	if !IsStreamPath("docker://agent") || !IsStreamPath("journald://claude.service") || IsStreamPath("/tmp/logs") {
+		t.Error("IsStreamPath should cover docker:// and journald:// paths only")
+	}
+}
+
+func TestParseDockerLine(t *testing.T) {
+	ts, line, ok := parseDockerLine(`2025-01-15T10:30:00.123456789Z {"type":"assistant"} done` + "\r")
+	if !ok || line != `{"type":"assistant"} done` || ts.Nanosecond() != 123456789 {
+		t.Errorf("parseDockerLine = %v, %q, %v", ts, line, ok)
+	}
+	if _, _, ok := parseDockerLine("Error response from daemon: No such container: agent"); ok {
+		t.Error("A line without a timestamp should not parse")
+	}
+}
+
+func TestDockerTailerWaitsForContainer(t *testing.T) {
+	argsPath, containerPath := fakeDocker(t)
+
+	tailer := NewDockerTailer("docker://agent")
+	tailer.RetryInterval = 20 * time.Millisecond
+	defer tailer.Close()
+
+	// The container doesn't exist yet: docker's error is reported, not matched
+	deadline := time.Now().Add(5 * time.Second)
+	var err error
+	for err == nil && time.Now().Before(deadline) {
+		var lines []string
+		lines, err = tailer.ReadNewLines()
+		if len(lines) > 0 {
+			t.Fatalf("Lines = %q before the container exists", lines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err == nil || !strings.Contains(err.Error(), "No such container") {
+		t.Fatalf("ReadNewLines error = %v, want the missing container", err)
+	}
+
+	// Reads wait out the retry interval before running docker again
+	tailer.Reset()
+	if lines, err := tailer.ReadNewLines(); lines != nil || err != nil || tailer.cmd != nil {
+		t.Errorf("ReadNewLines = %q, %v right after Reset, want a wait", lines, err)
+	}
+
+	// Once it exists, its output is followed
+	if err := os.WriteFile(containerPath, []byte("{\"type\":\"assistant\"}\n\nsecond\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	lines := readDocker(t, tailer, 2)
+	if strings.Join(lines, "|") != `{"type":"assistant"}|second` {
+		t.Errorf("Lines = %q", lines)
+	}
+
+	args, _ := os.ReadFile(argsPath)
+	runs := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if len(runs) < 2 || !strings.HasPrefix(runs[0], "logs -f --timestamps --since ") || !strings.HasSuffix(runs[0], " agent") {
+		t.Fatalf("docker runs = %q", runs)
+	}
+	// Every run resumes from the start of the first, so nothing logged
+	// while the container was starting up is missed
+	if runs[len(runs)-1] != runs[0] {
+		t.Errorf("docker runs = %q, want each to resume from the first read", runs)
+	}
+}
+
+func TestDockerTailerOversizedLine(t *testing.T) {
+	argsPath, containerPath := fakeDocker(t)
+	if err := os.WriteFile(containerPath, []byte("before\n"+strings.Repeat("x", 1000)+"\nafter\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewDockerTailer("docker://agent")
+	tailer.MaxLineBytes = 200
+	defer tailer.Close()
+
+	// The oversized line is skipped without stopping docker
+	lines := readDocker(t, tailer, 2)
+	if strings.Join(lines, "|") != "before|after" {
+		t.Errorf("Lines = %q, want the lines around the oversized one", lines)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if runs := strings.Split(strings.TrimSpace(string(args)), "\n"); len(runs) != 1 {
+		t.Errorf("docker runs = %q, want one", runs)
+	}
+}
+
+func TestWatcherDocker(t *testing.T) {
+	_, containerPath := fakeDocker(t)
+	clock := newFakeClock()
+	if err := os.WriteFile(containerPath, []byte(claudeLine(clock.Now(), "end_turn")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = config.PerInstanceAuto
+	rec := &recordingNotifier{}
+	agent := Agent{Name: "claude", DisplayName: "Claude Code", LogPath: "docker://agent", SessionFiles: true}
+	w, err := NewWatcher(cfg, rec, []Agent{agent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetClock(clock)
+	if !w.hasStreams() {
+		t.Fatal("Expected the watcher to poll the container")
+	}
+	if w.state.IsPerInstanceAgent("claude") {
+		t.Error("Expected a container agent to be tracked as a whole")
+	}
+
+	ctx := context.Background()
+	deadline := time.Now().Add(5 * time.Second)
+	for w.state.GetAgent("claude").LastCue.IsZero() && time.Now().Before(deadline) {
+		w.pollStreams(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	clock.Advance(time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second)
+	w.checkQuietPeriods(ctx)
+	if got := rec.titles(); len(got) != 1 || got[0] != "Cooling" {
+		t.Errorf("Sent %v, want one Cooling from the container", got)
+	}
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"slices"
+
+	"firebell/internal/detect"
+	"firebell/internal/notify"
+)
+
+// cueHistory is a bounded ring buffer of an agent's most recent cues, sent
+// along with its notifications to webhooks with include_history.
+type cueHistory struct {
+	entries []notify.HistoryEntry
+	next    int // Where the next entry goes once the buffer is full
+}
+
+// add appends e, overwriting the oldest entry once size are kept.
+func (h *cueHistory) add(e notify.HistoryEntry, size int) {
+	if len(h.entries) < size {
+		h.entries = append(h.entries, e)
+		return
+	}
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % size
+}
+
+// last returns up to n of the most recent entries, oldest first.
+func (h *cueHistory) last(n int) []notify.HistoryEntry {
+	ordered := append(slices.Clone(h.entries[h.next:]), h.entries[:h.next]...)
+	return ordered[max(0, len(ordered)-n):]
+}
+
+// SetHistorySize sets how many recent cues are kept per agent (0 = none).
+// Call before recording any.
+func (s *State) SetHistorySize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.historySize = n
+}
+
+// RecordHistory adds a cue, with the reason its line matched, to the
+// agent's recent history.
+func (s *State) RecordHistory(agentName string, cueType detect.MatchType, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[agentName]
+	if !ok || s.historySize <= 0 {
+		return
+	}
+	agent.history.add(notify.HistoryEntry{
+		Timestamp: s.clock.Now(),
+		Type:      cueType.String(),
+		Reason:    reason,
+	}, s.historySize)
+}
+
+// History returns the agent's last n cues, oldest first (nil if none).
+func (s *State) History(agentName string, n int) []notify.HistoryEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	agent, ok := s.agents[agentName]
+	if !ok || n <= 0 || len(agent.history.entries) == 0 {
+		return nil
+	}
+	return agent.history.last(n)
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ProjectKey encodes a working directory the way Claude Code names the
+// per-project directory holding its logs (~/.claude/projects/<key>): every
+// character other than an ASCII letter or digit becomes "-".
+func ProjectKey(dir string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, filepath.Clean(dir))
+}
+
+// instanceLog is a per-instance log considered by associateInstances.
+type instanceLog struct {
+	Path    string
+	Written time.Time // When the log last had a cue
+}
+
+// associateInstances maps instance log paths to the process writing each:
+// the most recently started process whose working directory encodes (see
+// ProjectKey) to the name of the log's directory. A process writes one log
+// at a time, so only the most recently written log of each directory is
+// associated. Logs with no such process, including those of agents that
+// don't keep logs per project, are left out.
+func associateInstances(logs []instanceLog, procs []procInfo) map[string]int {
+	byKey := make(map[string]procInfo)
+	for _, p := range procs {
+		if p.Cwd == "" {
+			continue
+		}
+		key := ProjectKey(p.Cwd)
+		if p.Create > byKey[key].Create {
+			byKey[key] = p
+		}
+	}
+
+	latest := make(map[string]instanceLog) // By directory name
+	for _, log := range logs {
+		key := filepath.Base(filepath.Dir(log.Path))
+		if cur, ok := latest[key]; !ok || log.Written.After(cur.Written) {
+			latest[key] = log
+		}
+	}
+
+	pids := make(map[string]int)
+	for key, log := range latest {
+		if p, ok := byKey[key]; ok {
+			pids[log.Path] = p.PID
+		}
+	}
+	return pids
+}
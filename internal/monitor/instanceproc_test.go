@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProjectKey(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/root/module", "-root-module"},
+		{"/home/me/src/my.app", "-home-me-src-my-app"},
+		{"/home/me/src/my_app/", "-home-me-src-my-app"},
+		{`C:\Users\me\app`, "C--Users-me-app"},
+	}
+	for _, tt := range tests {
+		if got := ProjectKey(tt.dir); got != tt.want {
+			t.Errorf("ProjectKey(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestAssociateInstances(t *testing.T) {
+	projects := "/home/me/.claude/projects"
+	api := filepath.Join(projects, "-work-api", "a1.jsonl")
+	apiOther := filepath.Join(projects, "-work-api", "a2.jsonl")
+	web := filepath.Join(projects, "-work-web", "w1.jsonl")
+	docs := filepath.Join(projects, "-work-docs", "d1.jsonl")
+	codex := "/home/me/.codex/sessions/2026/10/17/rollout.jsonl"
+
+	procs := []procInfo{
+		{PID: 10, Cwd: "/work/api", Create: 100},
+		{PID: 11, Cwd: "/work/api", Create: 300}, // Newer process in the same project
+		{PID: 20, Cwd: "/work/web", Create: 200},
+		{PID: 30, Cwd: "", Create: 400}, // Working directory unreadable
+		{PID: 40, Cwd: "/work/other", Create: 500},
+	}
+
+	now := time.Now()
+	logs := []instanceLog{
+		{Path: api, Written: now.Add(-time.Minute)},
+		{Path: apiOther, Written: now}, // The project's newest log
+		{Path: web, Written: now.Add(-time.Hour)},
+		{Path: docs, Written: now},
+		{Path: codex, Written: now},
+	}
+	got := associateInstances(logs, procs)
+	want := map[string]int{apiOther: 11, web: 20}
+	if !maps.Equal(got, want) {
+		t.Errorf("associateInstances() = %v, want %v", got, want)
+	}
+
+	if got := associateInstances(logs[:1], nil); len(got) != 0 {
+		t.Errorf("associateInstances() with no processes = %v, want none", got)
+	}
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// JournaldScheme prefixes an agent log path that names a systemd unit
+// instead of a file: journald://claude.service follows that unit's journal.
+const JournaldScheme = "journald://"
+
+// JournaldUnit returns the unit named by a journald:// path, and whether
+// path is one.
+func JournaldUnit(path string) (string, bool) {
+	unit, ok := strings.CutPrefix(path, JournaldScheme)
+	if !ok || unit == "" {
+		return "", false
+	}
+	return unit, true
+}
+
+// IsJournaldPath reports whether path names a journald unit.
+func IsJournaldPath(path string) bool {
+	_, ok := JournaldUnit(path)
+	return ok
+}
+
+// lineReader is a source of new log lines: a Tailer for files, a
+// JournaldTailer for journald units, a DockerTailer for containers.
+type lineReader interface {
+	ReadNewLines() ([]string, error)
+	Reset()
+	Close() error
+}
+
+// JournaldTailer follows a systemd unit's journal by running
+// `journalctl -u UNIT -f -o json` and returning each entry's MESSAGE, which
+// is the line the agent logged, for the matcher. After Reset, journalctl is
+// run again on the next read, resuming after the last entry read.
+type JournaldTailer struct {
+	commandTailer
+	Path string // journald:// path, used as the instance path
+	Unit string // systemd unit being followed
+
+	cursor string // Last entry read, to resume after a restart
+}
+
+// NewJournaldTailer creates a tailer for the unit named by a journald:// path.
+// Only entries written after the first read starts journalctl are returned.
+func NewJournaldTailer(path string) *JournaldTailer {
+	unit, _ := JournaldUnit(path)
+	t := &JournaldTailer{Path: path, Unit: unit}
+	t.commandTailer = commandTailer{
+		name:  "journalctl",
+		desc:  "journalctl -u " + unit,
+		args:  t.args,
+		parse: t.parse,
+	}
+	return t
+}
+
+// args returns journalctl's arguments, resuming after the last entry read if
+// there was one.
+func (t *JournaldTailer) args() []string {
+	args := []string{"-u", t.Unit, "-f", "-o", "json", "--no-pager"}
+	if t.cursor != "" {
+		return append(args, "--after-cursor", t.cursor)
+	}
+	return append(args, "-n", "0")
+}
+
+// parse returns the lines of one journal entry's message.
+func (t *JournaldTailer) parse(entry string) ([]string, string) {
+	message, cursor, ok := parseJournalEntry([]byte(entry))
+	if !ok {
+		return nil, ""
+	}
+	t.cursor = cursor
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(message, "\n"), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, ""
+}
+
+// parseJournalEntry returns the MESSAGE and __CURSOR of one journalctl JSON
+// entry. journalctl writes a MESSAGE that isn't valid UTF-8 as an array of
+// bytes.
+func parseJournalEntry(data []byte) (message, cursor string, ok bool) {
+	var entry struct {
+		Message json.RawMessage `json:"MESSAGE"`
+		Cursor  string          `json:"__CURSOR"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		Debugf("journald: skipping malformed entry: %v", err)
+		return "", "", false
+	}
+
+	var text string
+	if err := json.Unmarshal(entry.Message, &text); err != nil {
+		var codes []int
+		if json.Unmarshal(entry.Message, &codes) != nil {
+			return "", entry.Cursor, false
+		}
+		raw := make([]byte, len(codes))
+		for i, c := range codes {
+			raw[i] = byte(c)
+		}
+		text = string(raw)
+	}
+	return text, entry.Cursor, true
+}
@@ -0,0 +1,186 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+)
+
+// fakeJournalctl puts a journalctl script on PATH that records its arguments
+// and prints entries as journal JSON. With follow it then waits like -f
+// would; otherwise it exits. It returns the file holding one argument line
+// per run.
+func fakeJournalctl(t *testing.T, entries []string, follow bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	argsPath := filepath.Join(dir, "args")
+	entriesPath := filepath.Join(dir, "entries")
+	if err := os.WriteFile(entriesPath, []byte(strings.Join(entries, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	script := "#!/bin/sh\necho \"$@\" >> " + argsPath + "\ncat " + entriesPath + "\n"
+	if follow {
+		script += "exec sleep 30\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "journalctl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsPath
+}
+
+// journalEntry returns a journalctl JSON entry logging message.
+func journalEntry(cursor string, message any) string {
+	data, _ := json.Marshal(map[string]any{"__CURSOR": cursor, "_SYSTEMD_UNIT": "claude.service", "MESSAGE": message})
+	return string(data)
+}
+
+// readJournald reads from tailer until want lines have arrived.
+func readJournald(t *testing.T, tailer *JournaldTailer, want int) []string {
+	t.Helper()
+	var lines []string
+	deadline := time.Now().Add(5 * time.Second)
+	for len(lines) < want && time.Now().Before(deadline) {
+		got, err := tailer.ReadNewLines()
+		if err != nil && len(lines) < want {
+			t.Fatalf("ReadNewLines failed after %q: %v", lines, err)
+		}
+		lines = append(lines, got...)
+		time.Sleep(10 * time.Millisecond)
+	}
+	return lines
+}
+
+func TestJournaldUnit(t *testing.T) {
+	if unit, ok := JournaldUnit("journald://claude.service"); !ok || unit != "claude.service" {
+		t.Errorf("JournaldUnit = %q, %v; want claude.service", unit, ok)
+	}
+	for _, path := range []string{"journald://", "~/.claude/projects", "/var/log/journald"} {
+		if IsJournaldPath(path) {
+			t.Errorf("IsJournaldPath(%q) = true", path)
+		}
+	}
+}
+
+func TestJournaldTailer(t *testing.T) {
+	argsPath := fakeJournalctl(t, []string{
+		journalEntry("c1", `{"type":"assistant"}`),
+		"not json",
+		journalEntry("c2", []int{'h', 'i', 0xff}), // Not UTF-8: journalctl writes bytes
+		journalEntry("c3", "first\nsecond\n"),
+	}, true)
+
+	tailer := NewJournaldTailer("journald://claude.service")
+	defer tailer.Close()
+
+	lines := readJournald(t, tailer, 4)
+	want := []string{`{"type":"assistant"}`, "hi\xff", "first", "second"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("Lines = %q, want %q", lines, want)
+	}
+
+	args, _ := os.ReadFile(argsPath)
+	if got := strings.TrimSpace(string(args)); got != "-u claude.service -f -o json --no-pager -n 0" {
+		t.Errorf("journalctl args = %q", got)
+	}
+}
+
+func TestJournaldTailerRestart(t *testing.T) {
+	argsPath := fakeJournalctl(t, []string{journalEntry("c1", "one"), journalEntry("c2", "two")}, false)
+
+	tailer := NewJournaldTailer("journald://claude.service")
+	defer tailer.Close()
+	if lines := readJournald(t, tailer, 2); len(lines) != 2 {
+		t.Fatalf("Lines = %q, want 2", lines)
+	}
+
+	// Once journalctl exits, reads report it until reset
+	deadline := time.Now().Add(5 * time.Second)
+	var err error
+	for err == nil && time.Now().Before(deadline) {
+		_, err = tailer.ReadNewLines()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err == nil || !strings.Contains(err.Error(), "claude.service") {
+		t.Fatalf("ReadNewLines error = %v, want journalctl exit", err)
+	}
+
+	// The restart resumes after the last entry read
+	tailer.Reset()
+	readJournald(t, tailer, 2)
+	args, _ := os.ReadFile(argsPath)
+	runs := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if len(runs) != 2 || !strings.HasSuffix(runs[1], "--after-cursor c2") {
+		t.Errorf("journalctl runs = %q, want a restart after c2", runs)
+	}
+}
+
+func TestJournaldTailerOversizedEntry(t *testing.T) {
+	argsPath := fakeJournalctl(t, []string{
+		journalEntry("c1", "before"),
+		journalEntry("c2", strings.Repeat("x", 1000)),
+		journalEntry("c3", "after"),
+	}, true)
+
+	tailer := NewJournaldTailer("journald://claude.service")
+	tailer.MaxLineBytes = 200
+	defer tailer.Close()
+
+	// The oversized entry is skipped without stopping journalctl
+	lines := readJournald(t, tailer, 2)
+	if strings.Join(lines, "|") != "before|after" {
+		t.Errorf("Lines = %q, want the entries around the oversized one", lines)
+	}
+	if _, err := tailer.ReadNewLines(); err != nil {
+		t.Errorf("ReadNewLines error = %v, want journalctl still following", err)
+	}
+	args, _ := os.ReadFile(argsPath)
+	if runs := strings.Split(strings.TrimSpace(string(args)), "\n"); len(runs) != 1 {
+		t.Errorf("journalctl runs = %q, want one", runs)
+	}
+}
+
+func TestWatcherJournald(t *testing.T) {
+	clock := newFakeClock()
+	fakeJournalctl(t, []string{journalEntry("c1", claudeLine(clock.Now(), "end_turn"))}, true)
+
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = config.PerInstanceAuto
+	rec := &recordingNotifier{}
+	agent := Agent{Name: "claude", DisplayName: "Claude Code", LogPath: "journald://claude.service", SessionFiles: true}
+	w, err := NewWatcher(cfg, rec, []Agent{agent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetClock(clock)
+	if !w.hasStreams() {
+		t.Fatal("Expected the watcher to poll journald")
+	}
+
+	// The unit is one stream, not per-session files
+	if w.state.IsPerInstanceAgent("claude") {
+		t.Error("Expected a journald agent to be tracked as a whole")
+	}
+
+	ctx := context.Background()
+	deadline := time.Now().Add(5 * time.Second)
+	for w.state.GetAgent("claude").LastCue.IsZero() && time.Now().Before(deadline) {
+		w.pollStreams(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	clock.Advance(time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second)
+	w.checkQuietPeriods(ctx)
+	if got := rec.titles(); len(got) != 1 || got[0] != "Cooling" {
+		t.Errorf("Sent %v, want one Cooling from the journal", got)
+	}
+}
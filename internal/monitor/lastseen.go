@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LastSeenFile is the name of the last-seen file within the config directory.
+const LastSeenFile = "last_seen.json"
+
+// lastSeenInterval is the minimum time between writes of the last-seen file.
+const lastSeenInterval = 10 * time.Second
+
+// LastSeen maps agent names to the last time firebell detected activity.
+type LastSeen map[string]time.Time
+
+// LoadLastSeen reads a last-seen file. A missing file yields an empty map.
+func LoadLastSeen(path string) (LastSeen, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return LastSeen{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := LastSeen{}
+	if err := json.Unmarshal(data, &seen); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return seen, nil
+}
+
+// SaveLastSeen writes a last-seen file atomically.
+func SaveLastSeen(path string, seen LastSeen) error {
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lastSeenWriter records cue times and persists them at most once per interval.
+type lastSeenWriter struct {
+	path     string
+	interval time.Duration
+
+	mu        sync.Mutex
+	seen      LastSeen
+	dirty     bool
+	lastWrite time.Time
+}
+
+// newLastSeenWriter creates a writer seeded from the existing file, so agents
+// not seen in this run keep their previous times.
+func newLastSeenWriter(path string) *lastSeenWriter {
+	seen, err := LoadLastSeen(path)
+	if err != nil {
+		seen = LastSeen{}
+	}
+	return &lastSeenWriter{
+		path:     path,
+		interval: lastSeenInterval,
+		seen:     seen,
+	}
+}
+
+// Record notes activity for agent and writes the file if the interval has passed.
+func (l *lastSeenWriter) Record(agent string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seen[agent] = at
+	l.dirty = true
+	if time.Since(l.lastWrite) >= l.interval {
+		l.writeLocked()
+	}
+}
+
+// Flush writes any unsaved times.
+func (l *lastSeenWriter) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.dirty {
+		l.writeLocked()
+	}
+}
+
+// writeLocked saves the file. Must be called with l.mu held.
+func (l *lastSeenWriter) writeLocked() {
+	if err := SaveLastSeen(l.path, l.seen); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", l.path, err)
+		return
+	}
+	l.dirty = false
+	l.lastWrite = time.Now()
+}
@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastSeenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", LastSeenFile)
+	at := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	if err := SaveLastSeen(path, LastSeen{"claude": at}); err != nil {
+		t.Fatal(err)
+	}
+	seen, err := LoadLastSeen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !seen["claude"].Equal(at) {
+		t.Errorf("claude = %v, want %v", seen["claude"], at)
+	}
+}
+
+func TestLoadLastSeenMissing(t *testing.T) {
+	seen, err := LoadLastSeen(filepath.Join(t.TempDir(), LastSeenFile))
+	if err != nil {
+		t.Fatalf("Expected no error for missing file, got %v", err)
+	}
+	if len(seen) != 0 {
+		t.Errorf("Expected empty map, got %v", seen)
+	}
+}
+
+func TestLoadLastSeenCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LastSeenFile)
+	os.WriteFile(path, []byte("{not json"), 0600)
+	if _, err := LoadLastSeen(path); err == nil {
+		t.Error("Expected error for corrupt file")
+	}
+}
+
+func TestLastSeenWriterThrottle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LastSeenFile)
+	SaveLastSeen(path, LastSeen{"codex": time.Unix(100, 0)})
+
+	l := newLastSeenWriter(path)
+	first := time.Unix(200, 0)
+	l.Record("claude", first)
+
+	// Written immediately, keeping agents from the previous run
+	seen, _ := LoadLastSeen(path)
+	if !seen["claude"].Equal(first) || !seen["codex"].Equal(time.Unix(100, 0)) {
+		t.Fatalf("After first record got %v", seen)
+	}
+
+	// A second cue within the interval is held until Flush
+	second := time.Unix(300, 0)
+	l.Record("claude", second)
+	seen, _ = LoadLastSeen(path)
+	if !seen["claude"].Equal(first) {
+		t.Errorf("Expected throttled write to keep %v, got %v", first, seen["claude"])
+	}
+
+	l.Flush()
+	seen, _ = LoadLastSeen(path)
+	if !seen["claude"].Equal(second) {
+		t.Errorf("After Flush claude = %v, want %v", seen["claude"], second)
+	}
+}
+
+func TestWatcherWritesLastSeen(t *testing.T) {
+	w, _ := newTestWatcher(t, t.TempDir(), false)
+	path := filepath.Join(t.TempDir(), LastSeenFile)
+	w.SetLastSeenPath(path)
+
+	before := time.Now()
+	w.processLines(context.Background(), "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"ls"}`)})
+
+	seen, err := LoadLastSeen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen["claude"].Before(before) {
+		t.Errorf("Expected claude last seen after %v, got %v", before, seen["claude"])
+	}
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/notify"
+)
+
+// pathWatchAgent is the agent name used for ad-hoc path watching.
+const pathWatchAgent = "watch"
+
+// PathWatcher monitors a single arbitrary log path with the fallback matcher.
+// It needs no registry entry and runs in the foreground without a daemon.
+// Its lines are classified and notified by a Watcher for that one agent, so
+// monitor and agents settings apply as they do to the daemon.
+type PathWatcher struct {
+	w      *Watcher
+	reader io.Reader // Stream of log lines (see NewReaderWatcher)
+	agent  string    // Agent the lines are matched as (pathWatchAgent = fallback matcher)
+	name   string
+}
+
+// NewPathWatcher creates a PathWatcher for path, displayed as name.
+// If name is empty, the file or directory name is used.
+func NewPathWatcher(cfg *config.Config, notifier notify.Notifier, path, name string) (*PathWatcher, error) {
+	basePath := config.ExpandPath(path)
+	if err := CheckAllowedPath(basePath, cfg.Advanced.AllowedRoots); err != nil {
+		return nil, fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+	if _, err := os.Stat(basePath); err != nil {
+		return nil, fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = filepath.Base(basePath)
+	}
+	return newPathWatcher(cfg, notifier, Agent{Name: pathWatchAgent, DisplayName: name, LogPath: basePath}), nil
+}
+
+// newPathWatcher creates a PathWatcher reading agent's lines, tracked for
+// the agent as a whole.
+func newPathWatcher(cfg *config.Config, notifier notify.Notifier, agent Agent) *PathWatcher {
+	w := newWatcher(cfg, notifier, false)
+	w.addAgent(agent)
+	w.state.SetAgentPerInstance(agent.Name, false)
+	return &PathWatcher{w: w, agent: agent.Name, name: agent.DisplayName}
+}
+
+// SetSnoozeFile makes the watcher honor snoozes (see Watcher.SetSnoozeFile).
+func (p *PathWatcher) SetSnoozeFile(path string) {
+	p.w.SetSnoozeFile(path)
+}
+
+// Run polls the path for new lines until ctx is cancelled. A reader watcher
+// runs until its stream ends instead (see runReader).
+func (p *PathWatcher) Run(ctx context.Context) error {
+	if p.reader != nil {
+		return p.runReader(ctx)
+	}
+
+	manager := p.w.managers[p.agent]
+	if p.w.refreshFiles() == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no log files found under %s yet\n", manager.BasePath)
+	}
+
+	ticker := time.NewTicker(p.w.cfg.PollInterval())
+	defer ticker.Stop()
+
+	quietTicker := time.NewTicker(1 * time.Second)
+	defer quietTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+			p.w.refreshFiles()
+			for path, lines := range manager.ReadAllNew() {
+				p.w.processLines(ctx, p.agent, path, lines)
+			}
+
+		case <-quietTicker.C:
+			p.w.flushEntries(ctx)
+			p.w.checkQuietPeriods(ctx)
+		}
+	}
+}
+
+// Close releases the watched files.
+func (p *PathWatcher) Close() {
+	p.w.Close()
+}
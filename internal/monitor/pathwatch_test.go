@@ -0,0 +1,153 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/detect"
+)
+
+func newTestPathWatcher(t *testing.T, verbosity string) (*PathWatcher, *recordingNotifier) {
+	t.Helper()
+
+	logFile := filepath.Join(t.TempDir(), "myagent.log")
+	if err := os.WriteFile(logFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	cfg.Output.Verbosity = verbosity
+	cfg.Monitor.QuietSeconds = 0
+
+	rec := &recordingNotifier{}
+	p, err := NewPathWatcher(cfg, rec, logFile, "My Agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.Close)
+	return p, rec
+}
+
+// process classifies lines read from the watched path.
+func (p *PathWatcher) process(lines ...string) {
+	p.w.processLines(context.Background(), p.agent, p.w.managers[p.agent].BasePath, lines)
+}
+
+func TestNewPathWatcherMissingPath(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if _, err := NewPathWatcher(cfg, &recordingNotifier{}, filepath.Join(t.TempDir(), "nope.log"), ""); err == nil {
+		t.Error("Expected error for missing path")
+	}
+}
+
+func TestNewPathWatcherDefaultName(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "agent.log")
+	if err := os.WriteFile(logFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPathWatcher(config.DefaultConfig(), &recordingNotifier{}, logFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if p.name != "agent.log" {
+		t.Errorf("name = %q, want %q", p.name, "agent.log")
+	}
+}
+
+func TestPathWatcherProcessLines(t *testing.T) {
+	p, rec := newTestPathWatcher(t, "normal")
+
+	p.process(
+		"thinking about the problem",
+		"",
+		"unrelated noise",
+		"task complete",
+	)
+	if rec.count() != 0 {
+		t.Errorf("Expected no immediate notifications in normal mode, got %v", rec.titles())
+	}
+	if got := p.w.state.GetLastCueType(pathWatchAgent); got != detect.MatchComplete {
+		t.Errorf("LastCueType = %v, want MatchComplete", got)
+	}
+
+	p.w.checkQuietPeriods(context.Background())
+	if rec.count() != 1 {
+		t.Fatalf("Sent %v, want a quiet notification", rec.titles())
+	}
+	if n := rec.sent[0]; n.Title != "Cooling" || n.Agent != "My Agent" {
+		t.Errorf("Got %q for %q, want Cooling for My Agent", n.Title, n.Agent)
+	}
+	p.w.checkQuietPeriods(context.Background())
+	if rec.count() != 1 {
+		t.Error("Quiet notification should only be sent once")
+	}
+}
+
+func TestPathWatcherProcessLinesVerbose(t *testing.T) {
+	p, rec := newTestPathWatcher(t, "verbose")
+
+	p.process("thinking about the problem", "task complete")
+	if rec.count() != 2 {
+		t.Fatalf("Expected 2 activity notifications in verbose mode, got %v", rec.titles())
+	}
+	if rec.sent[0].Agent != "My Agent" {
+		t.Errorf("Agent = %q, want My Agent", rec.sent[0].Agent)
+	}
+}
+
+func TestPathWatcherSuppressReasons(t *testing.T) {
+	p, rec := newTestPathWatcher(t, "verbose")
+	p.process("thinking about the problem", "task complete")
+	first := slices.Clone(rec.sent)
+
+	p.w.cfg.Output.SuppressReasons = []string{first[0].Message}
+	p.process("thinking about the problem", "task complete")
+	if rec.count() != 3 || rec.sent[2].Message != first[1].Message {
+		t.Errorf("Sent %v with %q suppressed, want only %q more", rec.titles(), first[0].Message, first[1].Message)
+	}
+}
+
+func TestPathWatcherHolding(t *testing.T) {
+	p, rec := newTestPathWatcher(t, "normal")
+
+	p.process("running tests", "Allow edit to main.go? (y/n)")
+
+	p.w.checkQuietPeriods(context.Background())
+	if titles := rec.titles(); len(titles) != 1 || titles[0] != "Holding" {
+		t.Errorf("Sent %v, want a Holding notification", titles)
+	}
+}
+
+func TestPathWatcherSharesWatcherSettings(t *testing.T) {
+	p, rec := newTestPathWatcher(t, "normal")
+
+	// agents.min_complete_lines: one line of activity is too little for
+	// "task complete" to count, so the quiet period infers Awaiting
+	p.w.cfg.Agents.MinCompleteLines = 2
+	p.process("thinking about the problem", "task complete")
+	p.w.checkQuietPeriods(context.Background())
+	if titles := rec.titles(); len(titles) != 1 || titles[0] != "Awaiting" {
+		t.Errorf("Sent %v, want Awaiting", titles)
+	}
+
+	// Snoozes hold back alerts
+	snooze := filepath.Join(t.TempDir(), SnoozeFile)
+	if err := WriteSnooze(snooze, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	p.SetSnoozeFile(snooze)
+	p.process("thinking about the problem")
+	p.w.checkQuietPeriods(context.Background())
+	if rec.count() != 1 {
+		t.Errorf("Sent %v while snoozed, want nothing more", rec.titles())
+	}
+}
@@ -5,6 +5,7 @@ package monitor
 import (
 	"fmt"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -33,6 +34,9 @@ type ProcessMonitor struct {
 	cacheValid     bool          // Whether cached PID is still valid
 	lastDetect     time.Time     // Last time we scanned for processes
 	detectCooldown time.Duration // Minimum time between process scans
+	fixed          bool          // PID set explicitly; never auto-detect
+	minLifetime    time.Duration // Skip processes younger than this when detecting
+	clock          Clock         // Time source for detect cooldown and idle tracking
 }
 
 // NewProcessMonitor creates a new process monitor for the given candidate process names.
@@ -40,12 +44,30 @@ func NewProcessMonitor(candidates []string) *ProcessMonitor {
 	return &ProcessMonitor{
 		candidates:     candidates,
 		detectCooldown: 10 * time.Second,
+		clock:          realClock{},
 	}
 }
 
+// SetClock replaces the time source (for tests).
+func (pm *ProcessMonitor) SetClock(c Clock) {
+	pm.clock = c
+}
+
+// SetMinLifetime makes detection skip processes that have run less than d,
+// such as short-lived helpers an agent forks, so the tracked PID doesn't
+// flip to each one (0 = track any).
+func (pm *ProcessMonitor) SetMinLifetime(d time.Duration) {
+	pm.minLifetime = d
+}
+
 // GetPID returns the monitored process ID, auto-detecting if needed.
 // Uses caching to avoid repeated process scans.
 func (pm *ProcessMonitor) GetPID() int {
+	// An explicit PID is never replaced, even after the process exits
+	if pm.fixed {
+		return pm.pid
+	}
+
 	// If we have a cached PID and it's still alive, return it
 	if pm.pid > 0 && pm.cacheValid {
 		if pm.IsAlive() {
@@ -56,22 +78,24 @@ func (pm *ProcessMonitor) GetPID() int {
 	}
 
 	// Respect cooldown to avoid hammering process list
-	if time.Since(pm.lastDetect) < pm.detectCooldown {
+	if pm.clock.Now().Sub(pm.lastDetect) < pm.detectCooldown {
 		return pm.pid
 	}
 
 	// Auto-detect PID
 	pm.pid = pm.detectPID()
-	pm.lastDetect = time.Now()
+	pm.lastDetect = pm.clock.Now()
 	pm.cacheValid = pm.pid > 0
 
 	return pm.pid
 }
 
 // SetPID manually sets the PID to monitor (overrides auto-detection).
+// A positive PID disables detection; zero re-enables it.
 func (pm *ProcessMonitor) SetPID(pid int) {
 	pm.pid = pid
 	pm.cacheValid = pid > 0
+	pm.fixed = pid > 0
 }
 
 // IsAlive checks if the monitored process is still running.
@@ -147,9 +171,9 @@ func (pm *ProcessMonitor) CheckIdle(idleThreshold float64, idleDuration time.Dur
 
 	if pm.lastCPU < idleThreshold {
 		if pm.idleSince.IsZero() {
-			pm.idleSince = time.Now()
+			pm.idleSince = pm.clock.Now()
 		}
-		if !pm.idleNotified && time.Since(pm.idleSince) >= idleDuration {
+		if !pm.idleNotified && rewind(&pm.idleSince, pm.clock.Now()) >= idleDuration {
 			pm.idleNotified = true
 			return true
 		}
@@ -161,6 +185,12 @@ func (pm *ProcessMonitor) CheckIdle(idleThreshold float64, idleDuration time.Dur
 	return false
 }
 
+// IdleSince returns when the CPU went below the idle threshold (zero if it
+// isn't idle).
+func (pm *ProcessMonitor) IdleSince() time.Time {
+	return pm.idleSince
+}
+
 // ResetIdleState resets the idle notification state.
 func (pm *ProcessMonitor) ResetIdleState() {
 	pm.idleSince = time.Time{}
@@ -197,6 +227,20 @@ func HumanBytes(n int64) string {
 	return fmt.Sprintf("%.1f%ciB", value, "KMGTPE"[exp])
 }
 
+// ProcessStartTime returns when a process was created.
+// Falls back to the current time if the creation time can't be read.
+func ProcessStartTime(pid int) time.Time {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return time.Now()
+	}
+	create, err := p.CreateTime()
+	if err != nil || create <= 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(create)
+}
+
 // WatchPID creates a channel that closes when the specified PID exits.
 func WatchPID(pid int) <-chan struct{} {
 	done := make(chan struct{})
@@ -236,24 +280,69 @@ func GetProcessCandidates(agents []Agent) []string {
 	return candidates
 }
 
+// AddCandidates adds process names to search for, skipping duplicates.
+func (pm *ProcessMonitor) AddCandidates(names []string) {
+	for _, name := range names {
+		if !slices.Contains(pm.candidates, name) {
+			pm.candidates = append(pm.candidates, name)
+		}
+	}
+}
+
 // detectPID scans the process list for matching candidate process names.
 // Returns the most recently created matching process.
 func (pm *ProcessMonitor) detectPID() int {
-	if len(pm.candidates) == 0 {
-		return 0
+	var latest procInfo
+	for _, p := range pm.scan() {
+		if p.Create > latest.Create {
+			latest = p
+		}
 	}
+	return latest.PID
+}
 
-	procs, err := process.Processes()
-	if err != nil {
-		return 0
+// procInfo is a running process whose command line names a candidate.
+type procInfo struct {
+	PID    int
+	Cwd    string // Working directory ("" if it can't be read)
+	Create int64  // Creation time in ms since the epoch
+}
+
+// scan lists the running candidate processes that have lived at least the
+// minimum lifetime.
+func (pm *ProcessMonitor) scan() []procInfo {
+	// Creation times come from the OS, so compare against the real time
+	return longLived(scanProcesses(pm.candidates), time.Now(), pm.minLifetime)
+}
+
+// longLived returns the processes in procs created at least minAge before now.
+func longLived(procs []procInfo, now time.Time, minAge time.Duration) []procInfo {
+	if minAge <= 0 {
+		return procs
 	}
+	cutoff := now.Add(-minAge).UnixMilli()
+	var kept []procInfo
+	for _, p := range procs {
+		if p.Create <= cutoff {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
 
-	type found struct {
-		pid   int32
-		create int64
+// scanProcesses lists the running processes whose command line contains
+// one of the candidate names.
+func scanProcesses(candidates []string) []procInfo {
+	if len(candidates) == 0 {
+		return nil
 	}
-	var latest found
 
+	procs, err := process.Processes()
+	if err != nil {
+		return nil
+	}
+
+	var found []procInfo
 	for _, p := range procs {
 		// Get command line to check for matches
 		cmdline, err := p.Cmdline()
@@ -263,7 +352,7 @@ func (pm *ProcessMonitor) detectPID() int {
 
 		// Check if this process matches any of our candidates
 		matched := false
-		for _, name := range pm.candidates {
+		for _, name := range candidates {
 			if strings.Contains(cmdline, name) {
 				matched = true
 				break
@@ -278,13 +367,10 @@ func (pm *ProcessMonitor) detectPID() int {
 		if err != nil {
 			continue
 		}
-
-		if create > latest.create {
-			latest = found{pid: p.Pid, create: create}
-		}
+		cwd, _ := p.Cwd()
+		found = append(found, procInfo{PID: int(p.Pid), Cwd: cwd, Create: create})
 	}
-
-	return int(latest.pid)
+	return found
 }
 
 // ReadProcSample reads process stats using gopsutil (cross-platform).
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"os/exec"
 	"testing"
 	"time"
 )
@@ -38,8 +39,57 @@ func TestProcessMonitor(t *testing.T) {
 		}
 	})
 
+	t.Run("explicit PID skips detection", func(t *testing.T) {
+		pm := NewProcessMonitor([]string{"claude", "codex"})
+		pm.SetPID(1 << 30) // Not a running process
+
+		if got := pm.GetPID(); got != 1<<30 {
+			t.Errorf("GetPID() = %d, want explicit PID", got)
+		}
+		if !pm.lastDetect.IsZero() {
+			t.Error("detection should not run when a PID is provided")
+		}
+	})
+
+	t.Run("set PID to zero re-enables detection", func(t *testing.T) {
+		pm := NewProcessMonitor(nil)
+		pm.SetPID(1234)
+		pm.SetPID(0)
+		pm.GetPID()
+
+		if pm.lastDetect.IsZero() {
+			t.Error("detection should run after SetPID(0)")
+		}
+	})
+
+	t.Run("detection respects cooldown", func(t *testing.T) {
+		pm := NewProcessMonitor(nil)
+		clock := newFakeClock()
+		pm.SetClock(clock)
+
+		pm.GetPID()
+		first := pm.lastDetect
+		if !first.Equal(clock.Now()) {
+			t.Fatalf("lastDetect = %v, want %v", first, clock.Now())
+		}
+
+		clock.Advance(5 * time.Second)
+		pm.GetPID()
+		if !pm.lastDetect.Equal(first) {
+			t.Error("detection should not rerun within the cooldown")
+		}
+
+		clock.Advance(5 * time.Second)
+		pm.GetPID()
+		if !pm.lastDetect.Equal(clock.Now()) {
+			t.Error("detection should rerun after the cooldown")
+		}
+	})
+
 	t.Run("idle detection", func(t *testing.T) {
 		pm := NewProcessMonitor(nil)
+		clock := newFakeClock()
+		pm.SetClock(clock)
 		pm.lastCPU = 0.5 // Low CPU
 
 		// First check should start idle timer
@@ -50,8 +100,13 @@ func TestProcessMonitor(t *testing.T) {
 			t.Error("idleSince should be set")
 		}
 
-		// Wait for idle duration
-		time.Sleep(150 * time.Millisecond)
+		// Not yet idle long enough
+		clock.Advance(99 * time.Millisecond)
+		if pm.CheckIdle(1.0, 100*time.Millisecond) {
+			t.Error("should not notify before idle duration")
+		}
+
+		clock.Advance(time.Millisecond)
 
 		// Second check should trigger notification
 		if !pm.CheckIdle(1.0, 100*time.Millisecond) {
@@ -64,6 +119,21 @@ func TestProcessMonitor(t *testing.T) {
 		}
 	})
 
+	t.Run("idle detection after clock set back", func(t *testing.T) {
+		pm := NewProcessMonitor(nil)
+		clock := newFakeClock()
+		pm.SetClock(clock)
+		pm.lastCPU = 0.5
+
+		pm.CheckIdle(1.0, time.Minute)
+		clock.Advance(-time.Hour)
+		pm.CheckIdle(1.0, time.Minute)
+		clock.Advance(time.Minute)
+		if !pm.CheckIdle(1.0, time.Minute) {
+			t.Error("should notify a minute after the clock was set back, not an hour later")
+		}
+	})
+
 	t.Run("idle reset on activity", func(t *testing.T) {
 		pm := NewProcessMonitor(nil)
 		pm.lastCPU = 0.5
@@ -82,6 +152,53 @@ func TestProcessMonitor(t *testing.T) {
 	})
 }
 
+func TestProcessMonitorMinLifetime(t *testing.T) {
+	start := func() *exec.Cmd {
+		cmd := exec.Command("sleep", "421")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("cannot start a child process: %v", err)
+		}
+		t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+		return cmd
+	}
+
+	// The agent process, then short-lived helpers started after it
+	agent := start()
+	time.Sleep(2100 * time.Millisecond)
+	for range 3 {
+		start()
+	}
+
+	pm := NewProcessMonitor([]string{"sleep 421"})
+	pm.SetMinLifetime(2 * time.Second)
+	if got := pm.GetPID(); got != agent.Process.Pid {
+		t.Errorf("GetPID() = %d, want the long-lived PID %d over the decoys", got, agent.Process.Pid)
+	}
+
+	// Without a minimum lifetime the newest process wins
+	pm = NewProcessMonitor([]string{"sleep 421"})
+	if got := pm.GetPID(); got == agent.Process.Pid {
+		t.Errorf("GetPID() = %d, want a decoy when lifetimes aren't checked", got)
+	}
+}
+
+func TestLongLived(t *testing.T) {
+	now := time.UnixMilli(100_000)
+	procs := []procInfo{
+		{PID: 1, Create: 10_000},
+		{PID: 2, Create: 95_000},
+		{PID: 3, Create: 99_500},
+	}
+
+	if got := longLived(procs, now, 0); len(got) != 3 {
+		t.Errorf("longLived(0) = %v, want all", got)
+	}
+	got := longLived(procs, now, 5*time.Second)
+	if len(got) != 2 || got[0].PID != 1 || got[1].PID != 2 {
+		t.Errorf("longLived(5s) = %v, want PIDs 1 and 2", got)
+	}
+}
+
 func TestGetProcessCandidates(t *testing.T) {
 	agents := []Agent{
 		{Name: "claude", ProcessNames: []string{"claude", "claude-code"}},
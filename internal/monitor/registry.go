@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"firebell/internal/detect"
+)
+
+// RegistryFile is the name of the registry file within the config directory.
+// It adds agents to Registry, or overrides fields of built-in ones, without
+// recompiling; LoadRegistry merges it in at startup.
+const RegistryFile = "agents.yaml"
+
+// registryFile is the registry file's layout: agent name -> entry.
+//
+//	agents:
+//	  mytool:
+//	    display_name: My Tool
+//	    log_path: ~/.mytool/logs
+//	    patterns: ["*.jsonl"]
+//	    process_names: [mytool]
+//	  claude:
+//	    log_path: /data/claude/projects  # Override one field of a built-in agent
+type registryFile struct {
+	Agents map[string]registryEntry `yaml:"agents"`
+}
+
+// registryEntry is one agent in the registry file. Fields left out keep the
+// built-in agent's value; lists replace it rather than adding to it.
+type registryEntry struct {
+	DisplayName  string   `yaml:"display_name"`  // Human-readable name (default: the agent name)
+	LogPath      string   `yaml:"log_path"`      // Log path; required for a new agent
+	AltLogPaths  []string `yaml:"alt_log_paths"` // Other known log locations, probed by setup
+	Patterns     []string `yaml:"patterns"`      // Glob patterns for log files
+	ProcessNames []string `yaml:"process_names"` // Process names for PID detection
+	Format       string   `yaml:"format"`        // Log format, as agents.format: "openai_chat"
+	SessionFiles *bool    `yaml:"session_files"` // Writes one log file per session
+	WholeFile    *bool    `yaml:"whole_file"`    // Rewrites its whole log file on each update
+}
+
+// registryName matches the agent names a registry file may use: the
+// lowercase names GetAgent looks up.
+var registryName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// LoadRegistry merges the registry file at path into Registry. A missing
+// file is not an error; an invalid one changes nothing.
+func LoadRegistry(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	agents, err := parseRegistry(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, agent := range agents {
+		Registry[agent.Name] = agent
+	}
+	return nil
+}
+
+// parseRegistry parses a registry file, returning each entry merged onto
+// its built-in agent, if any.
+func parseRegistry(data []byte) ([]Agent, error) {
+	var file registryFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(file.Agents))
+	for name := range file.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	agents := make([]Agent, 0, len(names))
+	for _, name := range names {
+		agent, err := mergeRegistryEntry(name, file.Agents[name])
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+// mergeRegistryEntry applies entry to the built-in agent name, or creates
+// the agent if there is none.
+func mergeRegistryEntry(name string, entry registryEntry) (Agent, error) {
+	if !registryName.MatchString(name) {
+		return Agent{}, fmt.Errorf("agents.%s: name must be lowercase letters, digits, '-' or '_'", name)
+	}
+	if entry.Format != "" && entry.Format != detect.FormatOpenAIChat {
+		return Agent{}, fmt.Errorf("agents.%s.format: must be '%s'", name, detect.FormatOpenAIChat)
+	}
+
+	agent, builtin := Registry[name]
+	if !builtin {
+		if entry.LogPath == "" {
+			return Agent{}, fmt.Errorf("agents.%s.log_path: required for a new agent", name)
+		}
+		agent = Agent{Name: name, DisplayName: name}
+	}
+
+	if entry.DisplayName != "" {
+		agent.DisplayName = entry.DisplayName
+	}
+	if entry.LogPath != "" {
+		agent.LogPath = entry.LogPath
+	}
+	if entry.AltLogPaths != nil {
+		agent.AltLogPaths = entry.AltLogPaths
+	}
+	if entry.Patterns != nil {
+		agent.LogPatterns = entry.Patterns
+	}
+	if entry.ProcessNames != nil {
+		agent.ProcessNames = entry.ProcessNames
+	}
+	if entry.Format != "" {
+		agent.Format = entry.Format
+	}
+	if entry.SessionFiles != nil {
+		agent.SessionFiles = *entry.SessionFiles
+	}
+	if entry.WholeFile != nil {
+		agent.WholeFile = *entry.WholeFile
+	}
+	return agent, nil
+}
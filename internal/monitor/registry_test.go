@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// restoreRegistry puts the built-in Registry back after the test.
+func restoreRegistry(t *testing.T) {
+	t.Helper()
+	saved := maps.Clone(Registry)
+	t.Cleanup(func() { Registry = saved })
+}
+
+func TestLoadRegistry(t *testing.T) {
+	restoreRegistry(t)
+	claude := Registry["claude"]
+
+	path := filepath.Join(t.TempDir(), RegistryFile)
+	data := `agents:
+  mytool:
+    display_name: My Tool
+    log_path: ~/.mytool/logs
+    patterns: ["*.jsonl"]
+    process_names: [mytool, mytool-cli]
+    format: openai_chat
+    session_files: true
+  claude:
+    log_path: /data/claude/projects
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadRegistry(path); err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	want := Agent{
+		Name:         "mytool",
+		DisplayName:  "My Tool",
+		LogPath:      "~/.mytool/logs",
+		LogPatterns:  []string{"*.jsonl"},
+		ProcessNames: []string{"mytool", "mytool-cli"},
+		SessionFiles: true,
+		Format:       "openai_chat",
+	}
+	if got := GetAgent("mytool"); got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("GetAgent(mytool) = %+v, want %+v", got, want)
+	}
+	if got := LogFormat("mytool", nil); got != "openai_chat" {
+		t.Errorf("LogFormat(mytool) = %q, want the registry format", got)
+	}
+	if got := LogFormat("mytool", map[string]string{"mytool": "other"}); got != "other" {
+		t.Errorf("LogFormat(mytool) = %q, want agents.format to win", got)
+	}
+
+	// The override only changes the log path
+	want = claude
+	want.LogPath = "/data/claude/projects"
+	if got := GetAgent("claude"); got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("GetAgent(claude) = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadRegistryMissingFile(t *testing.T) {
+	restoreRegistry(t)
+	before := len(Registry)
+	if err := LoadRegistry(filepath.Join(t.TempDir(), RegistryFile)); err != nil {
+		t.Errorf("LoadRegistry() error = %v, want nil for a missing file", err)
+	}
+	if len(Registry) != before {
+		t.Errorf("Registry has %d agents, want %d", len(Registry), before)
+	}
+}
+
+func TestLoadRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"new agent without log path", "agents:\n  mytool:\n    display_name: My Tool\n", "agents.mytool.log_path"},
+		{"uppercase name", "agents:\n  MyTool:\n    log_path: /tmp\n", "agents.MyTool"},
+		{"unknown format", "agents:\n  claude:\n    format: xml\n", "agents.claude.format"},
+		{"unknown field", "agents:\n  claude:\n    log_dir: /tmp\n", "log_dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreRegistry(t)
+			before := maps.Clone(Registry)
+
+			path := filepath.Join(t.TempDir(), RegistryFile)
+			// A valid entry alongside the bad one is not applied either
+			data := tt.data + "  zzz:\n    log_path: /tmp/zzz\n"
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := LoadRegistry(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadRegistry() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(Registry, before) {
+				t.Error("Registry changed despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"firebell/internal/config"
+)
+
+// ErrOutsideRoots is returned for a path outside advanced.allowed_roots.
+var ErrOutsideRoots = errors.New("outside advanced.allowed_roots")
+
+// allowedRoots is advanced.allowed_roots with each root expanded and
+// resolved. An empty set allows every path.
+type allowedRoots []string
+
+// resolveRoots expands and resolves the configured roots.
+func resolveRoots(roots []string) allowedRoots {
+	var resolved allowedRoots
+	for _, root := range roots {
+		if root = strings.TrimSpace(root); root != "" {
+			resolved = append(resolved, resolvePath(config.ExpandPath(root)))
+		}
+	}
+	return resolved
+}
+
+// resolvePath returns path made absolute with symlinks resolved, so a link
+// inside a root can't point the watcher outside it. A path that doesn't
+// exist is only cleaned.
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return filepath.Clean(path)
+}
+
+// check returns an error wrapping ErrOutsideRoots unless path is one of the
+// roots or under one.
+func (r allowedRoots) check(path string) error {
+	if len(r) == 0 {
+		return nil
+	}
+	resolved := resolvePath(path)
+	for _, root := range r {
+		if underBase(root, resolved) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w (%s)", ErrOutsideRoots, strings.Join(r, ", "))
+}
+
+// CheckAllowedPath reports whether path may be watched under the configured
+// advanced.allowed_roots. Empty roots allow every path.
+func CheckAllowedPath(path string, roots []string) error {
+	return resolveRoots(roots).check(path)
+}
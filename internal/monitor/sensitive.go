@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sensitivePattern matches one monitor.sensitive_tools entry against tool
+// arguments: its words in order, as whole words (so "rm" matches "rm -rf x"
+// but not "format").
+func sensitivePattern(entry string) *regexp.Regexp {
+	words := strings.Fields(entry)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(`(^|[^\w.-])` + strings.Join(words, `\s+`) + `($|[^\w.-])`)
+}
+
+// sensitiveTools matches tool requests against monitor.sensitive_tools.
+type sensitiveTools struct {
+	entries  []string
+	patterns []*regexp.Regexp
+}
+
+// newSensitiveTools compiles the configured entries. Blank entries are
+// ignored.
+func newSensitiveTools(entries []string) *sensitiveTools {
+	s := &sensitiveTools{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		s.entries = append(s.entries, entry)
+		s.patterns = append(s.patterns, sensitivePattern(entry))
+	}
+	return s
+}
+
+// Match returns the entry that tool or its arguments match, or "". An entry
+// matches the tool name exactly (ignoring case), or a command in args such
+// as a shell tool's "git push origin main".
+func (s *sensitiveTools) Match(tool, args string) string {
+	for i, entry := range s.entries {
+		if strings.EqualFold(tool, entry) {
+			return entry
+		}
+		if args != "" && s.patterns[i].MatchString(args) {
+			return entry
+		}
+	}
+	return ""
+}
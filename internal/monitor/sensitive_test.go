@@ -0,0 +1,31 @@
+package monitor
+
+import "testing"
+
+func TestSensitiveToolsMatch(t *testing.T) {
+	s := newSensitiveTools([]string{"rm", "write_file", "git push", " "})
+
+	tests := []struct {
+		tool, args string
+		want       string
+	}{
+		{"write_file", `{"path":"a.txt"}`, "write_file"},
+		{"Write_File", "", "write_file"},
+		{"Bash", `{"command":"rm -rf build"}`, "rm"},
+		{"shell", `{"command":["bash","-lc","cd x && rm a"]}`, "rm"},
+		{"Bash", `{"command":"git  push origin main"}`, "git push"},
+		{"Bash", `{"command":"git pull"}`, ""},
+		{"Bash", `{"command":"gofmt -l ."}`, ""},
+		{"Bash", `{"command":"cat rm.txt"}`, ""},
+		{"read_file", `{"path":"notes"}`, ""},
+	}
+	for _, tt := range tests {
+		if got := s.Match(tt.tool, tt.args); got != tt.want {
+			t.Errorf("Match(%q, %q) = %q, want %q", tt.tool, tt.args, got, tt.want)
+		}
+	}
+
+	if got := newSensitiveTools(nil).Match("rm", ""); got != "" {
+		t.Errorf("Empty list matched %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SnoozeFile is the name of the snooze file within the config directory.
+// It holds the RFC 3339 time alerts resume; `firebell snooze` writes it and
+// the watcher reads it before each send.
+const SnoozeFile = "snooze"
+
+// WriteSnooze silences alerts until until.
+func WriteSnooze(path string, until time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(until.Format(time.RFC3339)+"\n"), 0644)
+}
+
+// ClearSnooze ends a snooze. A missing file is not an error.
+func ClearSnooze(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReadSnooze returns when the snooze at path ends, or the zero time if
+// there is none.
+func ReadSnooze(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	until, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid snooze file %s: %w", path, err)
+	}
+	return until, nil
+}
@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,7 +16,12 @@ type State struct {
 	agents      map[string]*AgentState    // key: agent name
 	instances   map[string]*InstanceState // key: filepath (per-instance mode)
 	process     *ProcessState
-	perInstance bool // Track each instance separately
+	perInstance bool              // Track each instance separately (default for all agents)
+	agentModes  map[string]bool   // Per-agent overrides of perInstance (mixed mode)
+	names       map[string]string // Display name overrides (agent -> name or template)
+	clock       Clock             // Time source for cues and quiet checks
+	panics      int               // Panics recovered while matching lines or handling events
+	historySize int               // Recent cues kept per agent (see RecordHistory; 0 = none)
 }
 
 // AgentState tracks per-agent monitoring state.
@@ -27,9 +33,221 @@ type AgentState struct {
 	LastCueType   detect.MatchType // Type of last cue (Complete, Activity, etc.)
 	QuietNotified bool             // Whether "cooling" was sent (replaces quietSent map)
 	WatchedPaths  []string         // Currently watched file paths
+	LinesRead     int              // Non-empty log lines processed
+	ParseErrors   int              // Lines that looked like JSON but failed to parse
+	Paused        bool             // Lines and quiet periods are ignored (firebell pause)
 
 	// Internal state
-	lastNotify time.Time // For potential future deduplication
+	lastNotify  time.Time     // For potential future deduplication
+	recentTools []toolRequest // Rolling window of tool requests for loop detection
+	activity    int           // Activity cues since the last completion
+	loopKey     string        // Tool request already reported as a loop
+	turn        turn          // Current stretch of activity, for working reminders
+	history     cueHistory    // Recent cues, for webhooks with include_history
+	holdingSent bool          // A Holding was sent since the last completion
+	holdingTool toolCall      // Tool of the last Holding cue, for its action hint
+}
+
+// toolCall is a tool request waiting for approval.
+type toolCall struct {
+	name string
+	args string
+}
+
+// turn tracks a continuous stretch of agent activity between completions.
+type turn struct {
+	start        time.Time // First cue of the turn (zero = no turn in progress)
+	lastReminder time.Time // When the last working reminder was sent
+}
+
+// cue extends the turn with a cue, starting one if needed. A completion
+// ends the turn.
+func (t *turn) cue(cueType detect.MatchType, at time.Time) {
+	if cueType == detect.MatchComplete {
+		t.end()
+		return
+	}
+	if t.start.IsZero() {
+		t.start = at
+	}
+}
+
+// end clears the turn.
+func (t *turn) end() {
+	*t = turn{}
+}
+
+// reminderDue reports whether a working reminder should be sent at now and,
+// if so, records it and returns how long the turn has been running. The turn
+// must still be active (last cue within quiet) and interval must have passed
+// since the turn started or the last reminder.
+func (t *turn) reminderDue(now, lastCue time.Time, quiet, interval time.Duration) (time.Duration, bool) {
+	if t.start.IsZero() || interval <= 0 || now.Sub(lastCue) >= quiet {
+		return 0, false
+	}
+	rewind(&t.start, now)
+	rewind(&t.lastReminder, now)
+	since := t.start
+	if t.lastReminder.After(since) {
+		since = t.lastReminder
+	}
+	if now.Sub(since) < interval {
+		return 0, false
+	}
+	t.lastReminder = now
+	return now.Sub(t.start), true
+}
+
+// trackedCue maps a cue to the type quiet tracking records. A compaction
+// happens mid-turn, so it counts as plain activity.
+func trackedCue(cueType detect.MatchType) detect.MatchType {
+	if cueType == detect.MatchCompaction {
+		return detect.MatchActivity
+	}
+	return cueType
+}
+
+// countActivity updates an activity-since-completion count for a cue.
+func countActivity(n int, cueType detect.MatchType) int {
+	switch cueType {
+	case detect.MatchActivity:
+		return n + 1
+	case detect.MatchComplete:
+		return 0
+	default:
+		return n
+	}
+}
+
+// ActivitySinceComplete returns the number of activity cues an agent has
+// had since its last completion.
+func (s *State) ActivitySinceComplete(agentName string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if agent, ok := s.agents[agentName]; ok {
+		return agent.activity
+	}
+	return 0
+}
+
+// InstanceActivitySinceComplete is ActivitySinceComplete for an instance.
+func (s *State) InstanceActivitySinceComplete(filePath string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if inst, ok := s.instances[filePath]; ok {
+		return inst.activity
+	}
+	return 0
+}
+
+// MarkHoldingSent records that a Holding was sent for an agent, reporting
+// whether it is the first since the agent's last completion.
+func (s *State) MarkHoldingSent(agentName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[agentName]
+	if !ok {
+		return false
+	}
+	first := !agent.holdingSent
+	agent.holdingSent = true
+	return first
+}
+
+// MarkInstanceHoldingSent is MarkHoldingSent for an instance.
+func (s *State) MarkInstanceHoldingSent(filePath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instances[filePath]
+	if !ok {
+		return false
+	}
+	first := !inst.holdingSent
+	inst.holdingSent = true
+	return first
+}
+
+// SetHoldingTool records the tool an agent is waiting on approval for, so a
+// Holding sent after the quiet period can name it.
+func (s *State) SetHoldingTool(agentName, tool, args string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if agent, ok := s.agents[agentName]; ok {
+		agent.holdingTool = toolCall{name: tool, args: args}
+	}
+}
+
+// HoldingTool returns the tool recorded by SetHoldingTool, or "" if none.
+func (s *State) HoldingTool(agentName string) (tool, args string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if agent, ok := s.agents[agentName]; ok {
+		return agent.holdingTool.name, agent.holdingTool.args
+	}
+	return "", ""
+}
+
+// SetInstanceHoldingTool is SetHoldingTool for an instance.
+func (s *State) SetInstanceHoldingTool(filePath, tool, args string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inst, ok := s.instances[filePath]; ok {
+		inst.holdingTool = toolCall{name: tool, args: args}
+	}
+}
+
+// InstanceHoldingTool is HoldingTool for an instance.
+func (s *State) InstanceHoldingTool(filePath string) (tool, args string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inst, ok := s.instances[filePath]; ok {
+		return inst.holdingTool.name, inst.holdingTool.args
+	}
+	return "", ""
+}
+
+// SetInstancePID associates an instance with the process writing its log
+// (0 clears it).
+func (s *State) SetInstancePID(filePath string, pid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inst, ok := s.instances[filePath]; ok {
+		inst.PID = pid
+	}
+}
+
+// InstancePID returns the process associated with an instance (0 = none).
+func (s *State) InstancePID(filePath string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if inst, ok := s.instances[filePath]; ok {
+		return inst.PID
+	}
+	return 0
+}
+
+// InstanceForPID returns the display name of the instance associated with
+// pid, or "" if none is. The lowest log path wins if several share it.
+func (s *State) InstanceForPID(pid int) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	name, path := "", ""
+	for _, inst := range s.instances {
+		if pid > 0 && inst.PID == pid && (path == "" || inst.FilePath < path) {
+			name, path = inst.DisplayName, inst.FilePath
+		}
+	}
+	return name
+}
+
+// toolRequest is a tool request seen at a point in time.
+type toolRequest struct {
+	key string // Tool name and arguments
+	at  time.Time
 }
 
 // InstanceState tracks per-instance (per-file) monitoring state.
@@ -41,6 +259,12 @@ type InstanceState struct {
 	LastCue       time.Time        // Last activity detected
 	LastCueType   detect.MatchType // Type of last cue
 	QuietNotified bool             // Whether notification was sent
+	PID           int              // Process writing the log (0 = not associated)
+
+	turn        turn     // Current stretch of activity, for working reminders
+	activity    int      // Activity cues since the last completion
+	holdingSent bool     // A Holding was sent since the last completion
+	holdingTool toolCall // Tool of the last Holding cue, for its action hint
 }
 
 // ProcessState tracks monitored process resources.
@@ -48,7 +272,9 @@ type InstanceState struct {
 // Note: ProcSample is defined in process.go.
 type ProcessState struct {
 	PID        int
+	StartTime  time.Time // When the process started (zero if unknown)
 	LastSample *ProcSample
+	CPUPercent float64 // Last calculated CPU percentage
 	IdleSince  time.Time
 
 	// Notification flags
@@ -64,14 +290,62 @@ func NewState(perInstance bool) *State {
 		instances:   make(map[string]*InstanceState),
 		process:     &ProcessState{},
 		perInstance: perInstance,
+		clock:       realClock{},
 	}
 }
 
-// IsPerInstance returns whether per-instance tracking is enabled.
+// SetClock replaces the time source (for tests).
+func (s *State) SetClock(c Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = c
+}
+
+// Now returns the current time from the state's clock.
+func (s *State) Now() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clock.Now()
+}
+
+// SetDisplayNames sets display name overrides used when naming new instances.
+func (s *State) SetDisplayNames(names map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.names = names
+}
+
+// IsPerInstance returns whether per-instance tracking is enabled by default.
 func (s *State) IsPerInstance() bool {
 	return s.perInstance
 }
 
+// SetAgentPerInstance overrides the default tracking mode for one agent, so
+// some agents can be tracked per instance while others are tracked as a whole.
+func (s *State) SetAgentPerInstance(agentName string, perInstance bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.agentModes == nil {
+		s.agentModes = make(map[string]bool)
+	}
+	s.agentModes[agentName] = perInstance
+}
+
+// IsPerInstanceAgent returns whether an agent is tracked per instance.
+func (s *State) IsPerInstanceAgent(agentName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isPerInstanceAgent(agentName)
+}
+
+// isPerInstanceAgent is IsPerInstanceAgent; the caller must hold s.mu.
+func (s *State) isPerInstanceAgent(agentName string) bool {
+	if perInstance, ok := s.agentModes[agentName]; ok {
+		return perInstance
+	}
+	return s.perInstance
+}
+
 // AddAgent adds or updates an agent's state.
 func (s *State) AddAgent(agent Agent) *AgentState {
 	s.mu.Lock()
@@ -127,13 +401,23 @@ func (s *State) GetAllAgents() []*AgentState {
 // RecordCue records that activity was detected for an agent.
 // Strong cues (MatchComplete, MatchHolding) are not overwritten by MatchActivity.
 func (s *State) RecordCue(agentName string, cueType detect.MatchType) {
+	s.RecordCueAt(agentName, cueType, s.Now())
+}
+
+// RecordCueAt records a cue for an agent at a specific time (used when seeding from history).
+func (s *State) RecordCueAt(agentName string, cueType detect.MatchType, at time.Time) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	cueType = trackedCue(cueType)
+	at = anchorTime(s.clock.Now(), at)
 	if agent, ok := s.agents[agentName]; ok {
-		agent.LastCue = time.Now()
+		agent.LastCue = at
 		agent.QuietNotified = false // Reset quiet notification
-		agent.lastNotify = time.Now()
+		agent.lastNotify = s.clock.Now()
+		agent.turn.cue(cueType, at)
+		agent.activity = countActivity(agent.activity, cueType)
+		agent.holdingSent = agent.holdingSent && cueType != detect.MatchComplete
 
 		// MatchActivity is a weak signal - don't overwrite strong cues
 		// Strong cues: MatchComplete (turn finished), MatchHolding (tool permission)
@@ -150,6 +434,19 @@ func (s *State) RecordCue(agentName string, cueType detect.MatchType) {
 	}
 }
 
+// TouchCue refreshes an agent's last cue time for n activity cues that
+// continue a run (see cueCollapser), without the rest of RecordCue's work.
+func (s *State) TouchCue(agentName string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if agent, ok := s.agents[agentName]; ok {
+		agent.LastCue = s.clock.Now()
+		agent.QuietNotified = false
+		agent.activity += n
+	}
+}
+
 // GetLastCueType returns the type of the last cue for an agent.
 func (s *State) GetLastCueType(agentName string) detect.MatchType {
 	s.mu.RLock()
@@ -168,14 +465,65 @@ func (s *State) MarkQuietNotified(agentName string) {
 
 	if agent, ok := s.agents[agentName]; ok {
 		agent.QuietNotified = true
+		agent.turn.end()
 	}
 }
 
+// SetPaused pauses or resumes an agent, returning false if it isn't tracked.
+// Pausing marks the agent and its instances quiet-notified, so a pending
+// notification isn't sent on resume.
+func (s *State) SetPaused(agentName string, paused bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[agentName]
+	if !ok {
+		return false
+	}
+	agent.Paused = paused
+	if paused {
+		agent.QuietNotified = true
+		agent.turn.end()
+		for _, inst := range s.instances {
+			if inst.AgentName == agentName {
+				inst.QuietNotified = true
+				inst.turn.end()
+			}
+		}
+	}
+	return true
+}
+
+// IsPaused reports whether an agent is paused.
+func (s *State) IsPaused(agentName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	agent, ok := s.agents[agentName]
+	return ok && agent.Paused
+}
+
+// WorkingReminderDue reports whether an agent that has stayed active for
+// interval since its turn began (or since the last reminder) is due a
+// still-working reminder, returning the turn's elapsed time. A true result
+// records the reminder.
+func (s *State) WorkingReminderDue(agentName string, quietDuration, interval time.Duration) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[agentName]
+	if !ok || agent.QuietNotified {
+		return 0, false
+	}
+	return agent.turn.reminderDue(s.clock.Now(), agent.LastCue, quietDuration, interval)
+}
+
 // ShouldSendQuiet checks if a quiet notification should be sent.
 // Returns true if: agent has had a cue, quiet period has elapsed, and not already notified.
+// If the clock was set back past the last cue, the quiet period restarts.
 func (s *State) ShouldSendQuiet(agentName string, quietDuration time.Duration) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	agent, ok := s.agents[agentName]
 	if !ok {
@@ -193,7 +541,48 @@ func (s *State) ShouldSendQuiet(agentName string, quietDuration time.Duration) b
 	}
 
 	// Check if quiet period has elapsed
-	return time.Since(agent.LastCue) >= quietDuration
+	return rewind(&agent.LastCue, s.clock.Now()) >= quietDuration
+}
+
+// RecordToolRequest adds a tool request to the agent's rolling window and
+// returns how many identical requests fall within window. loop is true the
+// first time the count exceeds threshold; it re-arms once the count drops back.
+func (s *State) RecordToolRequest(agentName, key string, at time.Time, window time.Duration, threshold int) (count int, loop bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, ok := s.agents[agentName]
+	if !ok {
+		return 0, false
+	}
+
+	// Drop requests that fell out of the window
+	cutoff := at.Add(-window)
+	kept := agent.recentTools[:0]
+	for _, req := range agent.recentTools {
+		if !req.at.Before(cutoff) {
+			kept = append(kept, req)
+		}
+	}
+	agent.recentTools = append(kept, toolRequest{key: key, at: at})
+
+	for _, req := range agent.recentTools {
+		if req.key == key {
+			count++
+		}
+	}
+
+	if count <= threshold {
+		if agent.loopKey == key {
+			agent.loopKey = ""
+		}
+		return count, false
+	}
+	if agent.loopKey == key {
+		return count, false
+	}
+	agent.loopKey = key
+	return count, true
 }
 
 // UpdateWatchedPaths updates the list of watched paths for an agent.
@@ -206,6 +595,83 @@ func (s *State) UpdateWatchedPaths(agentName string, paths []string) {
 	}
 }
 
+// FocusTarget returns the file path of the instance, or the name of the
+// agent (when not per-instance), with the most recent cue, among the agents
+// for which eligible reports true. It returns "" if none has been cued yet.
+func (s *State) FocusTarget(eligible func(agentName string) bool) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var target string
+	var latest time.Time
+	for path, inst := range s.instances {
+		if !eligible(inst.AgentName) {
+			continue
+		}
+		if inst.LastCue.After(latest) {
+			target, latest = path, inst.LastCue
+		}
+	}
+	for name, a := range s.agents {
+		if s.isPerInstanceAgent(name) || !eligible(name) {
+			continue
+		}
+		if a.LastCue.After(latest) {
+			target, latest = name, a.LastCue
+		}
+	}
+	return target
+}
+
+// LastActivity returns the most recent cue of any agent or instance that
+// isn't paused, and its type (zero time if there was none).
+func (s *State) LastActivity() (time.Time, detect.MatchType) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest time.Time
+	var cueType detect.MatchType
+	for _, inst := range s.instances {
+		if a := s.agents[inst.AgentName]; a != nil && a.Paused {
+			continue
+		}
+		if inst.LastCue.After(latest) {
+			latest, cueType = inst.LastCue, inst.LastCueType
+		}
+	}
+	for name, a := range s.agents {
+		if s.isPerInstanceAgent(name) || a.Paused {
+			continue
+		}
+		if a.LastCue.After(latest) {
+			latest, cueType = a.LastCue, a.LastCueType
+		}
+	}
+	return latest, cueType
+}
+
+// RecordPanic counts a panic recovered while matching a line or handling
+// an event, for the status dump.
+func (s *State) RecordPanic() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panics++
+}
+
+// RecordLines adds to an agent's processed-line and parse-error counts.
+func (s *State) RecordLines(agentName string, read, malformed int) {
+	if read == 0 && malformed == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if agent, ok := s.agents[agentName]; ok {
+		agent.LinesRead += read
+		agent.ParseErrors += malformed
+	}
+}
+
 // Instance-level methods (for per_instance mode)
 
 // GetOrCreateInstance returns the instance state for a filepath, creating it if needed.
@@ -220,7 +686,7 @@ func (s *State) GetOrCreateInstance(agentName, filePath string) *InstanceState {
 	inst := &InstanceState{
 		AgentName:   agentName,
 		FilePath:    filePath,
-		DisplayName: deriveInstanceDisplayName(agentName, filePath),
+		DisplayName: deriveInstanceDisplayName(agentName, filePath, s.names[agentName]),
 	}
 	s.instances[filePath] = inst
 	return inst
@@ -228,6 +694,11 @@ func (s *State) GetOrCreateInstance(agentName, filePath string) *InstanceState {
 
 // RecordInstanceCue records activity for a specific instance.
 func (s *State) RecordInstanceCue(filePath string, cueType detect.MatchType) {
+	s.RecordInstanceCueAt(filePath, cueType, s.Now())
+}
+
+// RecordInstanceCueAt records activity for a specific instance at a specific time.
+func (s *State) RecordInstanceCueAt(filePath string, cueType detect.MatchType, at time.Time) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -236,8 +707,13 @@ func (s *State) RecordInstanceCue(filePath string, cueType detect.MatchType) {
 		return
 	}
 
-	inst.LastCue = time.Now()
+	cueType = trackedCue(cueType)
+	at = anchorTime(s.clock.Now(), at)
+	inst.LastCue = at
 	inst.QuietNotified = false
+	inst.turn.cue(cueType, at)
+	inst.activity = countActivity(inst.activity, cueType)
+	inst.holdingSent = inst.holdingSent && cueType != detect.MatchComplete
 
 	// Same strong/weak cue logic as agent-level
 	if cueType == detect.MatchActivity {
@@ -249,6 +725,18 @@ func (s *State) RecordInstanceCue(filePath string, cueType detect.MatchType) {
 	}
 }
 
+// TouchInstanceCue is TouchCue for a specific instance.
+func (s *State) TouchInstanceCue(filePath string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inst, ok := s.instances[filePath]; ok {
+		inst.LastCue = s.clock.Now()
+		inst.QuietNotified = false
+		inst.activity += n
+	}
+}
+
 // GetInstanceCueType returns the cue type for a specific instance.
 func (s *State) GetInstanceCueType(filePath string) detect.MatchType {
 	s.mu.RLock()
@@ -267,13 +755,26 @@ func (s *State) MarkInstanceQuietNotified(filePath string) {
 
 	if inst, ok := s.instances[filePath]; ok {
 		inst.QuietNotified = true
+		inst.turn.end()
 	}
 }
 
+// InstanceWorkingReminderDue is WorkingReminderDue for an instance.
+func (s *State) InstanceWorkingReminderDue(filePath string, quietDuration, interval time.Duration) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instances[filePath]
+	if !ok || inst.QuietNotified {
+		return 0, false
+	}
+	return inst.turn.reminderDue(s.clock.Now(), inst.LastCue, quietDuration, interval)
+}
+
 // ShouldSendInstanceQuiet checks if a quiet notification should be sent for an instance.
 func (s *State) ShouldSendInstanceQuiet(filePath string, quietDuration time.Duration) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	inst, ok := s.instances[filePath]
 	if !ok {
@@ -288,7 +789,7 @@ func (s *State) ShouldSendInstanceQuiet(filePath string, quietDuration time.Dura
 		return false
 	}
 
-	return time.Since(inst.LastCue) >= quietDuration
+	return rewind(&inst.LastCue, s.clock.Now()) >= quietDuration
 }
 
 // GetAllInstances returns all instance states.
@@ -313,7 +814,35 @@ func (s *State) GetInstance(filePath string) *InstanceState {
 // deriveInstanceDisplayName creates a human-readable name from agent and filepath.
 // For Claude: "Claude Code (project-abc123)" from ~/.claude/projects/abc123/...
 // For others: "Agent (filename)" from the log file name
-func deriveInstanceDisplayName(agentName, filePath string) string {
+// A non-empty name replaces the agent's display name; if it contains
+// {instance}, the label is substituted there instead of appended.
+func deriveInstanceDisplayName(agentName, filePath, name string) string {
+	label := instanceLabel(agentName, filePath)
+
+	if name != "" {
+		if strings.Contains(name, instancePlaceholder) {
+			return strings.ReplaceAll(name, instancePlaceholder, label)
+		}
+		return name + " (" + label + ")"
+	}
+
+	// Get display name from registry
+	if agent := GetAgent(agentName); agent != nil {
+		return agent.DisplayName + " (" + label + ")"
+	}
+
+	return agentName + " (" + label + ")"
+}
+
+// instanceLabel returns the short label identifying an instance's log file.
+func instanceLabel(agentName, filePath string) string {
+	if unit, ok := JournaldUnit(filePath); ok {
+		return unit
+	}
+	if container, ok := DockerContainer(filePath); ok {
+		return container
+	}
+
 	// Get the directory containing the log file
 	dir := filepath.Dir(filePath)
 	base := filepath.Base(dir)
@@ -325,7 +854,7 @@ func deriveInstanceDisplayName(agentName, filePath string) string {
 			if len(base) > 8 {
 				base = base[:8] // Truncate long hashes
 			}
-			return "Claude Code (" + base + ")"
+			return base
 		}
 	}
 
@@ -335,13 +864,7 @@ func deriveInstanceDisplayName(agentName, filePath string) string {
 	if ext != "" {
 		fileName = fileName[:len(fileName)-len(ext)]
 	}
-
-	// Get display name from registry
-	if agent := GetAgent(agentName); agent != nil {
-		return agent.DisplayName + " (" + fileName + ")"
-	}
-
-	return agentName + " (" + fileName + ")"
+	return fileName
 }
 
 // Process state methods
@@ -360,6 +883,13 @@ func (s *State) SetPID(pid int) {
 	s.process.PID = pid
 }
 
+// SetProcessStart records when the monitored process started.
+func (s *State) SetProcessStart(start time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.process.StartTime = start
+}
+
 // UpdateProcSample updates the process sample.
 func (s *State) UpdateProcSample(sample *ProcSample) {
 	s.mu.Lock()
@@ -367,6 +897,21 @@ func (s *State) UpdateProcSample(sample *ProcSample) {
 	s.process.LastSample = sample
 }
 
+// UpdateProcCPU records the last calculated CPU percentage.
+func (s *State) UpdateProcCPU(pct float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.process.CPUPercent = pct
+}
+
+// IsProcessIdleNotified reports whether an idle notification was sent for
+// the current idle stretch.
+func (s *State) IsProcessIdleNotified() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.process.IdleNotified
+}
+
 // MarkProcessIdle marks that an idle notification was sent.
 func (s *State) MarkProcessIdle(idleSince time.Time) {
 	s.mu.Lock()
@@ -404,6 +949,14 @@ func (s *State) MarkProcessExited() {
 	s.process.ExitNotified = true
 }
 
+// ResetProcessExited clears the exit notification flag when a new process is
+// tracked, so its exit is reported too.
+func (s *State) ResetProcessExited() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.process.ExitNotified = false
+}
+
 // IsProcessExitNotified returns whether exit notification was already sent.
 func (s *State) IsProcessExitNotified() bool {
 	s.mu.RLock()
@@ -1,6 +1,9 @@
 package monitor
 
 import (
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,15 +37,15 @@ func TestState(t *testing.T) {
 
 	t.Run("record cue updates timestamp", func(t *testing.T) {
 		s := NewState(false)
+		clock := newFakeClock()
+		s.SetClock(clock)
 		s.AddAgent(Agent{Name: "claude"})
 
-		before := time.Now()
 		s.RecordCue("claude", detect.MatchComplete)
-		after := time.Now()
 
 		state := s.GetAgent("claude")
-		if state.LastCue.Before(before) || state.LastCue.After(after) {
-			t.Error("LastCue not updated correctly")
+		if !state.LastCue.Equal(clock.Now()) {
+			t.Errorf("LastCue = %v, want %v", state.LastCue, clock.Now())
 		}
 		if state.QuietNotified {
 			t.Error("QuietNotified should be cleared on cue")
@@ -54,19 +57,23 @@ func TestState(t *testing.T) {
 
 	t.Run("quiet notification lifecycle", func(t *testing.T) {
 		s := NewState(false)
+		clock := newFakeClock()
+		s.SetClock(clock)
 		s.AddAgent(Agent{Name: "claude"})
 
 		// Record activity
 		s.RecordCue("claude", detect.MatchComplete)
 
-		// Should not send quiet immediately
+		// Should not send quiet immediately, nor just before the period ends
 		if s.ShouldSendQuiet("claude", 1*time.Second) {
 			t.Error("should not send quiet immediately after cue")
 		}
+		clock.Advance(999 * time.Millisecond)
+		if s.ShouldSendQuiet("claude", 1*time.Second) {
+			t.Error("should not send quiet before duration passed")
+		}
 
-		// Simulate time passing by directly modifying state
-		state := s.GetAgent("claude")
-		state.LastCue = time.Now().Add(-2 * time.Second)
+		clock.Advance(time.Millisecond)
 
 		// Now should send quiet
 		if !s.ShouldSendQuiet("claude", 1*time.Second) {
@@ -83,8 +90,7 @@ func TestState(t *testing.T) {
 
 		// New cue resets
 		s.RecordCue("claude", detect.MatchActivity)
-		state = s.GetAgent("claude")
-		if state.QuietNotified {
+		if s.GetAgent("claude").QuietNotified {
 			t.Error("QuietNotified should be cleared after new cue")
 		}
 	})
@@ -129,6 +135,24 @@ func TestPerInstanceState(t *testing.T) {
 		}
 	})
 
+	t.Run("mixed modes", func(t *testing.T) {
+		s := NewState(false)
+		s.SetAgentPerInstance("claude", true)
+
+		if !s.IsPerInstanceAgent("claude") {
+			t.Error("claude should be tracked per instance")
+		}
+		if s.IsPerInstanceAgent("amazonq") {
+			t.Error("amazonq should follow the per-agent default")
+		}
+
+		s = NewState(true)
+		s.SetAgentPerInstance("amazonq", false)
+		if s.IsPerInstanceAgent("amazonq") || !s.IsPerInstanceAgent("claude") {
+			t.Error("override should apply only to amazonq")
+		}
+	})
+
 	t.Run("create and get instance", func(t *testing.T) {
 		s := NewState(true)
 
@@ -194,6 +218,8 @@ func TestPerInstanceState(t *testing.T) {
 
 	t.Run("instance quiet notification", func(t *testing.T) {
 		s := NewState(true)
+		clock := newFakeClock()
+		s.SetClock(clock)
 		path := "/path/to/project/log.jsonl"
 
 		s.GetOrCreateInstance("claude", path)
@@ -204,9 +230,7 @@ func TestPerInstanceState(t *testing.T) {
 			t.Error("should not send quiet immediately")
 		}
 
-		// Simulate time passing
-		inst := s.GetInstance(path)
-		inst.LastCue = time.Now().Add(-2 * time.Second)
+		clock.Advance(2 * time.Second)
 
 		// Now should send
 		if !s.ShouldSendInstanceQuiet(path, 1*time.Second) {
@@ -281,7 +305,7 @@ func TestDeriveInstanceDisplayName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.agent+"_"+tt.path, func(t *testing.T) {
-			got := deriveInstanceDisplayName(tt.agent, tt.path)
+			got := deriveInstanceDisplayName(tt.agent, tt.path, "")
 			if !containsSubstring(got, tt.contains) {
 				t.Errorf("deriveInstanceDisplayName(%q, %q) = %q, want to contain %q", tt.agent, tt.path, got, tt.contains)
 			}
@@ -289,6 +313,23 @@ func TestDeriveInstanceDisplayName(t *testing.T) {
 	}
 }
 
+func TestDeriveInstanceDisplayNameOverride(t *testing.T) {
+	path := "/home/user/.claude/projects/abc12345/log.jsonl"
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Main Claude", "Main Claude (abc12345)"},
+		{"Review Claude [{instance}]", "Review Claude [abc12345]"},
+	}
+
+	for _, tt := range tests {
+		if got := deriveInstanceDisplayName("claude", path, tt.name); got != tt.want {
+			t.Errorf("deriveInstanceDisplayName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
 func containsSubstring(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && findSubstring(s, substr)))
@@ -302,3 +343,178 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRecordToolRequest(t *testing.T) {
+	s := NewState(false)
+	s.AddAgent(Agent{Name: "claude", DisplayName: "Claude Code"})
+
+	start := time.Now()
+	window := time.Minute
+
+	for i := 0; i < 3; i++ {
+		if _, loop := s.RecordToolRequest("claude", "Bash", start, window, 3); loop {
+			t.Fatalf("Unexpected loop at request %d", i+1)
+		}
+	}
+
+	count, loop := s.RecordToolRequest("claude", "Bash", start, window, 3)
+	if !loop || count != 4 {
+		t.Errorf("Expected loop at count 4, got count=%d loop=%v", count, loop)
+	}
+
+	if _, loop := s.RecordToolRequest("claude", "Bash", start, window, 3); loop {
+		t.Error("Loop should only be reported once")
+	}
+
+	// Requests outside the window no longer count
+	count, loop = s.RecordToolRequest("claude", "Bash", start.Add(2*window), window, 3)
+	if loop || count != 1 {
+		t.Errorf("Expected window to reset, got count=%d loop=%v", count, loop)
+	}
+
+	if _, loop := s.RecordToolRequest("missing", "Bash", start, window, 3); loop {
+		t.Error("Unknown agent should never report a loop")
+	}
+}
+
+func TestTurnReminderCadence(t *testing.T) {
+	quiet := 15 * time.Second
+	interval := 5 * time.Minute
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var tr turn
+	tr.cue(detect.MatchActivity, start)
+
+	// Steady activity: a cue every 10s for 16 minutes, reminders checked every second
+	var reminders []time.Duration
+	for sec := 0; sec <= 16*60; sec++ {
+		now := start.Add(time.Duration(sec) * time.Second)
+		lastCue := start.Add(time.Duration(sec/10*10) * time.Second)
+		if sec%10 == 0 {
+			tr.cue(detect.MatchActivity, now)
+		}
+		if elapsed, ok := tr.reminderDue(now, lastCue, quiet, interval); ok {
+			reminders = append(reminders, elapsed)
+		}
+	}
+
+	want := []time.Duration{5 * time.Minute, 10 * time.Minute, 15 * time.Minute}
+	if !reflect.DeepEqual(reminders, want) {
+		t.Errorf("Reminders at %v, want %v", reminders, want)
+	}
+}
+
+func TestTurnReminderStopsWhenQuietOrComplete(t *testing.T) {
+	quiet := 15 * time.Second
+	interval := time.Minute
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var tr turn
+	tr.cue(detect.MatchActivity, start)
+
+	// Quiet for longer than the quiet period: not "still working"
+	if _, ok := tr.reminderDue(start.Add(2*time.Minute), start, quiet, interval); ok {
+		t.Error("Expected no reminder once the agent went quiet")
+	}
+
+	// Completion resets the turn; the next turn starts its own interval
+	tr.cue(detect.MatchComplete, start.Add(2*time.Minute))
+	if _, ok := tr.reminderDue(start.Add(2*time.Minute), start.Add(2*time.Minute), quiet, interval); ok {
+		t.Error("Expected no reminder after completion")
+	}
+	next := start.Add(3 * time.Minute)
+	tr.cue(detect.MatchActivity, next)
+	if _, ok := tr.reminderDue(next.Add(30*time.Second), next.Add(30*time.Second), quiet, interval); ok {
+		t.Error("Expected no reminder before the new turn reaches the interval")
+	}
+	if elapsed, ok := tr.reminderDue(next.Add(time.Minute), next.Add(time.Minute), quiet, interval); !ok || elapsed != time.Minute {
+		t.Errorf("Expected reminder at 1m into the new turn, got %v %v", elapsed, ok)
+	}
+
+	// Disabled
+	if _, ok := tr.reminderDue(next.Add(time.Hour), next.Add(time.Hour), quiet, 0); ok {
+		t.Error("Expected no reminder with interval 0")
+	}
+}
+
+func TestQuietPeriodClockSetBack(t *testing.T) {
+	s := NewState(false)
+	clock := newFakeClock()
+	s.SetClock(clock)
+	s.AddAgent(Agent{Name: "claude"})
+	path := "/path/to/project/log.jsonl"
+	s.GetOrCreateInstance("claude", path)
+
+	s.RecordCue("claude", detect.MatchComplete)
+	s.RecordInstanceCue(path, detect.MatchComplete)
+	clock.Advance(5 * time.Second)
+
+	// NTP sets the clock back an hour: the quiet period restarts instead of
+	// waiting an hour for the clock to pass the cue
+	clock.Advance(-time.Hour)
+	if s.ShouldSendQuiet("claude", 15*time.Second) || s.ShouldSendInstanceQuiet(path, 15*time.Second) {
+		t.Fatal("should not send quiet right after the jump")
+	}
+	clock.Advance(15 * time.Second)
+	if !s.ShouldSendQuiet("claude", 15*time.Second) {
+		t.Error("should send quiet a quiet period after the jump")
+	}
+	if !s.ShouldSendInstanceQuiet(path, 15*time.Second) {
+		t.Error("should send instance quiet a quiet period after the jump")
+	}
+}
+
+func TestRecordCueAnchorsLogTimes(t *testing.T) {
+	s := NewState(false)
+	s.AddAgent(Agent{Name: "claude"})
+
+	// A backfilled cue's log timestamp has no monotonic reading; it is
+	// stored with one, so later wall-clock jumps don't affect it
+	at := time.Now().Add(-time.Minute).Round(0)
+	s.RecordCueAt("claude", detect.MatchComplete, at)
+	cue := s.GetAgent("claude").LastCue
+	if !strings.Contains(cue.String(), "m=") {
+		t.Errorf("LastCue = %v, want a monotonic reading", cue)
+	}
+	if d := cue.Sub(at); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("LastCue = %v, want %v", cue, at)
+	}
+
+	// A log clock ahead of ours doesn't put the cue in the future
+	s.RecordCueAt("claude", detect.MatchComplete, time.Now().Add(time.Hour).Round(0))
+	if cue := s.GetAgent("claude").LastCue; cue.After(time.Now()) {
+		t.Errorf("LastCue = %v, want it clamped to now", cue)
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	s := NewState(false)
+	s.AddAgent(Agent{Name: "claude"})
+
+	// Nothing is kept until a size is set
+	s.RecordHistory("claude", detect.MatchActivity, "assistant")
+	if got := s.History("claude", 5); got != nil {
+		t.Fatalf("History() = %v, want nil with no size set", got)
+	}
+
+	s.SetHistorySize(3)
+	reasons := []string{"one", "two", "three", "four", "five"}
+	for _, r := range reasons {
+		s.RecordHistory("claude", detect.MatchActivity, r)
+	}
+	s.RecordHistory("unknown", detect.MatchActivity, "ignored")
+
+	var got []string
+	for _, e := range s.History("claude", 5) {
+		got = append(got, e.Reason)
+	}
+	if want := []string{"three", "four", "five"}; !slices.Equal(got, want) {
+		t.Errorf("History(5) = %v, want %v (bounded, oldest first)", got, want)
+	}
+	if h := s.History("claude", 2); len(h) != 2 || h[0].Reason != "four" || h[1].Type != "activity" {
+		t.Errorf("History(2) = %v, want the last two", h)
+	}
+	if h := s.History("unknown", 5); h != nil {
+		t.Errorf("History(unknown) = %v, want nil", h)
+	}
+}
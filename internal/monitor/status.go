@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"firebell/internal/notify"
+)
+
+// StatusSnapshot is a point-in-time copy of the watcher's state for debugging.
+type StatusSnapshot struct {
+	Time      time.Time          `json:"time"`
+	Agents    []AgentSnapshot    `json:"agents"`
+	Instances []InstanceSnapshot `json:"instances,omitempty"`
+	Process   ProcessSnapshot    `json:"process"`
+	Panics    int                `json:"panics,omitempty"` // Panics recovered while matching or handling events
+
+	Notifiers []notify.NotifierStatus `json:"notifiers,omitempty"` // Last send result per notifier
+	Dropped   int                     `json:"dropped,omitempty"`   // Notifications dropped by output.max_per_minute
+}
+
+// AgentSnapshot is the snapshot of one agent's state.
+type AgentSnapshot struct {
+	Name          string    `json:"name"`
+	DisplayName   string    `json:"display_name"`
+	LastCue       time.Time `json:"last_cue,omitempty"`
+	LastCueType   string    `json:"last_cue_type"`
+	QuietNotified bool      `json:"quiet_notified"`
+	WatchedPaths  []string  `json:"watched_paths"`
+	LinesRead     int       `json:"lines_read"`
+	ParseErrors   int       `json:"parse_errors"`
+	Paused        bool      `json:"paused,omitempty"`
+}
+
+// InstanceSnapshot is the snapshot of one instance's state (per-instance mode).
+type InstanceSnapshot struct {
+	Agent         string    `json:"agent"`
+	DisplayName   string    `json:"display_name"`
+	FilePath      string    `json:"file_path"`
+	LastCue       time.Time `json:"last_cue,omitempty"`
+	LastCueType   string    `json:"last_cue_type"`
+	QuietNotified bool      `json:"quiet_notified"`
+	PID           int       `json:"pid,omitempty"` // Process writing the log (process tracking)
+}
+
+// ProcessSnapshot is the snapshot of the tracked process (PID 0 = none).
+type ProcessSnapshot struct {
+	PID        int     `json:"pid"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   int64   `json:"rss_bytes,omitempty"`
+}
+
+// Snapshot copies the current state. Agents and instances are sorted by name.
+func (s *State) Snapshot() *StatusSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := &StatusSnapshot{
+		Time:   s.clock.Now(),
+		Agents: make([]AgentSnapshot, 0, len(s.agents)),
+		Panics: s.panics,
+		Process: ProcessSnapshot{
+			PID:        s.process.PID,
+			CPUPercent: s.process.CPUPercent,
+		},
+	}
+	if s.process.LastSample != nil {
+		snap.Process.RSSBytes = s.process.LastSample.RSSBytes
+	}
+
+	for _, a := range s.agents {
+		snap.Agents = append(snap.Agents, AgentSnapshot{
+			Name:          a.Agent.Name,
+			DisplayName:   a.Agent.DisplayName,
+			LastCue:       a.LastCue,
+			LastCueType:   a.LastCueType.String(),
+			QuietNotified: a.QuietNotified,
+			WatchedPaths:  append([]string(nil), a.WatchedPaths...),
+			LinesRead:     a.LinesRead,
+			ParseErrors:   a.ParseErrors,
+			Paused:        a.Paused,
+		})
+	}
+	sort.Slice(snap.Agents, func(i, j int) bool { return snap.Agents[i].Name < snap.Agents[j].Name })
+
+	for _, inst := range s.instances {
+		snap.Instances = append(snap.Instances, InstanceSnapshot{
+			Agent:         inst.AgentName,
+			DisplayName:   inst.DisplayName,
+			FilePath:      inst.FilePath,
+			LastCue:       inst.LastCue,
+			LastCueType:   inst.LastCueType.String(),
+			QuietNotified: inst.QuietNotified,
+			PID:           inst.PID,
+		})
+	}
+	sort.Slice(snap.Instances, func(i, j int) bool { return snap.Instances[i].FilePath < snap.Instances[j].FilePath })
+
+	return snap
+}
+
+// Lines formats the snapshot as human-readable log lines.
+func (snap *StatusSnapshot) Lines() []string {
+	lines := []string{}
+	for _, a := range snap.Agents {
+		lines = append(lines, fmt.Sprintf("Agent %s: last cue %s (%s), quiet notified %t, watching %d file(s), %d malformed of %d line(s)",
+			a.DisplayName, formatCueTime(a.LastCue, snap.Time), a.LastCueType, a.QuietNotified, len(a.WatchedPaths), a.ParseErrors, a.LinesRead))
+		if a.Paused {
+			lines[len(lines)-1] += ", paused"
+		}
+		for _, p := range a.WatchedPaths {
+			lines = append(lines, "  "+p)
+		}
+	}
+	for _, inst := range snap.Instances {
+		lines = append(lines, fmt.Sprintf("Instance %s: last cue %s (%s), quiet notified %t",
+			inst.DisplayName, formatCueTime(inst.LastCue, snap.Time), inst.LastCueType, inst.QuietNotified))
+		if inst.PID > 0 {
+			lines[len(lines)-1] += fmt.Sprintf(", PID %d", inst.PID)
+		}
+	}
+	if snap.Process.PID > 0 {
+		lines = append(lines, fmt.Sprintf("Process: PID %d, CPU %.1f%%, RSS %d MB",
+			snap.Process.PID, snap.Process.CPUPercent, snap.Process.RSSBytes/(1024*1024)))
+	} else {
+		lines = append(lines, "Process: not tracked")
+	}
+	for _, n := range snap.Notifiers {
+		lines = append(lines, "Notifier "+n.String(snap.Time))
+	}
+	if snap.Dropped > 0 {
+		lines = append(lines, fmt.Sprintf("Rate limit dropped %d notification(s)", snap.Dropped))
+	}
+	if snap.Panics > 0 {
+		lines = append(lines, fmt.Sprintf("Recovered from %d panic(s)", snap.Panics))
+	}
+	return lines
+}
+
+// Summary returns a one-line summary of the snapshot.
+func (snap *StatusSnapshot) Summary() string {
+	names := make([]string, 0, len(snap.Agents))
+	for _, a := range snap.Agents {
+		names = append(names, a.DisplayName+"="+a.LastCueType)
+	}
+	return fmt.Sprintf("Status: %d agent(s) [%s], %d instance(s), PID %d",
+		len(snap.Agents), strings.Join(names, ", "), len(snap.Instances), snap.Process.PID)
+}
+
+// formatCueTime formats a cue time relative to now ("never" if unset).
+func formatCueTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return now.Sub(t).Round(time.Second).String() + " ago"
+}
+
+// healthReporter is implemented by notifiers that track per-notifier send
+// results, such as notify.MultiNotifier.
+type healthReporter interface {
+	NotifierHealth() []notify.NotifierStatus
+}
+
+// dropReporter is implemented by notifiers that apply output.max_per_minute,
+// such as notify.MultiNotifier.
+type dropReporter interface {
+	Dropped() int
+}
+
+// DumpStatus returns a snapshot of the watcher's current state, including
+// notifier health and rate-limit drops when the notifier tracks them.
+// It is safe to call while the watcher is running.
+func (w *Watcher) DumpStatus() *StatusSnapshot {
+	snap := w.state.Snapshot()
+	if h, ok := w.notifier.(healthReporter); ok {
+		snap.Notifiers = h.NotifierHealth()
+	}
+	if d, ok := w.notifier.(dropReporter); ok {
+		snap.Dropped = d.Dropped()
+	}
+	return snap
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"firebell/internal/config"
+	"firebell/internal/notify"
+)
+
+// NewReaderWatcher creates a PathWatcher that classifies lines read from r
+// (e.g. stdin piped from an agent) instead of tailing files. Lines are matched
+// as agent's, with its matcher and agents settings, or with the fallback
+// matcher if agent is empty. Notifications are displayed as name.
+func NewReaderWatcher(cfg *config.Config, notifier notify.Notifier, r io.Reader, agent, name string) *PathWatcher {
+	if agent == "" {
+		agent = pathWatchAgent
+	}
+	p := newPathWatcher(cfg, notifier, Agent{Name: agent, DisplayName: name})
+	p.reader = r
+	return p
+}
+
+// runReader processes lines from the stream as they arrive. When the stream
+// ends, a pending quiet-period notification is still sent once due; then it
+// returns nil.
+func (p *PathWatcher) runReader(ctx context.Context) error {
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- readStreamLines(p.reader, p.w.cfg.LineLimit(), func(line string) bool {
+			select {
+			case lines <- line:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	quietTicker := time.NewTicker(1 * time.Second)
+	defer quietTicker.Stop()
+
+	ended := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case line := <-lines:
+			p.w.processLines(ctx, p.agent, "", []string{line})
+
+		case err := <-readErr:
+			if err != nil {
+				return err
+			}
+			ended = true
+			if !p.quietPending() {
+				return nil
+			}
+
+		case <-quietTicker.C:
+			p.w.flushEntries(ctx)
+			p.w.checkQuietPeriods(ctx)
+			if ended && !p.quietPending() {
+				return nil
+			}
+		}
+	}
+}
+
+// readStreamLines calls emit with each line read from r, without its line
+// ending, until r ends or emit returns false. Lines longer than limit bytes
+// (if limit > 0) are skipped, as the Tailer skips them, rather than ending
+// the stream.
+func readStreamLines(r io.Reader, limit int, emit func(string) bool) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var line []byte
+	skipping := false // Discarding the rest of an oversized line
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !skipping {
+			line = append(line, chunk...)
+			if limit > 0 && len(bytes.TrimSuffix(line, []byte("\n"))) > limit {
+				Debugf("stream: line exceeds %d bytes; skipping to next newline", limit)
+				line, skipping = line[:0], true
+			}
+		}
+
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err != nil && err != io.EOF:
+			return err
+		}
+		if len(line) > 0 {
+			text := bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			if !emit(string(text)) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		line, skipping = line[:0], false
+	}
+}
+
+// quietPending reports whether an entry is held for continuation lines or
+// a cue is waiting for its quiet-period notification.
+func (p *PathWatcher) quietPending() bool {
+	if len(p.w.entries) > 0 {
+		return true
+	}
+	if !p.w.cfg.CompletionDetectionFor(p.agent) {
+		return false
+	}
+	a := p.w.state.GetAgent(p.agent)
+	return a != nil && !a.LastCue.IsZero() && !a.QuietNotified
+}
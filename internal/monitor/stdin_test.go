@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+)
+
+func TestReaderWatcher(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	cfg.Output.Verbosity = "verbose"
+	cfg.Monitor.QuietSeconds = 0
+
+	r, w := io.Pipe()
+	rec := &recordingNotifier{}
+	p := NewReaderWatcher(cfg, rec, r, "claude", "Claude Code")
+	defer p.Close()
+
+	done := make(chan error, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go func() { done <- p.Run(ctx) }()
+
+	// Lines are classified with the agent's matcher as they arrive
+	now := time.Now()
+	io.WriteString(w, claudeLine(now, "")+"\n")
+	io.WriteString(w, "not json at all\n")
+	io.WriteString(w, claudeLine(now, "end_turn")+"\n")
+	w.Close()
+
+	// The end of input still lets the quiet period notify, then Run returns
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+	titles := rec.titles()
+	want := []string{"Activity Detected", "Activity Detected", "Cooling"}
+	if len(titles) != len(want) {
+		t.Fatalf("Sent %v, want %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Errorf("Sent %v, want %v", titles, want)
+			break
+		}
+	}
+	if rec.sent[0].Message != "assistant response" || rec.sent[1].Message != "end turn" {
+		t.Errorf("Reasons = %q, %q; want assistant response, end turn", rec.sent[0].Message, rec.sent[1].Message)
+	}
+	if rec.sent[2].Agent != "Claude Code" {
+		t.Errorf("Agent = %q, want Claude Code", rec.sent[2].Agent)
+	}
+}
+
+func TestReaderWatcherAgentCompletionDetection(t *testing.T) {
+	now := time.Now()
+	input := claudeLine(now, "") + "\n" + claudeLine(now, "end_turn") + "\n"
+
+	for _, tc := range []struct {
+		off    string // Agent with agents.completion_detection off
+		cooled bool
+	}{
+		{"claude", false},
+		{"codex", true}, // Another agent's entry leaves claude on
+	} {
+		cfg := config.DefaultConfig()
+		cfg.Monitor.QuietSeconds = 0
+		cfg.Agents.CompletionDetection = map[string]bool{tc.off: false}
+
+		rec := &recordingNotifier{}
+		p := NewReaderWatcher(cfg, rec, strings.NewReader(input), "claude", "Claude Code")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := p.Run(ctx); err != nil {
+			t.Fatalf("%s off: Run returned %v", tc.off, err)
+		}
+		cancel()
+		p.Close()
+
+		if cooled := slices.Contains(rec.titles(), "Cooling"); cooled != tc.cooled {
+			t.Errorf("%s off: sent %v, cooled = %v, want %v", tc.off, rec.titles(), cooled, tc.cooled)
+		}
+	}
+}
+
+func TestReadStreamLines(t *testing.T) {
+	long := strings.Repeat("x", 100*1024) // Longer than the read buffer too
+	input := "first\r\n" + long + "\n\nlast"
+
+	var got []string
+	err := readStreamLines(strings.NewReader(input), 1024, func(line string) bool {
+		got = append(got, line)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("readStreamLines() = %v, want nil for an oversized line", err)
+	}
+	if want := []string{"first", "", "last"}; !slices.Equal(got, want) {
+		t.Errorf("lines = %q, want %q (oversized line skipped)", got, want)
+	}
+}
+
+func TestReaderWatcherNoCues(t *testing.T) {
+	cfg := config.DefaultConfig()
+	rec := &recordingNotifier{}
+	p := NewReaderWatcher(cfg, rec, emptyReader{}, "", "stdin")
+
+	// Input without cues ends at once, with nothing sent
+	start := time.Now()
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+	if rec.count() != 0 || time.Since(start) > time.Second {
+		t.Errorf("Sent %v after %v, want nothing at once", rec.titles(), time.Since(start))
+	}
+}
+
+// emptyReader is a stream that has already ended.
+type emptyReader struct{}
+
+func (emptyReader) Read([]byte) (int, error) { return 0, io.EOF }
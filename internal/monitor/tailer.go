@@ -3,14 +3,17 @@ package monitor
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"firebell/internal/config"
 	"firebell/internal/util"
 )
 
@@ -23,15 +26,31 @@ type Tailer struct {
 	pending string    // Buffered incomplete line
 	started bool      // Whether initial read/seek occurred
 	fromBeg bool      // Read from beginning vs skip to end
+	startAt int64     // File size at creation; the first open skips to here
+
+	// MaxLineBytes caps the buffered partial line (0 = unbounded). A line
+	// that outgrows it is dropped and reading resyncs at the next newline.
+	MaxLineBytes int
+	skipping     bool // Discarding the rest of an oversized line
+
+	target string // File opened for Path, with symlinks resolved
 }
 
 // NewTailer creates a new Tailer for the given path.
-// If fromBeginning is false, it will skip to the end of existing content.
+// If fromBeginning is false, it will skip the content that exists now;
+// anything written after creation (even before the first read) is returned.
+// A file that is empty or missing at creation is read from its start.
 func NewTailer(path string, fromBeginning bool) *Tailer {
-	return &Tailer{
+	t := &Tailer{
 		Path:    path,
 		fromBeg: fromBeginning,
 	}
+	if !fromBeginning {
+		if info, err := os.Stat(path); err == nil {
+			t.startAt = info.Size()
+		}
+	}
+	return t
 }
 
 // ensureFile opens the file if not already open.
@@ -40,18 +59,26 @@ func (t *Tailer) ensureFile() error {
 		return nil
 	}
 
-	f, err := os.Open(t.Path)
+	// Open a symlink's target, so a repointed link can be noticed
+	target, err := filepath.EvalSymlinks(t.Path)
+	if err != nil {
+		target = t.Path
+	}
+	f, err := os.Open(target)
 	if err != nil {
 		return err
 	}
 	t.file = f
+	t.target = target
 	t.offset = 0
 	t.pending = ""
+	t.skipping = false
 
-	// Skip to end if not reading from beginning (first open only)
+	// Skip existing content if not reading from beginning (first open only).
+	// If the file shrank since creation it was rewritten, so read it all.
 	if !t.fromBeg && !t.started {
-		if info, err := t.file.Stat(); err == nil {
-			t.offset = info.Size()
+		if info, err := t.file.Stat(); err == nil && info.Size() >= t.startAt {
+			t.offset = t.startAt
 			if _, err := t.file.Seek(t.offset, io.SeekStart); err != nil {
 				t.offset = 0
 				t.file.Seek(0, io.SeekStart)
@@ -62,17 +89,50 @@ func (t *Tailer) ensureFile() error {
 	return nil
 }
 
-// Reset closes the file and resets state.
+// Reset closes the file and resets state. A from-end tailer that is reopened
+// afterwards resumes at the last read position.
 func (t *Tailer) Reset() {
 	if t.file != nil {
 		t.file.Close()
 	}
+	if t.started {
+		t.startAt = t.offset
+	}
 	t.file = nil
 	t.offset = 0
 	t.pending = ""
+	t.skipping = false
 	t.started = false
 }
 
+// restart reopens the file at offset 0 after it was truncated to size.
+// A buffered partial line belonged to the old content; it is discarded so it
+// cannot be glued onto the first line of the new content.
+func (t *Tailer) restart(size int64) error {
+	if t.pending != "" {
+		Debugf("tailer: %s truncated to %d bytes; discarding %d-byte partial line", t.Path, size, len(t.pending))
+	} else {
+		Debugf("tailer: %s truncated to %d bytes; reading from start", t.Path, size)
+	}
+	t.pending = ""
+
+	t.Reset()
+	t.started = true // Reopen at offset 0, not the pre-truncation position
+	return t.ensureFile()
+}
+
+// skipTo moves the read position to offset, dropping any partial line, so
+// content already seen in another form isn't returned again.
+func (t *Tailer) skipTo(offset int64) error {
+	if err := t.ensureFile(); err != nil {
+		return err
+	}
+	t.offset = offset
+	t.pending = ""
+	t.skipping = false
+	return nil
+}
+
 // Close closes the tailer.
 func (t *Tailer) Close() error {
 	if t.file != nil {
@@ -83,8 +143,34 @@ func (t *Tailer) Close() error {
 
 // ReadNewLines reads newly appended lines from the log file since last read.
 // Returns complete lines only; incomplete lines are buffered.
-// Detects log rotation by comparing file size to saved offset.
+// Detects log rotation by comparing file size to saved offset. If Path is a
+// symlink that now points at another file, the rest of the old file is
+// read, then the new one from its start.
 func (t *Tailer) ReadNewLines() ([]string, error) {
+	lines, err := t.readNewLines()
+	if err != nil || !t.repointed() {
+		return lines, err
+	}
+
+	Debugf("tailer: %s now points to a new file; reading it from the start", t.Path)
+	t.Reset()
+	t.started = true // Open the new target at offset 0
+	more, err := t.readNewLines()
+	return append(lines, more...), err
+}
+
+// repointed reports whether Path resolves to a different file than the one
+// open, as when a current.log symlink is moved to a new log.
+func (t *Tailer) repointed() bool {
+	if t.file == nil {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(t.Path)
+	return err == nil && target != t.target
+}
+
+// readNewLines reads the lines appended to the open file.
+func (t *Tailer) readNewLines() ([]string, error) {
 	if err := t.ensureFile(); err != nil {
 		return nil, err
 	}
@@ -95,10 +181,14 @@ func (t *Tailer) ReadNewLines() ([]string, error) {
 		return nil, err
 	}
 
-	// Detect rotation: if file size is smaller than our offset
+	// Detect truncation or rotation: if file size is smaller than our offset.
+	// Everything in the file is unread, so reopen it from the start.
 	if info.Size() < t.offset {
-		t.Reset()
-		if err := t.ensureFile(); err != nil {
+		if err := t.restart(info.Size()); err != nil {
+			return nil, err
+		}
+		if info, err = t.file.Stat(); err != nil {
+			t.Reset()
 			return nil, err
 		}
 	}
@@ -118,36 +208,69 @@ func (t *Tailer) ReadNewLines() ([]string, error) {
 	buf := util.GetBuffer()
 	defer util.PutBuffer(buf)
 
-	var accumulated bytes.Buffer
+	var lines []string
+	var line bytes.Buffer
+	line.WriteString(t.pending)
 	reader := bufio.NewReader(t.file)
 
 	for {
 		n, readErr := reader.Read(*buf)
 		if n > 0 {
-			accumulated.Write((*buf)[:n])
+			lines = t.splitChunk((*buf)[:n], &line, lines)
 			t.offset += int64(n)
 		}
 		if errors.Is(readErr, io.EOF) {
 			break
 		}
 		if readErr != nil {
+			t.pending = line.String()
 			return nil, readErr
 		}
 	}
 
-	// Split into lines, preserving pending partial line
-	data := t.pending + accumulated.String()
-	lines := strings.Split(data, "\n")
+	// Buffer the incomplete trailing line, if any
+	t.pending = line.String()
+	return lines, nil
+}
 
-	// If data doesn't end with newline, buffer the incomplete line
-	if !strings.HasSuffix(data, "\n") && len(lines) > 0 {
-		t.pending = lines[len(lines)-1]
-		lines = lines[:len(lines)-1]
-	} else {
-		t.pending = ""
+// splitChunk appends the complete lines in chunk to lines, carrying the
+// trailing partial line in line. Lines longer than MaxLineBytes are dropped
+// as soon as they exceed it, so a huge newline-less write cannot grow the
+// buffer without bound.
+func (t *Tailer) splitChunk(chunk []byte, line *bytes.Buffer, lines []string) []string {
+	for len(chunk) > 0 {
+		i := bytes.IndexByte(chunk, '\n')
+		if i < 0 {
+			if !t.skipping {
+				line.Write(chunk)
+				t.capLine(line)
+			}
+			break
+		}
+
+		if !t.skipping {
+			line.Write(chunk[:i])
+			if !t.capLine(line) {
+				lines = append(lines, line.String())
+			}
+		}
+		t.skipping = false // The newline ends any oversized line
+		line.Reset()
+		chunk = chunk[i+1:]
 	}
+	return lines
+}
 
-	return lines, nil
+// capLine discards line and starts skipping to the next newline if it is
+// longer than MaxLineBytes. It reports whether the line was discarded.
+func (t *Tailer) capLine(line *bytes.Buffer) bool {
+	if t.MaxLineBytes <= 0 || line.Len() <= t.MaxLineBytes {
+		return false
+	}
+	Debugf("tailer: %s line exceeds %d bytes; skipping to next newline", t.Path, t.MaxLineBytes)
+	line.Reset()
+	t.skipping = true
+	return true
 }
 
 // TailSnippet reads the last N lines from a file for context.
@@ -206,8 +329,22 @@ type FileEntry struct {
 
 // FindRecentFiles finds the most recently modified files in a directory.
 // Returns up to limit files, sorted by modification time (newest first).
-// Only includes files with allowed extensions: .log, .txt, .json, .jsonl
-func FindRecentFiles(basePath string, maxDepth, limit int) []FileEntry {
+// Only includes files with DefaultLogExtensions: .log, .txt, .json, .jsonl
+// Files matching any ignore glob (see IsIgnored) are skipped.
+func FindRecentFiles(basePath string, maxDepth, limit int, ignore ...string) []FileEntry {
+	return FindRecentFilesWithin(nil, nil, basePath, maxDepth, limit, ignore...)
+}
+
+// FindRecentFilesWithin is FindRecentFiles restricted to advanced.allowed_roots:
+// a base path or file outside roots (including through a symlink) is skipped.
+// Empty roots allow every path. Only files with one of exts are included.
+func FindRecentFilesWithin(roots []string, exts LogExtensions, basePath string, maxDepth, limit int, ignore ...string) []FileEntry {
+	allowed := resolveRoots(roots)
+	if err := allowed.check(basePath); err != nil {
+		Debugf("not scanning %s: %v", basePath, err)
+		return nil
+	}
+
 	info, err := os.Stat(basePath)
 	if err != nil {
 		return nil
@@ -215,7 +352,7 @@ func FindRecentFiles(basePath string, maxDepth, limit int) []FileEntry {
 
 	// If it's a file, check extension and return
 	if !info.IsDir() {
-		if hasLogExtension(basePath) {
+		if exts.Match(basePath) && !IsIgnored(basePath, ignore) {
 			return []FileEntry{{Path: basePath, ModTime: info.ModTime()}}
 		}
 		return nil
@@ -223,6 +360,7 @@ func FindRecentFiles(basePath string, maxDepth, limit int) []FileEntry {
 
 	// Walk directory and collect files
 	var entries []FileEntry
+	links := make(map[string]string) // Symlinked file -> its target
 	baseDepth := strings.Count(basePath, string(os.PathSeparator))
 
 	filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
@@ -244,14 +382,50 @@ func FindRecentFiles(basePath string, maxDepth, limit int) []FileEntry {
 		}
 
 		// Check extension
-		if !hasLogExtension(path) {
+		if !exts.Match(path) {
 			return nil
 		}
 
-		entries = append(entries, FileEntry{Path: path, ModTime: info.ModTime()})
+		if IsIgnored(path, ignore) {
+			return nil
+		}
+
+		// A symlinked file may point outside the roots, and is as recent as
+		// its target
+		modTime := info.ModTime()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if err := allowed.check(path); err != nil {
+				Debugf("not tailing %s: %v", path, err)
+				return nil
+			}
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return nil // Dangling
+			}
+			links[path] = target
+			if st, err := os.Stat(target); err == nil {
+				modTime = st.ModTime()
+			}
+		}
+
+		entries = append(entries, FileEntry{Path: path, ModTime: modTime})
 		return nil
 	})
 
+	// A link to a file that was found too (current.log -> 2025-01-15.log)
+	// would tail it twice; keep the target, which rotation replaces
+	if len(links) > 0 {
+		found := make(map[string]bool, len(entries))
+		for _, e := range entries {
+			if _, ok := links[e.Path]; !ok {
+				found[e.Path] = true
+			}
+		}
+		entries = slices.DeleteFunc(entries, func(e FileEntry) bool {
+			return found[links[e.Path]]
+		})
+	}
+
 	// Sort by modification time (newest first)
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].ModTime.After(entries[j].ModTime)
@@ -265,13 +439,37 @@ func FindRecentFiles(basePath string, maxDepth, limit int) []FileEntry {
 	return entries
 }
 
+// IsIgnored reports whether path matches any of the glob patterns.
+// Patterns without a path separator match the file name (e.g. "debug.log",
+// "*.tmp.jsonl"); others match the full path, with ~ expanded.
+func IsIgnored(path string, patterns []string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range patterns {
+		target := name
+		if strings.ContainsRune(pattern, filepath.Separator) {
+			pattern = config.ExpandPath(pattern)
+			target = path
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // TailerManager manages multiple tailers for an agent.
 type TailerManager struct {
 	BasePath   string
 	MaxFiles   int
 	MaxDepth   int
 	FromBeg    bool
-	tailers    map[string]*Tailer
+	Ignore     []string // Glob patterns of files not to tail
+	MaxLine    int      // Passed to each Tailer as MaxLineBytes
+	Allowed    []string // advanced.allowed_roots; files outside are not tailed (empty = all)
+	WholeFile  bool     // Files are rewritten whole; a rewrite with unchanged content is skipped
+	Extensions []string // advanced.log_extensions; files without one are not tailed (empty = defaults)
+	tailers    map[string]lineReader
+	sums       map[string]fileSum // Last content seen per path (WholeFile only)
 	lastScan   time.Time
 	scanTTL    time.Duration
 }
@@ -283,14 +481,74 @@ func NewTailerManager(basePath string, maxFiles, maxDepth int, fromBeg bool) *Ta
 		MaxFiles: maxFiles,
 		MaxDepth: maxDepth,
 		FromBeg:  fromBeg,
-		tailers:  make(map[string]*Tailer),
+		tailers:  make(map[string]lineReader),
+		sums:     make(map[string]fileSum),
 		scanTTL:  5 * time.Second, // Cache scan results for 5s
 	}
 }
 
+// fileSum identifies a file's content: its size and modification time, and
+// a checksum to tell a real change from a rewrite of the same bytes.
+type fileSum struct {
+	size int64
+	mod  time.Time
+	hash [sha256.Size]byte
+}
+
+// unchangedRewrite reports whether the file behind t was rewritten with the
+// content it had when last checked, in which case t is moved past it. Agents
+// that rewrite a whole JSON file (e.g. Gemini on a cursor move) would
+// otherwise be re-read from the start after an apparent truncation.
+func (m *TailerManager) unchangedRewrite(path string, t *Tailer) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	last, seen := m.sums[path]
+	if seen && info.Size() == last.size && info.ModTime().Equal(last.mod) {
+		return false // Untouched; the tailer has nothing new either
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return false // An empty file is a rewrite in progress; keep the last sum
+	}
+	sum := fileSum{size: int64(len(data)), mod: info.ModTime(), hash: sha256.Sum256(data)}
+	m.sums[path] = sum
+	if !seen || sum.hash != last.hash {
+		return false
+	}
+
+	Debugf("tailer: %s rewritten with unchanged content; skipping", path)
+	if err := t.skipTo(sum.size); err != nil {
+		t.Reset()
+	}
+	return true
+}
+
+// newStreamTailer creates the reader for a journald:// or docker:// base path.
+func (m *TailerManager) newStreamTailer() lineReader {
+	if IsDockerPath(m.BasePath) {
+		tailer := NewDockerTailer(m.BasePath)
+		tailer.MaxLineBytes = m.MaxLine
+		return tailer
+	}
+	tailer := NewJournaldTailer(m.BasePath)
+	tailer.MaxLineBytes = m.MaxLine
+	return tailer
+}
+
 // RefreshFiles updates the watched files based on recent activity.
-// Uses caching to avoid rescanning on every call.
+// Uses caching to avoid rescanning on every call. A journald:// or
+// docker:// base path is its single source.
 func (m *TailerManager) RefreshFiles() []string {
+	if IsStreamPath(m.BasePath) {
+		if _, ok := m.tailers[m.BasePath]; !ok {
+			m.tailers[m.BasePath] = m.newStreamTailer()
+		}
+		return []string{m.BasePath}
+	}
+
 	// Check cache
 	if time.Since(m.lastScan) < m.scanTTL {
 		paths := make([]string, 0, len(m.tailers))
@@ -301,7 +559,7 @@ func (m *TailerManager) RefreshFiles() []string {
 	}
 
 	// Find recent files
-	entries := FindRecentFiles(m.BasePath, m.MaxDepth, m.MaxFiles)
+	entries := FindRecentFilesWithin(m.Allowed, m.Extensions, m.BasePath, m.MaxDepth, m.MaxFiles, m.Ignore...)
 	m.lastScan = time.Now()
 
 	// Build desired set
@@ -315,13 +573,16 @@ func (m *TailerManager) RefreshFiles() []string {
 		if !desired[path] {
 			tailer.Close()
 			delete(m.tailers, path)
+			delete(m.sums, path)
 		}
 	}
 
 	// Add tailers for new files
 	for path := range desired {
 		if _, ok := m.tailers[path]; !ok {
-			m.tailers[path] = NewTailer(path, m.FromBeg)
+			tailer := NewTailer(path, m.FromBeg)
+			tailer.MaxLineBytes = m.MaxLine
+			m.tailers[path] = tailer
 		}
 	}
 
@@ -339,9 +600,13 @@ func (m *TailerManager) ReadAllNew() map[string][]string {
 	result := make(map[string][]string)
 
 	for path, tailer := range m.tailers {
+		if t, ok := tailer.(*Tailer); ok && m.WholeFile && m.unchangedRewrite(path, t) {
+			continue
+		}
 		lines, err := tailer.ReadNewLines()
 		if err != nil {
 			// Reset tailer on error
+			Debugf("tailer: %s: %v", path, err)
 			tailer.Reset()
 			continue
 		}
@@ -358,5 +623,6 @@ func (m *TailerManager) Close() {
 	for _, tailer := range m.tailers {
 		tailer.Close()
 	}
-	m.tailers = make(map[string]*Tailer)
+	m.tailers = make(map[string]lineReader)
+	m.sums = make(map[string]fileSum)
 }
@@ -1,8 +1,11 @@
 package monitor
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -505,6 +508,42 @@ func TestFindRecentFilesExtensions(t *testing.T) {
 	}
 }
 
+func TestFindRecentFilesCustomExtensions(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"agent.ndjson", "run.OUT", "session.jsonl", "notes.md"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found := func(entries []FileEntry) []string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, filepath.Base(e.Path))
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	// Configured extensions replace the defaults; the dot is optional
+	entries := FindRecentFilesWithin(nil, LogExtensions{".ndjson", "out"}, tmpDir, 1, 10)
+	if got := found(entries); !slices.Equal(got, []string{"agent.ndjson", "run.OUT"}) {
+		t.Errorf("custom extensions found %v", got)
+	}
+
+	// Unset, the defaults apply
+	if got := found(FindRecentFilesWithin(nil, nil, tmpDir, 1, 10)); !slices.Equal(got, []string{"session.jsonl"}) {
+		t.Errorf("default extensions found %v", got)
+	}
+
+	// The tailer manager discovers by its configured set
+	m := NewTailerManager(tmpDir, 10, 1, false)
+	m.Extensions = []string{".ndjson"}
+	if got := m.RefreshFiles(); len(got) != 1 || filepath.Base(got[0]) != "agent.ndjson" {
+		t.Errorf("RefreshFiles() = %v, want agent.ndjson", got)
+	}
+	m.Close()
+}
+
 func TestFindRecentFilesSingleFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "test.log")
@@ -656,3 +695,580 @@ func TestTailerManagerReadFromBeginning(t *testing.T) {
 		t.Error("Expected to have content for log1")
 	}
 }
+
+func TestFindRecentFilesIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	session := filepath.Join(tmpDir, "session.jsonl")
+	debug := filepath.Join(tmpDir, "debug.log")
+	for _, path := range []string{session, debug} {
+		if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := FindRecentFiles(tmpDir, 1, 10, "debug.log")
+	if len(entries) != 1 || entries[0].Path != session {
+		t.Errorf("Expected only %s, got %v", session, entries)
+	}
+
+	// Ignored newest file doesn't consume the limit
+	entries = FindRecentFiles(tmpDir, 1, 1, "*.log")
+	if len(entries) != 1 || entries[0].Path != session {
+		t.Errorf("Expected %s within limit, got %v", session, entries)
+	}
+
+	// Full-path pattern
+	entries = FindRecentFiles(tmpDir, 1, 10, filepath.Join(tmpDir, "*.jsonl"))
+	if len(entries) != 1 || entries[0].Path != debug {
+		t.Errorf("Expected only %s, got %v", debug, entries)
+	}
+
+	// Ignored single file
+	if entries := FindRecentFiles(debug, 0, 10, "debug.log"); len(entries) != 0 {
+		t.Errorf("Expected ignored file path to yield nothing, got %v", entries)
+	}
+}
+
+func TestTailerManagerIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	session := filepath.Join(tmpDir, "session.jsonl")
+	debug := filepath.Join(tmpDir, "debug.log")
+	for _, path := range []string{session, debug} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mgr := NewTailerManager(tmpDir, 5, 1, false)
+	mgr.Ignore = []string{"debug.log"}
+	defer mgr.Close()
+
+	paths := mgr.RefreshFiles()
+	if len(paths) != 1 || paths[0] != session {
+		t.Fatalf("Expected only session tailed, got %v", paths)
+	}
+
+	// Establish offsets, then append to both files
+	mgr.ReadAllNew()
+	for _, path := range []string{session, debug} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString("task complete\n")
+		f.Close()
+	}
+
+	newLines := mgr.ReadAllNew()
+	if _, ok := newLines[debug]; ok {
+		t.Error("Ignored file should not be tailed")
+	}
+	if lines := newLines[session]; len(lines) == 0 || lines[0] != "task complete" {
+		t.Errorf("Expected new session line, got %v", lines)
+	}
+}
+
+func TestTailerEmptyThenGrows(t *testing.T) {
+	for _, fromBeg := range []bool{true, false} {
+		testFile := filepath.Join(t.TempDir(), "test.jsonl")
+		if err := os.WriteFile(testFile, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		tailer := NewTailer(testFile, fromBeg)
+
+		// First batch is written before the tailer's first read
+		if err := os.WriteFile(testFile, []byte("line1\nline2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		lines, err := tailer.ReadNewLines()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) < 2 || lines[0] != "line1" || lines[1] != "line2" {
+			t.Errorf("fromBeg=%v: first read = %q, want line1, line2", fromBeg, lines)
+		}
+
+		f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString("line3\n")
+		f.Close()
+
+		lines, err = tailer.ReadNewLines()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) < 1 || lines[0] != "line3" {
+			t.Errorf("fromBeg=%v: second read = %q, want line3", fromBeg, lines)
+		}
+		tailer.Close()
+	}
+}
+
+func TestTailerEmptyReadThenGrows(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl")
+	if err := os.WriteFile(testFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, false)
+	defer tailer.Close()
+
+	lines, err := tailer.ReadNewLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("Expected 0 lines from empty file, got %q", lines)
+	}
+
+	if err := os.WriteFile(testFile, []byte("line1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines, err = tailer.ReadNewLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) < 1 || lines[0] != "line1" {
+		t.Errorf("Expected line1 after growth, got %q", lines)
+	}
+}
+
+func TestTailerRotationFromEnd(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(testFile, []byte(strings.Repeat("old\n", 10)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, false)
+	defer tailer.Close()
+	if _, err := tailer.ReadNewLines(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotated file is shorter than the old offset; all of it is new
+	if err := os.WriteFile(testFile, []byte("new1\nnew2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := tailer.ReadNewLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) < 2 || lines[0] != "new1" || lines[1] != "new2" {
+		t.Errorf("Expected new1, new2 after rotation, got %q", lines)
+	}
+}
+
+// appendTo appends text to the file at path.
+func appendTo(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// repoint atomically replaces the symlink at link with one to target, as
+// `ln -sf` does.
+func repoint(t *testing.T, link, target string) {
+	t.Helper()
+	tmp := link + ".tmp"
+	if err := os.Symlink(target, tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTailerFollowsRepointedSymlink(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "firebell-2025-01-15.log")
+	next := filepath.Join(dir, "firebell-2025-01-16.log")
+	link := filepath.Join(dir, "current.log")
+	if err := os.WriteFile(old, []byte("seen\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(old, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(link, false)
+	defer tailer.Close()
+	appendTo(t, old, "a\n")
+	if lines, err := tailer.ReadNewLines(); err != nil || !slices.Equal(lines, []string{"a"}) {
+		t.Fatalf("before rotation: %q, %v", lines, err)
+	}
+
+	// Rotation: the last of the old file, then all of the new one
+	if err := os.WriteFile(next, []byte("c\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(t, old, "b\n")
+	repoint(t, link, next)
+	if lines, err := tailer.ReadNewLines(); err != nil || !slices.Equal(lines, []string{"b", "c"}) {
+		t.Fatalf("at rotation: %q, %v, want b, c", lines, err)
+	}
+
+	// Only the new target is followed from now on
+	appendTo(t, old, "stale\n")
+	appendTo(t, next, "d\n")
+	if lines, err := tailer.ReadNewLines(); err != nil || !slices.Equal(lines, []string{"d"}) {
+		t.Errorf("after rotation: %q, %v, want d", lines, err)
+	}
+}
+
+func TestFindRecentFilesSymlinks(t *testing.T) {
+	dir, elsewhere := t.TempDir(), t.TempDir()
+	target := filepath.Join(dir, "firebell-2025-01-15.log")
+	outside := filepath.Join(elsewhere, "agent.log")
+	for _, path := range []string{target, outside} {
+		if err := os.WriteFile(path, []byte("content\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "current.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linked.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing.log"), filepath.Join(dir, "dangling.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A link to a file found anyway is dropped so it isn't tailed twice; a
+	// link elsewhere is kept, and a dangling one skipped
+	var names []string
+	for _, e := range FindRecentFiles(dir, 1, 10) {
+		names = append(names, filepath.Base(e.Path))
+	}
+	slices.Sort(names)
+	if want := []string{"firebell-2025-01-15.log", "linked.log"}; !slices.Equal(names, want) {
+		t.Errorf("FindRecentFiles = %v, want %v", names, want)
+	}
+}
+
+func TestTailerResetResumesFromEnd(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(testFile, []byte("line1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, false)
+	defer tailer.Close()
+	if _, err := tailer.ReadNewLines(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("line2\n")
+	f.Close()
+
+	tailer.Reset()
+	lines, err := tailer.ReadNewLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) < 1 || lines[0] != "line2" {
+		t.Errorf("Expected line2 after reset, got %q", lines)
+	}
+}
+
+// readLines is ReadNewLines without blank lines.
+func readLines(tailer *Tailer) ([]string, error) {
+	lines, err := tailer.ReadNewLines()
+	var out []string
+	for _, line := range lines {
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out, err
+}
+
+func TestTailerTruncateWithPendingLine(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("line1\npartial-old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var notes []string
+	origDebugf := Debugf
+	Debugf = func(format string, args ...any) {
+		notes = append(notes, fmt.Sprintf(format, args...))
+	}
+	defer func() { Debugf = origDebugf }()
+
+	tailer := NewTailer(testFile, true)
+	defer tailer.Close()
+
+	lines, err := readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0] != "line1" {
+		t.Fatalf("Expected [line1] with partial line pending, got %q", lines)
+	}
+
+	// Truncate in place (same inode) and write fresh content shorter than
+	// the old offset
+	f, err := os.OpenFile(testFile, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("new1\nnew")
+	f.Close()
+
+	lines, err = readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0] != "new1" {
+		t.Errorf("Expected [new1] after truncation, got %q", lines)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "discarding") {
+		t.Errorf("Expected one debug note about the discarded partial line, got %q", notes)
+	}
+
+	// The new partial line is still buffered and completes normally
+	f, err = os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("2\n")
+	f.Close()
+
+	lines, err = readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0] != "new2" {
+		t.Errorf("Expected [new2], got %q", lines)
+	}
+}
+
+func TestTailerTruncateToEmpty(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.log")
+
+	if err := os.WriteFile(testFile, []byte("line1\npartial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, true)
+	defer tailer.Close()
+	if _, err := tailer.ReadNewLines(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Truncate(testFile, 0); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("Expected no lines from an empty file, got %q", lines)
+	}
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("fresh\n")
+	f.Close()
+
+	lines, err = readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0] != "fresh" {
+		t.Errorf("Expected [fresh], got %q", lines)
+	}
+}
+
+func TestTailerCapsPendingLine(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl")
+	if err := os.WriteFile(testFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tailer := NewTailer(testFile, true)
+	tailer.MaxLineBytes = 64 * 1024
+	defer tailer.Close()
+
+	f, err := os.OpenFile(testFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Several large newline-less writes never grow the buffer past the cap
+	chunk := strings.Repeat("x", 1024*1024)
+	for i := 0; i < 5; i++ {
+		f.WriteString(chunk)
+		lines, err := readLines(tailer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 0 {
+			t.Fatalf("write %d: got %d lines from an unterminated line", i, len(lines))
+		}
+		if len(tailer.pending) > tailer.MaxLineBytes {
+			t.Fatalf("write %d: pending = %d bytes, want <= %d", i, len(tailer.pending), tailer.MaxLineBytes)
+		}
+	}
+
+	// The tail of the oversized line is dropped; reading resumes after it
+	f.WriteString("tail of the huge line\nnext\npartial")
+	lines, err := readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0] != "next" {
+		t.Errorf("after resync = %q, want [next]", lines)
+	}
+	if tailer.pending != "partial" {
+		t.Errorf("pending = %q, want partial", tailer.pending)
+	}
+
+	// A complete line over the cap in a single write is dropped too
+	f.WriteString(strings.Repeat("y", 2*tailer.MaxLineBytes) + "\nafter\n")
+	lines, err = readLines(tailer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || lines[0] != "after" {
+		t.Errorf("after oversized line = %q, want [after]", lines)
+	}
+}
+
+func TestFindRecentFilesWithin(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "allowed")
+	outside := filepath.Join(root, "secret")
+	for _, dir := range []string{inside, outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "session.jsonl"), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A symlinked log inside the root pointing out of it
+	if err := os.Symlink(filepath.Join(outside, "session.jsonl"), filepath.Join(inside, "escape.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := []string{inside}
+	entries := FindRecentFilesWithin(roots, nil, inside, 1, 10)
+	if len(entries) != 1 || filepath.Base(entries[0].Path) != "session.jsonl" {
+		t.Errorf("Entries inside root = %v, want only session.jsonl", entries)
+	}
+	if entries := FindRecentFilesWithin(roots, nil, outside, 1, 10); len(entries) != 0 {
+		t.Errorf("Scanned a path outside the roots: %v", entries)
+	}
+	if entries := FindRecentFilesWithin(nil, nil, inside, 1, 10); len(entries) != 2 {
+		t.Errorf("No roots: got %d entries, want 2", len(entries))
+	}
+
+	// The manager applies its roots too
+	m := NewTailerManager(outside, 10, 1, false)
+	m.Allowed = roots
+	if paths := m.RefreshFiles(); len(paths) != 0 {
+		t.Errorf("Manager tailed %v outside the roots", paths)
+	}
+}
+
+func TestCheckAllowedPath(t *testing.T) {
+	root := t.TempDir()
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{root, true},
+		{filepath.Join(root, "a", "b"), true},
+		{filepath.Join(root, "..", "other"), false},
+		{root + "-sibling", false},
+		{"/etc", false},
+	}
+	for _, tt := range tests {
+		err := CheckAllowedPath(tt.path, []string{root})
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckAllowedPath(%q) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrOutsideRoots) {
+			t.Errorf("CheckAllowedPath(%q) error %v does not wrap ErrOutsideRoots", tt.path, err)
+		}
+	}
+	if err := CheckAllowedPath("/etc", nil); err != nil {
+		t.Errorf("Empty roots rejected /etc: %v", err)
+	}
+}
+
+func TestTailerManagerWholeFileChecksum(t *testing.T) {
+	tmpDir := t.TempDir()
+	session := filepath.Join(tmpDir, "session.json")
+	content := "{\n  \"messages\": [\n    {\"type\": \"gemini\", \"content\": \"done\"}\n  ]\n}\n"
+	if err := os.WriteFile(session, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewTailerManager(tmpDir, 5, 1, true)
+	mgr.WholeFile = true
+	mgr.RefreshFiles()
+	if lines := mgr.ReadAllNew()[session]; len(lines) != 5 {
+		t.Fatalf("Expected 5 lines on first read, got %q", lines)
+	}
+
+	// A rewrite with the same content, caught mid-write, isn't re-read
+	if err := os.Truncate(session, 0); err != nil {
+		t.Fatal(err)
+	}
+	if lines := mgr.ReadAllNew()[session]; len(lines) != 0 {
+		t.Errorf("Expected no lines from the truncated file, got %q", lines)
+	}
+	future := time.Now().Add(time.Second)
+	if err := os.WriteFile(session, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(session, future, future)
+	if lines := mgr.ReadAllNew()[session]; len(lines) != 0 {
+		t.Errorf("Expected an identical rewrite to be skipped, got %q", lines)
+	}
+
+	// A rewrite that changes the content is still read
+	more := strings.Replace(content, "  ]", "  ,{\"type\": \"user\", \"content\": \"next\"}\n  ]", 1)
+	if err := os.WriteFile(session, []byte(more), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if lines := mgr.ReadAllNew()[session]; len(lines) == 0 {
+		t.Error("Expected lines after the content changed")
+	}
+
+	// Without WholeFile, the same identical rewrite is read again
+	plain := NewTailerManager(tmpDir, 5, 1, true)
+	plain.RefreshFiles()
+	plain.ReadAllNew()
+	os.Truncate(session, 0)
+	plain.ReadAllNew()
+	os.WriteFile(session, []byte(more), 0644)
+	if lines := plain.ReadAllNew()[session]; len(lines) == 0 {
+		t.Error("Expected a plain manager to re-read the rewritten file")
+	}
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// TopRow is one agent's line in `firebell top`.
+type TopRow struct {
+	Name        string
+	State       string        // "paused", "idle", or the last cue type
+	LinesPerSec float64       // Log lines read per second since the previous snapshot
+	CueAge      time.Duration // Time since the last cue (-1 = never)
+}
+
+// TopRows computes each agent's row of cur, with line rates measured
+// against prev (nil for the first snapshot, giving rates of 0).
+func TopRows(prev, cur *StatusSnapshot) []TopRow {
+	before := make(map[string]int)
+	var elapsed float64
+	if prev != nil {
+		for _, a := range prev.Agents {
+			before[a.Name] = a.LinesRead
+		}
+		elapsed = cur.Time.Sub(prev.Time).Seconds()
+	}
+
+	rows := make([]TopRow, 0, len(cur.Agents))
+	for _, a := range cur.Agents {
+		row := TopRow{Name: a.DisplayName, State: topState(a), CueAge: -1}
+		if !a.LastCue.IsZero() {
+			row.CueAge = cur.Time.Sub(a.LastCue)
+		}
+		// A counter that went backwards is a restarted daemon; skip that round
+		if n, ok := before[a.Name]; ok && elapsed > 0 && a.LinesRead >= n {
+			row.LinesPerSec = float64(a.LinesRead-n) / elapsed
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// topState summarizes an agent's state: paused, idle once its quiet-period
+// notification was sent (or before any cue), else the last cue type.
+func topState(a AgentSnapshot) string {
+	switch {
+	case a.Paused:
+		return "paused"
+	case a.QuietNotified || a.LastCue.IsZero():
+		return "idle"
+	default:
+		return a.LastCueType
+	}
+}
+
+// FormatTop renders the `firebell top` screen for cur: a header, the
+// tracked process, and one row per agent.
+func FormatTop(prev, cur *StatusSnapshot) []string {
+	lines := []string{fmt.Sprintf("firebell top - %s", cur.Time.Format("15:04:05"))}
+	if cur.Process.PID > 0 {
+		lines = append(lines, fmt.Sprintf("Process: PID %d, CPU %.1f%%, RSS %d MB",
+			cur.Process.PID, cur.Process.CPUPercent, cur.Process.RSSBytes/(1024*1024)))
+	} else {
+		lines = append(lines, "Process: not tracked")
+	}
+	lines = append(lines, "", fmt.Sprintf("%-16s %-10s %9s  %s", "AGENT", "STATE", "LINES/S", "LAST CUE"))
+
+	rows := TopRows(prev, cur)
+	if len(rows) == 0 {
+		lines = append(lines, "(no agents monitored)")
+	}
+	for _, r := range rows {
+		age := "never"
+		if r.CueAge >= 0 {
+			age = r.CueAge.Round(time.Second).String() + " ago"
+		}
+		lines = append(lines, fmt.Sprintf("%-16s %-10s %9.1f  %s", r.Name, r.State, r.LinesPerSec, age))
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTopRows(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	prev := &StatusSnapshot{
+		Time: now.Add(-2 * time.Second),
+		Agents: []AgentSnapshot{
+			{Name: "claude", DisplayName: "Claude Code", LinesRead: 10},
+			{Name: "codex", DisplayName: "Codex", LinesRead: 50},
+		},
+	}
+	cur := &StatusSnapshot{
+		Time: now,
+		Agents: []AgentSnapshot{
+			{Name: "claude", DisplayName: "Claude Code", LinesRead: 30, LastCue: now.Add(-5 * time.Second), LastCueType: "holding"},
+			{Name: "codex", DisplayName: "Codex", LinesRead: 50, LastCue: now.Add(-time.Minute), LastCueType: "complete", QuietNotified: true},
+			{Name: "gemini", DisplayName: "Gemini", LinesRead: 7, Paused: true},
+		},
+	}
+
+	rows := TopRows(prev, cur)
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %+v", rows)
+	}
+	want := []TopRow{
+		{Name: "Claude Code", State: "holding", LinesPerSec: 10, CueAge: 5 * time.Second},
+		{Name: "Codex", State: "idle", LinesPerSec: 0, CueAge: time.Minute},
+		{Name: "Gemini", State: "paused", LinesPerSec: 0, CueAge: -1}, // New agent: no rate yet
+	}
+	for i, w := range want {
+		if rows[i] != w {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], w)
+		}
+	}
+
+	// The first snapshot has no rates
+	for _, r := range TopRows(nil, cur) {
+		if r.LinesPerSec != 0 {
+			t.Errorf("%s: rate %v without a previous snapshot", r.Name, r.LinesPerSec)
+		}
+	}
+
+	// A restarted daemon's counters go backwards; no negative rate
+	restarted := &StatusSnapshot{Time: now.Add(time.Second), Agents: []AgentSnapshot{{Name: "claude", DisplayName: "Claude Code", LinesRead: 2}}}
+	if r := TopRows(cur, restarted); r[0].LinesPerSec != 0 {
+		t.Errorf("rate after restart = %v, want 0", r[0].LinesPerSec)
+	}
+}
+
+func TestFormatTop(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	snap := &StatusSnapshot{
+		Time:    now,
+		Agents:  []AgentSnapshot{{Name: "claude", DisplayName: "Claude Code", LastCue: now.Add(-3 * time.Second), LastCueType: "activity"}},
+		Process: ProcessSnapshot{PID: 4242, CPUPercent: 12.5, RSSBytes: 100 * 1024 * 1024},
+	}
+	out := strings.Join(FormatTop(nil, snap), "\n")
+	for _, want := range []string{"PID 4242, CPU 12.5%, RSS 100 MB", "Claude Code", "activity", "3s ago"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatTop missing %q:\n%s", want, out)
+		}
+	}
+
+	empty := strings.Join(FormatTop(nil, &StatusSnapshot{Time: now}), "\n")
+	if !strings.Contains(empty, "Process: not tracked") || !strings.Contains(empty, "no agents monitored") {
+		t.Errorf("FormatTop of an empty snapshot:\n%s", empty)
+	}
+}
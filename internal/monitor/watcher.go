@@ -2,9 +2,13 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -14,6 +18,13 @@ import (
 	"firebell/internal/notify"
 )
 
+// loopWindow is how far back identical tool requests are counted for loop detection.
+const loopWindow = 5 * time.Minute
+
+// noLogFilesAfter is how long every watched log file must be gone before
+// "No log files found" is sent.
+const noLogFilesAfter = time.Minute
+
 // Watcher monitors log files for AI activity using fsnotify.
 type Watcher struct {
 	cfg      *config.Config
@@ -24,10 +35,35 @@ type Watcher struct {
 	// Per-agent resources
 	managers map[string]*TailerManager
 	matchers map[string]detect.Matcher
+	links    map[string]string // Symlinked log file -> its target, whose directory is watched too
 
 	// Process monitoring
-	procMon *ProcessMonitor
-	pidDone <-chan struct{} // Closed when monitored process exits
+	procMon  *ProcessMonitor
+	pidDone  <-chan struct{} // Closed when monitored process exits
+	exitedAt time.Time       // When it exited, while "Process Exit" is held back (see processExited)
+
+	// Per-instance process monitors, by PID (see sampleInstanceProcesses)
+	instProcs        map[int]*instanceProcess
+	lastInstanceScan time.Time
+
+	lastSeen   *lastSeenWriter // Persists last cue times (nil = off)
+	readyFile  string          // Written once Run is watching, removed when it returns ("" = off)
+	snoozeFile string          // Holds the time alerts resume (see SnoozeFile; "" = off)
+
+	watchFailed bool // A watch could not be added due to inotify limits; Run polls
+
+	// Log file presence across all agents (see checkLogFiles)
+	hadFiles     bool      // Log files have been found since start
+	noFilesSince time.Time // When the last one disappeared (zero = some exist)
+	noFilesSent  bool      // "No log files found" was sent for this stretch
+
+	sensitive *sensitiveTools // monitor.sensitive_tools
+	collapser *cueCollapser   // monitor.collapse_cues_ms
+
+	// Last entry of each log, held for continuation lines (see joinEntries)
+	entries map[cueSource]HistoryLine
+
+	clock Clock // Time source shared with state and procMon
 }
 
 // NewWatcher creates a new Watcher.
@@ -37,49 +73,116 @@ func NewWatcher(cfg *config.Config, notifier notify.Notifier, agents []Agent) (*
 		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
 	}
 
-	w := &Watcher{
-		cfg:      cfg,
-		state:    NewState(cfg.Monitor.PerInstance),
-		notifier: notifier,
-		fsw:      fsw,
-		managers: make(map[string]*TailerManager),
-		matchers: make(map[string]detect.Matcher),
-	}
+	w := newWatcher(cfg, notifier, cfg.Monitor.PerInstance.IsOn())
+	w.fsw = fsw
 
 	// Initialize process monitor if enabled
 	if cfg.Monitor.ProcessTracking {
 		candidates := GetProcessCandidates(agents)
 		w.procMon = NewProcessMonitor(candidates)
+		w.procMon.SetMinLifetime(cfg.MinProcessLifetime())
 	}
 
 	// Initialize per-agent resources
-	for _, agent := range agents {
-		w.state.AddAgent(agent)
+	w.state.SetDisplayNames(cfg.Agents.DisplayNames)
+	for _, agent := range ApplyDisplayNames(agents, cfg.Agents.DisplayNames) {
+		w.addAgent(agent)
+	}
 
-		// Create tailer manager
-		basePath := ExpandPath(agent.LogPath)
-		w.managers[agent.Name] = NewTailerManager(
-			basePath,
-			cfg.Advanced.MaxRecentFiles,
-			cfg.Advanced.WatchDepth,
-			false, // Don't read from beginning
-		)
+	return w, nil
+}
 
-		// Create matcher
-		w.matchers[agent.Name] = detect.CreateMatcher(agent.Name)
+// newWatcher creates a Watcher with no agents, no fsnotify watcher, and no
+// process monitor. Without a fsnotify watcher, the caller reads the agents'
+// lines (see PathWatcher).
+func newWatcher(cfg *config.Config, notifier notify.Notifier, perInstance bool) *Watcher {
+	w := &Watcher{
+		cfg:      cfg,
+		state:    NewState(perInstance),
+		notifier: notifier,
+		managers: make(map[string]*TailerManager),
+		matchers: make(map[string]detect.Matcher),
+		links:    make(map[string]string),
+		clock:    realClock{},
+
+		instProcs: make(map[int]*instanceProcess),
+
+		sensitive: newSensitiveTools(cfg.Monitor.SensitiveTools),
+		collapser: newCueCollapser(cfg.CollapseCuesWindow()),
+		entries:   make(map[cueSource]HistoryLine),
+	}
+	w.state.SetHistorySize(cfg.Notify.HistorySize())
+	return w
+}
 
-		// Add watch on base path
-		if err := w.addWatch(basePath); err != nil {
-			// Non-fatal: directory might not exist yet
-			fmt.Fprintf(os.Stderr, "Warning: cannot watch %s: %v\n", basePath, err)
+// addAgent sets up state, a tailer manager, a matcher, and watches for agent.
+func (w *Watcher) addAgent(agent Agent) {
+	cfg := w.cfg
+	w.state.AddAgent(agent)
+	if cfg.Monitor.PerInstance.IsAuto() {
+		w.state.SetAgentPerInstance(agent.Name, AutoPerInstance(agent))
+	}
+
+	// Create tailer manager
+	basePath := config.ExpandPath(agent.LogPath)
+	w.managers[agent.Name] = NewTailerManager(
+		basePath,
+		cfg.Advanced.MaxRecentFiles,
+		cfg.Advanced.WatchDepth,
+		false, // Don't read from beginning
+	)
+	w.managers[agent.Name].Ignore = cfg.Agents.IgnoreFiles
+	w.managers[agent.Name].MaxLine = cfg.LineLimit()
+	w.managers[agent.Name].Allowed = cfg.Advanced.AllowedRoots
+	w.managers[agent.Name].Extensions = cfg.Advanced.LogExtensions
+	w.managers[agent.Name].WholeFile = agent.WholeFile
+
+	// Create matcher
+	w.matchers[agent.Name] = NewAgentMatcher(cfg, agent.Name)
+
+	// journald units and containers have no files to watch; Run polls them
+	// instead. Without fsnotify, the caller reads every agent.
+	if IsStreamPath(basePath) || w.fsw == nil {
+		return
+	}
+
+	// Add watch on base path
+	if err := w.addWatch(basePath); err != nil {
+		// Non-fatal: directory might not exist yet
+		fmt.Fprintf(os.Stderr, "Warning: cannot watch %s: %v\n", basePath, err)
+		if isWatchFailure(err) {
+			w.watchFailed = true
 		}
 	}
+}
 
-	return w, nil
+// discoverAgents re-runs auto-detection when monitor.wait_for_agents is set
+// and starts monitoring any agent that has appeared since the last check.
+func (w *Watcher) discoverAgents() {
+	if !w.cfg.Monitor.WaitForAgents {
+		return
+	}
+
+	detected := DetectActiveAgentsWith(w.cfg.Agents.Paths, w.cfg.ActiveWindow(), w.cfg.Advanced.LogExtensions)
+	for _, agent := range ApplyDisplayNames(detected, w.cfg.Agents.DisplayNames) {
+		if _, ok := w.managers[agent.Name]; ok {
+			continue
+		}
+		w.addAgent(agent)
+		if w.procMon != nil {
+			w.procMon.AddCandidates(agent.ProcessNames)
+		}
+		fmt.Printf("  Detected %s: %s\n", agent.DisplayName, agent.LogPath)
+	}
 }
 
 // addWatch adds a watch on a path, creating parent directories if needed.
+// Paths outside advanced.allowed_roots are rejected.
 func (w *Watcher) addWatch(path string) error {
+	if err := CheckAllowedPath(path, w.cfg.Advanced.AllowedRoots); err != nil {
+		return err
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return err
@@ -109,16 +212,50 @@ func (w *Watcher) addWatch(path string) error {
 	}
 
 	// Watch parent directory for file
-	return w.fsw.Add(filepath.Dir(path))
+	if err := w.fsw.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return w.watchLinkTarget(path)
+}
+
+// watchLinkTarget also watches the directory of the file a symlinked log
+// path points to, since writes through the link are reported for the target.
+// It is called again when the link is replaced, to follow a rotation.
+func (w *Watcher) watchLinkTarget(path string) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil || target == path {
+		delete(w.links, path)
+		return nil
+	}
+	if err := CheckAllowedPath(target, w.cfg.Advanced.AllowedRoots); err != nil {
+		return err
+	}
+	if w.links[path] == target {
+		return nil
+	}
+	if filepath.Dir(target) != filepath.Dir(path) {
+		if err := w.fsw.Add(filepath.Dir(target)); err != nil {
+			return err
+		}
+	}
+	Debugf("watcher: %s points to %s", path, target)
+	w.links[path] = target
+	return nil
 }
 
 // Run starts the watcher event loop.
 func (w *Watcher) Run(ctx context.Context) error {
 	// Initial file discovery
-	w.refreshFiles()
+	w.checkLogFiles(ctx, w.refreshFiles())
+
+	// Seed state from recent history if configured
+	if backfill := w.cfg.BackfillDuration(); backfill > 0 {
+		w.Backfill(w.clock.Now().Add(-backfill))
+	}
 
 	// Setup process monitoring if enabled
-	w.setupProcessMonitoring()
+	w.setupProcessMonitoring(ctx)
+	defer w.markReady()()
 
 	// Create tickers
 	refreshTicker := time.NewTicker(5 * time.Second)
@@ -130,6 +267,37 @@ func (w *Watcher) Run(ctx context.Context) error {
 	procTicker := time.NewTicker(5 * time.Second)
 	defer procTicker.Stop()
 
+	// Polling fallback, started if fsnotify stops delivering events
+	var pollTicker *time.Ticker
+	var pollC <-chan time.Time
+	defer func() {
+		if pollTicker != nil {
+			pollTicker.Stop()
+		}
+	}()
+	if w.watchFailed {
+		fmt.Fprintln(os.Stderr, "Some paths could not be watched; polling as well")
+		pollTicker = time.NewTicker(w.cfg.PollInterval())
+		pollC = pollTicker.C
+	}
+
+	// journald units and containers get no fsnotify events, so read them
+	// on a ticker, started once there is one to follow
+	var streamTicker *time.Ticker
+	var streamC <-chan time.Time
+	defer func() {
+		if streamTicker != nil {
+			streamTicker.Stop()
+		}
+	}()
+	startStreams := func() {
+		if streamTicker == nil && w.hasStreams() {
+			streamTicker = time.NewTicker(w.cfg.PollInterval())
+			streamC = streamTicker.C
+		}
+	}
+	startStreams()
+
 	fmt.Println("Watching for activity...")
 
 	for {
@@ -139,8 +307,7 @@ func (w *Watcher) Run(ctx context.Context) error {
 
 		case <-w.pidDone:
 			// Process exited
-			w.handleProcessExit(ctx)
-			w.pidDone = nil // Prevent repeated handling
+			w.processExited(ctx)
 
 		case event, ok := <-w.fsw.Events:
 			if !ok {
@@ -153,11 +320,26 @@ func (w *Watcher) Run(ctx context.Context) error {
 				return nil
 			}
 			fmt.Fprintf(os.Stderr, "fsnotify error: %v\n", err)
+			if pollTicker == nil && isWatchFailure(err) {
+				fmt.Fprintln(os.Stderr, "fsnotify is losing events; switching to polling mode")
+				pollTicker = time.NewTicker(w.cfg.PollInterval())
+				pollC = pollTicker.C
+				w.pollAllAgents(ctx) // Catch up on anything missed
+			}
+
+		case <-pollC:
+			w.pollAllAgents(ctx)
+
+		case <-streamC:
+			w.pollStreams(ctx)
 
 		case <-refreshTicker.C:
-			w.refreshFiles()
+			w.discoverAgents()
+			w.checkLogFiles(ctx, w.refreshFiles())
+			startStreams()
 
 		case <-quietTicker.C:
+			w.flushEntries(ctx)
 			w.checkQuietPeriods(ctx)
 
 		case <-procTicker.C:
@@ -166,34 +348,86 @@ func (w *Watcher) Run(ctx context.Context) error {
 	}
 }
 
+// isWatchFailure reports whether an fsnotify error means events are being
+// lost (queue overflow, or the inotify watch/descriptor limit was hit).
+func isWatchFailure(err error) bool {
+	return errors.Is(err, fsnotify.ErrEventOverflow) ||
+		errors.Is(err, syscall.ENOSPC) ||
+		errors.Is(err, syscall.EMFILE)
+}
+
 // handleFSEvent processes a filesystem event.
 func (w *Watcher) handleFSEvent(ctx context.Context, event fsnotify.Event) {
+	defer w.recoverPanic("handling " + event.Name)
 	// Only care about writes and creates
 	if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
 		return
 	}
 
-	// Find which agent owns this path
+	// A replaced symlink may point to a new file elsewhere
+	if _, ok := w.links[event.Name]; ok {
+		if err := w.watchLinkTarget(event.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot watch %s: %v\n", event.Name, err)
+		}
+	}
+
+	// Agents may share a log root, so every agent whose base contains the
+	// path (or links to it) reads it; each matcher decides which lines are
+	// its own
 	for name, mgr := range w.managers {
-		// Check if path is under this manager's base
-		rel, err := filepath.Rel(mgr.BasePath, event.Name)
-		if err != nil || len(rel) > 0 && rel[0] == '.' {
-			continue
+		if underBase(mgr.BasePath, event.Name) || w.links[mgr.BasePath] == event.Name {
+			w.readManager(ctx, name, mgr)
 		}
+	}
+}
 
-		// Refresh and read
-		mgr.RefreshFiles()
-		newLines := mgr.ReadAllNew()
+// readManager refreshes an agent's sources and processes their new lines.
+func (w *Watcher) readManager(ctx context.Context, name string, mgr *TailerManager) {
+	mgr.RefreshFiles()
+	for path, lines := range mgr.ReadAllNew() {
+		w.processLines(ctx, name, path, lines)
+	}
+}
 
-		for path, lines := range newLines {
-			w.processLines(ctx, name, path, lines)
-		}
-		return
+// underBase reports whether path is base or inside it.
+func underBase(base, path string) bool {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// recoverPanic recovers from a panic in the calling goroutine, logging it
+// and counting it in the status dump, so one bad log line or event can't
+// take down the daemon. It must be deferred directly.
+func (w *Watcher) recoverPanic(what string) {
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "Recovered from panic %s: %v\n", what, r)
+		Debugf("%s", debug.Stack())
+		w.state.RecordPanic()
 	}
 }
 
-// processLines processes new lines from a file.
+// matchLine runs an agent's matcher on a line, or a multi-line entry (see
+// matchEntry). A panic in the matcher is recovered (see recoverPanic) and
+// the line skipped, as if it didn't match.
+func (w *Watcher) matchLine(agentName string, m detect.Matcher, line string) (match *detect.Match, err error) {
+	defer w.recoverPanic("matching " + agentName + " line")
+	return matchEntry(m, line)
+}
+
+// processLines processes new lines from a file, joining continuation lines
+// with agents.continuation_prefix set (see joinEntries).
 func (w *Watcher) processLines(ctx context.Context, agentName, path string, lines []string) {
+	if prefix := w.cfg.Agents.ContinuationPrefix[agentName]; prefix != "" {
+		lines = w.joinEntries(agentName, path, lines, prefix)
+	}
+	w.processEntries(ctx, agentName, path, lines)
+}
+
+// processEntries processes logical lines from a file.
+func (w *Watcher) processEntries(ctx context.Context, agentName, path string, lines []string) {
 	matcher := w.matchers[agentName]
 	if matcher == nil {
 		return
@@ -203,9 +437,12 @@ func (w *Watcher) processLines(ctx context.Context, agentName, path string, line
 	if agentState == nil {
 		return
 	}
+	if w.state.IsPaused(agentName) {
+		return
+	}
 
 	// In per-instance mode, ensure instance exists
-	if w.state.IsPerInstance() {
+	if w.state.IsPerInstanceAgent(agentName) {
 		w.state.GetOrCreateInstance(agentName, path)
 	}
 
@@ -215,18 +452,49 @@ func (w *Watcher) processLines(ctx context.Context, agentName, path string, line
 	// - stdout verbose: Send all activity notifications
 	sendActivity := w.cfg.Notify.Type == "stdout" && w.cfg.Output.Verbosity == "verbose"
 
+	// Count lines read and malformed lines for the status dump
+	var read, malformed int
+	defer func() { w.state.RecordLines(agentName, read, malformed) }()
+
+	// Activity cues that only continue a run are counted and refreshed
+	// together, before the next cue that does more and at the end
+	var collapsed int
+	touch := func() {
+		if collapsed > 0 {
+			w.touchCue(agentName, path, collapsed)
+			collapsed = 0
+		}
+	}
+	defer touch()
+
 	for _, line := range lines {
 		if line == "" {
 			continue
 		}
+		read++
 
-		match := matcher.Match(line)
+		match, err := w.matchLine(agentName, matcher, line)
+		if err != nil {
+			malformed++
+			Debugf("%v", err)
+			continue
+		}
 		if match == nil {
 			continue
 		}
+		if w.collapser.collapse(agentName, path, match.Type, w.clock.Now()) {
+			collapsed++
+			continue
+		}
+		touch()
+		if match.Type == detect.MatchComplete && !w.completeAccepted(agentName, path) {
+			Debugf("%s: ignoring completion after too little activity: %s", agentName, match.Reason)
+			continue
+		}
 
 		// Record cue (per-instance or per-agent)
 		w.recordCue(agentName, path, match.Type)
+		w.state.RecordHistory(agentName, match.Type, match.Reason)
 
 		// Handle based on match type
 		switch match.Type {
@@ -235,7 +503,7 @@ func (w *Watcher) processLines(ctx context.Context, agentName, path string, line
 			// After quiet period, this will trigger "Cooling"
 
 			// Only send activity notification if verbose stdout mode
-			if sendActivity {
+			if sendActivity && !w.cfg.Output.SuppressesReason(match.Reason) {
 				displayName := w.getDisplayName(agentName, path)
 				n := notify.NewNotificationFromMatch(
 					agentName,
@@ -244,10 +512,13 @@ func (w *Watcher) processLines(ctx context.Context, agentName, path string, line
 					match.Line,
 				)
 				if w.cfg.Output.IncludeSnippets {
-					n.Snippet = TailSnippet(path, w.cfg.Output.SnippetLines, 500)
+					n.Snippet = notify.DedupeSnippet(TailSnippet(path, w.cfg.Output.SnippetLines, 500), match.Line, n.Message)
+				}
+				if w.cfg.Output.IncludeRawMatch {
+					notify.AttachRawMatch(n, match.Line)
 				}
-				if err := w.notifier.Send(ctx, n); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", err)
+				if err := w.deliverFor(ctx, agentName, n); err != nil {
+					logSendError(w.notifier, "notification", err)
 				}
 			}
 
@@ -255,18 +526,39 @@ func (w *Watcher) processLines(ctx context.Context, agentName, path string, line
 			// Tool permission requested - record cue for quiet period tracking
 			// After quiet period, this will trigger "Holding" notification
 			// (Don't notify immediately - tool may be auto-approved)
+			w.checkToolLoop(ctx, agentName, path, match)
+			w.recordHoldingTool(agentName, path, match)
+
+			// Sensitive tools alert now at high priority, whatever the other
+			// settings; otherwise, if tools are never auto-approved (or this
+			// is the turn's first request), notify now instead of after quiet
+			first := w.cfg.Monitor.NotifyFirstHolding && w.markHoldingSent(agentName, path)
+			if w.sendSensitiveHolding(ctx, agentName, path, match) {
+				w.markQuietNotified(agentName, path)
+			} else if w.cfg.Monitor.ImmediateHolding || first {
+				n := w.holdingNotification(w.getDisplayName(agentName, path), agentName, path)
+				if err := w.deliverFor(ctx, agentName, n); err != nil {
+					logSendError(w.notifier, "awaiting notification", err)
+				}
+				w.markQuietNotified(agentName, path)
+			}
+
+		case detect.MatchCompaction:
+			// Context compacted: the session is getting long, the turn goes on
+			w.sendCompactionNotification(ctx, agentName, path, match)
 
 		case detect.MatchAwaiting:
 			// Explicit awaiting (rare - most agents use MatchComplete + quiet period)
 			displayName := w.getDisplayName(agentName, path)
-			w.sendAwaitingNotification(ctx, displayName, "Awaiting", "Ready for your input")
+			w.sendAwaitingNotification(ctx, agentName, displayName, "Awaiting", "Ready for your input")
 
 		case detect.MatchActivity:
 			// Normal activity (no completion signal) - record cue for quiet period tracking
 			// After quiet period without a MatchComplete, this will trigger inferred "Awaiting"
 
-			// Only send activity notification if verbose stdout mode
-			if !sendActivity {
+			// Only send activity notification if verbose stdout mode, and
+			// not for reasons the user finds noisy
+			if !sendActivity || w.cfg.Output.SuppressesReason(match.Reason) {
 				continue
 			}
 
@@ -281,28 +573,207 @@ func (w *Watcher) processLines(ctx context.Context, agentName, path string, line
 
 			// Add snippet if configured
 			if w.cfg.Output.IncludeSnippets {
-				n.Snippet = TailSnippet(path, w.cfg.Output.SnippetLines, 500)
+				n.Snippet = notify.DedupeSnippet(TailSnippet(path, w.cfg.Output.SnippetLines, 500), match.Line, n.Message)
+			}
+			if w.cfg.Output.IncludeRawMatch {
+				notify.AttachRawMatch(n, match.Line)
 			}
 
-			if err := w.notifier.Send(ctx, n); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", err)
+			if err := w.deliverFor(ctx, agentName, n); err != nil {
+				logSendError(w.notifier, "notification", err)
 			}
 		}
 	}
 }
 
+// checkToolLoop alerts when the same tool request keeps repeating for an agent.
+func (w *Watcher) checkToolLoop(ctx context.Context, agentName, path string, match *detect.Match) {
+	threshold := w.cfg.Monitor.LoopThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	tool, _ := match.Meta["tool"].(string)
+	if tool == "" {
+		return
+	}
+	args, _ := match.Meta["tool_args"].(string)
+
+	count, loop := w.state.RecordToolRequest(agentName, tool+"\x00"+args, w.clock.Now(), loopWindow, threshold)
+	if !loop {
+		return
+	}
+
+	n := &notify.Notification{
+		Agent:   w.getDisplayName(agentName, path),
+		Title:   "Possible loop",
+		Message: fmt.Sprintf("%s requested %d times in the last %s", tool, count, loopWindow),
+		Time:    w.clock.Now(),
+	}
+	if err := w.deliverFor(ctx, agentName, n); err != nil {
+		logSendError(w.notifier, "loop notification", err)
+	}
+}
+
+// sendSensitiveHolding sends a high-priority "Holding" right away if match
+// requests a tool listed in monitor.sensitive_tools, reporting whether it did.
+func (w *Watcher) sendSensitiveHolding(ctx context.Context, agentName, path string, match *detect.Match) bool {
+	tool, _ := match.Meta["tool"].(string)
+	args, _ := match.Meta["tool_args"].(string)
+	entry := w.sensitive.Match(tool, args)
+	if entry == "" {
+		return false
+	}
+
+	message := "Waiting for approval of sensitive tool " + entry
+	if tool != "" && !strings.EqualFold(tool, entry) {
+		message += " (" + tool + ")"
+	}
+	n := &notify.Notification{
+		Agent:    w.getDisplayName(agentName, path),
+		Title:    "Holding",
+		Message:  message,
+		Time:     w.clock.Now(),
+		Metadata: map[string]any{"tool": tool, "sensitive": entry, "action": notify.ToolAction(tool, args, true)},
+		Priority: notify.PriorityHigh,
+	}
+	if err := w.deliverFor(ctx, agentName, n); err != nil {
+		logSendError(w.notifier, "awaiting notification", err)
+	}
+	return true
+}
+
+// recordHoldingTool remembers the tool a Holding match requests, for the
+// action hint of a Holding sent after the quiet period.
+func (w *Watcher) recordHoldingTool(agentName, path string, match *detect.Match) {
+	tool, _ := match.Meta["tool"].(string)
+	args, _ := match.Meta["tool_args"].(string)
+	if w.state.IsPerInstanceAgent(agentName) {
+		w.state.SetInstanceHoldingTool(path, tool, args)
+	} else {
+		w.state.SetHoldingTool(agentName, tool, args)
+	}
+}
+
+// newHoldingNotification builds a "Holding" notification: the agent asked
+// to run a tool and is waiting for approval.
+func newHoldingNotification(displayName string, now time.Time) *notify.Notification {
+	return &notify.Notification{
+		Agent:   displayName,
+		Title:   "Holding",
+		Message: "Waiting for tool approval",
+		Time:    now,
+	}
+}
+
+// holdingNotification builds a "Holding" notification for an agent or
+// instance, with an action hint naming the tool when it is known.
+func (w *Watcher) holdingNotification(displayName, agentName, path string) *notify.Notification {
+	n := newHoldingNotification(displayName, w.clock.Now())
+	tool, args := w.state.HoldingTool(agentName)
+	if w.state.IsPerInstanceAgent(agentName) {
+		tool, args = w.state.InstanceHoldingTool(path)
+	}
+	if tool != "" {
+		sensitive := w.sensitive.Match(tool, args) != ""
+		n.Metadata = map[string]any{"action": notify.ToolAction(tool, args, sensitive)}
+	}
+	return n
+}
+
+// sendCompactionNotification reports that an agent compacted its context.
+func (w *Watcher) sendCompactionNotification(ctx context.Context, agentName, path string, match *detect.Match) {
+	message := "Context compacted"
+	trigger, _ := match.Meta["trigger"].(string)
+	tokens, _ := match.Meta["pre_tokens"].(int)
+	switch {
+	case trigger != "" && tokens > 0:
+		message += fmt.Sprintf(" (%s, %d tokens before)", trigger, tokens)
+	case trigger != "":
+		message += " (" + trigger + ")"
+	}
+
+	metadata := map[string]any{}
+	if trigger != "" {
+		metadata["trigger"] = trigger
+	}
+	if tokens > 0 {
+		metadata["pre_tokens"] = tokens
+	}
+	n := &notify.Notification{
+		Agent:    w.getDisplayName(agentName, path),
+		Title:    "Compacted",
+		Message:  message,
+		Time:     w.clock.Now(),
+		Metadata: metadata,
+	}
+	if err := w.deliverFor(ctx, agentName, n); err != nil {
+		logSendError(w.notifier, "compaction notification", err)
+	}
+}
+
 // recordCue records activity cue, using per-instance or per-agent mode.
 func (w *Watcher) recordCue(agentName, path string, cueType detect.MatchType) {
-	if w.state.IsPerInstance() {
+	if w.state.IsPerInstanceAgent(agentName) {
 		w.state.RecordInstanceCue(path, cueType)
 	} else {
 		w.state.RecordCue(agentName, cueType)
 	}
+	if w.lastSeen != nil {
+		w.lastSeen.Record(agentName, w.clock.Now())
+	}
+}
+
+// touchCue refreshes the cue time of an agent or instance for n collapsed
+// activity cues (see cueCollapser).
+func (w *Watcher) touchCue(agentName, path string, n int) {
+	if w.state.IsPerInstanceAgent(agentName) {
+		w.state.TouchInstanceCue(path, n)
+	} else {
+		w.state.TouchCue(agentName, n)
+	}
+	if w.lastSeen != nil {
+		w.lastSeen.Record(agentName, w.clock.Now())
+	}
+}
+
+// completeAccepted reports whether a completion should be recorded: for
+// text-based agents, agents.min_complete_lines activity cues must have
+// accumulated since the last completion. Agents with a log format, from
+// agents.format or the registry file, are structured.
+func (w *Watcher) completeAccepted(agentName, path string) bool {
+	need := w.cfg.Agents.MinCompleteLines
+	if need <= 0 || !detect.TextBased(agentName) || LogFormat(agentName, w.cfg.Agents.Format) != "" {
+		return true
+	}
+	if w.state.IsPerInstanceAgent(agentName) {
+		return w.state.InstanceActivitySinceComplete(path) >= need
+	}
+	return w.state.ActivitySinceComplete(agentName) >= need
+}
+
+// markQuietNotified marks the quiet notification as sent, using per-instance
+// or per-agent mode.
+func (w *Watcher) markQuietNotified(agentName, path string) {
+	if w.state.IsPerInstanceAgent(agentName) {
+		w.state.MarkInstanceQuietNotified(path)
+	} else {
+		w.state.MarkQuietNotified(agentName)
+	}
+}
+
+// markHoldingSent records a Holding for the agent or instance, reporting
+// whether it is the first since the last completion.
+func (w *Watcher) markHoldingSent(agentName, path string) bool {
+	if w.state.IsPerInstanceAgent(agentName) {
+		return w.state.MarkInstanceHoldingSent(path)
+	}
+	return w.state.MarkHoldingSent(agentName)
 }
 
 // getDisplayName returns the display name for notifications.
 func (w *Watcher) getDisplayName(agentName, path string) string {
-	if w.state.IsPerInstance() {
+	if w.state.IsPerInstanceAgent(agentName) {
 		if inst := w.state.GetInstance(path); inst != nil {
 			return inst.DisplayName
 		}
@@ -314,35 +785,77 @@ func (w *Watcher) getDisplayName(agentName, path string) string {
 }
 
 // sendAwaitingNotification sends an awaiting notification immediately.
-func (w *Watcher) sendAwaitingNotification(ctx context.Context, displayName, title, message string) {
+func (w *Watcher) sendAwaitingNotification(ctx context.Context, agentName, displayName, title, message string) {
 	n := &notify.Notification{
 		Agent:   displayName,
 		Title:   title,
 		Message: message,
-		Time:    time.Now(),
+		Time:    w.clock.Now(),
 	}
 
-	if err := w.notifier.Send(ctx, n); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to send awaiting notification: %v\n", err)
+	if err := w.deliverFor(ctx, agentName, n); err != nil {
+		logSendError(w.notifier, "awaiting notification", err)
 	}
 }
 
-// refreshFiles refreshes the watched files for all agents.
-func (w *Watcher) refreshFiles() {
+// refreshFiles refreshes the watched files for all agents, returning how
+// many there are.
+func (w *Watcher) refreshFiles() int {
+	total := 0
 	for name, mgr := range w.managers {
 		paths := mgr.RefreshFiles()
 		w.state.UpdateWatchedPaths(name, paths)
+		total += len(paths)
+	}
+	return total
+}
+
+// checkLogFiles tracks how long refreshFiles has found no log files, given
+// its count. Once every file has been gone for noLogFilesAfter it sends
+// "No log files found", once, and "Resumed" when files reappear. Nothing is
+// sent if no files were ever found, e.g. for an agent not yet used.
+func (w *Watcher) checkLogFiles(ctx context.Context, files int) {
+	now := w.clock.Now()
+	if files > 0 {
+		w.hadFiles = true
+		w.noFilesSince = time.Time{}
+		if w.noFilesSent {
+			w.noFilesSent = false
+			n := notify.NewLogFilesResumedNotification(files)
+			n.Time = now
+			if err := w.deliver(ctx, n); err != nil {
+				logSendError(w.notifier, "notification", err)
+			}
+		}
+		return
+	}
+
+	if !w.hadFiles {
+		return
+	}
+	if w.noFilesSince.IsZero() {
+		w.noFilesSince = now
+		return
+	}
+	missing := rewind(&w.noFilesSince, now)
+	if w.noFilesSent || missing < noLogFilesAfter {
+		return
+	}
+	w.noFilesSent = true
+	n := notify.NewNoLogFilesNotification(missing)
+	n.Time = now
+	if err := w.deliver(ctx, n); err != nil {
+		logSendError(w.notifier, "notification", err)
 	}
 }
 
 // checkQuietPeriods checks for quiet period notifications.
 // Sends "Cooling" if last cue was MatchComplete (turn finished).
 // Sends "Awaiting" if last cue was MatchActivity (no completion signal - inferred waiting).
+//
+// Agents with completion detection off (see config.CompletionDetectionFor)
+// are skipped.
 func (w *Watcher) checkQuietPeriods(ctx context.Context) {
-	if !w.cfg.Monitor.CompletionDetection {
-		return
-	}
-
 	quietDuration := w.cfg.QuietDuration()
 
 	// Get CPU percentage if available
@@ -351,24 +864,87 @@ func (w *Watcher) checkQuietPeriods(ctx context.Context) {
 		cpuPct = w.procMon.LastCPU()
 	}
 
-	if w.state.IsPerInstance() {
-		w.checkInstanceQuietPeriods(ctx, quietDuration, cpuPct)
-	} else {
-		w.checkAgentQuietPeriods(ctx, quietDuration, cpuPct)
+	// In focus mode only the most recently cued agent/instance notifies,
+	// among those that notify quiet periods at all
+	focus := ""
+	if w.cfg.Monitor.Focus {
+		focus = w.state.FocusTarget(w.cfg.CompletionDetectionFor)
+	}
+
+	// Instances exist only for per-instance agents, and the agent-level
+	// check skips those, so mixed modes are both covered
+	w.checkInstanceQuietPeriods(ctx, quietDuration, cpuPct, focus)
+	w.checkAgentQuietPeriods(ctx, quietDuration, cpuPct, focus)
+
+	if interval := w.cfg.WorkingReminderInterval(); interval > 0 {
+		w.checkWorkingReminders(ctx, quietDuration, interval)
+	}
+}
+
+// checkWorkingReminders sends a low-key "Still working" reminder for agents
+// (or instances) that have been continuously active for another interval.
+func (w *Watcher) checkWorkingReminders(ctx context.Context, quietDuration, interval time.Duration) {
+	for _, inst := range w.state.GetAllInstances() {
+		if w.state.IsPaused(inst.AgentName) || !w.cfg.CompletionDetectionFor(inst.AgentName) {
+			continue
+		}
+		if elapsed, ok := w.state.InstanceWorkingReminderDue(inst.FilePath, quietDuration, interval); ok {
+			w.sendWorkingReminder(ctx, inst.AgentName, inst.DisplayName, elapsed)
+		}
+	}
+	for _, agentState := range w.state.GetAllAgents() {
+		if w.state.IsPerInstanceAgent(agentState.Agent.Name) || w.state.IsPaused(agentState.Agent.Name) || !w.cfg.CompletionDetectionFor(agentState.Agent.Name) {
+			continue
+		}
+		if elapsed, ok := w.state.WorkingReminderDue(agentState.Agent.Name, quietDuration, interval); ok {
+			w.sendWorkingReminder(ctx, agentState.Agent.Name, agentState.Agent.DisplayName, elapsed)
+		}
+	}
+}
+
+// formatElapsed formats a turn's running time ("45s", "5m", "1h5m").
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
+
+// sendWorkingReminder sends a "Still working" notification.
+func (w *Watcher) sendWorkingReminder(ctx context.Context, agentName, displayName string, elapsed time.Duration) {
+	n := &notify.Notification{
+		Agent:   displayName,
+		Title:   "Still working",
+		Message: fmt.Sprintf("%s still working, %s elapsed", displayName, formatElapsed(elapsed)),
+		Time:    w.clock.Now(),
+	}
+	if err := w.deliverFor(ctx, agentName, n); err != nil {
+		logSendError(w.notifier, "reminder notification", err)
 	}
 }
 
 // checkAgentQuietPeriods checks quiet periods for agent-level tracking.
-func (w *Watcher) checkAgentQuietPeriods(ctx context.Context, quietDuration time.Duration, cpuPct float64) {
+// If focus is set, other agents are marked notified without sending.
+func (w *Watcher) checkAgentQuietPeriods(ctx context.Context, quietDuration time.Duration, cpuPct float64, focus string) {
 	for _, agentState := range w.state.GetAllAgents() {
+		if w.state.IsPerInstanceAgent(agentState.Agent.Name) || w.state.IsPaused(agentState.Agent.Name) || !w.cfg.CompletionDetectionFor(agentState.Agent.Name) {
+			continue
+		}
 		if w.state.ShouldSendQuiet(agentState.Agent.Name, quietDuration) {
-			// Determine notification type based on last cue type
-			lastCueType := w.state.GetLastCueType(agentState.Agent.Name)
-
-			n := w.buildQuietNotification(agentState.Agent.DisplayName, lastCueType, cpuPct)
+			if focus == "" || focus == agentState.Agent.Name {
+				// Determine notification type based on last cue type
+				lastCueType := w.state.GetLastCueType(agentState.Agent.Name)
+
+				var n *notify.Notification
+				if lastCueType == detect.MatchHolding {
+					n = w.holdingNotification(agentState.Agent.DisplayName, agentState.Agent.Name, "")
+				} else {
+					n = buildQuietNotification(agentState.Agent.DisplayName, lastCueType, cpuPct)
+				}
 
-			if err := w.notifier.Send(ctx, n); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", err)
+				if err := w.deliverFor(ctx, agentState.Agent.Name, n); err != nil {
+					logSendError(w.notifier, "notification", err)
+				}
 			}
 
 			w.state.MarkQuietNotified(agentState.Agent.Name)
@@ -377,15 +953,32 @@ func (w *Watcher) checkAgentQuietPeriods(ctx context.Context, quietDuration time
 }
 
 // checkInstanceQuietPeriods checks quiet periods for per-instance tracking.
-func (w *Watcher) checkInstanceQuietPeriods(ctx context.Context, quietDuration time.Duration, cpuPct float64) {
+// If focus is set, other instances are marked notified without sending.
+func (w *Watcher) checkInstanceQuietPeriods(ctx context.Context, quietDuration time.Duration, cpuPct float64, focus string) {
 	for _, inst := range w.state.GetAllInstances() {
+		if w.state.IsPaused(inst.AgentName) || !w.cfg.CompletionDetectionFor(inst.AgentName) {
+			continue
+		}
 		if w.state.ShouldSendInstanceQuiet(inst.FilePath, quietDuration) {
-			lastCueType := w.state.GetInstanceCueType(inst.FilePath)
+			if focus == "" || focus == inst.FilePath {
+				lastCueType := w.state.GetInstanceCueType(inst.FilePath)
+
+				// Report the instance's own process if one is associated
+				instCPU := cpuPct
+				if p := w.instProcs[inst.PID]; p != nil {
+					instCPU = p.mon.LastCPU()
+				}
 
-			n := w.buildQuietNotification(inst.DisplayName, lastCueType, cpuPct)
+				var n *notify.Notification
+				if lastCueType == detect.MatchHolding {
+					n = w.holdingNotification(inst.DisplayName, inst.AgentName, inst.FilePath)
+				} else {
+					n = buildQuietNotification(inst.DisplayName, lastCueType, instCPU)
+				}
 
-			if err := w.notifier.Send(ctx, n); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", err)
+				if err := w.deliverFor(ctx, inst.AgentName, n); err != nil {
+					logSendError(w.notifier, "notification", err)
+				}
 			}
 
 			w.state.MarkInstanceQuietNotified(inst.FilePath)
@@ -393,8 +986,9 @@ func (w *Watcher) checkInstanceQuietPeriods(ctx context.Context, quietDuration t
 	}
 }
 
-// buildQuietNotification creates a notification based on cue type.
-func (w *Watcher) buildQuietNotification(displayName string, cueType detect.MatchType, cpuPct float64) *notify.Notification {
+// buildQuietNotification creates a notification based on cue type. Holding
+// cues get a Holding notification instead (see newHoldingNotification).
+func buildQuietNotification(displayName string, cueType detect.MatchType, cpuPct float64) *notify.Notification {
 	switch cueType {
 	case detect.MatchComplete:
 		// Turn was completed - send "Cooling" notification
@@ -410,23 +1004,142 @@ func (w *Watcher) buildQuietNotification(displayName string, cueType detect.Matc
 			Time:    time.Now(),
 		}
 
-	case detect.MatchHolding:
-		// Tool permission was requested and agent is still quiet - send "Holding"
-		return &notify.Notification{
-			Agent:   displayName,
-			Title:   "Holding",
-			Message: "Waiting for tool approval",
-			Time:    time.Now(),
-		}
-
 	default:
 		// Default to Cooling for any other case
 		return notify.NewQuietNotification(displayName, cpuPct)
 	}
 }
 
+// SetClock replaces the time source for the watcher, its state, and its
+// process monitor (for tests). Call before Run.
+func (w *Watcher) SetClock(c Clock) {
+	w.clock = c
+	w.state.SetClock(c)
+	if w.procMon != nil {
+		w.procMon.SetClock(c)
+	}
+}
+
+// SetLastSeenPath persists each agent's last cue time to path (see
+// LoadLastSeen), written at most every 10 seconds and on Close.
+// Call before Run.
+func (w *Watcher) SetLastSeenPath(path string) {
+	w.lastSeen = newLastSeenWriter(path)
+}
+
+// SetReadyFile makes Run write path (containing the PID) once initial file
+// discovery and process setup are done, and remove it when Run returns, so
+// supervisors can tell when firebell is watching. Call before Run.
+func (w *Watcher) SetReadyFile(path string) {
+	w.readyFile = path
+}
+
+// SetSnoozeFile makes the watcher check path (see WriteSnooze) before each
+// send; while the snooze lasts, notifications are only recorded to the
+// event file.
+func (w *Watcher) SetSnoozeFile(path string) {
+	w.snoozeFile = path
+}
+
+// SetPaused pauses or resumes one agent while the watcher runs: a paused
+// agent's lines are skipped and its quiet periods not checked. It is safe to
+// call from another goroutine (e.g. a socket command).
+func (w *Watcher) SetPaused(agentName string, paused bool) error {
+	if !w.state.SetPaused(agentName, paused) {
+		return fmt.Errorf("agent %q is not being monitored", agentName)
+	}
+	return nil
+}
+
+// recorder is implemented by notifiers that can record a notification
+// without alerting anyone (see notify.MultiNotifier.Record).
+type recorder interface {
+	Record(ctx context.Context, n *notify.Notification) error
+}
+
+// deliver sends n, or only records it while snoozed.
+func (w *Watcher) deliver(ctx context.Context, n *notify.Notification) error {
+	if until := w.snoozedUntil(); !until.IsZero() {
+		Debugf("snoozed until %s: suppressing %q for %s", until.Format(time.RFC3339), n.Title, n.Agent)
+		if r, ok := w.notifier.(recorder); ok {
+			return r.Record(ctx, n)
+		}
+		return nil
+	}
+	return w.notifier.Send(ctx, n)
+}
+
+// localRecorder is implemented by notifiers that can deliver a notification
+// to the event file and socket only (see notify.MultiNotifier.RecordLocal).
+type localRecorder interface {
+	RecordLocal(ctx context.Context, n *notify.Notification) error
+}
+
+// deliverFor delivers n on behalf of agentName, with the agent's recent
+// history for webhooks that include it. Agents listed in
+// agents.notify_disabled skip the notifier: their events only reach the
+// event file and socket. A snooze still applies on top.
+func (w *Watcher) deliverFor(ctx context.Context, agentName string, n *notify.Notification) error {
+	n.History = w.state.History(agentName, w.cfg.Notify.HistorySize())
+	if w.cfg.Agents.NotifyEnabled(agentName) || !w.snoozedUntil().IsZero() {
+		return w.deliver(ctx, n)
+	}
+	Debugf("%s: notifications disabled, recording %q locally", agentName, n.Title)
+	if r, ok := w.notifier.(localRecorder); ok {
+		return r.RecordLocal(ctx, n)
+	}
+	return nil
+}
+
+// snoozedUntil returns when the current snooze ends, or the zero time if
+// alerts aren't snoozed.
+func (w *Watcher) snoozedUntil() time.Time {
+	if w.snoozeFile == "" {
+		return time.Time{}
+	}
+	until, err := ReadSnooze(w.snoozeFile)
+	if err != nil {
+		Debugf("%v", err)
+		return time.Time{}
+	}
+	if !until.After(w.clock.Now()) {
+		return time.Time{}
+	}
+	return until
+}
+
+// markReady writes the ready file, returning a func that removes it.
+func (w *Watcher) markReady() func() {
+	if w.readyFile == "" {
+		return func() {}
+	}
+	if err := os.MkdirAll(filepath.Dir(w.readyFile), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create ready file directory: %v\n", err)
+		return func() {}
+	}
+	if err := os.WriteFile(w.readyFile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write ready file: %v\n", err)
+		return func() {}
+	}
+	return func() { os.Remove(w.readyFile) }
+}
+
+// SetPID tracks pid instead of auto-detecting the agent process.
+// It enables process tracking even if monitor.process_tracking is off.
+// Call before Run.
+func (w *Watcher) SetPID(pid int) {
+	if pid <= 0 {
+		return
+	}
+	if w.procMon == nil {
+		w.procMon = NewProcessMonitor(nil)
+		w.procMon.SetClock(w.clock)
+	}
+	w.procMon.SetPID(pid)
+}
+
 // setupProcessMonitoring initializes process tracking.
-func (w *Watcher) setupProcessMonitoring() {
+func (w *Watcher) setupProcessMonitoring(ctx context.Context) {
 	if w.procMon == nil {
 		return
 	}
@@ -434,24 +1147,104 @@ func (w *Watcher) setupProcessMonitoring() {
 	// Try to detect a PID
 	pid := w.procMon.GetPID()
 	if pid > 0 {
-		w.state.SetPID(pid)
-		w.pidDone = WatchPID(pid)
+		w.trackProcess(ctx, pid)
 		fmt.Printf("  Tracking process: PID %d\n", pid)
 	}
 }
 
+// trackProcess starts tracking pid, which was just detected, and sends a
+// "Process Started" notification (a restart if it replaces another PID).
+func (w *Watcher) trackProcess(ctx context.Context, pid int) {
+	previous := w.state.GetProcess().PID
+	w.state.SetPID(pid)
+	w.state.SetProcessStart(ProcessStartTime(pid))
+	w.state.ResetProcessExited()
+	w.state.ResetProcessIdle()
+	w.procMon.ResetIdleState()
+	w.pidDone = WatchPID(pid)
+
+	n := notify.NewProcessStartNotification(pid, previous)
+	n.Time = w.clock.Now()
+	if err := w.deliver(ctx, n); err != nil {
+		logSendError(w.notifier, "notification", err)
+	}
+}
+
+// processExited handles the tracked process exiting. It is reported at once,
+// or with monitor.min_process_lifetime_sec set, held back that long (see
+// checkPendingExit) so an agent that replaces its process doesn't send an
+// exit and a start for each one.
+func (w *Watcher) processExited(ctx context.Context) {
+	w.pidDone = nil // Prevent repeated handling
+	if w.cfg.MinProcessLifetime() <= 0 || w.procMon.fixed {
+		w.handleProcessExit(ctx)
+		return
+	}
+	w.exitedAt = w.clock.Now()
+}
+
+// checkPendingExit sends a held-back "Process Exit" once
+// monitor.min_process_lifetime_sec has passed. If another agent process was
+// detected first, the exit is dropped and sampleProcess reports that process
+// as a restart instead.
+func (w *Watcher) checkPendingExit(ctx context.Context) {
+	if pid := w.procMon.GetPID(); pid > 0 && pid != w.state.GetProcess().PID {
+		w.exitedAt = time.Time{}
+		w.clearInstanceProcesses(w.state.GetProcess().PID)
+		return
+	}
+	if w.clock.Now().Sub(w.exitedAt) >= w.cfg.MinProcessLifetime() {
+		w.exitedAt = time.Time{}
+		w.handleProcessExit(ctx)
+	}
+}
+
 // handleProcessExit handles when the monitored process exits.
 func (w *Watcher) handleProcessExit(ctx context.Context) {
 	if w.state.IsProcessExitNotified() {
 		return
 	}
 
-	pid := w.state.GetProcess().PID
-	n := notify.NewProcessExitNotification(pid)
-	if err := w.notifier.Send(ctx, n); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", err)
+	proc := w.state.GetProcess()
+	n := notify.NewProcessExitNotification(processExitInfo(proc, w.clock.Now()))
+	if name := w.state.InstanceForPID(proc.PID); name != "" {
+		n.Agent = name // The process wrote this instance's log
+	}
+	if err := w.deliver(ctx, n); err != nil {
+		logSendError(w.notifier, "notification", err)
 	}
 	w.state.MarkProcessExited()
+
+	// Instances it was associated with are reported here, not again
+	w.clearInstanceProcesses(proc.PID)
+}
+
+// clearInstanceProcesses drops the association of an instance with pid.
+func (w *Watcher) clearInstanceProcesses(pid int) {
+	if p := w.instProcs[pid]; p != nil {
+		w.detachInstance(p.path, pid)
+		delete(w.instProcs, pid)
+	}
+}
+
+// detachInstance clears the instance at path's PID if it is still pid.
+func (w *Watcher) detachInstance(path string, pid int) {
+	if w.state.InstancePID(path) == pid {
+		w.state.SetInstancePID(path, 0)
+	}
+}
+
+// processExitInfo summarizes the tracked process from its last sample and start time.
+func processExitInfo(proc *ProcessState, now time.Time) notify.ProcessExitInfo {
+	info := notify.ProcessExitInfo{PID: proc.PID}
+	if sample := proc.LastSample; sample != nil {
+		info.RSSBytes = sample.RSSBytes
+		info.CPUSeconds = sample.CPUSeconds
+	}
+	if !proc.StartTime.IsZero() && now.After(proc.StartTime) {
+		info.Runtime = now.Sub(proc.StartTime)
+	}
+	return info
 }
 
 // sampleProcess samples the monitored process.
@@ -459,6 +1252,10 @@ func (w *Watcher) sampleProcess(ctx context.Context) {
 	if w.procMon == nil {
 		return
 	}
+	w.sampleInstanceProcesses(ctx)
+	if !w.exitedAt.IsZero() {
+		w.checkPendingExit(ctx)
+	}
 
 	// If we don't have a PID yet, try to detect one
 	if w.procMon.GetPID() <= 0 {
@@ -469,8 +1266,7 @@ func (w *Watcher) sampleProcess(ctx context.Context) {
 	currentPID := w.procMon.GetPID()
 	statePID := w.state.GetProcess().PID
 	if currentPID != statePID && currentPID > 0 {
-		w.state.SetPID(currentPID)
-		w.pidDone = WatchPID(currentPID)
+		w.trackProcess(ctx, currentPID)
 		fmt.Printf("  Now tracking process: PID %d\n", currentPID)
 	}
 
@@ -481,20 +1277,155 @@ func (w *Watcher) sampleProcess(ctx context.Context) {
 	if sample := w.procMon.LastSample(); sample != nil {
 		w.state.UpdateProcSample(sample)
 	}
+	w.state.UpdateProcCPU(w.procMon.LastCPU())
+
+	w.checkProcessIdle(ctx)
+}
+
+// instanceProcess is a process associated with a per-instance log.
+type instanceProcess struct {
+	mon   *ProcessMonitor // Fixed to the process's PID
+	start time.Time       // When the process started (zero if unknown)
+	path  string          // The instance's log
+}
+
+// sampleInstanceProcesses associates per-instance logs with the processes
+// writing them, by working directory (see associateInstances), and samples
+// each, so an instance reports its own process's CPU and exit when several
+// agent processes run. Each process is monitored once and reports one exit,
+// for the log it wrote last. Processes are rescanned at most once per detect
+// cooldown. A --pid set explicitly is never associated.
+func (w *Watcher) sampleInstanceProcesses(ctx context.Context) {
+	if w.procMon.fixed {
+		return
+	}
+	instances := w.state.GetAllInstances()
+	if len(instances) == 0 {
+		return
+	}
+
+	now := w.clock.Now()
+	if now.Sub(w.lastInstanceScan) >= w.procMon.detectCooldown {
+		w.lastInstanceScan = now
+		logs := make([]instanceLog, 0, len(instances))
+		for _, inst := range instances {
+			logs = append(logs, instanceLog{Path: inst.FilePath, Written: inst.LastCue})
+		}
+		for path, pid := range associateInstances(logs, w.procMon.scan()) {
+			if old := w.state.InstancePID(path); old != 0 && old != pid {
+				if p := w.instProcs[old]; p != nil && p.path == path {
+					p.path = "" // The log has a new writer
+				}
+			}
+			if p := w.instProcs[pid]; p != nil {
+				if p.path != path {
+					w.detachInstance(p.path, pid) // Moved on to a newer log
+					p.path = path
+					w.state.SetInstancePID(path, pid)
+					Debugf("%s: associated with PID %d", path, pid)
+				}
+				continue
+			}
+			mon := NewProcessMonitor(nil)
+			mon.SetClock(w.clock)
+			mon.SetPID(pid)
+			w.instProcs[pid] = &instanceProcess{mon: mon, start: ProcessStartTime(pid), path: path}
+			w.state.SetInstancePID(path, pid)
+			Debugf("%s: associated with PID %d", path, pid)
+		}
+	}
+
+	globalPID := w.state.GetProcess().PID
+	for pid, p := range w.instProcs {
+		if p.mon.IsAlive() {
+			p.mon.Sample()
+			continue
+		}
+		if pid == globalPID {
+			continue // Reported, and cleared, by handleProcessExit
+		}
+		w.handleInstanceProcessExit(ctx, pid, p)
+	}
+}
+
+// handleInstanceProcessExit reports that the process pid associated with
+// an instance exited, and clears the association.
+func (w *Watcher) handleInstanceProcessExit(ctx context.Context, pid int, p *instanceProcess) {
+	delete(w.instProcs, pid)
+	w.detachInstance(p.path, pid)
+
+	proc := &ProcessState{PID: pid, StartTime: p.start, LastSample: p.mon.LastSample()}
+	n := notify.NewProcessExitNotification(processExitInfo(proc, w.clock.Now()))
+	var agentName string
+	if inst := w.state.GetInstance(p.path); inst != nil {
+		n.Agent = inst.DisplayName
+		agentName = inst.AgentName
+	}
+	n.Time = w.clock.Now()
+	if err := w.deliverFor(ctx, agentName, n); err != nil {
+		logSendError(w.notifier, "notification", err)
+	}
+}
+
+// checkProcessIdle sends "Idle" once the tracked process has stayed below
+// monitor.idle_cpu_percent for monitor.idle_alert_seconds while the logs
+// were silent and the last cue wasn't a completion. Unlike an inferred
+// "Awaiting", this points to a process stuck on something, such as a hung
+// network call. One alert is sent per idle stretch.
+func (w *Watcher) checkProcessIdle(ctx context.Context) {
+	idleFor := w.cfg.IdleAlertDuration()
+	if idleFor <= 0 {
+		return
+	}
+	threshold := w.cfg.IdleCPUThreshold()
+	if !w.procMon.CheckIdle(threshold, idleFor) {
+		if w.procMon.IdleSince().IsZero() && w.state.IsProcessIdleNotified() {
+			w.state.ResetProcessIdle() // Busy again
+		}
+		return
+	}
+
+	// The logs must have been silent as long, mid-turn; otherwise start over
+	now := w.clock.Now()
+	lastCue, cueType := w.state.LastActivity()
+	if lastCue.IsZero() || cueType == detect.MatchComplete || now.Sub(lastCue) < idleFor {
+		w.procMon.ResetIdleState()
+		return
+	}
+
+	idleSince := w.procMon.IdleSince()
+	n := notify.NewIdleNotification(w.procMon.GetPID(), w.procMon.LastCPU(), now.Sub(idleSince))
+	n.Time = now
+	if err := w.deliver(ctx, n); err != nil {
+		logSendError(w.notifier, "notification", err)
+	}
+	w.state.MarkProcessIdle(idleSince)
 }
 
 // Close cleans up watcher resources.
 func (w *Watcher) Close() error {
+	if w.lastSeen != nil {
+		w.lastSeen.Flush()
+	}
 	for _, mgr := range w.managers {
 		mgr.Close()
 	}
+	if w.fsw == nil {
+		return nil
+	}
 	return w.fsw.Close()
 }
 
 // RunPolling runs in polling mode (fallback when fsnotify unavailable).
 func (w *Watcher) RunPolling(ctx context.Context) error {
+	// Seed state from recent history if configured
+	if backfill := w.cfg.BackfillDuration(); backfill > 0 {
+		w.Backfill(w.clock.Now().Add(-backfill))
+	}
+
 	// Setup process monitoring if enabled
-	w.setupProcessMonitoring()
+	w.setupProcessMonitoring(ctx)
+	defer w.markReady()()
 
 	pollInterval := w.cfg.PollInterval()
 	ticker := time.NewTicker(pollInterval)
@@ -506,6 +1437,9 @@ func (w *Watcher) RunPolling(ctx context.Context) error {
 	procTicker := time.NewTicker(5 * time.Second)
 	defer procTicker.Stop()
 
+	discoverTicker := time.NewTicker(5 * time.Second)
+	defer discoverTicker.Stop()
+
 	fmt.Println("Watching for activity (polling mode)...")
 
 	for {
@@ -514,13 +1448,17 @@ func (w *Watcher) RunPolling(ctx context.Context) error {
 			return ctx.Err()
 
 		case <-w.pidDone:
-			w.handleProcessExit(ctx)
-			w.pidDone = nil
+			w.processExited(ctx)
 
 		case <-ticker.C:
 			w.pollAllAgents(ctx)
 
+		case <-discoverTicker.C:
+			w.discoverAgents()
+			w.checkLogFiles(ctx, w.refreshFiles())
+
 		case <-quietTicker.C:
+			w.flushEntries(ctx)
 			w.checkQuietPeriods(ctx)
 
 		case <-procTicker.C:
@@ -532,11 +1470,26 @@ func (w *Watcher) RunPolling(ctx context.Context) error {
 // pollAllAgents polls all agents for new lines.
 func (w *Watcher) pollAllAgents(ctx context.Context) {
 	for name, mgr := range w.managers {
-		mgr.RefreshFiles()
-		newLines := mgr.ReadAllNew()
+		w.readManager(ctx, name, mgr)
+	}
+}
+
+// pollStreams reads new lines for agents that follow a journald unit or a
+// container.
+func (w *Watcher) pollStreams(ctx context.Context) {
+	for name, mgr := range w.managers {
+		if IsStreamPath(mgr.BasePath) {
+			w.readManager(ctx, name, mgr)
+		}
+	}
+}
 
-		for path, lines := range newLines {
-			w.processLines(ctx, name, path, lines)
+// hasStreams reports whether any agent follows a journald unit or a container.
+func (w *Watcher) hasStreams() bool {
+	for _, mgr := range w.managers {
+		if IsStreamPath(mgr.BasePath) {
+			return true
 		}
 	}
+	return false
 }
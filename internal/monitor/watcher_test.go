@@ -0,0 +1,1858 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"firebell/internal/config"
+	"firebell/internal/detect"
+	"firebell/internal/notify"
+)
+
+// recordingNotifier captures notifications for assertions.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []*notify.Notification
+}
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Send(ctx context.Context, n *notify.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func (r *recordingNotifier) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sent)
+}
+
+// perInstanceMode converts a test's on/off flag to the config mode.
+func perInstanceMode(on bool) config.PerInstanceMode {
+	if on {
+		return config.PerInstanceOn
+	}
+	return config.PerInstanceOff
+}
+
+func newTestWatcher(t testing.TB, dir string, perInstance bool) (*Watcher, *recordingNotifier) {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = perInstanceMode(perInstance)
+	cfg.Monitor.QuietSeconds = 15
+
+	rec := &recordingNotifier{}
+	agent := Agent{Name: "claude", DisplayName: "Claude Code", LogPath: dir}
+	w, err := NewWatcher(cfg, rec, []Agent{agent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w, rec
+}
+
+func claudeToolLine(tool, input string) string {
+	return fmt.Sprintf(`{"type":"assistant","message":{"stop_reason":"tool_use","content":[{"type":"tool_use","name":%q,"id":"toolu_1","input":%s}]}}`, tool, input)
+}
+
+func (r *recordingNotifier) titles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	titles := make([]string, 0, len(r.sent))
+	for _, n := range r.sent {
+		titles = append(titles, n.Title)
+	}
+	return titles
+}
+
+func TestWatcherToolLoopAlert(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	w.cfg.Monitor.LoopThreshold = 3
+
+	line := claudeToolLine("Bash", `{"command":"npm test"}`)
+	for i := 0; i < 6; i++ {
+		w.processLines(context.Background(), "claude", "session.jsonl", []string{line})
+	}
+
+	loops := 0
+	for _, title := range rec.titles() {
+		if title == "Possible loop" {
+			loops++
+		}
+	}
+	if loops != 1 {
+		t.Errorf("Expected 1 loop alert, got %d (%v)", loops, rec.titles())
+	}
+	if msg := rec.sent[0].Message; !strings.Contains(msg, "Bash requested 4 times") {
+		t.Errorf("Unexpected loop message %q", msg)
+	}
+}
+
+func TestWatcherToolLoopDifferentArgs(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	w.cfg.Monitor.LoopThreshold = 3
+
+	for i := 0; i < 6; i++ {
+		line := claudeToolLine("Bash", fmt.Sprintf(`{"command":"step %d"}`, i))
+		w.processLines(context.Background(), "claude", "session.jsonl", []string{line})
+	}
+
+	if rec.count() != 0 {
+		t.Errorf("Expected no loop alert for varying args, got %v", rec.titles())
+	}
+}
+
+func TestWatcherToolLoopDisabled(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	w.cfg.Monitor.LoopThreshold = 0
+
+	line := claudeToolLine("Bash", `{"command":"npm test"}`)
+	for i := 0; i < 10; i++ {
+		w.processLines(context.Background(), "claude", "session.jsonl", []string{line})
+	}
+
+	if rec.count() != 0 {
+		t.Errorf("Expected no alerts when disabled, got %v", rec.titles())
+	}
+}
+
+func TestWatcherProcessExitMetadata(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+
+	w.state.SetPID(4242)
+	w.state.SetProcessStart(time.Now().Add(-12 * time.Minute))
+	w.state.UpdateProcSample(&ProcSample{CPUSeconds: 95.5, RSSBytes: 800 * 1024 * 1024})
+
+	w.handleProcessExit(context.Background())
+	w.handleProcessExit(context.Background()) // Only notifies once
+
+	if rec.count() != 1 {
+		t.Fatalf("Expected 1 exit notification, got %d", rec.count())
+	}
+	n := rec.sent[0]
+	if !strings.Contains(n.Message, "ran 12m") || !strings.Contains(n.Message, "RSS 800.0MiB") {
+		t.Errorf("Unexpected exit message %q", n.Message)
+	}
+
+	event := notify.NewEventFromNotification(n, notify.DetermineEventType(n))
+	if event.Event != notify.EventProcessExit {
+		t.Errorf("Event = %q, want process_exit", event.Event)
+	}
+	if event.Metadata["pid"] != 4242 {
+		t.Errorf("Metadata[pid] = %v, want 4242", event.Metadata["pid"])
+	}
+	if event.Metadata["rss_bytes"] != int64(800*1024*1024) {
+		t.Errorf("Metadata[rss_bytes] = %v", event.Metadata["rss_bytes"])
+	}
+	if event.Metadata["cpu_seconds"] != 95.5 {
+		t.Errorf("Metadata[cpu_seconds] = %v, want 95.5", event.Metadata["cpu_seconds"])
+	}
+	if runtime, ok := event.Metadata["runtime_seconds"].(float64); !ok || runtime < 12*60 {
+		t.Errorf("Metadata[runtime_seconds] = %v, want >= 720", event.Metadata["runtime_seconds"])
+	}
+}
+
+func TestProcessExitInfoUnknownStats(t *testing.T) {
+	info := processExitInfo(&ProcessState{PID: 7}, time.Now())
+	n := notify.NewProcessExitNotification(info)
+
+	if n.Message != "Monitored process (PID 7) has terminated" {
+		t.Errorf("Unexpected message %q", n.Message)
+	}
+	if _, ok := n.Metadata["rss_bytes"]; ok {
+		t.Error("Unknown RSS should not be reported")
+	}
+}
+
+func TestWatcherDisplayNameOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		perInstance bool
+		override    string
+		want        string
+	}{
+		{"agent", false, "Main Claude", "Main Claude"},
+		{"agent strips placeholder", false, "Main Claude ({instance})", "Main Claude"},
+		{"instance appended", true, "Review Claude", "Review Claude (abc12345)"},
+		{"instance template", true, "Review {instance}", "Review abc12345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := config.DefaultConfig()
+			cfg.Monitor.ProcessTracking = false
+			cfg.Monitor.PerInstance = perInstanceMode(tt.perInstance)
+			cfg.Monitor.QuietSeconds = 15
+			cfg.Agents.DisplayNames = map[string]string{"claude": tt.override}
+
+			rec := &recordingNotifier{}
+			agent := Agent{Name: "claude", DisplayName: "Claude Code", LogPath: dir}
+			w, err := NewWatcher(cfg, rec, []Agent{agent})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+			clock := newFakeClock()
+			w.SetClock(clock)
+
+			path := filepath.Join(dir, "abc12345", "session.jsonl")
+			w.processLines(context.Background(), "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+			clock.Advance(time.Minute)
+			w.checkQuietPeriods(context.Background())
+
+			if rec.count() != 1 {
+				t.Fatalf("Expected 1 notification, got %v", rec.titles())
+			}
+			if got := rec.sent[0].Agent; got != tt.want {
+				t.Errorf("Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcherDumpStatus(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := newTestWatcher(t, dir, true)
+
+	path := filepath.Join(dir, "abc12345", "session.jsonl")
+	w.state.UpdateWatchedPaths("claude", []string{path})
+	w.processLines(context.Background(), "claude", path, []string{claudeLine(time.Now(), "end_turn")})
+	w.state.SetPID(4242)
+	w.state.UpdateProcCPU(12.5)
+
+	snap := w.DumpStatus()
+
+	if len(snap.Agents) != 1 {
+		t.Fatalf("Expected 1 agent, got %d", len(snap.Agents))
+	}
+	a := snap.Agents[0]
+	if a.Name != "claude" || a.DisplayName != "Claude Code" {
+		t.Errorf("Agent = %q/%q", a.Name, a.DisplayName)
+	}
+	if len(a.WatchedPaths) != 1 || a.WatchedPaths[0] != path {
+		t.Errorf("WatchedPaths = %v", a.WatchedPaths)
+	}
+
+	if len(snap.Instances) != 1 {
+		t.Fatalf("Expected 1 instance, got %d", len(snap.Instances))
+	}
+	inst := snap.Instances[0]
+	if inst.LastCueType != "complete" || inst.LastCue.IsZero() {
+		t.Errorf("Instance cue = %s at %v, want complete", inst.LastCueType, inst.LastCue)
+	}
+	if inst.DisplayName != "Claude Code (abc12345)" {
+		t.Errorf("Instance DisplayName = %q", inst.DisplayName)
+	}
+
+	if snap.Process.PID != 4242 || snap.Process.CPUPercent != 12.5 {
+		t.Errorf("Process = %+v", snap.Process)
+	}
+
+	lines := snap.Lines()
+	if !strings.Contains(strings.Join(lines, "\n"), "PID 4242") {
+		t.Errorf("Lines missing process: %v", lines)
+	}
+}
+
+func TestWatcherDumpStatusNotifiers(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+
+	multi := notify.NewMultiNotifier(&recordingNotifier{}, &recordingNotifier{})
+	w, err := NewWatcher(cfg, multi, []Agent{{Name: "claude", DisplayName: "Claude Code", LogPath: dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	path := filepath.Join(dir, "session.jsonl")
+	w.processLines(context.Background(), "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second)
+	w.checkQuietPeriods(context.Background())
+
+	snap := w.DumpStatus()
+	if len(snap.Notifiers) != 2 {
+		t.Fatalf("Expected 2 notifier statuses, got %+v", snap.Notifiers)
+	}
+	if snap.Notifiers[1].Name != "recording#2" || snap.Notifiers[1].LastSend.IsZero() {
+		t.Errorf("Notifiers[1] = %+v, want recording#2 with a send", snap.Notifiers[1])
+	}
+	if !strings.Contains(strings.Join(snap.Lines(), "\n"), "Notifier recording: ok") {
+		t.Errorf("Lines missing notifier health: %v", snap.Lines())
+	}
+
+	// Rate-limit drops are counted in the snapshot
+	multi.SetRateLimit(1)
+	for i := 0; i < 3; i++ {
+		multi.Send(context.Background(), &notify.Notification{Title: "Activity Detected"})
+	}
+	snap = w.DumpStatus()
+	if snap.Dropped != 2 || !strings.Contains(strings.Join(snap.Lines(), "\n"), "Rate limit dropped 2 notification(s)") {
+		t.Errorf("Dropped = %d, lines %v; want 2 drops reported", snap.Dropped, snap.Lines())
+	}
+}
+
+func TestWatcherCountsParseErrors(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+
+	w.processLines(context.Background(), "claude", "session.jsonl", []string{
+		`{"type":"assistant","message":{"stop_rea`,
+		`{"type":"user"}`,
+		"",
+		`{"type":"assistant",}`,
+	})
+
+	snap := w.DumpStatus()
+	a := snap.Agents[0]
+	if a.LinesRead != 3 || a.ParseErrors != 2 {
+		t.Errorf("LinesRead/ParseErrors = %d/%d, want 3/2", a.LinesRead, a.ParseErrors)
+	}
+	if !w.state.GetAgent("claude").LastCue.IsZero() {
+		t.Error("Malformed lines should not record a cue")
+	}
+	if rec.count() != 0 {
+		t.Errorf("Expected no notifications, got %v", rec.titles())
+	}
+	if !strings.Contains(snap.Lines()[0], "2 malformed of 3 line(s)") {
+		t.Errorf("Lines missing parse errors: %v", snap.Lines())
+	}
+}
+
+// panickyMatcher panics on lines containing "boom" and otherwise defers
+// to the wrapped matcher.
+type panickyMatcher struct{ detect.Matcher }
+
+func (m panickyMatcher) Match(line string) *detect.Match {
+	if strings.Contains(line, "boom") {
+		panic("malformed input")
+	}
+	return m.Matcher.Match(line)
+}
+
+func TestWatcherRecoversMatcherPanic(t *testing.T) {
+	w, _ := newTestWatcher(t, t.TempDir(), false)
+	w.matchers["claude"] = panickyMatcher{w.matchers["claude"]}
+
+	now := time.Now()
+	w.processLines(context.Background(), "claude", "session.jsonl", []string{"boom", claudeLine(now, "end_turn")})
+
+	// The line after the panic is still processed
+	if a := w.state.GetAgent("claude"); a.LastCueType != detect.MatchComplete {
+		t.Errorf("LastCueType = %v, want Complete from the line after the panic", a.LastCueType)
+	}
+	snap := w.DumpStatus()
+	if snap.Panics != 1 || snap.Agents[0].LinesRead != 2 {
+		t.Errorf("Panics/LinesRead = %d/%d, want 1/2", snap.Panics, snap.Agents[0].LinesRead)
+	}
+	if !slices.Contains(snap.Lines(), "Recovered from 1 panic(s)") {
+		t.Errorf("Lines missing panic count: %v", snap.Lines())
+	}
+}
+
+func TestWatcherFocus(t *testing.T) {
+	t.Run("agents", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := config.DefaultConfig()
+		cfg.Monitor.ProcessTracking = false
+		cfg.Monitor.PerInstance = config.PerInstanceOff
+		cfg.Monitor.QuietSeconds = 15
+		cfg.Monitor.Focus = true
+
+		rec := &recordingNotifier{}
+		w, err := NewWatcher(cfg, rec, []Agent{
+			{Name: "claude", DisplayName: "Claude Code", LogPath: dir},
+			{Name: "gemini", DisplayName: "Gemini", LogPath: dir},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Close()
+		clock := newFakeClock()
+		w.SetClock(clock)
+
+		w.processLines(context.Background(), "claude", "claude.jsonl", []string{claudeLine(clock.Now(), "end_turn")})
+		clock.Advance(2 * time.Second)
+		w.processLines(context.Background(), "gemini", "gemini.json", []string{`{"type":"gemini"}`})
+
+		clock.Advance(20 * time.Second)
+		w.checkQuietPeriods(context.Background())
+		if rec.count() != 1 || rec.sent[0].Agent != "Gemini" {
+			t.Fatalf("Expected one notification for Gemini, got %v", rec.sent)
+		}
+
+		// Claude was silenced, not deferred: it stays quiet until its next cue
+		clock.Advance(20 * time.Second)
+		w.checkQuietPeriods(context.Background())
+		if rec.count() != 1 {
+			t.Errorf("Silenced agent notified later: %v", rec.titles())
+		}
+	})
+
+	t.Run("instances", func(t *testing.T) {
+		w, rec := newTestWatcher(t, t.TempDir(), true)
+		w.cfg.Monitor.Focus = true
+		clock := newFakeClock()
+		w.SetClock(clock)
+
+		w.processLines(context.Background(), "claude", "/p/aaa/s.jsonl", []string{claudeLine(clock.Now(), "end_turn")})
+		clock.Advance(time.Second)
+		w.processLines(context.Background(), "claude", "/p/bbb/s.jsonl", []string{claudeLine(clock.Now(), "end_turn")})
+
+		clock.Advance(20 * time.Second)
+		w.checkQuietPeriods(context.Background())
+		if rec.count() != 1 || !strings.Contains(rec.sent[0].Agent, "bbb") {
+			t.Fatalf("Expected one notification for instance bbb, got %v", rec.sent)
+		}
+	})
+}
+
+func TestWatcherMinCompleteLines(t *testing.T) {
+	restoreRegistry(t)
+	Registry["chattool"] = Agent{Name: "chattool", DisplayName: "Chat Tool", Format: detect.FormatOpenAIChat}
+
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = config.PerInstanceOff
+	cfg.Agents.MinCompleteLines = 2
+
+	w, err := NewWatcher(cfg, &recordingNotifier{}, []Agent{
+		{Name: "mytool", DisplayName: "My Tool", LogPath: dir},
+		{Name: "claude", DisplayName: "Claude Code", LogPath: dir},
+		{Name: "chattool", DisplayName: "Chat Tool", LogPath: dir},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	ctx := context.Background()
+
+	// A lone "done" is ignored
+	w.processLines(ctx, "mytool", "tool.log", []string{"done"})
+	if cue := w.state.GetLastCueType("mytool"); cue == detect.MatchComplete {
+		t.Fatal("Lone completion line should be ignored")
+	}
+
+	// One activity cue is still not enough
+	w.processLines(ctx, "mytool", "tool.log", []string{"thinking", "done"})
+	if cue := w.state.GetLastCueType("mytool"); cue == detect.MatchComplete {
+		t.Fatal("Completion after 1 activity cue should be ignored")
+	}
+
+	w.processLines(ctx, "mytool", "tool.log", []string{"thinking", "done"})
+	if cue := w.state.GetLastCueType("mytool"); cue != detect.MatchComplete {
+		t.Fatalf("Completion after 2 activity cues should count, got %s", cue)
+	}
+	if n := w.state.ActivitySinceComplete("mytool"); n != 0 {
+		t.Errorf("Activity count after completion = %d, want 0", n)
+	}
+
+	// Structured matchers are unaffected
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(time.Now(), "end_turn")})
+	if cue := w.state.GetLastCueType("claude"); cue != detect.MatchComplete {
+		t.Errorf("Claude completion should not be gated, got %s", cue)
+	}
+
+	// So are agents given a format by the registry file
+	w.processLines(ctx, "chattool", "chat.jsonl", []string{`{"choices":[{"finish_reason":"stop","message":{"content":"Done!"}}]}`})
+	if cue := w.state.GetLastCueType("chattool"); cue != detect.MatchComplete {
+		t.Errorf("Registry-format completion should not be gated, got %s", cue)
+	}
+}
+
+func TestWatcherSetPID(t *testing.T) {
+	w, _ := newTestWatcher(t, t.TempDir(), false)
+	if w.procMon != nil {
+		t.Fatal("process tracking should be off in test watcher")
+	}
+
+	pid := os.Getpid()
+	w.SetPID(pid)
+	w.setupProcessMonitoring(context.Background())
+
+	if got := w.state.GetProcess().PID; got != pid {
+		t.Errorf("tracked PID = %d, want %d", got, pid)
+	}
+	if !w.procMon.lastDetect.IsZero() {
+		t.Error("auto-detection should be skipped for an explicit PID")
+	}
+}
+
+func TestWatcherProcessIdle(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	w.cfg.Monitor.IdleAlertSeconds = 60
+	w.procMon = NewProcessMonitor(nil)
+	w.procMon.SetPID(os.Getpid())
+	w.state.SetPID(os.Getpid())
+	clock := newFakeClock()
+	w.SetClock(clock)
+	ctx := context.Background()
+
+	// sample feeds one CPU reading, ten seconds after the last
+	sample := func(cpu float64) {
+		clock.Advance(10 * time.Second)
+		w.procMon.lastCPU = cpu
+		w.checkProcessIdle(ctx)
+	}
+
+	// Mid-turn, the logs go silent and the CPU drops; the first low
+	// sample starts the idle stretch
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "")})
+	for i := 0; i < 6; i++ {
+		sample(0.2)
+	}
+	if rec.count() != 0 {
+		t.Fatalf("Idle sent after 50s, before idle_alert_seconds: %v", rec.titles())
+	}
+	sample(0.2)
+	if got := rec.titles(); len(got) != 1 || got[0] != "Idle" {
+		t.Fatalf("Sent %v after 60s idle, want Idle", got)
+	}
+	event := notify.NewEventFromNotification(rec.sent[0], notify.DetermineEventType(rec.sent[0]))
+	if event.Event != notify.EventIdle || event.Metadata["cpu_percent"] != 0.2 || !strings.Contains(event.Message, "CPU: 0.2%") {
+		t.Errorf("Idle event = %+v", event)
+	}
+	if !w.state.GetProcess().IdleNotified {
+		t.Error("ProcessState.IdleNotified not set")
+	}
+
+	// Once per idle stretch
+	for i := 0; i < 10; i++ {
+		sample(0.2)
+	}
+	if rec.count() != 1 {
+		t.Errorf("Idle repeated: %v", rec.titles())
+	}
+
+	// Busy again, then idle again: a new stretch alerts again
+	sample(40)
+	if w.state.GetProcess().IdleNotified {
+		t.Error("IdleNotified not reset when the CPU rose")
+	}
+	for i := 0; i < 7; i++ {
+		sample(0.2)
+	}
+	if rec.count() != 2 {
+		t.Errorf("Sent %v, want a second Idle", rec.titles())
+	}
+}
+
+func TestWatcherProcessIdleAfterCompletion(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	w.cfg.Monitor.IdleAlertSeconds = 30
+	w.procMon = NewProcessMonitor(nil)
+	w.procMon.SetPID(os.Getpid())
+	clock := newFakeClock()
+	w.SetClock(clock)
+	ctx := context.Background()
+
+	// An idle process after a finished turn is just waiting for the user
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "end_turn")})
+	for i := 0; i < 10; i++ {
+		clock.Advance(10 * time.Second)
+		w.procMon.lastCPU = 0.1
+		w.checkProcessIdle(ctx)
+	}
+	if rec.count() != 0 {
+		t.Errorf("Sent %v after a completed turn, want nothing", rec.titles())
+	}
+
+	// Logs still being written keep an idle CPU from alerting
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "")})
+	for i := 0; i < 10; i++ {
+		clock.Advance(10 * time.Second)
+		w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "")})
+		w.procMon.lastCPU = 0.1
+		w.checkProcessIdle(ctx)
+	}
+	if rec.count() != 0 {
+		t.Errorf("Sent %v while logs were active, want nothing", rec.titles())
+	}
+}
+
+func TestWatcherProcessStart(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	ctx := context.Background()
+
+	// First detection
+	pid := os.Getpid()
+	w.SetPID(pid)
+	w.setupProcessMonitoring(ctx)
+	if got := rec.titles(); len(got) != 1 || got[0] != "Process Started" {
+		t.Fatalf("Sent %v, want Process Started", got)
+	}
+	event := notify.NewEventFromNotification(rec.sent[0], notify.DetermineEventType(rec.sent[0]))
+	if event.Event != notify.EventProcessStart || event.Metadata["pid"] != pid || event.Metadata["previous_pid"] != nil {
+		t.Errorf("Start event = %+v", event)
+	}
+
+	// Sampling the same process doesn't repeat it
+	w.sampleProcess(ctx)
+	if rec.count() != 1 {
+		t.Errorf("Sent %v after resampling, want only the start", rec.titles())
+	}
+
+	// The process exits and a new one takes its place
+	child := exec.Command("sleep", "10")
+	if err := child.Start(); err != nil {
+		t.Skipf("cannot start a child process: %v", err)
+	}
+	defer child.Process.Kill()
+
+	w.handleProcessExit(ctx)
+	w.procMon.SetPID(child.Process.Pid)
+	w.sampleProcess(ctx)
+	w.handleProcessExit(ctx) // The new process's exit is reported too
+
+	want := []string{"Process Started", "Process Exited", "Process Started", "Process Exited"}
+	if got := rec.titles(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Sent %v, want %v", got, want)
+	}
+	restart := rec.sent[2]
+	if restart.Metadata["pid"] != child.Process.Pid || restart.Metadata["previous_pid"] != pid {
+		t.Errorf("Restart metadata = %v, want pid %d after %d", restart.Metadata, child.Process.Pid, pid)
+	}
+	if !strings.Contains(restart.Message, "restarted") {
+		t.Errorf("Restart message = %q", restart.Message)
+	}
+}
+
+func TestWatcherInstanceProcesses(t *testing.T) {
+	dir := t.TempDir()
+	w, rec := newTestWatcher(t, dir, true)
+	w.procMon = NewProcessMonitor([]string{"sleep 417"})
+	ctx := context.Background()
+
+	// Two agent processes, each running in its own project
+	start := func(cwd string) *exec.Cmd {
+		if err := os.MkdirAll(cwd, 0755); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("sleep", "417")
+		cmd.Dir = cwd
+		if err := cmd.Start(); err != nil {
+			t.Skipf("cannot start a child process: %v", err)
+		}
+		t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+		return cmd
+	}
+	logs := make(map[*exec.Cmd]string)
+	var stale []string // Earlier sessions in the same projects
+	for _, project := range []string{"api", "web"} {
+		cmd := start(filepath.Join(dir, "work", project))
+		logs[cmd] = filepath.Join(dir, "projects", ProjectKey(cmd.Dir), "session.jsonl")
+		stale = append(stale, filepath.Join(dir, "projects", ProjectKey(cmd.Dir), "earlier.jsonl"))
+		w.processLines(ctx, "claude", stale[len(stale)-1], []string{claudeLine(time.Now(), "")})
+		w.processLines(ctx, "claude", logs[cmd], []string{claudeLine(time.Now(), "")})
+	}
+
+	w.sampleProcess(ctx)
+	for cmd, path := range logs {
+		if got := w.state.InstancePID(path); got != cmd.Process.Pid {
+			t.Errorf("%s instance PID = %d, want %d", cmd.Dir, got, cmd.Process.Pid)
+		}
+	}
+	for _, path := range stale {
+		if got := w.state.InstancePID(path); got != 0 {
+			t.Errorf("%s instance PID = %d, want only the newest log associated", path, got)
+		}
+	}
+
+	// One process is tracked globally; the other's exit is still reported,
+	// for its own instance
+	var tracked, other *exec.Cmd
+	for cmd := range logs {
+		if cmd.Process.Pid == w.state.GetProcess().PID {
+			tracked = cmd
+		} else {
+			other = cmd
+		}
+	}
+	if tracked == nil {
+		t.Fatalf("Tracking PID %d, want one of the agent processes", w.state.GetProcess().PID)
+	}
+	other.Process.Kill()
+	other.Wait()
+	w.sampleProcess(ctx)
+
+	if got := rec.titles(); strings.Join(got, ",") != "Process Started,Process Exited" {
+		t.Fatalf("Sent %v, want the start and the other process's exit", got)
+	}
+	exit := rec.sent[1]
+	if exit.Agent != w.state.GetInstance(logs[other]).DisplayName || exit.Metadata["pid"] != other.Process.Pid {
+		t.Errorf("Exit = %q with %v, want the instance of PID %d", exit.Agent, exit.Metadata, other.Process.Pid)
+	}
+	if w.state.InstancePID(logs[other]) != 0 {
+		t.Error("The exited process should no longer be associated")
+	}
+
+	// The tracked process's exit names its instance, once
+	tracked.Process.Kill()
+	tracked.Wait()
+	w.sampleProcess(ctx)
+	w.handleProcessExit(ctx)
+	if rec.count() != 3 || rec.sent[2].Agent != w.state.GetInstance(logs[tracked]).DisplayName {
+		t.Errorf("Sent %v, want one exit for the tracked process's instance", rec.titles())
+	}
+	if w.state.InstancePID(logs[tracked]) != 0 {
+		t.Error("The exited process should no longer be associated")
+	}
+}
+
+func TestWatcherInstanceProcessExitNotifyDisabled(t *testing.T) {
+	dir := t.TempDir()
+	w, rec := newTestWatcher(t, dir, true)
+	w.cfg.Agents.NotifyDisabled = []string{"claude"}
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "session.jsonl")
+	w.processLines(ctx, "claude", path, []string{claudeLine(time.Now(), "")})
+	exit := func(pid int) {
+		p := &instanceProcess{mon: NewProcessMonitor(nil), path: path}
+		w.instProcs[pid] = p
+		w.state.SetInstancePID(path, pid)
+		w.handleInstanceProcessExit(ctx, pid, p)
+	}
+
+	exit(4242)
+	if rec.count() != 0 {
+		t.Errorf("Sent %v for a notify-disabled agent's instance", rec.titles())
+	}
+
+	w.cfg.Agents.NotifyDisabled = nil
+	exit(4243)
+	if got := rec.titles(); len(got) != 1 || got[0] != "Process Exited" {
+		t.Errorf("Sent %v once re-enabled, want one Process Exited", got)
+	}
+}
+
+func TestWatcherProcessExitDebounce(t *testing.T) {
+	ctx := context.Background()
+	setup := func(candidates ...string) (*Watcher, *recordingNotifier, *fakeClock) {
+		w, rec := newTestWatcher(t, t.TempDir(), false)
+		w.cfg.Monitor.MinProcessLifetimeSec = 30
+		w.procMon = NewProcessMonitor(candidates)
+		clock := newFakeClock()
+		w.SetClock(clock)
+		w.state.SetPID(1 << 30) // The tracked process, now gone
+		return w, rec, clock
+	}
+
+	t.Run("exit sent after min lifetime", func(t *testing.T) {
+		w, rec, clock := setup() // No agent process to replace it
+		w.processExited(ctx)
+		if rec.count() != 0 {
+			t.Fatalf("Sent %v at exit, want it held back", rec.titles())
+		}
+		clock.Advance(29 * time.Second)
+		w.sampleProcess(ctx)
+		if rec.count() != 0 {
+			t.Fatalf("Sent %v before min_process_lifetime_sec", rec.titles())
+		}
+		clock.Advance(time.Second)
+		w.sampleProcess(ctx)
+		w.sampleProcess(ctx)
+		if got := rec.titles(); strings.Join(got, ",") != "Process Exited" {
+			t.Errorf("Sent %v, want one Process Exited", got)
+		}
+	})
+
+	t.Run("replacement is a restart", func(t *testing.T) {
+		cmd := exec.Command("sleep", "422")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("cannot start a child process: %v", err)
+		}
+		t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+
+		w, rec, clock := setup("sleep 422")
+		w.processExited(ctx)
+		clock.Advance(5 * time.Second)
+		w.sampleProcess(ctx)
+		clock.Advance(time.Minute)
+		w.sampleProcess(ctx)
+
+		if got := rec.titles(); strings.Join(got, ",") != "Process Started" {
+			t.Fatalf("Sent %v, want only the restart", got)
+		}
+		if rec.sent[0].Metadata["previous_pid"] != 1<<30 || w.state.GetProcess().PID != cmd.Process.Pid {
+			t.Errorf("Start = %v, want PID %d replacing the exited one", rec.sent[0].Metadata, cmd.Process.Pid)
+		}
+	})
+}
+
+func TestWatcherHoldingDeferred(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	w.processLines(context.Background(), "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"ls"}`)})
+	if rec.count() != 0 {
+		t.Fatalf("Expected no immediate notification, got %v", rec.titles())
+	}
+
+	// Quiet period elapses with the tool request still pending
+	clock.Advance(14 * time.Second)
+	w.checkQuietPeriods(context.Background())
+	if rec.count() != 0 {
+		t.Fatalf("Expected no notification before the quiet period, got %v", rec.titles())
+	}
+	clock.Advance(time.Second)
+	w.checkQuietPeriods(context.Background())
+
+	if titles := rec.titles(); len(titles) != 1 || titles[0] != "Holding" {
+		t.Errorf("Expected one deferred Holding, got %v", titles)
+	}
+}
+
+func TestWatcherHoldingImmediate(t *testing.T) {
+	for _, perInstance := range []bool{false, true} {
+		w, rec := newTestWatcher(t, t.TempDir(), perInstance)
+		clock := newFakeClock()
+		w.SetClock(clock)
+		w.cfg.Monitor.ImmediateHolding = true
+
+		w.processLines(context.Background(), "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"ls"}`)})
+		if titles := rec.titles(); len(titles) != 1 || titles[0] != "Holding" {
+			t.Fatalf("perInstance=%v: expected immediate Holding, got %v", perInstance, titles)
+		}
+		if got := rec.sent[0].Agent; !strings.HasPrefix(got, "Claude Code") {
+			t.Errorf("perInstance=%v: Agent = %q, want Claude Code", perInstance, got)
+		}
+
+		// The quiet check must not repeat it
+		clock.Advance(time.Minute)
+		w.checkQuietPeriods(context.Background())
+		if rec.count() != 1 {
+			t.Errorf("perInstance=%v: expected no repeat after quiet, got %v", perInstance, rec.titles())
+		}
+	}
+}
+
+func TestWatcherNotifyFirstHolding(t *testing.T) {
+	for _, perInstance := range []bool{false, true} {
+		w, rec := newTestWatcher(t, t.TempDir(), perInstance)
+		clock := newFakeClock()
+		w.SetClock(clock)
+		w.cfg.Monitor.NotifyFirstHolding = true
+		ctx := context.Background()
+		tool := claudeToolLine("Bash", `{"command":"ls"}`)
+
+		// The turn's first request notifies at once
+		w.processLines(ctx, "claude", "session.jsonl", []string{tool})
+		if titles := rec.titles(); len(titles) != 1 || titles[0] != "Holding" {
+			t.Fatalf("perInstance=%v: expected immediate Holding, got %v", perInstance, titles)
+		}
+
+		// A second in the same turn waits for the quiet period
+		w.processLines(ctx, "claude", "session.jsonl", []string{tool})
+		if rec.count() != 1 {
+			t.Errorf("perInstance=%v: second Holding sent immediately: %v", perInstance, rec.titles())
+		}
+		clock.Advance(time.Minute)
+		w.checkQuietPeriods(ctx)
+		if titles := rec.titles(); len(titles) != 2 || titles[1] != "Holding" {
+			t.Errorf("perInstance=%v: expected the second Holding after quiet, got %v", perInstance, titles)
+		}
+
+		// A completion starts a new turn
+		w.processLines(ctx, "claude", "session.jsonl", []string{claudeLine(clock.Now(), "end_turn"), tool})
+		if titles := rec.titles(); len(titles) != 3 || titles[2] != "Holding" {
+			t.Errorf("perInstance=%v: expected immediate Holding in the next turn, got %v", perInstance, titles)
+		}
+	}
+}
+
+func TestWatcherHoldingAction(t *testing.T) {
+	for _, perInstance := range []bool{false, true} {
+		w, rec := newTestWatcher(t, t.TempDir(), perInstance)
+		clock := newFakeClock()
+		w.SetClock(clock)
+		w.cfg.Monitor.SensitiveTools = []string{"git push"}
+		w.sensitive = newSensitiveTools(w.cfg.Monitor.SensitiveTools)
+		ctx := context.Background()
+
+		// A Holding sent after the quiet period carries the tool's action hint
+		w.processLines(ctx, "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"ls -la"}`)})
+		clock.Advance(time.Minute)
+		w.checkQuietPeriods(ctx)
+		if rec.count() != 1 {
+			t.Fatalf("perInstance=%v: expected one Holding, got %v", perInstance, rec.titles())
+		}
+		event := notify.NewEventFromNotification(rec.sent[0], notify.DetermineEventType(rec.sent[0]))
+		data, err := event.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Event    string `json:"event"`
+			Metadata struct {
+				Action struct {
+					Tool            string `json:"tool"`
+					Args            string `json:"args"`
+					SuggestedAction string `json:"suggested_action"`
+				} `json:"action"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		action := decoded.Metadata.Action
+		if decoded.Event != "holding" || action.Tool != "Bash" || !strings.Contains(action.Args, "ls -la") || action.SuggestedAction != notify.ActionApprove {
+			t.Errorf("perInstance=%v: event = %s", perInstance, data)
+		}
+
+		// A sensitive tool suggests a review
+		w.processLines(ctx, "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"git push origin main"}`)})
+		if rec.count() != 2 {
+			t.Fatalf("perInstance=%v: expected a sensitive Holding, got %v", perInstance, rec.titles())
+		}
+		got, _ := rec.sent[1].Metadata["action"].(map[string]any)
+		if got["suggested_action"] != notify.ActionReview {
+			t.Errorf("perInstance=%v: sensitive action = %v, want review", perInstance, got)
+		}
+	}
+}
+
+func TestWatcherSensitiveToolImmediate(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+	w.cfg.Monitor.SensitiveTools = []string{"rm", "write_file", "git push"}
+	w.sensitive = newSensitiveTools(w.cfg.Monitor.SensitiveTools)
+
+	// A read-only command still waits for the quiet period
+	ctx := context.Background()
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"git status"}`)})
+	if rec.count() != 0 {
+		t.Fatalf("Non-sensitive tool sent %v immediately", rec.titles())
+	}
+
+	// A destructive one alerts at once, at high priority, even with
+	// immediate_holding off
+	w.processLines(ctx, "claude", "session.jsonl", []string{claudeToolLine("Bash", `{"command":"git push origin main"}`)})
+	if titles := rec.titles(); len(titles) != 1 || titles[0] != "Holding" {
+		t.Fatalf("Expected an immediate Holding, got %v", titles)
+	}
+	n := rec.sent[0]
+	if n.Priority != notify.PriorityHigh {
+		t.Errorf("Priority = %q, want high", n.Priority)
+	}
+	if !strings.Contains(n.Message, "git push") || n.Metadata["tool"] != "Bash" {
+		t.Errorf("Notification = %q %v, want it to name git push and Bash", n.Message, n.Metadata)
+	}
+
+	// The quiet check must not repeat it
+	clock.Advance(time.Minute)
+	w.checkQuietPeriods(ctx)
+	if rec.count() != 1 {
+		t.Errorf("Expected no repeat after quiet, got %v", rec.titles())
+	}
+}
+
+func TestWatcherCompaction(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	ctx := context.Background()
+	compact := `{"type":"system","subtype":"compact_boundary","content":"Conversation compacted","compactMetadata":{"trigger":"auto","preTokens":155000}}`
+	w.processLines(ctx, "claude", "session.jsonl", []string{compact})
+	titles := rec.titles()
+	if len(titles) != 1 || titles[0] != "Compacted" {
+		t.Fatalf("Expected an immediate Compacted, got %v", titles)
+	}
+	n := rec.sent[0]
+	if notify.DetermineEventType(n) != notify.EventCompaction {
+		t.Errorf("Event type = %q, want compaction", notify.DetermineEventType(n))
+	}
+	if n.Metadata["trigger"] != "auto" || n.Metadata["pre_tokens"] != 155000 {
+		t.Errorf("Metadata = %v, want trigger and pre_tokens", n.Metadata)
+	}
+
+	// The turn goes on: a compaction counts as activity, not a new strong cue
+	if got := w.state.GetLastCueType("claude"); got != detect.MatchActivity {
+		t.Errorf("Last cue = %v, want activity", got)
+	}
+}
+
+func TestWatcherRejectsPathOutsideRoots(t *testing.T) {
+	allowed, outside := t.TempDir(), t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Advanced.AllowedRoots = []string{allowed}
+
+	w, err := NewWatcher(cfg, &recordingNotifier{}, []Agent{{Name: "claude", DisplayName: "Claude Code", LogPath: outside}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := w.addWatch(outside); !errors.Is(err, ErrOutsideRoots) {
+		t.Errorf("addWatch outside the roots = %v, want ErrOutsideRoots", err)
+	}
+	if len(w.fsw.WatchList()) != 0 {
+		t.Errorf("Watching %v despite allowed_roots", w.fsw.WatchList())
+	}
+	if err := w.addWatch(allowed); err != nil {
+		t.Errorf("addWatch inside the roots = %v", err)
+	}
+}
+
+func TestIsWatchFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fsnotify.ErrEventOverflow, true},
+		{fmt.Errorf("inotify_add_watch: %w", syscall.ENOSPC), true},
+		{syscall.EMFILE, true},
+		{errors.New("permission denied"), false},
+	}
+	for _, tt := range tests {
+		if got := isWatchFailure(tt.err); got != tt.want {
+			t.Errorf("isWatchFailure(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWatcherFallsBackToPolling(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(logFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, rec := newTestWatcher(t, dir, false)
+	w.cfg.Monitor.ImmediateHolding = true
+	w.cfg.Advanced.PollIntervalMS = 20
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	defer func() { cancel(); <-done }()
+
+	// fsnotify goes blind: drop the watch and report the inotify limit
+	w.fsw.Remove(dir)
+	w.fsw.Errors <- fmt.Errorf("inotify_add_watch: %w", syscall.ENOSPC)
+
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(claudeToolLine("Bash", `{"command":"ls"}`) + "\n")
+	f.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for rec.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if titles := rec.titles(); len(titles) != 1 || titles[0] != "Holding" {
+		t.Errorf("Expected Holding via polling, got %v", titles)
+	}
+}
+
+func TestWatcherReadyFile(t *testing.T) {
+	w, _ := newTestWatcher(t, t.TempDir(), false)
+	ready := filepath.Join(t.TempDir(), "run", "ready")
+	w.SetReadyFile(ready)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	data, err := os.ReadFile(ready)
+	if err != nil {
+		t.Fatalf("Ready file not written after startup: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Ready file = %q, want PID %d", data, os.Getpid())
+	}
+
+	cancel()
+	<-done
+	if _, err := os.Stat(ready); !os.IsNotExist(err) {
+		t.Errorf("Ready file should be removed on stop, stat err = %v", err)
+	}
+}
+
+func TestWatcherSnooze(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+
+	rec := &recordingNotifier{}
+	eventPath := filepath.Join(t.TempDir(), "events.jsonl")
+	eventFile, err := notify.NewEventFileNotifier(eventPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(cfg, notify.NewMultiNotifier(rec, eventFile), []Agent{{Name: "claude", DisplayName: "Claude Code", LogPath: dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	snooze := filepath.Join(t.TempDir(), SnoozeFile)
+	w.SetSnoozeFile(snooze)
+	if err := WriteSnooze(snooze, clock.Now().Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "session.jsonl")
+	quiet := time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second
+
+	// Snoozed: nothing is sent, but the event file still records the Cooling
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(quiet)
+	w.checkQuietPeriods(ctx)
+	if rec.count() != 0 {
+		t.Errorf("Sent %v while snoozed", rec.titles())
+	}
+	data, err := os.ReadFile(eventPath)
+	if err != nil || strings.Count(string(data), `"title":"Cooling"`) != 1 {
+		t.Errorf("Event file = %q (err %v), want one Cooling event", data, err)
+	}
+
+	// Expired: alerts resume
+	clock.Advance(10 * time.Minute)
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(quiet)
+	w.checkQuietPeriods(ctx)
+	if rec.count() != 1 {
+		t.Errorf("Sent %v after snooze expired, want one Cooling", rec.titles())
+	}
+}
+
+func TestWatcherNotifyDisabled(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.ImmediateHolding = true
+	cfg.Agents.NotifyDisabled = []string{"claude"}
+
+	rec := &recordingNotifier{}
+	webhook := &recordingNotifier{}
+	eventPath := filepath.Join(t.TempDir(), "events.jsonl")
+	eventFile, err := notify.NewEventFileNotifier(eventPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(cfg, notify.NewMultiNotifier(rec, eventFile, webhook), []Agent{{Name: "claude", DisplayName: "Claude Code", LogPath: dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "session.jsonl")
+	w.processLines(ctx, "claude", path, []string{claudeToolLine("Bash", `{"command":"ls"}`)})
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second)
+	w.checkQuietPeriods(ctx)
+
+	// Events are recorded, but neither the notifier nor webhooks hear of them
+	if rec.count() != 0 || webhook.count() != 0 {
+		t.Errorf("Sent %v / %v for a notify-disabled agent", rec.titles(), webhook.titles())
+	}
+	data, err := os.ReadFile(eventPath)
+	if err != nil || !strings.Contains(string(data), `"title":"Holding"`) || !strings.Contains(string(data), `"title":"Cooling"`) {
+		t.Errorf("Event file = %q (err %v), want Holding and Cooling events", data, err)
+	}
+
+	// Other agents still notify
+	cfg.Agents.NotifyDisabled = []string{"codex"}
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second)
+	w.checkQuietPeriods(ctx)
+	if got := rec.titles(); len(got) != 1 || got[0] != "Cooling" {
+		t.Errorf("Sent %v once re-enabled, want one Cooling", got)
+	}
+}
+
+func TestWatcherSuppressReasons(t *testing.T) {
+	dir := t.TempDir()
+	w, rec := newTestWatcher(t, dir, false)
+	w.cfg.Notify.Type = "stdout"
+	w.cfg.Output.Verbosity = "verbose"
+	w.cfg.Output.SuppressReasons = []string{"assistant response"}
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "session.jsonl")
+	now := time.Now()
+	w.processLines(ctx, "claude", path, []string{claudeLine(now, ""), claudeLine(now, "end_turn")})
+
+	// The suppressed reason is dropped; other reasons still notify
+	if rec.count() != 1 || rec.sent[0].Message != "end turn" {
+		t.Errorf("Sent %d notifications, want only the end turn activity", rec.count())
+	}
+	if a := w.state.GetAgent("claude"); a == nil || a.LastCue.IsZero() {
+		t.Error("Suppressed activity should still record a cue")
+	}
+}
+
+func TestWatcherIncludeRawMatch(t *testing.T) {
+	for _, include := range []bool{false, true} {
+		dir := t.TempDir()
+		w, rec := newTestWatcher(t, dir, false)
+		w.cfg.Notify.Type = "stdout"
+		w.cfg.Output.Verbosity = "verbose"
+		w.cfg.Output.IncludeSnippets = false
+		w.cfg.Output.IncludeRawMatch = include
+
+		line := claudeLine(time.Now(), "end_turn")
+		w.processLines(context.Background(), "claude", filepath.Join(dir, "session.jsonl"), []string{line})
+
+		if rec.count() != 1 {
+			t.Fatalf("include=%v: sent %d notifications, want 1", include, rec.count())
+		}
+		raw, ok := rec.sent[0].Metadata["raw_match"].(string)
+		if ok != include {
+			t.Errorf("include=%v: raw_match present = %v", include, ok)
+		}
+		if include && !strings.Contains(raw, `"stop_reason": "end_turn"`) {
+			t.Errorf("raw_match = %q, want the pretty-printed line", raw)
+		}
+		if !include && rec.sent[0].Snippet != "" {
+			t.Errorf("Snippet = %q, want none without include_raw_match", rec.sent[0].Snippet)
+		}
+	}
+}
+
+func TestWatcherPause(t *testing.T) {
+	dir := t.TempDir()
+	w, rec := newTestWatcher(t, dir, false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+	quiet := time.Duration(w.cfg.Monitor.QuietSeconds+1) * time.Second
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "session.jsonl")
+	if err := w.SetPaused("codex", true); err == nil {
+		t.Error("Expected an error pausing an agent that isn't monitored")
+	}
+
+	// A turn that finishes just before the pause isn't reported
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	if err := w.SetPaused("claude", true); err != nil {
+		t.Fatalf("SetPaused failed: %v", err)
+	}
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), ""), claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(quiet)
+	w.checkQuietPeriods(ctx)
+	if rec.count() != 0 {
+		t.Errorf("Paused agent sent %d notification(s)", rec.count())
+	}
+	if a := w.state.GetAgent("claude"); a.LinesRead != 1 {
+		t.Errorf("LinesRead = %d, want only the line before the pause", a.LinesRead)
+	}
+	if snap := w.DumpStatus(); !snap.Agents[0].Paused {
+		t.Error("Status should show the agent paused")
+	}
+
+	// Resuming restores processing
+	if err := w.SetPaused("claude", false); err != nil {
+		t.Fatalf("SetPaused failed: %v", err)
+	}
+	w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(quiet)
+	w.checkQuietPeriods(ctx)
+	if rec.count() != 1 || rec.sent[0].Title != "Cooling" {
+		t.Errorf("Sent %d notification(s) after resume, want one Cooling", rec.count())
+	}
+}
+
+func TestReadSnooze(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SnoozeFile)
+	if until, err := ReadSnooze(path); err != nil || !until.IsZero() {
+		t.Errorf("Missing file = %v, %v; want zero time", until, err)
+	}
+
+	want := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	if err := WriteSnooze(path, want); err != nil {
+		t.Fatal(err)
+	}
+	if until, err := ReadSnooze(path); err != nil || !until.Equal(want) {
+		t.Errorf("ReadSnooze = %v, %v; want %v", until, err, want)
+	}
+
+	if err := ClearSnooze(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearSnooze(path); err != nil {
+		t.Errorf("Clearing a missing snooze: %v", err)
+	}
+	if until, _ := ReadSnooze(path); !until.IsZero() {
+		t.Errorf("After clear = %v, want zero time", until)
+	}
+}
+
+func TestWatcherWorkingReminder(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+	w.cfg.Monitor.WorkingReminderSeconds = 300
+
+	// Steady activity every 10s for 11 minutes, checked every second
+	for sec := 0; sec < 11*60; sec++ {
+		if sec%10 == 0 {
+			w.state.RecordCue("claude", detect.MatchActivity)
+		}
+		w.checkQuietPeriods(context.Background())
+		clock.Advance(time.Second)
+	}
+
+	if titles := rec.titles(); len(titles) != 2 || titles[0] != "Still working" || titles[1] != "Still working" {
+		t.Fatalf("Expected two Still working reminders, got %v", titles)
+	}
+	if msg := rec.sent[0].Message; msg != "Claude Code still working, 5m elapsed" {
+		t.Errorf("Unexpected first reminder message %q", msg)
+	}
+	if msg := rec.sent[1].Message; msg != "Claude Code still working, 10m elapsed" {
+		t.Errorf("Unexpected second reminder message %q", msg)
+	}
+	if got := notify.DetermineEventType(rec.sent[0]); got != notify.EventWorking {
+		t.Errorf("Event type = %q, want %q", got, notify.EventWorking)
+	}
+
+	// Completion ends the turn
+	w.state.RecordCue("claude", detect.MatchComplete)
+	clock.Advance(5 * time.Minute)
+	w.checkQuietPeriods(context.Background())
+	if titles := rec.titles(); len(titles) != 3 || titles[2] != "Cooling" {
+		t.Errorf("Expected only Cooling after completion, got %v", titles)
+	}
+	if rec.count() != 3 {
+		t.Errorf("Expected no reminder after completion, got %v", rec.titles())
+	}
+}
+
+func TestWatcherWorkingReminderDisabled(t *testing.T) {
+	w, rec := newTestWatcher(t, t.TempDir(), false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	for i := 0; i < 360; i++ {
+		w.state.RecordCue("claude", detect.MatchActivity)
+		clock.Advance(10 * time.Second)
+		w.checkQuietPeriods(context.Background())
+	}
+
+	if rec.count() != 0 {
+		t.Errorf("Expected no reminders when disabled, got %v", rec.titles())
+	}
+}
+
+func TestWatcherPerInstanceAuto(t *testing.T) {
+	claudeDir := t.TempDir()
+	amazonqDir := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = config.PerInstanceAuto
+	cfg.Monitor.QuietSeconds = 15
+
+	rec := &recordingNotifier{}
+	agents := []Agent{
+		{Name: "claude", DisplayName: "Claude Code", LogPath: claudeDir, SessionFiles: true},
+		{Name: "amazonq", DisplayName: "Amazon Q", LogPath: amazonqDir},
+	}
+	w, err := NewWatcher(cfg, rec, agents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	if !w.state.IsPerInstanceAgent("claude") || w.state.IsPerInstanceAgent("amazonq") {
+		t.Fatalf("Expected claude per-instance and amazonq per-agent, got claude=%v amazonq=%v",
+			w.state.IsPerInstanceAgent("claude"), w.state.IsPerInstanceAgent("amazonq"))
+	}
+
+	ctx := context.Background()
+	for _, project := range []string{"aaaaaaaa", "bbbbbbbb"} {
+		path := filepath.Join(claudeDir, project, "session.jsonl")
+		w.processLines(ctx, "claude", path, []string{claudeLine(clock.Now(), "end_turn")})
+	}
+	for _, name := range []string{"chat.log", "qchat.log"} {
+		w.processLines(ctx, "amazonq", filepath.Join(amazonqDir, name), []string{`{"type":"response_complete"}`})
+	}
+
+	clock.Advance(time.Minute)
+	w.checkQuietPeriods(ctx)
+
+	var agentsNotified []string
+	for _, n := range rec.sent {
+		if n.Title != "Cooling" {
+			t.Errorf("Expected only Cooling notifications, got %q for %s", n.Title, n.Agent)
+		}
+		agentsNotified = append(agentsNotified, n.Agent)
+	}
+	sort.Strings(agentsNotified)
+	want := []string{"Amazon Q", "Claude Code (aaaaaaaa)", "Claude Code (bbbbbbbb)"}
+	if !reflect.DeepEqual(agentsNotified, want) {
+		t.Errorf("Notified %q, want %q", agentsNotified, want)
+	}
+
+	// Nothing more until new activity
+	w.checkQuietPeriods(ctx)
+	if rec.count() != 3 {
+		t.Errorf("Expected no repeat notifications, got %v", rec.titles())
+	}
+}
+
+func TestWatcherPerAgentCompletionDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    bool
+		overrides map[string]bool
+		focus     bool
+		want      []string
+	}{
+		{"global on", true, nil, false, []string{"Amazon Q", "Claude Code (aaaaaaaa)"}},
+		{"off for a per-agent agent", true, map[string]bool{"amazonq": false}, false, []string{"Claude Code (aaaaaaaa)"}},
+		{"off for a per-instance agent", true, map[string]bool{"claude": false}, false, []string{"Amazon Q"}},
+		{"on for one agent only", false, map[string]bool{"amazonq": true}, false, []string{"Amazon Q"}},
+		{"global off", false, nil, false, nil},
+		// Amazon Q is cued last but never cools, so Claude keeps the focus
+		{"focus skips agents that are off", true, map[string]bool{"amazonq": false}, true, []string{"Claude Code (aaaaaaaa)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claudeDir := t.TempDir()
+			amazonqDir := t.TempDir()
+
+			cfg := config.DefaultConfig()
+			cfg.Monitor.ProcessTracking = false
+			cfg.Monitor.PerInstance = config.PerInstanceAuto
+			cfg.Monitor.QuietSeconds = 15
+			cfg.Monitor.CompletionDetection = tt.global
+			cfg.Agents.CompletionDetection = tt.overrides
+			cfg.Monitor.Focus = tt.focus
+
+			rec := &recordingNotifier{}
+			agents := []Agent{
+				{Name: "claude", DisplayName: "Claude Code", LogPath: claudeDir, SessionFiles: true},
+				{Name: "amazonq", DisplayName: "Amazon Q", LogPath: amazonqDir},
+			}
+			w, err := NewWatcher(cfg, rec, agents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+			clock := newFakeClock()
+			w.SetClock(clock)
+
+			// Both agents finish a turn, then keep quiet
+			ctx := context.Background()
+			w.processLines(ctx, "claude", filepath.Join(claudeDir, "aaaaaaaa", "session.jsonl"), []string{claudeLine(clock.Now(), "end_turn")})
+			clock.Advance(time.Second)
+			w.processLines(ctx, "amazonq", filepath.Join(amazonqDir, "chat.log"), []string{`{"type":"response_complete"}`})
+			for i := 0; i < 3; i++ {
+				clock.Advance(time.Minute)
+				w.checkQuietPeriods(ctx)
+			}
+
+			var cooled []string
+			for _, n := range rec.sent {
+				cooled = append(cooled, n.Agent)
+			}
+			sort.Strings(cooled)
+			if !reflect.DeepEqual(cooled, tt.want) {
+				t.Errorf("Notified %q, want %q", cooled, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoPerInstance(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "single.log")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		agent Agent
+		want  bool
+	}{
+		{"session directory", Agent{Name: "claude", LogPath: dir, SessionFiles: true}, true},
+		{"session directory not created yet", Agent{Name: "claude", LogPath: filepath.Join(dir, "missing"), SessionFiles: true}, true},
+		{"session agent pointed at one file", Agent{Name: "claude", LogPath: file, SessionFiles: true}, false},
+		{"single-file agent", Agent{Name: "amazonq", LogPath: dir}, false},
+		{"registry claude", Registry["claude"], true},
+		{"registry amazonq", Registry["amazonq"], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AutoPerInstance(tt.agent); got != tt.want {
+				t.Errorf("AutoPerInstance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcherWaitsForAgents(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.WaitForAgents = true
+
+	// Point every agent at a path that doesn't exist yet
+	cfg.Agents.Paths = make(map[string]string)
+	for _, agent := range Registry {
+		cfg.Agents.Paths[agent.Name] = filepath.Join(root, agent.Name)
+	}
+
+	rec := &recordingNotifier{}
+	w, err := NewWatcher(cfg, rec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	clock := newFakeClock()
+	w.SetClock(clock)
+
+	w.discoverAgents()
+	if len(w.managers) != 0 {
+		t.Fatalf("Managers before any log dir exists: %d", len(w.managers))
+	}
+
+	// The agent's log dir is created after startup
+	dir := cfg.Agents.Paths["claude"]
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	w.discoverAgents()
+	if _, ok := w.managers["claude"]; !ok {
+		t.Fatal("claude not picked up after its log dir appeared")
+	}
+	if len(w.managers) != 1 {
+		t.Errorf("Managers = %d, want only claude", len(w.managers))
+	}
+
+	// Discovering again doesn't replace the running manager
+	mgr := w.managers["claude"]
+	w.discoverAgents()
+	if w.managers["claude"] != mgr {
+		t.Error("Rediscovery replaced the claude manager")
+	}
+
+	// The new agent is fully monitored
+	ctx := context.Background()
+	w.processLines(ctx, "claude", filepath.Join(dir, "session.jsonl"), []string{claudeLine(clock.Now(), "end_turn")})
+	clock.Advance(time.Duration(cfg.Monitor.QuietSeconds+1) * time.Second)
+	w.checkQuietPeriods(ctx)
+	if titles := rec.titles(); len(titles) != 1 || titles[0] != "Cooling" {
+		t.Errorf("Sent %v, want one Cooling", titles)
+	}
+}
+
+func TestWatcherDiscoveryOff(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Agents.Paths = map[string]string{"claude": root}
+
+	w, err := NewWatcher(cfg, &recordingNotifier{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.discoverAgents()
+	if len(w.managers) != 0 {
+		t.Errorf("Picked up agents with wait_for_agents off: %d", len(w.managers))
+	}
+}
+
+func TestWatcherSharedLogRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sessions")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(sub, "rollout.jsonl")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = config.PerInstanceOff
+	w, err := NewWatcher(cfg, &recordingNotifier{}, []Agent{
+		{Name: "claude", DisplayName: "Claude Code", LogPath: root},
+		{Name: "codex", DisplayName: "Codex", LogPath: sub},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.refreshFiles()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, claudeLine(time.Now(), "end_turn"))
+	f.Close()
+
+	// Both agents' roots contain the file, so both read the new line
+	w.handleFSEvent(context.Background(), fsnotify.Event{Name: path, Op: fsnotify.Write})
+	for _, name := range []string{"claude", "codex"} {
+		if got := w.state.GetAgent(name).LinesRead; got != 1 {
+			t.Errorf("%s read %d lines, want 1", name, got)
+		}
+	}
+
+	// Only the matcher that recognizes the line records a cue
+	if w.state.GetAgent("claude").LastCue.IsZero() {
+		t.Error("claude did not record the end_turn line")
+	}
+	if !w.state.GetAgent("codex").LastCue.IsZero() {
+		t.Error("codex recorded a cue for a Claude line")
+	}
+}
+
+func TestWatcherSymlinkedLog(t *testing.T) {
+	linkDir, logDir, rotatedDir := t.TempDir(), t.TempDir(), t.TempDir()
+	target := filepath.Join(logDir, "session-1.jsonl")
+	if err := os.WriteFile(target, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(linkDir, "current.jsonl")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Monitor.ProcessTracking = false
+	cfg.Monitor.PerInstance = config.PerInstanceOff
+	w, err := NewWatcher(cfg, &recordingNotifier{}, []Agent{{Name: "claude", DisplayName: "Claude Code", LogPath: link}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.refreshFiles()
+
+	// The target's directory is watched, and its writes read through the link
+	if !slices.Contains(w.fsw.WatchList(), logDir) {
+		t.Errorf("WatchList() = %v, want the target's directory %s", w.fsw.WatchList(), logDir)
+	}
+	appendTo(t, target, claudeLine(time.Now(), "end_turn")+"\n")
+	w.handleFSEvent(context.Background(), fsnotify.Event{Name: target, Op: fsnotify.Write})
+	if got := w.state.GetAgent("claude").LinesRead; got != 1 {
+		t.Fatalf("read %d lines through the link, want 1", got)
+	}
+
+	// Repointing the link moves the watch to the new target
+	rotated := filepath.Join(rotatedDir, "session-2.jsonl")
+	if err := os.WriteFile(rotated, []byte(claudeLine(time.Now(), "end_turn")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repoint(t, link, rotated)
+	w.handleFSEvent(context.Background(), fsnotify.Event{Name: link, Op: fsnotify.Create})
+	if !slices.Contains(w.fsw.WatchList(), rotatedDir) {
+		t.Errorf("WatchList() = %v, want the new target's directory %s", w.fsw.WatchList(), rotatedDir)
+	}
+	if got := w.state.GetAgent("claude").LinesRead; got != 2 {
+		t.Fatalf("read %d lines after rotation, want 2", got)
+	}
+	appendTo(t, rotated, claudeLine(time.Now(), "end_turn")+"\n")
+	w.handleFSEvent(context.Background(), fsnotify.Event{Name: rotated, Op: fsnotify.Write})
+	if got := w.state.GetAgent("claude").LinesRead; got != 3 {
+		t.Errorf("read %d lines from the new target, want 3", got)
+	}
+}
+
+func TestUnderBase(t *testing.T) {
+	tests := []struct {
+		base, path string
+		want       bool
+	}{
+		{"/logs", "/logs", true},
+		{"/logs", "/logs/a/b.jsonl", true},
+		{"/logs", "/logs/.hidden.jsonl", true},
+		{"/logs", "/logs-old/a.jsonl", false},
+		{"/logs/a", "/logs/b.jsonl", false},
+		{"/logs", "/logs/..foo", true},
+	}
+	for _, tt := range tests {
+		if got := underBase(tt.base, tt.path); got != tt.want {
+			t.Errorf("underBase(%q, %q) = %v, want %v", tt.base, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWatcherNoLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	w, rec := newTestWatcher(t, dir, false)
+	clock := newFakeClock()
+	w.SetClock(clock)
+	w.managers["claude"].scanTTL = 0
+	ctx := context.Background()
+
+	path := filepath.Join(dir, "session.jsonl")
+	create := func() {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	remove := func() {
+		t.Helper()
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	refresh := func() { w.checkLogFiles(ctx, w.refreshFiles()) }
+
+	// No files yet, e.g. an agent not yet used: nothing to report
+	refresh()
+	clock.Advance(2 * noLogFilesAfter)
+	refresh()
+	if rec.count() != 0 {
+		t.Fatalf("Sent %v before any files were found", rec.titles())
+	}
+
+	create()
+	refresh()
+	remove()
+	refresh()
+	clock.Advance(noLogFilesAfter - time.Second)
+	refresh()
+	if rec.count() != 0 {
+		t.Fatalf("Sent %v before files were gone for %s", rec.titles(), noLogFilesAfter)
+	}
+	clock.Advance(time.Second)
+	refresh()
+	clock.Advance(time.Minute)
+	refresh()
+	if got := rec.titles(); !slices.Equal(got, []string{"No log files found"}) {
+		t.Fatalf("Sent %v, want one \"No log files found\"", got)
+	}
+
+	create()
+	refresh()
+	refresh()
+	want := []string{"No log files found", "Resumed"}
+	if got := rec.titles(); !slices.Equal(got, want) {
+		t.Fatalf("Sent %v, want %v", got, want)
+	}
+
+	// Files briefly gone, e.g. while rotated, are not reported
+	remove()
+	refresh()
+	clock.Advance(noLogFilesAfter / 2)
+	refresh()
+	create()
+	refresh()
+	clock.Advance(noLogFilesAfter)
+	refresh()
+	if got := rec.titles(); !slices.Equal(got, want) {
+		t.Errorf("Sent %v, want %v", got, want)
+	}
+}
+
+// failingNotifier fails every Send with err.
+type failingNotifier struct {
+	name string
+	err  error
+}
+
+func (f *failingNotifier) Name() string { return f.name }
+
+func (f *failingNotifier) Send(ctx context.Context, n *notify.Notification) error { return f.err }
+
+func TestWatcherLogsDeliveryFailuresBySeverity(t *testing.T) {
+	var errorLogs, debugLogs []string
+	origErrorf, origDebugf := Errorf, Debugf
+	Errorf = func(format string, args ...any) { errorLogs = append(errorLogs, fmt.Sprintf(format, args...)) }
+	Debugf = func(format string, args ...any) { debugLogs = append(debugLogs, fmt.Sprintf(format, args...)) }
+	defer func() { Errorf, Debugf = origErrorf, origDebugf }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	noRetries := 0
+	webhooks := notify.NewWebhookNotifier([]config.WebhookConfig{
+		{URL: server.URL, Retries: &noRetries, Critical: true},
+		{URL: server.URL, Retries: &noRetries},
+	})
+
+	w, _ := newTestWatcher(t, t.TempDir(), false)
+	w.notifier = notify.NewMultiNotifier(&recordingNotifier{},
+		&failingNotifier{name: "eventfile", err: errors.New("disk full")},
+		webhooks,
+	)
+	w.sendAwaitingNotification(context.Background(), "claude", "Claude Code", "Awaiting", "Waiting for input")
+
+	if len(errorLogs) != 1 || !strings.Contains(errorLogs[0], "via webhook[0]") {
+		t.Errorf("ERROR logs = %q, want only the critical webhook[0]", errorLogs)
+	}
+	var bestEffort []string
+	for _, line := range debugLogs {
+		if strings.Contains(line, "best effort") {
+			bestEffort = append(bestEffort, line)
+		}
+	}
+	if len(bestEffort) != 2 || !strings.Contains(bestEffort[0], "via eventfile (best effort): disk full") || !strings.Contains(bestEffort[1], "via webhook[1]") {
+		t.Errorf("DEBUG logs = %q, want the event file and webhook[1] as best effort", bestEffort)
+	}
+
+	// A failing primary is critical
+	errorLogs = nil
+	w.notifier = notify.NewMultiNotifier(&failingNotifier{name: "slack", err: errors.New("invalid_token")})
+	w.sendAwaitingNotification(context.Background(), "claude", "Claude Code", "Awaiting", "Waiting for input")
+	if len(errorLogs) != 1 || !strings.Contains(errorLogs[0], "via slack: invalid_token") {
+		t.Errorf("ERROR logs = %q, want the slack failure", errorLogs)
+	}
+}
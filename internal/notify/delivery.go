@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DeliveryFailure is one route's failure to deliver a notification.
+type DeliveryFailure struct {
+	Route    string // Notifier name (as in NotifierStatus), or "webhook[N]" for the Nth configured endpoint
+	Critical bool   // A critical route: the primary notifier, or a webhook with critical: true
+	Err      error
+}
+
+// DeliveryError aggregates the failures of one delivery across routes, so
+// callers can tell a failing critical route (Slack) from a best-effort one
+// (the event file, socket, or a webhook not marked critical).
+type DeliveryError struct {
+	Failures []DeliveryFailure
+}
+
+// Error lists each failing route, e.g. "slack: invalid_token; eventfile: disk full".
+func (e *DeliveryError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("%s: %v", f.Route, f.Err)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap returns the routes' errors.
+func (e *DeliveryError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// Critical returns the failures of critical routes.
+func (e *DeliveryError) Critical() []DeliveryFailure {
+	var critical []DeliveryFailure
+	for _, f := range e.Failures {
+		if f.Critical {
+			critical = append(critical, f)
+		}
+	}
+	return critical
+}
+
+// BestEffort returns the failures of best-effort routes.
+func (e *DeliveryError) BestEffort() []DeliveryFailure {
+	var bestEffort []DeliveryFailure
+	for _, f := range e.Failures {
+		if !f.Critical {
+			bestEffort = append(bestEffort, f)
+		}
+	}
+	return bestEffort
+}
+
+// DeliveryFailures splits err from a Send into per-route failures. An error
+// that isn't a DeliveryError, such as one from a lone primary notifier, is
+// a single critical failure of route.
+func DeliveryFailures(err error, route string) []DeliveryFailure {
+	if err == nil {
+		return nil
+	}
+	var de *DeliveryError
+	if errors.As(err, &de) {
+		return de.Failures
+	}
+	return []DeliveryFailure{{Route: route, Critical: true, Err: err}}
+}
+
+// CriticalOnly returns err without its best-effort failures, nil if only
+// best-effort routes failed, for callers that should only fail when a
+// critical route does. Errors other than a DeliveryError are returned as is.
+func CriticalOnly(err error) error {
+	var de *DeliveryError
+	if !errors.As(err, &de) {
+		return err
+	}
+	return deliveryError(de.Critical())
+}
+
+// deliveryError returns failures as a *DeliveryError, or nil if there are none.
+func deliveryError(failures []DeliveryFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &DeliveryError{Failures: failures}
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+)
+
+func TestDeliveryError(t *testing.T) {
+	diskFull := errors.New("disk full")
+	de := &DeliveryError{Failures: []DeliveryFailure{
+		{Route: "slack", Critical: true, Err: errors.New("invalid_token")},
+		{Route: "eventfile", Err: diskFull},
+	}}
+
+	if got, want := de.Error(), "slack: invalid_token; eventfile: disk full"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(de, diskFull) {
+		t.Error("errors.Is should find a route's error")
+	}
+	if c := de.Critical(); len(c) != 1 || c[0].Route != "slack" {
+		t.Errorf("Critical() = %+v, want slack", c)
+	}
+	if b := de.BestEffort(); len(b) != 1 || b[0].Route != "eventfile" {
+		t.Errorf("BestEffort() = %+v, want eventfile", b)
+	}
+}
+
+func TestDeliveryFailures(t *testing.T) {
+	if got := DeliveryFailures(nil, "slack"); got != nil {
+		t.Errorf("DeliveryFailures(nil) = %+v, want nil", got)
+	}
+
+	// A plain error is the notifier's own, critical failure
+	got := DeliveryFailures(errors.New("invalid_token"), "slack")
+	if len(got) != 1 || got[0].Route != "slack" || !got[0].Critical {
+		t.Errorf("DeliveryFailures(plain) = %+v, want one critical slack failure", got)
+	}
+}
+
+func TestCriticalOnly(t *testing.T) {
+	bestEffort := &DeliveryError{Failures: []DeliveryFailure{{Route: "eventfile", Err: errors.New("disk full")}}}
+	if err := CriticalOnly(bestEffort); err != nil {
+		t.Errorf("CriticalOnly(best effort) = %v, want nil", err)
+	}
+
+	mixed := &DeliveryError{Failures: []DeliveryFailure{
+		{Route: "eventfile", Err: errors.New("disk full")},
+		{Route: "webhook[0]", Critical: true, Err: errors.New("status 500")},
+	}}
+	if err := CriticalOnly(mixed); err == nil || err.Error() != "webhook[0]: status 500" {
+		t.Errorf("CriticalOnly(mixed) = %v, want only the webhook", err)
+	}
+
+	plain := errors.New("invalid_token")
+	if err := CriticalOnly(plain); err != plain {
+		t.Errorf("CriticalOnly(plain) = %v, want it unchanged", err)
+	}
+}
+
+func TestMultiNotifierRouteCriticality(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+
+	noRetries := 0
+	webhooks := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: ok.URL, Retries: &noRetries, Critical: true},
+		{URL: server.URL, Retries: &noRetries, Critical: true},
+		{URL: server.URL, Retries: &noRetries},
+	})
+	m := NewMultiNotifier(&stubNotifier{name: "slack"}, webhooks, &stubNotifier{name: "socket", err: errors.New("broken pipe")})
+
+	err := m.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	var de *DeliveryError
+	if !errors.As(err, &de) {
+		t.Fatalf("Send() error = %v, want a *DeliveryError", err)
+	}
+	want := []struct {
+		route    string
+		critical bool
+	}{{"webhook[1]", true}, {"webhook[2]", false}, {"socket", false}}
+	if len(de.Failures) != len(want) {
+		t.Fatalf("Failures = %+v, want %d", de.Failures, len(want))
+	}
+	for i, w := range want {
+		if f := de.Failures[i]; f.Route != w.route || f.Critical != w.critical {
+			t.Errorf("Failures[%d] = %s (critical %v), want %s (critical %v)", i, f.Route, f.Critical, w.route, w.critical)
+		}
+	}
+
+	// Local recording is best effort
+	err = m.RecordLocal(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	if !errors.As(err, &de) || len(de.Critical()) != 0 || len(de.BestEffort()) != 1 {
+		t.Errorf("RecordLocal() error = %v, want one best-effort failure", err)
+	}
+}
@@ -3,7 +3,11 @@ package notify
 
 import (
 	"encoding/json"
+	"os"
+	"sync"
 	"time"
+
+	"firebell/internal/config"
 )
 
 // EventType represents the type of event being notified.
@@ -14,9 +18,17 @@ const (
 	EventCooling           EventType = "cooling"
 	EventAwaiting EventType = "awaiting" // Waiting for user input (inferred)
 	EventHolding  EventType = "holding"  // Waiting for tool approval (immediate)
+	EventLoop     EventType = "loop"     // Same tool request repeating
+	EventWorking  EventType = "working"  // Periodic reminder during a long turn
+	EventCompaction EventType = "compaction" // Agent compacted its context
+	EventProcessStart EventType = "process_start" // Tracked process detected or restarted
 	EventProcessExit       EventType = "process_exit"
+	EventIdle EventType = "idle" // Tracked process idle mid-turn with silent logs
+	EventNoLogs EventType = "no_logs" // Every watched log file disappeared
+	EventLogsResumed EventType = "logs_resumed" // Log files found again after no_logs
 	EventDaemonStart       EventType = "daemon_start"
 	EventDaemonStop        EventType = "daemon_stop"
+	EventStatus            EventType = "status" // State snapshot requested via SIGUSR1
 )
 
 // Event is the unified event structure used by all hook/integration methods.
@@ -29,13 +41,44 @@ type Event struct {
 	Message   string            `json:"message,omitempty"`
 	Snippet   string            `json:"snippet,omitempty"`
 	Metadata  map[string]any    `json:"metadata,omitempty"`
+	Host      string            `json:"host,omitempty"`    // Machine that produced the event
+	Version   string            `json:"version,omitempty"` // firebell version that produced the event
+
+	Priority Priority `json:"priority,omitempty"` // "high" for urgent alerts; omitted when normal
+	Severity Severity `json:"severity,omitempty"` // "info", "notice", "warning", or "high" (see SeverityMap)
+
+	History []HistoryEntry `json:"history,omitempty"` // The agent's recent events, oldest first, for webhooks with include_history
+}
+
+// HistoryEntry is one of an agent's recent events: a line its matcher
+// classified, whether or not it led to a notification.
+type HistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`             // Match type: "activity", "complete", "holding", ...
+	Reason    string    `json:"reason,omitempty"` // Why the line matched (e.g. "end_turn")
+}
+
+// lastHistory returns the last n entries of history (all if fewer).
+func lastHistory(history []HistoryEntry, n int) []HistoryEntry {
+	if n >= len(history) {
+		return history
+	}
+	return history[len(history)-n:]
 }
 
+// eventHost is the hostname stamped on every Event, looked up once.
+var eventHost = sync.OnceValue(func() string {
+	host, _ := os.Hostname()
+	return host
+})
+
 // NewEvent creates a new Event with the current timestamp.
 func NewEvent(eventType EventType) *Event {
 	return &Event{
 		Event:     eventType,
 		Timestamp: time.Now(),
+		Host:      eventHost(),
+		Version:   config.Version,
 	}
 }
 
@@ -48,6 +91,11 @@ func NewEventFromNotification(n *Notification, eventType EventType) *Event {
 		Title:     n.Title,
 		Message:   n.Message,
 		Snippet:   n.Snippet,
+		Metadata:  n.Metadata,
+		Host:      eventHost(),
+		Version:   config.Version,
+		Priority:  n.Priority,
+		Severity:  severityOf(n, eventType),
 	}
 }
 
@@ -82,6 +130,17 @@ func (e *Event) JSONLine() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// IsAgentEvent reports whether t describes agent activity, as opposed to
+// daemon lifecycle events, status snapshots, or socket housekeeping such as
+// welcome and heartbeat messages.
+func IsAgentEvent(t EventType) bool {
+	switch t {
+	case EventActivity, EventCooling, EventAwaiting, EventHolding, EventLoop, EventWorking, EventCompaction, EventProcessStart, EventProcessExit, EventIdle:
+		return true
+	}
+	return false
+}
+
 // DetermineEventType infers the event type from a Notification.
 func DetermineEventType(n *Notification) EventType {
 	switch n.Title {
@@ -91,8 +150,22 @@ func DetermineEventType(n *Notification) EventType {
 		return EventAwaiting
 	case "Holding":
 		return EventHolding
+	case "Possible loop":
+		return EventLoop
+	case "Still working":
+		return EventWorking
+	case "Compacted":
+		return EventCompaction
+	case "Process Started":
+		return EventProcessStart
 	case "Process Exited", "Process Exit":
 		return EventProcessExit
+	case "Idle":
+		return EventIdle
+	case "No log files found":
+		return EventNoLogs
+	case "Resumed":
+		return EventLogsResumed
 	default:
 		return EventActivity
 	}
@@ -3,19 +3,40 @@ package notify
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 )
 
+// diskFullRetry is how long the event file stays disabled after a write
+// fails because the disk is full before writing is tried again.
+const diskFullRetry = 30 * time.Second
+
+// truncationMarker ends a snippet, raw match, or message cut short to keep an event's
+// line under the limit (see SetMaxLineBytes).
+const truncationMarker = "... [truncated]"
+
 // EventFileNotifier writes events to a JSONL file for external consumption.
 type EventFileNotifier struct {
 	path    string
 	maxSize int64
+	maxLine int // Longest line written, newline included (0 = no limit)
 	mu      sync.Mutex
 	file    *os.File
+
+	// Disk-full degradation: while disabled, events are dropped without
+	// error so the other notifiers keep working
+	disabled   bool
+	retryAt    time.Time // When to try writing again
+	dropped    int       // Events dropped while disabled
+	now        func() time.Time
+	writeEvent func(data []byte) error // Appends one line (nil = the file; for tests)
 }
 
 // NewEventFileNotifier creates a new event file notifier.
@@ -43,6 +64,7 @@ func NewEventFileNotifier(path string, maxSize int64) (*EventFileNotifier, error
 	return &EventFileNotifier{
 		path:    path,
 		maxSize: maxSize,
+		now:     time.Now,
 	}, nil
 }
 
@@ -56,6 +78,15 @@ func (e *EventFileNotifier) Path() string {
 	return e.path
 }
 
+// SetMaxLineBytes keeps each event's line within n bytes, newline included,
+// for line-based consumers with a bounded buffer. Values below 1 mean no
+// limit.
+func (e *EventFileNotifier) SetMaxLineBytes(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxLine = max(n, 0)
+}
+
 // Send writes a notification as a JSON event to the file.
 func (e *EventFileNotifier) Send(ctx context.Context, n *Notification) error {
 	eventType := DetermineEventType(n)
@@ -64,10 +95,114 @@ func (e *EventFileNotifier) Send(ctx context.Context, n *Notification) error {
 }
 
 // WriteEvent writes an event directly to the file.
+//
+// If the disk is full, the notifier disables itself instead of failing every
+// send: the event is dropped, a warning is logged once, and writing is tried
+// again after diskFullRetry. The first successful write re-enables it.
+//
+// A line over the SetMaxLineBytes limit is shortened as fitLine describes,
+// cut text ending with truncationMarker; the event passed in is left as is.
 func (e *EventFileNotifier) WriteEvent(event *Event) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if e.disabled && e.now().Before(e.retryAt) {
+		e.dropped++
+		return nil
+	}
+
+	// Serialize event
+	data, err := fitLine(event, e.maxLine)
+	if err != nil {
+		return fmt.Errorf("failed to serialize event: %w", err)
+	}
+	line := append(data, '\n')
+	if e.disabled {
+		// Terminate any line cut short by the failed write
+		line = append([]byte{'\n'}, line...)
+	}
+
+	write := e.writeEvent
+	if write == nil {
+		write = e.writeFile
+	}
+	if err := write(line); err != nil {
+		if !errors.Is(err, syscall.ENOSPC) {
+			return err
+		}
+		if !e.disabled {
+			fmt.Fprintf(os.Stderr, "Warning: disk full, event file %s disabled; retrying every %s\n", e.path, diskFullRetry)
+			e.disabled = true
+		}
+		e.retryAt = e.now().Add(diskFullRetry)
+		e.dropped++
+		return nil
+	}
+
+	if e.disabled {
+		fmt.Fprintf(os.Stderr, "Event file %s re-enabled (%d events dropped while the disk was full)\n", e.path, e.dropped)
+		e.disabled = false
+		e.dropped = 0
+	}
+	return nil
+}
+
+// fitLine serializes event in at most limit bytes with a newline (0 = any
+// length), cutting its snippet, then its "raw_match" metadata, then dropping
+// its history oldest first, and last cutting its message as needed. Other
+// fields are never cut, so an event too large without those still exceeds
+// limit.
+func fitLine(event *Event, limit int) ([]byte, error) {
+	data, err := event.JSONLine()
+	if err != nil || limit <= 0 || len(data) < limit {
+		return data, err
+	}
+
+	cut := *event
+	cut.Snippet = cutText(cut.Snippet, len(data)+1-limit)
+	if data, err = cut.JSONLine(); err != nil || len(data) < limit {
+		return data, err
+	}
+
+	if raw, ok := cut.Metadata["raw_match"].(string); ok {
+		cut.Metadata = maps.Clone(cut.Metadata)
+		cut.Metadata["raw_match"] = cutText(raw, len(data)+1-limit)
+		if data, err = cut.JSONLine(); err != nil || len(data) < limit {
+			return data, err
+		}
+	}
+
+	for len(cut.History) > 0 {
+		cut.History = cut.History[1:]
+		if data, err = cut.JSONLine(); err != nil || len(data) < limit {
+			return data, err
+		}
+	}
+
+	cut.Message = cutText(cut.Message, len(data)+1-limit)
+	return cut.JSONLine()
+}
+
+// cutText shortens s by at least over bytes, ending it with
+// truncationMarker. Each byte removed shortens the JSON encoding by at
+// least one, so the encoded text shrinks as much.
+func cutText(s string, over int) string {
+	keep := len(s) - over - len(truncationMarker)
+	if keep <= 0 {
+		if len(s) <= len(truncationMarker) {
+			return ""
+		}
+		return truncationMarker
+	}
+	for keep > 0 && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+	return s[:keep] + truncationMarker
+}
+
+// writeFile appends line to the event file, rotating and opening it as
+// needed. Must be called with e.mu held.
+func (e *EventFileNotifier) writeFile(line []byte) error {
 	// Check if rotation is needed
 	if err := e.maybeRotate(); err != nil {
 		return fmt.Errorf("failed to rotate event file: %w", err)
@@ -82,14 +217,7 @@ func (e *EventFileNotifier) WriteEvent(event *Event) error {
 		e.file = f
 	}
 
-	// Serialize event
-	data, err := event.JSONLine()
-	if err != nil {
-		return fmt.Errorf("failed to serialize event: %w", err)
-	}
-
-	// Write with newline
-	if _, err := e.file.Write(append(data, '\n')); err != nil {
+	if _, err := e.file.Write(line); err != nil {
 		return fmt.Errorf("failed to write event: %w", err)
 	}
 
@@ -156,3 +284,12 @@ func (e *EventFileNotifier) EmitDaemonStop() error {
 		WithMessage("Firebell daemon stopping")
 	return e.WriteEvent(event)
 }
+
+// EmitStatus writes a status event carrying a state snapshot.
+func (e *EventFileNotifier) EmitStatus(message string, snapshot any) error {
+	event := NewEvent(EventStatus).
+		WithAgent("firebell").
+		WithMessage(message).
+		WithMetadata("status", snapshot)
+	return e.WriteEvent(event)
+}
@@ -4,11 +4,17 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
+	"unicode/utf8"
+
+	"firebell/internal/config"
 )
 
 func TestEventFileNotifier_Send(t *testing.T) {
@@ -56,6 +62,9 @@ func TestEventFileNotifier_Send(t *testing.T) {
 	if event.Message != "No activity for 20 seconds" {
 		t.Errorf("Message = %q, want %q", event.Message, "No activity for 20 seconds")
 	}
+	if event.Host != eventHost() || event.Version != config.Version {
+		t.Errorf("Host/Version = %q/%q, want %q/%q", event.Host, event.Version, eventHost(), config.Version)
+	}
 }
 
 func TestEventFileNotifier_WriteEvent(t *testing.T) {
@@ -106,6 +115,140 @@ func TestEventFileNotifier_WriteEvent(t *testing.T) {
 	}
 }
 
+func TestEventFileNotifier_MaxLineBytes(t *testing.T) {
+	const limit = 1024
+	eventPath := filepath.Join(t.TempDir(), "events.jsonl")
+	notifier, err := NewEventFileNotifier(eventPath, 0)
+	if err != nil {
+		t.Fatalf("NewEventFileNotifier failed: %v", err)
+	}
+	defer notifier.Close()
+	notifier.SetMaxLineBytes(limit)
+
+	// Multi-byte runes and characters JSON escapes make the line longer
+	// than the text
+	bigSnippet := strings.Repeat("naïve \"quoted\"\n<tag> ", 5000)
+	small := NewEvent(EventCooling).WithAgent("Claude Code").WithMessage("Cooling")
+	snippet := NewEvent(EventActivity).WithAgent("Claude Code").WithMessage("Tool request")
+	snippet.Snippet = bigSnippet
+	both := NewEvent(EventActivity).WithAgent("Claude Code").WithMessage(strings.Repeat("é", 3000))
+	both.Snippet = bigSnippet
+
+	for _, e := range []*Event{small, snippet, both} {
+		if err := notifier.WriteEvent(e); err != nil {
+			t.Fatalf("WriteEvent failed: %v", err)
+		}
+	}
+	if snippet.Snippet != bigSnippet {
+		t.Error("WriteEvent changed the event passed in")
+	}
+
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	var got []Event
+	for i, line := range lines {
+		if len(line) > limit {
+			t.Errorf("line %d is %d bytes, want at most %d", i, len(line), limit)
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		got = append(got, event)
+	}
+
+	if got[0].Message != "Cooling" {
+		t.Errorf("short event message = %q, want it whole", got[0].Message)
+	}
+	if got[1].Message != "Tool request" || !strings.HasSuffix(got[1].Snippet, truncationMarker) || !strings.HasPrefix(bigSnippet, strings.TrimSuffix(got[1].Snippet, truncationMarker)) {
+		t.Errorf("long snippet event = %q / %q, want the snippet cut and marked, the message whole", got[1].Message, got[1].Snippet)
+	}
+	if !strings.HasSuffix(got[2].Message, truncationMarker) || !utf8.ValidString(got[2].Message) {
+		t.Errorf("long message = %q, want it cut at a rune boundary and marked", got[2].Message)
+	}
+	if got[2].Snippet != truncationMarker {
+		t.Errorf("snippet = %q, want only the marker once the message had to be cut too", got[2].Snippet)
+	}
+}
+
+func TestEventFileNotifier_MaxLineBytesRawMatchAndHistory(t *testing.T) {
+	const limit = 1024
+	eventPath := filepath.Join(t.TempDir(), "events.jsonl")
+	notifier, err := NewEventFileNotifier(eventPath, 0)
+	if err != nil {
+		t.Fatalf("NewEventFileNotifier failed: %v", err)
+	}
+	defer notifier.Close()
+	notifier.SetMaxLineBytes(limit)
+
+	// With output.include_raw_match the matched line is both the snippet
+	// and the "raw_match" metadata
+	rawLine := `{"type":"assistant","text":"` + strings.Repeat("x", 4000) + `"}`
+	n := &Notification{Title: "Activity Detected", Agent: "Claude Code", Message: "Tool request", Time: time.Now()}
+	AttachRawMatch(n, rawLine)
+	raw := n.Metadata["raw_match"]
+	if err := notifier.Send(context.Background(), n); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if n.Metadata["raw_match"] != raw {
+		t.Error("Send changed the notification's metadata")
+	}
+
+	history := NewEvent(EventCooling).WithAgent("Claude Code").WithMessage("Cooling")
+	for i := range 100 {
+		history.History = append(history.History, HistoryEntry{Timestamp: time.Now(), Type: "activity", Reason: fmt.Sprintf("tool_use %d", i)})
+	}
+	if err := notifier.WriteEvent(history); err != nil {
+		t.Fatalf("WriteEvent failed: %v", err)
+	}
+
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var got []Event
+	for i, line := range lines {
+		if len(line) > limit {
+			t.Errorf("line %d is %d bytes, want at most %d", i, len(line), limit)
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		got = append(got, event)
+	}
+
+	if got[0].Message != "Tool request" {
+		t.Errorf("raw match event message = %q, want it whole", got[0].Message)
+	}
+	if cut, _ := got[0].Metadata["raw_match"].(string); !strings.HasSuffix(cut, truncationMarker) {
+		t.Errorf("raw_match = %q, want it cut and marked", cut)
+	}
+
+	if got[1].Message != "Cooling" {
+		t.Errorf("history event message = %q, want it whole", got[1].Message)
+	}
+	if len(got[1].History) == 0 || len(got[1].History) >= 100 {
+		t.Fatalf("kept %d history entries, want some dropped to fit", len(got[1].History))
+	}
+	if last := got[1].History[len(got[1].History)-1]; last.Reason != "tool_use 99" {
+		t.Errorf("last history entry = %q, want the newest kept", last.Reason)
+	}
+	if len(history.History) != 100 {
+		t.Error("WriteEvent changed the event's history")
+	}
+}
+
 func TestEventFileNotifier_Rotation(t *testing.T) {
 	tmpDir := t.TempDir()
 	eventPath := filepath.Join(tmpDir, "events.jsonl")
@@ -145,6 +288,23 @@ func TestEventFileNotifier_Rotation(t *testing.T) {
 	}
 }
 
+func TestEventFileNotifier_CreatesParentDirs(t *testing.T) {
+	eventPath := filepath.Join(t.TempDir(), "a", "b", "events.jsonl")
+
+	notifier, err := NewEventFileNotifier(eventPath, 0)
+	if err != nil {
+		t.Fatalf("NewEventFileNotifier failed: %v", err)
+	}
+	defer notifier.Close()
+
+	if err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if _, err := os.Stat(eventPath); err != nil {
+		t.Errorf("Event file not created: %v", err)
+	}
+}
+
 func TestEventFileNotifier_DefaultPath(t *testing.T) {
 	// Test with empty path (should use default)
 	notifier, err := NewEventFileNotifier("", 0)
@@ -220,6 +380,8 @@ func TestDetermineEventType(t *testing.T) {
 		expected EventType
 	}{
 		{"Cooling", EventCooling},
+		{"Compacted", EventCompaction},
+		{"Process Started", EventProcessStart},
 		{"Process Exited", EventProcessExit},
 		{"Process Exit", EventProcessExit},
 		{"Activity Detected", EventActivity},
@@ -285,3 +447,109 @@ func TestEvent_JSON(t *testing.T) {
 		t.Errorf("Agent = %q, want %q", parsed.Agent, "Claude Code")
 	}
 }
+
+func TestEvent_HostAndVersion(t *testing.T) {
+	host, _ := os.Hostname()
+	for _, event := range []*Event{
+		NewEvent(EventDaemonStart),
+		NewEventFromNotification(&Notification{Title: "Cooling", Time: time.Now()}, EventCooling),
+	} {
+		if event.Host != host || event.Version != config.Version {
+			t.Errorf("%s: Host/Version = %q/%q, want %q/%q", event.Event, event.Host, event.Version, host, config.Version)
+		}
+		data, _ := event.JSON()
+		if !strings.Contains(string(data), `"version":"`+config.Version+`"`) {
+			t.Errorf("%s: JSON missing version: %s", event.Event, data)
+		}
+	}
+}
+
+func TestEvent_Priority(t *testing.T) {
+	event := NewEventFromNotification(&Notification{Title: "Holding", Time: time.Now(), Priority: PriorityHigh}, EventHolding)
+	data, _ := event.JSON()
+	if !strings.Contains(string(data), `"priority":"high"`) {
+		t.Errorf("JSON missing priority: %s", data)
+	}
+
+	data, _ = NewEventFromNotification(&Notification{Title: "Holding", Time: time.Now()}, EventHolding).JSON()
+	if strings.Contains(string(data), "priority") {
+		t.Errorf("Normal priority should be omitted: %s", data)
+	}
+}
+
+func TestEventFileNotifier_DiskFull(t *testing.T) {
+	e, err := NewEventFileNotifier(filepath.Join(t.TempDir(), "events.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	now := time.Now()
+	e.now = func() time.Time { return now }
+	full := true
+	var written []string
+	writes := 0
+	e.writeEvent = func(data []byte) error {
+		writes++
+		if full {
+			return &os.PathError{Op: "write", Path: e.path, Err: syscall.ENOSPC}
+		}
+		written = append(written, string(data))
+		return nil
+	}
+
+	// A full disk disables the notifier without failing the send
+	ctx := context.Background()
+	if err := e.Send(ctx, &Notification{Title: "Cooling", Time: now}); err != nil {
+		t.Fatalf("Send on a full disk = %v, want nil", err)
+	}
+	if !e.disabled {
+		t.Fatal("Notifier not disabled after ENOSPC")
+	}
+
+	// While disabled, events are dropped without touching the disk
+	e.Send(ctx, &Notification{Title: "Holding", Time: now})
+	if writes != 1 || e.dropped != 2 {
+		t.Errorf("writes = %d, dropped = %d; want 1, 2", writes, e.dropped)
+	}
+
+	// Still full at the retry: stays disabled
+	now = now.Add(diskFullRetry)
+	e.Send(ctx, &Notification{Title: "Cooling", Time: now})
+	if writes != 2 || !e.disabled {
+		t.Errorf("writes = %d, disabled = %v after a failed retry", writes, e.disabled)
+	}
+
+	// Space returns: the next retry re-enables it
+	full = false
+	now = now.Add(diskFullRetry)
+	if err := e.Send(ctx, &Notification{Title: "Awaiting", Time: now}); err != nil {
+		t.Fatalf("Send after recovery = %v", err)
+	}
+	if e.disabled || e.dropped != 0 {
+		t.Errorf("disabled = %v, dropped = %d after recovery", e.disabled, e.dropped)
+	}
+	if len(written) != 1 || !strings.HasPrefix(written[0], "\n{") || !strings.Contains(written[0], `"title":"Awaiting"`) {
+		t.Errorf("Written = %q, want the Awaiting event after a separating newline", written)
+	}
+
+	e.Send(ctx, &Notification{Title: "Cooling", Time: now})
+	if len(written) != 2 || strings.HasPrefix(written[1], "\n") {
+		t.Errorf("Written = %q, want a plain line once re-enabled", written)
+	}
+}
+
+func TestEventFileNotifier_OtherErrorsReturned(t *testing.T) {
+	e, err := NewEventFileNotifier(filepath.Join(t.TempDir(), "events.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.writeEvent = func(data []byte) error { return errors.New("permission denied") }
+
+	if err := e.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err == nil {
+		t.Error("Expected a non-ENOSPC error to be returned")
+	}
+	if e.disabled {
+		t.Error("Disabled on an error other than ENOSPC")
+	}
+}
@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxEventLineSize bounds a single event line when reading event files.
+const maxEventLineSize = 1024 * 1024
+
+// EventFiles returns the event file at path and its rotations, oldest first.
+// Rotations are named path + "." + timestamp, so they sort chronologically.
+// Missing files are skipped; an empty result means there are no events.
+func EventFiles(path string) ([]string, error) {
+	matches, err := filepath.Glob(globEscape(path) + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, m := range matches {
+		suffix := strings.TrimPrefix(m, path+".")
+		if suffix == "" || strings.Contains(suffix, string(filepath.Separator)) {
+			continue
+		}
+		files = append(files, m)
+	}
+	sort.Strings(files)
+
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// globEscape escapes glob metacharacters in a literal path.
+func globEscape(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		switch r {
+		case '*', '?', '[', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EventReader iterates over event lines across an event file and its
+// rotations in chronological order. Use it like bufio.Scanner:
+//
+//	r, err := NewEventReader(path)
+//	for r.Next() {
+//		line := r.Line()
+//	}
+//	err = r.Err()
+type EventReader struct {
+	files   []string
+	idx     int
+	file    *os.File
+	scanner *bufio.Scanner
+	line    string
+	err     error
+}
+
+// NewEventReader creates a reader over the event file at path and its rotations.
+func NewEventReader(path string) (*EventReader, error) {
+	files, err := EventFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return &EventReader{files: files}, nil
+}
+
+// Next advances to the next non-empty line, opening the next file as needed.
+// Returns false when all files are exhausted or an error occurs.
+func (r *EventReader) Next() bool {
+	for r.err == nil {
+		if r.scanner == nil {
+			if r.idx >= len(r.files) {
+				return false
+			}
+			f, err := os.Open(r.files[r.idx])
+			r.idx++
+			if os.IsNotExist(err) {
+				continue // Rotated away while reading
+			}
+			if err != nil {
+				r.err = err
+				return false
+			}
+			r.file = f
+			r.scanner = bufio.NewScanner(f)
+			r.scanner.Buffer(make([]byte, 64*1024), maxEventLineSize)
+		}
+
+		if r.scanner.Scan() {
+			line := r.scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			r.line = line
+			return true
+		}
+
+		r.err = r.scanner.Err()
+		r.closeFile()
+	}
+	return false
+}
+
+// Line returns the current line.
+func (r *EventReader) Line() string {
+	return r.line
+}
+
+// Err returns the first error encountered while reading.
+func (r *EventReader) Err() error {
+	return r.err
+}
+
+// Close releases the open file, if any.
+func (r *EventReader) Close() error {
+	r.closeFile()
+	return nil
+}
+
+func (r *EventReader) closeFile() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	r.scanner = nil
+}
+
+// TailEvents returns up to n event lines ending offset lines before the
+// newest, in chronological order. It reads across rotations.
+func TailEvents(path string, n, offset int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	r, err := NewEventReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Ring buffer of the last n+offset lines
+	size := n + offset
+	ring := make([]string, size)
+	total := 0
+	for r.Next() {
+		ring[total%size] = r.Line()
+		total++
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	end := total - offset
+	if end <= 0 {
+		return nil, nil
+	}
+	start := end - n
+	if start < 0 {
+		start = 0
+	}
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		lines = append(lines, ring[i%size])
+	}
+	return lines, nil
+}
+
+// CountEvents counts the events in the file at path by type. Unless all is
+// set, only agent events (see IsAgentEvent) are counted. Lines that are not
+// valid events are skipped.
+func CountEvents(path string, all bool) (map[EventType]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	counts := make(map[EventType]int)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxEventLineSize)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Event == "" {
+			continue
+		}
+		if !all && !IsAgentEvent(e.Event) {
+			continue
+		}
+		counts[e.Event]++
+	}
+	return counts, scanner.Err()
+}
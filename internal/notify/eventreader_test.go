@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEventFiles creates an event file and rotations holding numbered lines.
+// Each element of counts is one file, oldest first; the last is the live file.
+func writeEventFiles(t *testing.T, counts ...int) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+
+	n := 0
+	for i, count := range counts {
+		var b strings.Builder
+		for j := 0; j < count; j++ {
+			fmt.Fprintf(&b, `{"event":"activity","n":%d}`+"\n", n)
+			n++
+		}
+		name := path
+		if i < len(counts)-1 {
+			name = fmt.Sprintf("%s.2025-01-15-1000%02d", path, i)
+		}
+		if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func eventNumbers(lines []string) []int {
+	nums := make([]int, 0, len(lines))
+	for _, line := range lines {
+		var n int
+		fmt.Sscanf(line[strings.Index(line, `"n":`)+4:], "%d", &n)
+		nums = append(nums, n)
+	}
+	return nums
+}
+
+func TestEventFiles(t *testing.T) {
+	path := writeEventFiles(t, 1, 1, 1)
+
+	// Unrelated file sharing the prefix directory
+	os.WriteFile(filepath.Join(filepath.Dir(path), "other.jsonl"), []byte("x\n"), 0644)
+
+	files, err := EventFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 files, got %v", files)
+	}
+	if files[len(files)-1] != path {
+		t.Errorf("Live file should be last, got %v", files)
+	}
+	if files[0] >= files[1] {
+		t.Errorf("Rotations not in chronological order: %v", files)
+	}
+}
+
+func TestEventReaderAcrossRotations(t *testing.T) {
+	path := writeEventFiles(t, 3, 2, 4)
+
+	r, err := NewEventReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var lines []string
+	for r.Next() {
+		lines = append(lines, r.Line())
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := eventNumbers(lines)
+	if len(got) != 9 {
+		t.Fatalf("Expected 9 lines, got %d", len(got))
+	}
+	for i, n := range got {
+		if n != i {
+			t.Fatalf("Lines out of order: %v", got)
+		}
+	}
+}
+
+func TestEventReaderNoFiles(t *testing.T) {
+	r, err := NewEventReader(filepath.Join(t.TempDir(), "events.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Next() {
+		t.Error("Expected no lines")
+	}
+}
+
+func TestTailEvents(t *testing.T) {
+	// Lines 0-2 | 3-4 | 5-8
+	path := writeEventFiles(t, 3, 2, 4)
+
+	tests := []struct {
+		name      string
+		n, offset int
+		want      []int
+	}{
+		{"newest within live file", 2, 0, []int{7, 8}},
+		{"spans rotation boundary", 6, 0, []int{3, 4, 5, 6, 7, 8}},
+		{"offset into rotations", 3, 4, []int{2, 3, 4}},
+		{"page past start", 5, 6, []int{0, 1, 2}},
+		{"offset beyond all", 3, 20, nil},
+		{"more than exists", 50, 0, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := TailEvents(path, tt.n, tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := eventNumbers(lines)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+				t.Errorf("TailEvents(%d, %d) = %v, want %v", tt.n, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	lines := []string{
+		`{"event":"daemon_start","timestamp":"2025-01-15T10:00:00Z"}`,
+		`{"event":"activity","timestamp":"2025-01-15T10:00:01Z","agent":"Claude Code"}`,
+		`{"event":"activity","timestamp":"2025-01-15T10:00:02Z","agent":"Claude Code"}`,
+		`{"event":"heartbeat","timestamp":"2025-01-15T10:00:03Z"}`,
+		`{"event":"welcome","timestamp":"2025-01-15T10:00:03Z"}`,
+		`{"type":"welcome","version":"dev"}`,
+		`{"event":"status","timestamp":"2025-01-15T10:00:04Z"}`,
+		`{"event":"cooling","timestamp":"2025-01-15T10:00:05Z","agent":"Claude Code","message":"{\"event\":\"fake\"}"}`,
+		`not json`,
+		`{"event":"daemon_stop","timestamp":"2025-01-15T10:00:06Z"}`,
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("agent events only", func(t *testing.T) {
+		counts, err := CountEvents(path, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[EventType]int{EventActivity: 2, EventCooling: 1}
+		if len(counts) != len(want) {
+			t.Fatalf("CountEvents = %v, want %v", counts, want)
+		}
+		for k, v := range want {
+			if counts[k] != v {
+				t.Errorf("counts[%s] = %d, want %d", k, counts[k], v)
+			}
+		}
+	})
+
+	t.Run("all events", func(t *testing.T) {
+		counts, err := CountEvents(path, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[EventType]int{
+			EventActivity:    2,
+			EventCooling:     1,
+			EventDaemonStart: 1,
+			EventDaemonStop:  1,
+			EventStatus:      1,
+			"heartbeat":      1,
+			"welcome":        1,
+		}
+		if len(counts) != len(want) {
+			t.Fatalf("CountEvents = %v, want %v", counts, want)
+		}
+		for k, v := range want {
+			if counts[k] != v {
+				t.Errorf("counts[%s] = %d, want %d", k, counts[k], v)
+			}
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := CountEvents(filepath.Join(t.TempDir(), "none.jsonl"), false); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
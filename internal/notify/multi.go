@@ -3,23 +3,82 @@ package notify
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 )
 
 // MultiNotifier sends notifications to multiple notifiers.
 type MultiNotifier struct {
 	primary   Notifier
 	secondary []Notifier
+
+	mu     sync.Mutex
+	health []NotifierStatus // Primary first, then secondaries in order
+
+	limit      *rateLimiter // nil = no output.max_per_minute
+	severities SeverityMap  // Resolves each notification's severity (nil = defaults)
+}
+
+// NotifierStatus is the outcome of a notifier's most recent send.
+type NotifierStatus struct {
+	Name     string    `json:"name"`                // Notifier name, "#N" appended to repeats (e.g. "webhook#2")
+	LastSend time.Time `json:"last_send,omitempty"` // Zero if nothing was sent yet
+	Error    string    `json:"error,omitempty"`     // Empty if the last send succeeded
+}
+
+// String formats the status relative to now: "slack: ok 12s ago" or
+// "webhook#2: error 3m0s ago: status 500".
+func (s NotifierStatus) String(now time.Time) string {
+	if s.LastSend.IsZero() {
+		return s.Name + ": no sends yet"
+	}
+	ago := now.Sub(s.LastSend).Round(time.Second).String() + " ago"
+	if s.Error != "" {
+		return fmt.Sprintf("%s: error %s: %s", s.Name, ago, s.Error)
+	}
+	return fmt.Sprintf("%s: ok %s", s.Name, ago)
 }
 
 // NewMultiNotifier creates a notifier that sends to multiple destinations.
 // The primary notifier is required; secondary notifiers are optional.
 func NewMultiNotifier(primary Notifier, secondary ...Notifier) *MultiNotifier {
-	return &MultiNotifier{
+	m := &MultiNotifier{
 		primary:   primary,
 		secondary: secondary,
 	}
+
+	seen := make(map[string]int)
+	for _, n := range append([]Notifier{primary}, secondary...) {
+		name := n.Name()
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, seen[name])
+		}
+		m.health = append(m.health, NotifierStatus{Name: name})
+	}
+	return m
+}
+
+// record stores the result of a send by notifier i (0 = primary).
+func (m *MultiNotifier) record(i int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health[i].LastSend = time.Now()
+	m.health[i].Error = ""
+	if err != nil {
+		m.health[i].Error = err.Error()
+	}
+}
+
+// NotifierHealth returns the last send result of each notifier, primary
+// first. It lets a stale credential show up before an alert goes missing.
+func (m *MultiNotifier) NotifierHealth() []NotifierStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]NotifierStatus(nil), m.health...)
 }
 
 // Name returns the combined notifier names.
@@ -31,24 +90,122 @@ func (m *MultiNotifier) Name() string {
 	return strings.Join(names, "+")
 }
 
-// Send delivers the notification to all notifiers.
-// Errors from secondary notifiers are logged but don't fail the operation.
+// SetRateLimit caps what Send delivers to the primary notifier and webhooks
+// at perMinute notifications a minute, in bursts of up to perMinute; the rest
+// are dropped, with at most one alert a minute saying so. The event file and
+// socket clients still get every notification. 0 removes the cap.
+func (m *MultiNotifier) SetRateLimit(perMinute int) {
+	m.limit = nil
+	if perMinute > 0 {
+		m.limit = newRateLimiter(perMinute)
+	}
+}
+
+// SetSeverities sets the severities given to notifications by event type,
+// as built from output.severity_overrides by NewSeverityMap.
+func (m *MultiNotifier) SetSeverities(severities SeverityMap) {
+	m.severities = severities
+}
+
+// resolveSeverity sets n's severity from the event type, unless set already.
+func (m *MultiNotifier) resolveSeverity(n *Notification) {
+	if n.Severity == "" {
+		n.Severity = m.severities.Of(n.Priority, DetermineEventType(n))
+	}
+}
+
+// Dropped returns how many notifications the rate limit has dropped.
+func (m *MultiNotifier) Dropped() int {
+	if m.limit == nil {
+		return 0
+	}
+	m.limit.mu.Lock()
+	defer m.limit.mu.Unlock()
+	return m.limit.dropped
+}
+
+// Send delivers the notification to all notifiers. Failures are returned as
+// a *DeliveryError: a primary failure, which is critical and stops delivery
+// to the secondaries, or the secondaries' failures, which are best effort
+// except for webhooks marked critical. Past the rate limit, the notification
+// is only recorded locally (see RecordLocal).
 func (m *MultiNotifier) Send(ctx context.Context, n *Notification) error {
+	if m.limit != nil {
+		ok, alert := m.limit.allow()
+		if !ok {
+			err := m.RecordLocal(ctx, n)
+			if alert {
+				if alertErr := m.send(ctx, m.limit.alertNotification()); alertErr != nil {
+					return alertErr
+				}
+			}
+			return err
+		}
+	}
+	return m.send(ctx, n)
+}
+
+// send delivers the notification to all notifiers, ignoring the rate limit.
+func (m *MultiNotifier) send(ctx context.Context, n *Notification) error {
+	m.resolveSeverity(n)
+
 	// Send to primary first
-	if err := m.primary.Send(ctx, n); err != nil {
-		return fmt.Errorf("primary notifier (%s) failed: %w", m.primary.Name(), err)
+	err := m.primary.Send(ctx, n)
+	m.record(0, err)
+	if err != nil {
+		return deliveryError(DeliveryFailures(err, m.health[0].Name))
 	}
 
-	// Send to secondary notifiers (best effort)
-	for _, notifier := range m.secondary {
-		if err := notifier.Send(ctx, n); err != nil {
-			// Log error but continue - secondary notifiers are best effort
-			// In a real implementation, you might want to use a logger
-			_ = err
+	return m.sendSecondary(ctx, n, func(Notifier) bool { return true })
+}
+
+// sendSecondary delivers the notification to the secondary notifiers
+// accepted by keep, returning their failures as a *DeliveryError. They are
+// best effort unless a notifier reports otherwise (critical webhooks).
+func (m *MultiNotifier) sendSecondary(ctx context.Context, n *Notification, keep func(Notifier) bool) error {
+	var failures []DeliveryFailure
+	for i, notifier := range m.secondary {
+		if !keep(notifier) {
+			continue
+		}
+		err := notifier.Send(ctx, n)
+		m.record(i+1, err)
+		if err == nil {
+			continue
+		}
+		var de *DeliveryError
+		if errors.As(err, &de) {
+			failures = append(failures, de.Failures...)
+		} else {
+			failures = append(failures, DeliveryFailure{Route: m.health[i+1].Name, Err: err})
 		}
 	}
+	return deliveryError(failures)
+}
 
-	return nil
+// Record delivers the notification only to the event file notifiers, so it
+// is kept for integrations without alerting anyone (used while snoozed).
+func (m *MultiNotifier) Record(ctx context.Context, n *Notification) error {
+	m.resolveSeverity(n)
+	return m.sendSecondary(ctx, n, func(notifier Notifier) bool {
+		_, ok := notifier.(*EventFileNotifier)
+		return ok
+	})
+}
+
+// RecordLocal delivers the notification only to the local integrations, the
+// event file and socket clients, skipping the primary notifier and webhooks
+// (used for agents in agents.notify_disabled).
+func (m *MultiNotifier) RecordLocal(ctx context.Context, n *Notification) error {
+	m.resolveSeverity(n)
+	return m.sendSecondary(ctx, n, isLocal)
+}
+
+// isLocal reports whether the notifier is a local integration: the event
+// file or socket clients.
+func isLocal(notifier Notifier) bool {
+	name := notifier.Name()
+	return name == "eventfile" || name == "socket"
 }
 
 // Primary returns the primary notifier.
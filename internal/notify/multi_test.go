@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubNotifier counts sends and returns err from every Send.
+type stubNotifier struct {
+	name string
+	err  error
+	sent int
+}
+
+func (s *stubNotifier) Send(ctx context.Context, n *Notification) error { s.sent++; return s.err }
+func (s *stubNotifier) Name() string                                    { return s.name }
+
+func TestMultiNotifierHealth(t *testing.T) {
+	failing := &stubNotifier{name: "webhook", err: errors.New("webhook returned status 500")}
+	m := NewMultiNotifier(
+		&stubNotifier{name: "slack"},
+		&stubNotifier{name: "webhook"},
+		failing,
+	)
+
+	health := m.NotifierHealth()
+	if len(health) != 3 {
+		t.Fatalf("Expected 3 statuses, got %+v", health)
+	}
+	for _, h := range health {
+		if !h.LastSend.IsZero() {
+			t.Errorf("%s should have no sends yet", h.Name)
+		}
+	}
+
+	before := time.Now()
+	// The failing secondary is reported, as best effort
+	err := m.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	var de *DeliveryError
+	if !errors.As(err, &de) || len(de.Critical()) != 0 || len(de.BestEffort()) != 1 {
+		t.Fatalf("Send() error = %v, want one best-effort failure", err)
+	}
+
+	health = m.NotifierHealth()
+	names := []string{health[0].Name, health[1].Name, health[2].Name}
+	if strings.Join(names, ",") != "slack,webhook,webhook#2" {
+		t.Errorf("Names = %v, want slack, webhook, webhook#2", names)
+	}
+	for _, h := range health {
+		if h.LastSend.Before(before) {
+			t.Errorf("%s LastSend = %v, want after %v", h.Name, h.LastSend, before)
+		}
+	}
+	if health[0].Error != "" || health[1].Error != "" {
+		t.Errorf("Expected slack and webhook ok, got %+v", health[:2])
+	}
+	if health[2].Error != "webhook returned status 500" {
+		t.Errorf("webhook#2 Error = %q", health[2].Error)
+	}
+
+	// A later success clears the error
+	failing.err = nil
+	m.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	if got := m.NotifierHealth()[2].Error; got != "" {
+		t.Errorf("Error after recovery = %q, want empty", got)
+	}
+}
+
+func TestMultiNotifierHealthPrimaryFailure(t *testing.T) {
+	m := NewMultiNotifier(
+		&stubNotifier{name: "slack", err: errors.New("invalid_token")},
+		&stubNotifier{name: "eventfile"},
+	)
+
+	if err := m.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err == nil {
+		t.Fatal("Expected primary error")
+	}
+
+	health := m.NotifierHealth()
+	if health[0].Error != "invalid_token" || health[0].LastSend.IsZero() {
+		t.Errorf("primary status = %+v, want invalid_token error", health[0])
+	}
+	if !health[1].LastSend.IsZero() {
+		t.Errorf("secondary should not be sent to after a primary failure, got %+v", health[1])
+	}
+}
+
+func TestMultiNotifierRecordLocal(t *testing.T) {
+	slack := &stubNotifier{name: "slack"}
+	webhook := &stubNotifier{name: "webhook"}
+	eventFile := &stubNotifier{name: "eventfile"}
+	socket := &stubNotifier{name: "socket"}
+	m := NewMultiNotifier(slack, eventFile, webhook, socket)
+
+	if err := m.RecordLocal(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err != nil {
+		t.Fatalf("RecordLocal failed: %v", err)
+	}
+	if slack.sent != 0 || webhook.sent != 0 {
+		t.Errorf("Alerted slack %d, webhook %d times; want none", slack.sent, webhook.sent)
+	}
+	if eventFile.sent != 1 || socket.sent != 1 {
+		t.Errorf("Recorded to event file %d, socket %d times; want one each", eventFile.sent, socket.sent)
+	}
+}
+
+func TestNotifierStatusString(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		status NotifierStatus
+		want   string
+	}{
+		{NotifierStatus{Name: "slack"}, "slack: no sends yet"},
+		{NotifierStatus{Name: "slack", LastSend: now.Add(-12 * time.Second)}, "slack: ok 12s ago"},
+		{NotifierStatus{Name: "webhook#2", LastSend: now.Add(-3 * time.Minute), Error: "status 500"}, "webhook#2: error 3m0s ago: status 500"},
+	}
+	for _, tt := range tests {
+		if got := tt.status.String(now); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMultiNotifierRateLimit(t *testing.T) {
+	primary := &stubNotifier{name: "slack"}
+	webhook := &stubNotifier{name: "webhook"}
+	eventFile := &stubNotifier{name: "eventfile"}
+	socket := &stubNotifier{name: "socket"}
+	m := NewMultiNotifier(primary, eventFile, webhook, socket)
+	m.SetRateLimit(3)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.limit.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	send := func(count int) {
+		t.Helper()
+		for i := 0; i < count; i++ {
+			if err := m.Send(ctx, &Notification{Title: "Activity Detected"}); err != nil {
+				t.Fatalf("Send %d failed: %v", i, err)
+			}
+		}
+	}
+
+	// A burst of 3 plus one alert reaches the primary and webhooks; the
+	// other 7 are dropped there
+	send(10)
+	if primary.sent != 4 || webhook.sent != 4 {
+		t.Errorf("sent = %d/%d, want 4 each", primary.sent, webhook.sent)
+	}
+	if got := m.Dropped(); got != 7 {
+		t.Errorf("Dropped() = %d, want 7", got)
+	}
+	// Local recording isn't limited
+	if eventFile.sent != 11 || socket.sent != 11 {
+		t.Errorf("local sent = %d/%d, want all 10 and the alert", eventFile.sent, socket.sent)
+	}
+
+	// 20s refills one token, and no second alert within the minute
+	now = now.Add(20 * time.Second)
+	send(3)
+	if primary.sent != 5 {
+		t.Errorf("sent = %d after 20s, want 5", primary.sent)
+	}
+	if got := m.Dropped(); got != 9 {
+		t.Errorf("Dropped() = %d, want 9", got)
+	}
+
+	// A minute after the first alert: two more tokens, and another alert
+	now = now.Add(40 * time.Second)
+	send(3)
+	if primary.sent != 8 {
+		t.Errorf("sent = %d after a minute, want 8", primary.sent)
+	}
+	if got := m.Dropped(); got != 10 {
+		t.Errorf("Dropped() = %d, want 10", got)
+	}
+	if eventFile.sent != 18 {
+		t.Errorf("event file sent = %d, want all 16 and both alerts", eventFile.sent)
+	}
+}
+
+func TestMultiNotifierRateLimitAlert(t *testing.T) {
+	var titles []string
+	rec := &recordingStub{onSend: func(n *Notification) { titles = append(titles, n.Title) }}
+	m := NewMultiNotifier(rec)
+	m.SetRateLimit(1)
+
+	for i := 0; i < 4; i++ {
+		m.Send(context.Background(), &Notification{Title: "Holding"})
+	}
+	if strings.Join(titles, ",") != "Holding,"+RateLimitTitle {
+		t.Errorf("titles = %v, want one Holding then one %q", titles, RateLimitTitle)
+	}
+}
+
+// recordingStub calls onSend for every notification it's sent.
+type recordingStub struct {
+	onSend func(*Notification)
+}
+
+func (r *recordingStub) Send(ctx context.Context, n *Notification) error { r.onSend(n); return nil }
+func (r *recordingStub) Name() string                                    { return "recording" }
@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"context"
+)
+
+// NoneNotifier discards notifications.
+// Use it when only the event file, socket, or webhooks should receive events.
+type NoneNotifier struct{}
+
+// NewNoneNotifier creates a new no-op notifier.
+func NewNoneNotifier() *NoneNotifier {
+	return &NoneNotifier{}
+}
+
+// Name returns the notifier type.
+func (n *NoneNotifier) Name() string {
+	return "none"
+}
+
+// Send does nothing.
+func (n *NoneNotifier) Send(ctx context.Context, notification *Notification) error {
+	return nil
+}
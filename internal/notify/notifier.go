@@ -2,7 +2,9 @@
 package notify
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -17,6 +19,41 @@ type Notification struct {
 	Message string    // Body text
 	Snippet string    // Optional log context
 	Time    time.Time // When this notification was created
+
+	Metadata map[string]any // Optional structured data carried into Events
+
+	Priority Priority // PriorityHigh for alerts that skip batching; "" = normal
+	Severity Severity // Set by MultiNotifier from output.severity_overrides; "" = by event type
+
+	History []HistoryEntry // The agent's recent events, oldest first; only webhooks with include_history send them
+}
+
+// Priority marks how urgently a notification should be delivered.
+type Priority string
+
+// PriorityHigh is set on alerts that need attention now, such as a
+// sensitive tool waiting for approval.
+const PriorityHigh Priority = "high"
+
+// Suggested actions for a tool waiting for approval (see ToolAction).
+const (
+	ActionApprove = "approve" // Routine tool, safe to approve from a dashboard
+	ActionReview  = "review"  // Sensitive tool, look before approving
+)
+
+// ToolAction returns the "action" metadata of a Holding notification, so
+// socket and WebSocket clients can render approval buttons: the tool, its
+// arguments, and the suggested action, "review" for sensitive tools.
+func ToolAction(tool, args string, sensitive bool) map[string]any {
+	suggested := ActionApprove
+	if sensitive {
+		suggested = ActionReview
+	}
+	action := map[string]any{"tool": tool, "suggested_action": suggested}
+	if args != "" {
+		action["args"] = args
+	}
+	return action
 }
 
 // Notifier is the interface for sending notifications.
@@ -47,7 +84,18 @@ func NewNotifierWithExtras(cfg *config.Config, extras []Notifier) (Notifier, err
 		}
 		primary = NewSlackNotifier(cfg.Notify.Slack.Webhook)
 	case "stdout":
-		primary = NewStdoutNotifier()
+		stdout := NewStdoutNotifier()
+		stdout.SetTimeFormat(cfg.TimeLayout(), cfg.TimeLocation())
+		stdout.SetTheme(cfg.Output.Theme)
+		primary = stdout
+	case "syslog":
+		syslogNotifier, err := NewSyslogNotifier(cfg.Notify.SyslogTag())
+		if err != nil {
+			return nil, fmt.Errorf("syslog: %w", err)
+		}
+		primary = syslogNotifier
+	case "none":
+		primary = NewNoneNotifier()
 	default:
 		return nil, fmt.Errorf("unknown notification type: %s", cfg.Notify.Type)
 	}
@@ -59,6 +107,7 @@ func NewNotifierWithExtras(cfg *config.Config, extras []Notifier) (Notifier, err
 	if cfg.Daemon.EventFile {
 		eventFile, err := NewEventFileNotifier(cfg.Daemon.EventFilePath, cfg.Daemon.EventFileMaxSize)
 		if err == nil {
+			eventFile.SetMaxLineBytes(cfg.Daemon.EventMaxLineBytes)
 			secondary = append(secondary, eventFile)
 		}
 		// Log warning but continue without event file if it fails
@@ -67,6 +116,7 @@ func NewNotifierWithExtras(cfg *config.Config, extras []Notifier) (Notifier, err
 	// Add webhook notifiers if configured
 	if len(cfg.Notify.Webhooks) > 0 {
 		webhookNotifier := NewWebhookNotifier(cfg.Notify.Webhooks)
+		webhookNotifier.SetConcurrency(cfg.Notify.WebhookConcurrency)
 		if webhookNotifier.EndpointCount() > 0 {
 			secondary = append(secondary, webhookNotifier)
 		}
@@ -75,14 +125,38 @@ func NewNotifierWithExtras(cfg *config.Config, extras []Notifier) (Notifier, err
 	// Add extra notifiers (like socket)
 	secondary = append(secondary, extras...)
 
-	// Return multi-notifier if we have secondary notifiers
-	if len(secondary) > 0 {
-		return NewMultiNotifier(primary, secondary...), nil
+	// Return multi-notifier if we have secondary notifiers, or a rate limit
+	// or severity overrides to apply across all of them
+	if len(secondary) > 0 || cfg.Output.MaxPerMinute > 0 || len(cfg.Output.SeverityOverrides) > 0 {
+		multi := NewMultiNotifier(primary, secondary...)
+		multi.SetRateLimit(cfg.Output.MaxPerMinute)
+		multi.SetSeverities(NewSeverityMap(cfg.Output.SeverityOverrides))
+		return multi, nil
 	}
 
 	return primary, nil
 }
 
+// ManualTitle is the title used by SendManual when none is given.
+const ManualTitle = "Notification"
+
+// SendManual sends a single ad-hoc notification, as used by `firebell notify`.
+// An empty title defaults to ManualTitle; the message is required.
+func SendManual(ctx context.Context, notifier Notifier, title, agent, message string) error {
+	if message == "" {
+		return fmt.Errorf("message is required")
+	}
+	if title == "" {
+		title = ManualTitle
+	}
+	return notifier.Send(ctx, &Notification{
+		Title:   title,
+		Agent:   agent,
+		Message: message,
+		Time:    time.Now(),
+	})
+}
+
 // FormatNotification formats a notification for display.
 func FormatNotification(n *Notification, verbosity string, includeSnippet bool) string {
 	var sb strings.Builder
@@ -124,6 +198,45 @@ func FormatNotification(n *Notification, verbosity string, includeSnippet bool)
 	return sb.String()
 }
 
+// DedupeSnippet drops the snippet's trailing line when it is the matched
+// line and the message already carries it. It returns "" if what remains
+// just repeats the message, so the same text isn't sent twice.
+func DedupeSnippet(snippet, matched, message string) string {
+	message = strings.TrimSpace(message)
+	if snippet == "" || message == "" {
+		return snippet
+	}
+
+	lines := strings.Split(strings.TrimRight(snippet, "\r\n"), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if last == strings.TrimSpace(matched) && strings.Contains(message, last) {
+		lines = lines[:len(lines)-1]
+	}
+	snippet = strings.Join(lines, "\n")
+	if strings.TrimSpace(snippet) == message {
+		return ""
+	}
+	return snippet
+}
+
+// AttachRawMatch adds the log line that triggered n as "raw_match"
+// metadata, pretty-printed if it is JSON, and uses it as the snippet when n
+// has none (see config.OutputConfig.IncludeRawMatch).
+func AttachRawMatch(n *Notification, line string) {
+	raw := strings.TrimSpace(line)
+	var buf bytes.Buffer
+	if json.Indent(&buf, []byte(raw), "", "  ") == nil {
+		raw = buf.String()
+	}
+	if n.Metadata == nil {
+		n.Metadata = map[string]any{}
+	}
+	n.Metadata["raw_match"] = raw
+	if n.Snippet == "" {
+		n.Snippet = raw
+	}
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -1,8 +1,15 @@
 package notify
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"firebell/internal/config"
 )
 
 func TestFormatNotification(t *testing.T) {
@@ -103,6 +110,54 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestDedupeSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		matched string
+		message string
+		want    string
+	}{
+		{"no overlap", "building\ndone", "done", "end turn", "building\ndone"},
+		{"trailing matched line in message", "building\nTask complete", "Task complete", "Task complete", "building"},
+		{"trailing newline from the log", "building\nTask complete\n", "Task complete", "Finished: Task complete", "building"},
+		{"only the matched line", "Task complete", "Task complete", "Task complete", ""},
+		{"snippet equals message", "end turn", "{}", "end turn", ""},
+		{"matched line not trailing", "Task complete\nnext", "Task complete", "Task complete", "Task complete\nnext"},
+		{"empty message", "a\nb", "b", "", "a\nb"},
+		{"empty snippet", "", "b", "b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DedupeSnippet(tt.snippet, tt.matched, tt.message); got != tt.want {
+				t.Errorf("DedupeSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachRawMatch(t *testing.T) {
+	n := &Notification{Message: "end turn"}
+	AttachRawMatch(n, `{"type":"assistant","stop_reason":"end_turn"}`+"\n")
+	want := "{\n  \"type\": \"assistant\",\n  \"stop_reason\": \"end_turn\"\n}"
+	if n.Metadata["raw_match"] != want {
+		t.Errorf("raw_match = %q, want %q", n.Metadata["raw_match"], want)
+	}
+	if n.Snippet != want {
+		t.Errorf("Snippet = %q, want the raw match", n.Snippet)
+	}
+
+	// Plain lines are kept as they are, and an existing snippet is not replaced
+	n = &Notification{Snippet: "building", Metadata: map[string]any{"pid": 1}}
+	AttachRawMatch(n, "Task complete")
+	if n.Metadata["raw_match"] != "Task complete" || n.Metadata["pid"] != 1 {
+		t.Errorf("Metadata = %v, want raw_match added", n.Metadata)
+	}
+	if n.Snippet != "building" {
+		t.Errorf("Snippet = %q, want it unchanged", n.Snippet)
+	}
+}
+
 func TestNewNotificationFromMatch(t *testing.T) {
 	n := NewNotificationFromMatch("claude", "Claude Code", "assistant response", "test line")
 
@@ -150,3 +205,186 @@ func containsSubstr(s, substr string) bool {
 	}
 	return false
 }
+
+// captureNotifier records every notification it is sent.
+type captureNotifier struct {
+	sent []*Notification
+}
+
+func (c *captureNotifier) Send(ctx context.Context, n *Notification) error {
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func (c *captureNotifier) Name() string { return "capture" }
+
+func TestSendManual(t *testing.T) {
+	t.Run("delivers notification", func(t *testing.T) {
+		c := &captureNotifier{}
+		if err := SendManual(context.Background(), c, "Build", "CI", "done"); err != nil {
+			t.Fatalf("SendManual failed: %v", err)
+		}
+		if len(c.sent) != 1 {
+			t.Fatalf("sent %d notifications, want 1", len(c.sent))
+		}
+		n := c.sent[0]
+		if n.Title != "Build" || n.Agent != "CI" || n.Message != "done" {
+			t.Errorf("got title=%q agent=%q message=%q, want Build/CI/done", n.Title, n.Agent, n.Message)
+		}
+		if n.Time.IsZero() {
+			t.Error("Time should be set")
+		}
+	})
+
+	t.Run("default title", func(t *testing.T) {
+		c := &captureNotifier{}
+		if err := SendManual(context.Background(), c, "", "", "done"); err != nil {
+			t.Fatalf("SendManual failed: %v", err)
+		}
+		if len(c.sent) != 1 || c.sent[0].Title != ManualTitle {
+			t.Errorf("expected one notification titled %q, got %+v", ManualTitle, c.sent)
+		}
+	})
+
+	t.Run("message required", func(t *testing.T) {
+		c := &captureNotifier{}
+		if err := SendManual(context.Background(), c, "Build", "CI", ""); err == nil {
+			t.Error("expected error for empty message")
+		}
+		if len(c.sent) != 0 {
+			t.Errorf("sent %d notifications, want 0", len(c.sent))
+		}
+	})
+}
+
+func TestNewNotifier_None(t *testing.T) {
+	eventPath := filepath.Join(t.TempDir(), "events.jsonl")
+
+	cfg := config.DefaultConfig()
+	cfg.Notify.Type = "none"
+	cfg.Daemon.EventFile = true
+	cfg.Daemon.EventFilePath = eventPath
+
+	n, err := NewNotifier(cfg)
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	multi, ok := n.(*MultiNotifier)
+	if !ok {
+		t.Fatalf("expected *MultiNotifier, got %T", n)
+	}
+	defer multi.Close()
+
+	if multi.Primary().Name() != "none" {
+		t.Errorf("Primary().Name() = %q, want 'none'", multi.Primary().Name())
+	}
+
+	// Capture stdout while sending
+	origStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe failed: %v", err)
+	}
+	os.Stdout = w
+
+	sendErr := n.Send(context.Background(), &Notification{
+		Title:   "Cooling",
+		Agent:   "Claude Code",
+		Message: "No activity detected",
+		Time:    time.Now(),
+	})
+
+	w.Close()
+	os.Stdout = origStdout
+	output, _ := io.ReadAll(r)
+
+	if sendErr != nil {
+		t.Fatalf("Send failed: %v", sendErr)
+	}
+	if len(output) != 0 {
+		t.Errorf("expected no stdout output, got %q", output)
+	}
+
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		t.Fatalf("Failed to read event file: %v", err)
+	}
+	if !containsSubstr(string(data), `"event":"cooling"`) {
+		t.Errorf("event file missing cooling event: %s", data)
+	}
+}
+
+func TestStdoutNotifierTimeFormat(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	cfg.Daemon.EventFile = false
+	cfg.Output.TimeFormat = "2006-01-02 15:04:05 MST"
+	cfg.Output.Timezone = "America/New_York"
+
+	n, err := NewNotifier(cfg)
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+
+	origStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe failed: %v", err)
+	}
+	os.Stdout = w
+
+	sendErr := n.Send(context.Background(), &Notification{
+		Title: "Cooling",
+		Agent: "Claude Code",
+		Time:  time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC),
+	})
+
+	w.Close()
+	os.Stdout = origStdout
+	output, _ := io.ReadAll(r)
+
+	if sendErr != nil {
+		t.Fatalf("Send failed: %v", sendErr)
+	}
+	want := "[2025-01-14 22:00:00 EST] Claude Code | Cooling"
+	if !containsSubstr(string(output), want) {
+		t.Errorf("output = %q, want to contain %q", output, want)
+	}
+}
+
+func TestStdoutNotifierThemes(t *testing.T) {
+	n := &Notification{
+		Title:   "Cooling",
+		Agent:   "Claude Code",
+		Message: "No activity detected",
+		Snippet: "last line",
+		Time:    time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		theme string
+		want  string
+	}{
+		{"", "[03:00:00] Claude Code | Cooling\n  No activity detected\n  ---\n  last line\n  ---\n\n"},
+		{"plain", "[03:00:00] Claude Code | Cooling\n  No activity detected\n  ---\n  last line\n  ---\n\n"},
+		{"emoji", "[03:00:00] Claude Code | ✅ Cooling\n  No activity detected\n  ---\n  last line\n  ---\n\n"},
+		{"compact", "03:00:00 Claude Code | Cooling: No activity detected\n"},
+		{"json", `{"event":"cooling","timestamp":"2025-01-15T03:00:00Z","agent":"Claude Code","title":"Cooling","message":"No activity detected","snippet":"last line","host":"` + eventHost() + `","version":"` + config.Version + `","severity":"notice"}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.theme, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := NewStdoutNotifier()
+			s.SetTimeFormat("15:04:05", time.UTC)
+			s.SetTheme(tt.theme)
+			s.out = &buf
+
+			if err := s.Send(context.Background(), n); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitTitle is the title of the alert sent when output.max_per_minute
+// starts dropping notifications.
+const RateLimitTitle = "Rate Limit Reached"
+
+// rateLimiter is a token bucket allowing bursts of up to max notifications
+// and max per minute sustained: the bucket starts full and refills one token
+// every minute/max. A runaway loop so costs at most max notifications in any
+// minute after the first burst, and at most one alert a minute.
+type rateLimiter struct {
+	max int
+	now func() time.Time
+
+	mu        sync.Mutex
+	tokens    float64   // Notifications that may be sent now, up to max
+	last      time.Time // When tokens was last refilled
+	lastAlert time.Time // When the last alert was allowed (zero = never)
+	dropped   int       // Notifications dropped since startup
+}
+
+func newRateLimiter(max int) *rateLimiter {
+	return &rateLimiter{max: max, now: time.Now, tokens: float64(max)}
+}
+
+// allow reports whether a notification may be sent, taking a token if so.
+// When it may not, the drop is counted and alert is true if no alert was
+// allowed in the last minute.
+func (r *rateLimiter) allow() (ok, alert bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if !r.last.IsZero() {
+		refill := now.Sub(r.last).Minutes() * float64(r.max)
+		r.tokens = min(float64(r.max), r.tokens+refill)
+	}
+	r.last = now
+
+	if r.tokens >= 1 {
+		r.tokens--
+		return true, false
+	}
+	r.dropped++
+	if r.lastAlert.IsZero() || now.Sub(r.lastAlert) >= time.Minute {
+		r.lastAlert = now
+		return false, true
+	}
+	return false, false
+}
+
+// alertNotification describes why notifications are being dropped.
+func (r *rateLimiter) alertNotification() *Notification {
+	return &Notification{
+		Title: RateLimitTitle,
+		Agent: "firebell",
+		Message: fmt.Sprintf("More than %d notifications a minute; dropping alerts until the rate falls (output.max_per_minute). The event file and socket still get every one",
+			r.max),
+		Time:     r.now(),
+		Priority: PriorityHigh,
+	}
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ReadEvents parses a recorded event file: one JSON Event per line, as
+// written by EventFileNotifier. Blank lines are skipped.
+func ReadEvents(r io.Reader) ([]*Event, error) {
+	var events []*Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxEventLineSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		events = append(events, &event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Notification reconstructs the Notification the event was built from.
+func (e *Event) Notification() *Notification {
+	return &Notification{
+		Title:    e.Title,
+		Agent:    e.Agent,
+		Message:  e.Message,
+		Snippet:  e.Snippet,
+		Time:     e.Timestamp,
+		Metadata: e.Metadata,
+		Priority: e.Priority,
+		Severity: e.Severity,
+	}
+}
+
+// Replayer re-sends recorded events through a notifier, keeping their
+// original spacing scaled by Speed, as used by `firebell replay`.
+type Replayer struct {
+	Speed float64 // Playback speed: 1 = original timing, 10 = ten times faster, 0 = no delay
+
+	wait func(ctx context.Context, d time.Duration) error // Sleeps between events (for tests)
+}
+
+// NewReplayer creates a Replayer at the given speed.
+func NewReplayer(speed float64) *Replayer {
+	return &Replayer{Speed: speed, wait: sleepContext}
+}
+
+// Run sends each agent event in events to notifier in order, waiting the
+// scaled gap between their timestamps. Daemon lifecycle and status events are
+// skipped. A failed send doesn't stop the replay; all failures are returned
+// together. It returns the number of events sent.
+func (r *Replayer) Run(ctx context.Context, notifier Notifier, events []*Event) (int, error) {
+	var errs []error
+	var last time.Time
+	sent := 0
+	for _, event := range events {
+		if !IsAgentEvent(event.Event) {
+			continue
+		}
+		if r.Speed > 0 && !last.IsZero() {
+			if gap := event.Timestamp.Sub(last); gap > 0 {
+				if err := r.wait(ctx, time.Duration(float64(gap)/r.Speed)); err != nil {
+					return sent, err
+				}
+			}
+		}
+		last = event.Timestamp
+
+		if err := CriticalOnly(notifier.Send(ctx, event.Notification())); err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", event.Event, event.Title, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replayFixture is a short recording: a daemon start, a tool request, a
+// completion 30s later, and a stop.
+const replayFixture = `{"event":"daemon_start","timestamp":"2025-01-15T10:00:00Z"}
+{"event":"holding","timestamp":"2025-01-15T10:00:10Z","agent":"Claude Code","title":"Holding","message":"Waiting for tool approval","priority":"high"}
+
+{"event":"cooling","timestamp":"2025-01-15T10:00:40Z","agent":"Claude Code","title":"Cooling","message":"No activity for 20 seconds","metadata":{"cpu_percent":2.5}}
+{"event":"daemon_stop","timestamp":"2025-01-15T10:01:00Z"}
+`
+
+// capturingNotifier records every notification and fails those titled failTitle.
+type capturingNotifier struct {
+	sent      []*Notification
+	failTitle string
+}
+
+func (c *capturingNotifier) Send(ctx context.Context, n *Notification) error {
+	if n.Title == c.failTitle {
+		return errors.New("send failed")
+	}
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func (c *capturingNotifier) Name() string { return "capturing" }
+
+func TestReadEvents(t *testing.T) {
+	events, err := ReadEvents(strings.NewReader(replayFixture))
+	if err != nil {
+		t.Fatalf("ReadEvents failed: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Read %d events, want 4", len(events))
+	}
+	if events[1].Event != EventHolding || events[1].Priority != PriorityHigh {
+		t.Errorf("events[1] = %+v, want a high-priority holding", events[1])
+	}
+
+	if _, err := ReadEvents(strings.NewReader("{\"event\":\"cooling\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Malformed line error = %v, want one naming line 2", err)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	events, err := ReadEvents(strings.NewReader(replayFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var waits []time.Duration
+	r := NewReplayer(10)
+	r.wait = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	rec := &capturingNotifier{}
+	sent, err := r.Run(context.Background(), rec, events)
+	if err != nil || sent != 2 {
+		t.Fatalf("Run = %d, %v; want 2 agent events sent", sent, err)
+	}
+
+	// Daemon events are skipped; the 30s gap plays back 10x faster
+	if rec.sent[0].Title != "Holding" || rec.sent[1].Title != "Cooling" {
+		t.Errorf("Sent %q, %q; want Holding, Cooling", rec.sent[0].Title, rec.sent[1].Title)
+	}
+	if len(waits) != 1 || waits[0] != 3*time.Second {
+		t.Errorf("Waits = %v, want [3s]", waits)
+	}
+
+	// Notifications are rebuilt from the recorded fields
+	cooling := rec.sent[1]
+	if cooling.Agent != "Claude Code" || cooling.Message != "No activity for 20 seconds" || cooling.Metadata["cpu_percent"] != 2.5 {
+		t.Errorf("Cooling = %+v", cooling)
+	}
+	if !cooling.Time.Equal(time.Date(2025, 1, 15, 10, 0, 40, 0, time.UTC)) {
+		t.Errorf("Time = %v, want the recorded timestamp", cooling.Time)
+	}
+	if DetermineEventType(rec.sent[0]) != EventHolding || rec.sent[0].Priority != PriorityHigh {
+		t.Errorf("Holding = %+v, want a high-priority holding", rec.sent[0])
+	}
+}
+
+func TestReplayer_NoDelayAndFailures(t *testing.T) {
+	events, err := ReadEvents(strings.NewReader(replayFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReplayer(0)
+	r.wait = func(ctx context.Context, d time.Duration) error {
+		t.Errorf("Waited %v at speed 0", d)
+		return nil
+	}
+
+	// A failed send is reported but the rest still go out
+	rec := &capturingNotifier{failTitle: "Holding"}
+	sent, err := r.Run(context.Background(), rec, events)
+	if sent != 1 || len(rec.sent) != 1 || rec.sent[0].Title != "Cooling" {
+		t.Errorf("Run sent %d (%d recorded), want only Cooling", sent, len(rec.sent))
+	}
+	if err == nil || !strings.Contains(err.Error(), "Holding") {
+		t.Errorf("Error = %v, want the Holding failure", err)
+	}
+}
+
+func TestReplayer_Cancel(t *testing.T) {
+	events, err := ReadEvents(strings.NewReader(replayFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := &capturingNotifier{}
+	if _, err := NewReplayer(1).Run(ctx, rec, events); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run after cancel = %v, want context.Canceled", err)
+	}
+	if len(rec.sent) != 1 {
+		t.Errorf("Sent %d before the first wait, want 1", len(rec.sent))
+	}
+}
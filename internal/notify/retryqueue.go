@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRetryMaxAge is how long a queued delivery is retried before it
+	// is dropped.
+	DefaultRetryMaxAge = 24 * time.Hour
+
+	// MaxRetryQueueLen caps the queue; the oldest entries are dropped first.
+	MaxRetryQueueLen = 1000
+
+	// RetryInterval is how often the background worker checks for due entries.
+	RetryInterval = 15 * time.Second
+
+	retryBaseBackoff = 30 * time.Second
+	retryMaxBackoff  = 10 * time.Minute
+)
+
+// ErrEndpointGone is returned by a retry send function when the entry's
+// endpoint is no longer configured; the entry is dropped instead of retried.
+var ErrEndpointGone = errors.New("endpoint no longer configured")
+
+// RetryItem is one queued delivery.
+type RetryItem struct {
+	URL      string    `json:"url"`
+	Event    *Event    `json:"event"`
+	Queued   time.Time `json:"queued"`
+	Attempts int       `json:"attempts"` // Background retries so far
+	Next     time.Time `json:"next"`     // Earliest time of the next retry
+}
+
+// RetrySendFunc delivers a queued event to url once.
+type RetrySendFunc func(ctx context.Context, url string, event *Event) error
+
+// RetryQueue holds webhook deliveries that failed all their attempts and
+// retries them with backoff in the background. The queue is saved to disk on
+// every change and reloaded on start, so an outage that outlasts the daemon
+// doesn't lose notifications.
+type RetryQueue struct {
+	mu     sync.Mutex
+	path   string
+	items  []*RetryItem
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewRetryQueue loads the queue persisted at path. A missing file is an
+// empty queue.
+func NewRetryQueue(path string) (*RetryQueue, error) {
+	q := &RetryQueue{
+		path:   path,
+		maxAge: DefaultRetryMaxAge,
+		now:    time.Now,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry queue: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &q.items); err != nil {
+			return nil, fmt.Errorf("invalid retry queue %s: %w", path, err)
+		}
+	}
+	return q, nil
+}
+
+// Add queues event for delivery to url. The entry is kept in memory even if
+// saving it fails; the save error is returned.
+func (q *RetryQueue) Add(url string, event *Event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.items = append(q.items, &RetryItem{
+		URL:    url,
+		Event:  event,
+		Queued: now,
+		Next:   now.Add(retryBaseBackoff),
+	})
+	if over := len(q.items) - MaxRetryQueueLen; over > 0 {
+		q.items = q.items[over:]
+	}
+	return q.save()
+}
+
+// Len returns the number of queued deliveries.
+func (q *RetryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Flush retries every due entry once with send. Delivered entries and
+// entries older than the max age are removed; the rest back off
+// exponentially. It returns the number delivered.
+func (q *RetryQueue) Flush(ctx context.Context, send RetrySendFunc) int {
+	q.mu.Lock()
+	now := q.now()
+	var due []*RetryItem
+	for _, item := range q.items {
+		if !item.Next.After(now) {
+			due = append(due, item)
+		}
+	}
+	q.mu.Unlock()
+
+	if len(due) == 0 {
+		return 0
+	}
+
+	// Send without the lock so failing deliveries can still be queued
+	done := make(map[*RetryItem]bool)
+	for _, item := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		err := send(ctx, item.URL, item.Event)
+		if err == nil || errors.Is(err, ErrEndpointGone) {
+			done[item] = true
+			continue
+		}
+		q.mu.Lock()
+		item.Attempts++
+		item.Next = q.now().Add(retryBackoff(item.Attempts))
+		q.mu.Unlock()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivered := 0
+	kept := q.items[:0]
+	for _, item := range q.items {
+		switch {
+		case done[item]:
+			delivered++
+		case now.Sub(item.Queued) > q.maxAge:
+			// Expired; give up
+		default:
+			kept = append(kept, item)
+		}
+	}
+	q.items = kept
+	q.save()
+	return delivered
+}
+
+// Run flushes the queue every interval until ctx is done.
+func (q *RetryQueue) Run(ctx context.Context, interval time.Duration, send RetrySendFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.Flush(ctx, send)
+		}
+	}
+}
+
+// retryBackoff returns the wait after the given number of failed retries:
+// 30s, 1m, 2m, ... capped at 10m.
+func retryBackoff(attempts int) time.Duration {
+	backoff := retryBaseBackoff
+	for i := 1; i < attempts && backoff < retryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, retryMaxBackoff)
+}
+
+// save writes the queue to disk atomically. Caller must hold q.mu.
+func (q *RetryQueue) save() error {
+	if q.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("failed to create retry queue directory %s: %w", filepath.Dir(q.path), err)
+	}
+
+	data, err := json.Marshal(q.items)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry queue: %w", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write retry queue: %w", err)
+	}
+	return os.Rename(tmp, q.path)
+}
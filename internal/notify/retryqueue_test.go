@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"firebell/internal/config"
+)
+
+// flakyServer returns 503 until up is set, counting successful deliveries.
+func flakyServer(t *testing.T) (*httptest.Server, *atomic.Bool, *atomic.Int32) {
+	var up atomic.Bool
+	var delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &up, &delivered
+}
+
+func newQueuedWebhook(t *testing.T, url, path string) (*WebhookNotifier, *RetryQueue) {
+	retries := 0
+	w := NewWebhookNotifier([]config.WebhookConfig{{URL: url, Timeout: 1, Retries: &retries}})
+	queue, err := NewRetryQueue(path)
+	if err != nil {
+		t.Fatalf("NewRetryQueue failed: %v", err)
+	}
+	w.SetRetryQueue(queue)
+	return w, queue
+}
+
+func TestRetryQueue_EnqueueOnFailure(t *testing.T) {
+	server, _, _ := flakyServer(t)
+	path := filepath.Join(t.TempDir(), "retry-queue.json")
+	w, queue := newQueuedWebhook(t, server.URL, path)
+
+	err := w.Send(context.Background(), &Notification{Agent: "claude", Title: "Cooling", Message: "done", Time: time.Now()})
+	if err == nil {
+		t.Fatal("Expected error from failing webhook")
+	}
+	if queue.Len() != 1 {
+		t.Fatalf("Queue length = %d, want 1", queue.Len())
+	}
+
+	// The queued entry survives a restart
+	reloaded, err := NewRetryQueue(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reloaded.Len() != 1 || reloaded.items[0].URL != server.URL || reloaded.items[0].Event.Title != "Cooling" {
+		t.Errorf("Reloaded queue = %+v, want the Cooling delivery", reloaded.items)
+	}
+}
+
+func TestRetryQueue_RetrySuccess(t *testing.T) {
+	server, up, delivered := flakyServer(t)
+	w, queue := newQueuedWebhook(t, server.URL, filepath.Join(t.TempDir(), "retry-queue.json"))
+
+	now := time.Now()
+	queue.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	w.Send(ctx, &Notification{Title: "Cooling", Time: now})
+
+	// Not due yet
+	if n := queue.Flush(ctx, w.Resend); n != 0 || delivered.Load() != 0 {
+		t.Fatalf("Flush before backoff delivered %d", n)
+	}
+
+	// Still down: backs off further
+	now = now.Add(retryBaseBackoff)
+	if n := queue.Flush(ctx, w.Resend); n != 0 {
+		t.Fatalf("Flush while down delivered %d", n)
+	}
+	if item := queue.items[0]; item.Attempts != 1 || !item.Next.Equal(now.Add(retryBackoff(1))) {
+		t.Errorf("After failed retry: attempts=%d next=%v", item.Attempts, item.Next)
+	}
+
+	up.Store(true)
+	now = now.Add(retryBackoff(1))
+	if n := queue.Flush(ctx, w.Resend); n != 1 {
+		t.Fatalf("Flush after recovery delivered %d, want 1", n)
+	}
+	if queue.Len() != 0 || delivered.Load() != 1 {
+		t.Errorf("Queue length = %d, deliveries = %d; want 0, 1", queue.Len(), delivered.Load())
+	}
+}
+
+func TestRetryQueue_PersistsAcrossRestart(t *testing.T) {
+	server, up, delivered := flakyServer(t)
+	path := filepath.Join(t.TempDir(), "retry-queue.json")
+
+	first, _ := newQueuedWebhook(t, server.URL, path)
+	first.Send(context.Background(), &Notification{Title: "Holding", Time: time.Now()})
+
+	// A new daemon loads the queue and delivers once the endpoint is back
+	up.Store(true)
+	second, queue := newQueuedWebhook(t, server.URL, path)
+	queue.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if n := queue.Flush(context.Background(), second.Resend); n != 1 {
+		t.Fatalf("Flush after restart delivered %d, want 1", n)
+	}
+	if delivered.Load() != 1 {
+		t.Errorf("deliveries = %d, want 1", delivered.Load())
+	}
+
+	reloaded, err := NewRetryQueue(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reloaded.Len() != 0 {
+		t.Errorf("Delivered entry still persisted: %+v", reloaded.items)
+	}
+}
+
+func TestRetryQueue_DropsExpiredAndRemovedEndpoints(t *testing.T) {
+	queue, err := NewRetryQueue(filepath.Join(t.TempDir(), "retry-queue.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	queue.now = func() time.Time { return now }
+	queue.Add("http://gone.invalid", NewEvent(EventCooling))
+	queue.Add("http://down.invalid", NewEvent(EventCooling))
+
+	now = now.Add(DefaultRetryMaxAge + time.Minute)
+	queue.Flush(context.Background(), func(ctx context.Context, url string, event *Event) error {
+		if url == "http://gone.invalid" {
+			return ErrEndpointGone
+		}
+		return context.DeadlineExceeded
+	})
+	if queue.Len() != 0 {
+		t.Errorf("Queue length = %d, want 0", queue.Len())
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{10, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"maps"
+
+	"firebell/internal/config"
+)
+
+// Severity is how urgent a notification is, normalized across destinations.
+// Each notifier maps it to its own scale: syslog levels, card colors.
+type Severity string
+
+// Severities, lowest first (see config.SeverityInfo).
+const (
+	SeverityInfo    Severity = config.SeverityInfo    // Routine activity and reminders
+	SeverityNotice  Severity = config.SeverityNotice  // A turn or process finished
+	SeverityWarning Severity = config.SeverityWarning // An agent needs the user
+	SeverityHigh    Severity = config.SeverityHigh    // Needs attention now
+)
+
+// defaultSeverities maps event types to their severity; event types not
+// listed are info.
+var defaultSeverities = SeverityMap{
+	EventHolding:      SeverityWarning,
+	EventAwaiting:     SeverityWarning,
+	EventLoop:         SeverityWarning,
+	EventIdle:         SeverityWarning,
+	EventNoLogs:       SeverityWarning,
+	EventCooling:      SeverityNotice,
+	EventCompaction:   SeverityNotice,
+	EventProcessStart: SeverityNotice,
+	EventProcessExit:  SeverityNotice,
+}
+
+// SeverityMap maps event types to severities. A nil map uses the defaults.
+type SeverityMap map[EventType]Severity
+
+// NewSeverityMap returns the default severities with output.severity_overrides
+// applied. Overrides are validated at config load.
+func NewSeverityMap(overrides map[string]string) SeverityMap {
+	m := maps.Clone(defaultSeverities)
+	for event, severity := range overrides {
+		m[EventType(event)] = Severity(severity)
+	}
+	return m
+}
+
+// Of returns the severity of a notification with the given priority and
+// event type. High-priority notifications (e.g. sensitive tool requests)
+// are always high.
+func (m SeverityMap) Of(priority Priority, t EventType) Severity {
+	if priority == PriorityHigh {
+		return SeverityHigh
+	}
+	if m == nil {
+		m = defaultSeverities
+	}
+	if severity, ok := m[t]; ok {
+		return severity
+	}
+	return SeverityInfo
+}
+
+// severityOf returns the severity of n: the one resolved by MultiNotifier
+// if set, otherwise the default for its event type t.
+func severityOf(n *Notification, t EventType) Severity {
+	if n.Severity != "" {
+		return n.Severity
+	}
+	return SeverityMap(nil).Of(n.Priority, t)
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"firebell/internal/config"
+)
+
+func TestSeverityMap(t *testing.T) {
+	m := NewSeverityMap(map[string]string{"cooling": "high", "working": "warning", "holding": "info"})
+	tests := []struct {
+		m        SeverityMap
+		priority Priority
+		event    EventType
+		want     Severity
+	}{
+		{nil, "", EventCooling, SeverityNotice},
+		{nil, "", EventAwaiting, SeverityWarning},
+		{nil, "", EventActivity, SeverityInfo},
+		{nil, PriorityHigh, EventActivity, SeverityHigh},
+		{m, "", EventCooling, SeverityHigh},
+		{m, "", EventWorking, SeverityWarning},
+		{m, "", EventHolding, SeverityInfo},
+		{m, PriorityHigh, EventHolding, SeverityHigh}, // Sensitive tool requests stay high
+		{m, "", EventLoop, SeverityWarning},           // Not overridden
+	}
+	for _, tt := range tests {
+		if got := tt.m.Of(tt.priority, tt.event); got != tt.want {
+			t.Errorf("Of(%q, %s) = %q, want %q", tt.priority, tt.event, got, tt.want)
+		}
+	}
+
+	// The defaults aren't modified by overrides
+	if got := SeverityMap(nil).Of("", EventCooling); got != SeverityNotice {
+		t.Errorf("Default cooling severity = %q after overrides, want notice", got)
+	}
+}
+
+func TestSeverityOverrideKeys(t *testing.T) {
+	// Every event type a notification can get is a valid override key
+	titles := []string{"Activity Detected", "Cooling", "Awaiting", "Holding", "Possible loop", "Still working", "Compacted",
+		"Process Started", "Process Exited", "Idle", "No log files found", "Resumed"}
+	for _, title := range titles {
+		event := DetermineEventType(&Notification{Title: title})
+		if !slices.Contains(config.NotificationEventTypes, string(event)) {
+			t.Errorf("config.NotificationEventTypes is missing %q (title %q)", event, title)
+		}
+	}
+	for event := range defaultSeverities {
+		if !slices.Contains(config.NotificationEventTypes, string(event)) {
+			t.Errorf("config.NotificationEventTypes is missing %q", event)
+		}
+	}
+}
+
+func TestSeverityOverridesReachNotifiers(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make(map[string]map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		json.Unmarshal(data, &body)
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	syslog := &fakeSyslog{}
+	webhooks := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: server.URL + "/generic"},
+		{URL: server.URL + "/discord", Format: config.WebhookFormatDiscord},
+	})
+	multi := NewMultiNotifier(&SyslogNotifier{w: syslog}, webhooks)
+	multi.SetSeverities(NewSeverityMap(map[string]string{"cooling": "high"}))
+
+	if err := multi.Send(context.Background(), &Notification{Title: "Cooling", Agent: "Claude Code"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if len(syslog.logged) != 1 || !strings.HasPrefix(syslog.logged[0], "err Cooling") {
+		t.Errorf("Syslog logged %v, want cooling at err", syslog.logged)
+	}
+	if got := bodies["/generic"]["severity"]; got != "high" {
+		t.Errorf("Generic webhook severity = %v, want high", got)
+	}
+	embeds, _ := bodies["/discord"]["embeds"].([]any)
+	if len(embeds) != 1 || embeds[0].(map[string]any)["color"] != float64(discordColors["attention"]) {
+		t.Errorf("Discord embeds = %v, want the attention color", embeds)
+	}
+}
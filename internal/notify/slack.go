@@ -6,13 +6,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
+// slackBatchWindow is how long after a send further notifications are
+// coalesced into one message, to stay under Slack's webhook rate limit.
+const slackBatchWindow = 1 * time.Second
+
 // SlackNotifier sends notifications via Slack Incoming Webhooks.
+// A notification is sent immediately unless another was sent within the
+// batch window; those are buffered and flushed together as one message.
 type SlackNotifier struct {
 	webhook string
 	client  *http.Client
+	window  time.Duration // Batch window (0 = never batch)
+	onError func(error)   // Receives failed batched sends (nil = printed to stderr)
+
+	mu       sync.Mutex
+	lastSend time.Time       // When the last message was sent
+	pending  []*Notification // Buffered notifications awaiting flush
+	timer    *time.Timer     // Scheduled flush of pending
 }
 
 // NewSlackNotifier creates a new Slack notifier.
@@ -22,6 +38,7 @@ func NewSlackNotifier(webhookURL string) *SlackNotifier {
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		window: slackBatchWindow,
 	}
 }
 
@@ -30,13 +47,95 @@ func (s *SlackNotifier) Name() string {
 	return "slack"
 }
 
-// Send delivers a notification to Slack.
+// Send delivers a notification to Slack, or buffers it if a message was
+// sent within the batch window. High-priority notifications are never
+// buffered. Errors from buffered sends go to the SetErrorHandler handler.
 func (s *SlackNotifier) Send(ctx context.Context, n *Notification) error {
-	// Build message body
-	body := FormatNotification(n, "normal", true)
+	s.mu.Lock()
+	now := time.Now()
+	if n.Priority != PriorityHigh && s.window > 0 && (s.timer != nil || now.Sub(s.lastSend) < s.window) {
+		s.pending = append(s.pending, n)
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.lastSend.Add(s.window).Sub(now), s.flushPending)
+		}
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSend = now
+	s.mu.Unlock()
+
+	return s.post(ctx, []*Notification{n})
+}
+
+// Close sends any buffered notifications.
+func (s *SlackNotifier) Close() error {
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.mu.Unlock()
+	return s.flush()
+}
+
+// SetErrorHandler passes failed sends of buffered notifications, which
+// happen after Send has returned, to fn, such as the daemon's error log.
+// Call before the first Send.
+func (s *SlackNotifier) SetErrorHandler(fn func(error)) {
+	s.onError = fn
+}
+
+// flushPending is the timer callback for buffered notifications.
+func (s *SlackNotifier) flushPending() {
+	err := s.flush()
+	if err == nil {
+		return
+	}
+	if s.onError != nil {
+		s.onError(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Failed to send notification: %v\n", err)
+}
 
+// flush sends all buffered notifications as one message.
+func (s *SlackNotifier) flush() error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.timer = nil
+	if len(batch) > 0 {
+		s.lastSend = time.Now()
+	}
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+	defer cancel()
+	return s.post(ctx, batch)
+}
+
+// post sends notifications as a single Slack message. One notification is
+// sent as plain text; several become one attachment block each.
+func (s *SlackNotifier) post(ctx context.Context, batch []*Notification) error {
 	// Create Slack payload
-	payload := map[string]string{"text": body}
+	var payload map[string]any
+	if len(batch) == 1 {
+		payload = map[string]any{"text": formatSlackText(batch[0])}
+	} else {
+		attachments := make([]map[string]string, 0, len(batch))
+		for _, n := range batch {
+			attachments = append(attachments, map[string]string{
+				"text": formatSlackText(n),
+			})
+		}
+		payload = map[string]any{
+			"text":        fmt.Sprintf("%d notifications", len(batch)),
+			"attachments": attachments,
+		}
+	}
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
@@ -64,6 +163,36 @@ func (s *SlackNotifier) Send(ctx context.Context, n *Notification) error {
 	return nil
 }
 
+// slackSnippetMax bounds the snippet length in a Slack message.
+const slackSnippetMax = 500
+
+// formatSlackText renders a notification as Slack mrkdwn, with the snippet
+// (if any) formatted by formatSnippetForSlack.
+func formatSlackText(n *Notification) string {
+	plain := *n
+	plain.Snippet = ""
+	text := FormatNotification(&plain, "normal", false)
+	if n.Snippet != "" {
+		text += formatSnippetForSlack(n.Snippet)
+	}
+	return text
+}
+
+// formatSnippetForSlack wraps a snippet in a fenced code block so Slack
+// renders it monospaced. A snippet that parses as JSON is pretty-printed.
+func formatSnippetForSlack(snippet string) string {
+	snippet = strings.TrimSpace(snippet)
+	if json.Valid([]byte(snippet)) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(snippet), "", "  "); err == nil {
+			snippet = buf.String()
+		}
+	}
+	// A literal fence would end the block early
+	snippet = strings.ReplaceAll(snippet, "```", "`\u200b``")
+	return "```\n" + truncate(snippet, slackSnippetMax) + "\n```"
+}
+
 // TestWebhook sends a test message to verify the webhook works.
 func (s *SlackNotifier) TestWebhook(ctx context.Context) error {
 	n := &Notification{
@@ -0,0 +1,231 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slackPayload struct {
+	Text        string `json:"text"`
+	Attachments []struct {
+		Text string `json:"text"`
+	} `json:"attachments"`
+}
+
+// newSlackTestServer returns a server that records each Slack payload.
+func newSlackTestServer(t *testing.T) (*httptest.Server, func() []slackPayload) {
+	t.Helper()
+	var mu sync.Mutex
+	var payloads []slackPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p slackPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []slackPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]slackPayload(nil), payloads...)
+	}
+}
+
+func TestSlackNotifier_SingleIsImmediate(t *testing.T) {
+	server, payloads := newSlackTestServer(t)
+	notifier := NewSlackNotifier(server.URL)
+
+	err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Agent: "Claude Code", Message: "done", Time: time.Now()})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	got := payloads()
+	if len(got) != 1 {
+		t.Fatalf("Received %d requests, want 1", len(got))
+	}
+	if len(got[0].Attachments) != 0 {
+		t.Errorf("Single notification has %d attachments, want 0", len(got[0].Attachments))
+	}
+	if !containsSubstr(got[0].Text, "Claude Code") {
+		t.Errorf("Text = %q, want agent name", got[0].Text)
+	}
+}
+
+func TestSlackNotifier_BurstIsBatched(t *testing.T) {
+	server, payloads := newSlackTestServer(t)
+	notifier := NewSlackNotifier(server.URL)
+	notifier.window = 100 * time.Millisecond
+
+	agents := []string{"Claude Code", "Codex", "Gemini", "Copilot"}
+	for _, agent := range agents {
+		err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Agent: agent, Message: "done", Time: time.Now()})
+		if err != nil {
+			t.Fatalf("Send(%s) failed: %v", agent, err)
+		}
+	}
+
+	// The first notification goes out immediately; the rest are buffered.
+	if got := payloads(); len(got) != 1 {
+		t.Fatalf("Received %d requests before flush, want 1", len(got))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(payloads()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	got := payloads()
+	if len(got) != 2 {
+		t.Fatalf("Received %d requests, want 2", len(got))
+	}
+	batch := got[1]
+	if len(batch.Attachments) != len(agents)-1 {
+		t.Fatalf("Batch has %d attachments, want %d", len(batch.Attachments), len(agents)-1)
+	}
+	for i, a := range batch.Attachments {
+		if !containsSubstr(a.Text, agents[i+1]) {
+			t.Errorf("Attachment %d = %q, want agent %q", i, a.Text, agents[i+1])
+		}
+	}
+}
+
+func TestSlackNotifier_BatchErrorHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+	notifier := NewSlackNotifier(server.URL)
+	notifier.window = 50 * time.Millisecond
+	errs := make(chan error, 1)
+	notifier.SetErrorHandler(func(err error) { errs <- err })
+
+	ctx := context.Background()
+	if err := notifier.Send(ctx, &Notification{Title: "Cooling", Agent: "Codex", Time: time.Now()}); err == nil {
+		t.Error("Send() = nil, want the immediate failure")
+	}
+	if err := notifier.Send(ctx, &Notification{Title: "Cooling", Agent: "Gemini", Time: time.Now()}); err != nil {
+		t.Errorf("Send() = %v, want nil for a buffered notification", err)
+	}
+
+	select {
+	case err := <-errs:
+		if !containsSubstr(err.Error(), "403") {
+			t.Errorf("Handler got %v, want the batch's status 403", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Failed batch was not reported to the error handler")
+	}
+}
+
+func TestSlackNotifier_CloseFlushes(t *testing.T) {
+	server, payloads := newSlackTestServer(t)
+	notifier := NewSlackNotifier(server.URL)
+	notifier.window = time.Hour
+
+	for _, agent := range []string{"Claude Code", "Codex", "Gemini"} {
+		notifier.Send(context.Background(), &Notification{Title: "Cooling", Agent: agent, Message: "done", Time: time.Now()})
+	}
+	if err := notifier.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	got := payloads()
+	if len(got) != 2 {
+		t.Fatalf("Received %d requests, want 2", len(got))
+	}
+	if len(got[1].Attachments) != 2 {
+		t.Errorf("Flushed batch has %d attachments, want 2", len(got[1].Attachments))
+	}
+}
+
+func TestSlackNotifier_HighPriorityNotBatched(t *testing.T) {
+	server, payloads := newSlackTestServer(t)
+	notifier := NewSlackNotifier(server.URL)
+	notifier.window = time.Hour
+	defer notifier.Close()
+
+	ctx := context.Background()
+	notifier.Send(ctx, &Notification{Title: "Cooling", Agent: "Codex", Time: time.Now()})
+	notifier.Send(ctx, &Notification{Title: "Holding", Agent: "Claude Code", Message: "rm", Time: time.Now(), Priority: PriorityHigh})
+
+	got := payloads()
+	if len(got) != 2 {
+		t.Fatalf("Received %d requests, want 2", len(got))
+	}
+	if !containsSubstr(got[1].Text, "Holding") {
+		t.Errorf("Text = %q, want the high-priority Holding", got[1].Text)
+	}
+}
+
+func TestFormatSnippetForSlack(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{
+			name:    "JSON object is pretty-printed",
+			snippet: `{"type":"assistant","message":{"stop_reason":"end_turn"}}`,
+			want:    "```\n{\n  \"type\": \"assistant\",\n  \"message\": {\n    \"stop_reason\": \"end_turn\"\n  }\n}\n```",
+		},
+		{
+			name:    "JSON array with surrounding whitespace",
+			snippet: "\n[1, 2]\n",
+			want:    "```\n[\n  1,\n  2\n]\n```",
+		},
+		{
+			name:    "plain text passes through",
+			snippet: "Running tests...\nok  firebell/internal/notify",
+			want:    "```\nRunning tests...\nok  firebell/internal/notify\n```",
+		},
+		{
+			name:    "JSONL is not a single JSON value",
+			snippet: "{\"a\":1}\n{\"b\":2}",
+			want:    "```\n{\"a\":1}\n{\"b\":2}\n```",
+		},
+		{
+			name:    "embedded fence is neutralized",
+			snippet: "see ```code```",
+			want:    "```\nsee `\u200b``code`\u200b``\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatSnippetForSlack(tt.snippet); got != tt.want {
+				t.Errorf("formatSnippetForSlack() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlackNotifier_SnippetFormatted(t *testing.T) {
+	server, payloads := newSlackTestServer(t)
+	notifier := NewSlackNotifier(server.URL)
+
+	notifier.Send(context.Background(), &Notification{
+		Title:   "Cooling",
+		Agent:   "Claude Code",
+		Message: "done",
+		Snippet: `{"ok":true}`,
+		Time:    time.Now(),
+	})
+
+	got := payloads()
+	if len(got) != 1 {
+		t.Fatalf("Received %d requests, want 1", len(got))
+	}
+	if !containsSubstr(got[0].Text, "```\n{\n  \"ok\": true\n}\n```") {
+		t.Errorf("Expected pretty-printed snippet in %q", got[0].Text)
+	}
+}
@@ -3,16 +3,37 @@ package notify
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
+
+	"firebell/internal/config"
 )
 
 // StdoutNotifier prints notifications to stdout.
-type StdoutNotifier struct{}
+type StdoutNotifier struct {
+	layout   string         // Timestamp layout
+	location *time.Location // Timestamp timezone
+	theme    string         // "plain" (default), "emoji", "compact", or "json"
+	out      io.Writer      // Destination (nil = os.Stdout)
+}
 
-// NewStdoutNotifier creates a new stdout notifier.
+// NewStdoutNotifier creates a new stdout notifier using local 15:04:05 timestamps.
 func NewStdoutNotifier() *StdoutNotifier {
-	return &StdoutNotifier{}
+	return &StdoutNotifier{layout: config.DefaultTimeFormat, location: time.Local}
+}
+
+// SetTimeFormat sets the layout and timezone used for timestamps.
+func (s *StdoutNotifier) SetTimeFormat(layout string, location *time.Location) {
+	s.layout = layout
+	s.location = location
+}
+
+// SetTheme selects the output format (see config.OutputConfig.Theme).
+// Unknown themes print as "plain".
+func (s *StdoutNotifier) SetTheme(theme string) {
+	s.theme = theme
 }
 
 // Name returns the notifier type.
@@ -20,33 +41,85 @@ func (s *StdoutNotifier) Name() string {
 	return "stdout"
 }
 
-// Send prints a notification to stdout.
+// themeEmoji maps notification titles to the "emoji" theme's prefixes.
+var themeEmoji = map[string]string{
+	"Cooling":            "✅",
+	"Awaiting":           "⏳",
+	"Holding":            "✋",
+	"Possible loop":      "🔁",
+	"Still working":      "🔄",
+	"Compacted":          "🗜️",
+	"Process Started":    "🟢",
+	"Process Exited":     "🛑",
+	"Process Exit":       "🛑",
+	"Idle":               "💤",
+	"No log files found": "📭",
+	"Resumed":            "📬",
+}
+
+// Send prints a notification to stdout in the configured theme.
 func (s *StdoutNotifier) Send(ctx context.Context, n *Notification) error {
-	timestamp := n.Time.Format("15:04:05")
+	out := s.out
+	if out == nil {
+		out = os.Stdout
+	}
+	timestamp := n.Time.In(s.location).Format(s.layout)
+
+	switch s.theme {
+	case "json":
+		data, err := NewEventFromNotification(n, DetermineEventType(n)).JSON()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+
+	case "compact":
+		// One line per notification, no snippet
+		line := timestamp + " "
+		if n.Agent != "" {
+			line += n.Agent + " | "
+		}
+		line += n.Title
+		if n.Message != "" {
+			line += ": " + n.Message
+		}
+		fmt.Fprintln(out, line)
+		return nil
+	}
+
+	title := n.Title
+	if s.theme == "emoji" {
+		icon, ok := themeEmoji[n.Title]
+		if !ok {
+			icon = "🔔"
+		}
+		title = icon + " " + title
+	}
 
 	// Header line
 	if n.Agent != "" {
-		fmt.Fprintf(os.Stdout, "[%s] %s | %s\n", timestamp, n.Agent, n.Title)
+		fmt.Fprintf(out, "[%s] %s | %s\n", timestamp, n.Agent, title)
 	} else {
-		fmt.Fprintf(os.Stdout, "[%s] %s\n", timestamp, n.Title)
+		fmt.Fprintf(out, "[%s] %s\n", timestamp, title)
 	}
 
 	// Message
 	if n.Message != "" {
-		fmt.Fprintf(os.Stdout, "  %s\n", n.Message)
+		fmt.Fprintf(out, "  %s\n", n.Message)
 	}
 
 	// Snippet
 	if n.Snippet != "" {
-		fmt.Fprintln(os.Stdout, "  ---")
+		fmt.Fprintln(out, "  ---")
 		lines := splitLines(n.Snippet)
 		for _, line := range lines {
-			fmt.Fprintf(os.Stdout, "  %s\n", line)
+			fmt.Fprintf(out, "  %s\n", line)
 		}
-		fmt.Fprintln(os.Stdout, "  ---")
+		fmt.Fprintln(out, "  ---")
 	}
 
-	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(out)
 	return nil
 }
 
@@ -89,12 +162,102 @@ func NewQuietNotification(displayName string, cpuPct float64) *Notification {
 	}
 }
 
+// NewProcessStartNotification creates a notification that a tracked process
+// was detected. A positive previousPID means it replaced that process (a
+// restart); it is added to the message and metadata.
+func NewProcessStartNotification(pid, previousPID int) *Notification {
+	msg := fmt.Sprintf("Monitored process (PID %d) has started", pid)
+	meta := map[string]any{"pid": pid}
+	if previousPID > 0 {
+		msg = fmt.Sprintf("Monitored process restarted as PID %d (was %d)", pid, previousPID)
+		meta["previous_pid"] = previousPID
+	}
+
+	return &Notification{
+		Title:    "Process Started",
+		Agent:    "firebell",
+		Message:  msg,
+		Time:     time.Now(),
+		Metadata: meta,
+	}
+}
+
+// NewIdleNotification creates an "Idle" notification: the tracked process
+// has been idle (at cpuPct CPU) for idle while the logs were silent
+// mid-turn, which often means it is stuck on a hung call.
+func NewIdleNotification(pid int, cpuPct float64, idle time.Duration) *Notification {
+	return &Notification{
+		Title: "Idle",
+		Agent: "firebell",
+		Message: fmt.Sprintf("Monitored process (PID %d) idle for %s (CPU: %.1f%%) with no log activity mid-turn; it may be stuck",
+			pid, idle.Round(time.Second), cpuPct),
+		Time:     time.Now(),
+		Metadata: map[string]any{"pid": pid, "cpu_percent": cpuPct, "idle_seconds": idle.Seconds()},
+	}
+}
+
+// NewNoLogFilesNotification creates a "No log files found" notification:
+// every watched log file has been gone for missing, so nothing is being
+// monitored (e.g. the agent was uninstalled or its logs were cleared).
+func NewNoLogFilesNotification(missing time.Duration) *Notification {
+	return &Notification{
+		Title:    "No log files found",
+		Agent:    "firebell",
+		Message:  fmt.Sprintf("No agent log files found for %s; nothing is being watched", missing.Round(time.Second)),
+		Time:     time.Now(),
+		Metadata: map[string]any{"missing_seconds": missing.Seconds()},
+	}
+}
+
+// NewLogFilesResumedNotification creates a "Resumed" notification: log
+// files were found again after "No log files found".
+func NewLogFilesResumedNotification(files int) *Notification {
+	return &Notification{
+		Title:    "Resumed",
+		Agent:    "firebell",
+		Message:  fmt.Sprintf("Log files found again; watching %d", files),
+		Time:     time.Now(),
+		Metadata: map[string]any{"files": files},
+	}
+}
+
+// ProcessExitInfo summarizes a tracked process when it exits.
+// Zero values mean the statistic is unknown.
+type ProcessExitInfo struct {
+	PID        int
+	RSSBytes   int64         // Last-known resident memory
+	CPUSeconds float64       // Total CPU time (user + system)
+	Runtime    time.Duration // Wall time since the process started
+}
+
 // NewProcessExitNotification creates a process exit notification.
-func NewProcessExitNotification(pid int) *Notification {
+// Known statistics are added to the message and to the event metadata.
+func NewProcessExitNotification(info ProcessExitInfo) *Notification {
+	msg := fmt.Sprintf("Monitored process (PID %d) has terminated", info.PID)
+	meta := map[string]any{"pid": info.PID}
+
+	var stats []string
+	if info.Runtime > 0 {
+		stats = append(stats, "ran "+info.Runtime.Round(time.Second).String())
+		meta["runtime_seconds"] = info.Runtime.Seconds()
+	}
+	if info.RSSBytes > 0 {
+		stats = append(stats, fmt.Sprintf("RSS %.1fMiB", float64(info.RSSBytes)/(1024*1024)))
+		meta["rss_bytes"] = info.RSSBytes
+	}
+	if info.CPUSeconds > 0 {
+		stats = append(stats, fmt.Sprintf("CPU %.1fs", info.CPUSeconds))
+		meta["cpu_seconds"] = info.CPUSeconds
+	}
+	if len(stats) > 0 {
+		msg += " (" + strings.Join(stats, ", ") + ")"
+	}
+
 	return &Notification{
-		Title:   "Process Exited",
-		Agent:   "firebell",
-		Message: fmt.Sprintf("Monitored process (PID %d) has terminated", pid),
-		Time:    time.Now(),
+		Title:    "Process Exited",
+		Agent:    "firebell",
+		Message:  msg,
+		Time:     time.Now(),
+		Metadata: meta,
 	}
 }
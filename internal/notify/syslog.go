@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+// syslogWriter is the part of *syslog.Writer the notifier uses, so tests can
+// record what would be logged.
+type syslogWriter interface {
+	Err(msg string) error
+	Warning(msg string) error
+	Notice(msg string) error
+	Info(msg string) error
+	Close() error
+}
+
+// formatSyslogMessage renders a notification as one syslog line, e.g.
+// "Cooling [Claude Code]: No activity for 20 seconds (event=cooling)".
+// Syslog lines are single-line, so snippets are left out.
+func formatSyslogMessage(n *Notification, t EventType) string {
+	msg := n.Title
+	if n.Agent != "" {
+		msg += " [" + n.Agent + "]"
+	}
+	if n.Message != "" {
+		msg += ": " + n.Message
+	}
+	return fmt.Sprintf("%s (event=%s)", msg, t)
+}
+
+// SyslogNotifier writes notifications to the local syslog daemon, at the
+// level of their severity: high is err, then warning, notice, and info.
+type SyslogNotifier struct {
+	w syslogWriter
+}
+
+// Name returns the notifier type.
+func (s *SyslogNotifier) Name() string {
+	return "syslog"
+}
+
+// Send writes the notification to syslog.
+func (s *SyslogNotifier) Send(ctx context.Context, n *Notification) error {
+	t := DetermineEventType(n)
+	msg := formatSyslogMessage(n, t)
+	switch severityOf(n, t) {
+	case SeverityHigh:
+		return s.w.Err(msg)
+	case SeverityWarning:
+		return s.w.Warning(msg)
+	case SeverityNotice:
+		return s.w.Notice(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+// Close closes the connection to the syslog daemon.
+func (s *SyslogNotifier) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package notify
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// NewSyslogNotifier reports that syslog isn't available on this platform.
+func NewSyslogNotifier(tag string) (*SyslogNotifier, error) {
+	return nil, fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeSyslog records each message with the severity it was written at.
+type fakeSyslog struct {
+	logged []string
+	closed bool
+}
+
+func (f *fakeSyslog) log(severity, msg string) error {
+	f.logged = append(f.logged, severity+" "+msg)
+	return nil
+}
+
+func (f *fakeSyslog) Err(msg string) error     { return f.log("err", msg) }
+func (f *fakeSyslog) Warning(msg string) error { return f.log("warning", msg) }
+func (f *fakeSyslog) Notice(msg string) error  { return f.log("notice", msg) }
+func (f *fakeSyslog) Info(msg string) error    { return f.log("info", msg) }
+func (f *fakeSyslog) Close() error             { f.closed = true; return nil }
+
+func TestSyslogNotifier(t *testing.T) {
+	tests := []struct {
+		n    *Notification
+		want string
+	}{
+		{
+			&Notification{Title: "Cooling", Agent: "Claude Code", Message: "No activity for 20 seconds"},
+			"notice Cooling [Claude Code]: No activity for 20 seconds (event=cooling)",
+		},
+		{
+			&Notification{Title: "Holding", Agent: "Codex", Message: "Waiting for tool approval", Snippet: "rm -rf build"},
+			"warning Holding [Codex]: Waiting for tool approval (event=holding)",
+		},
+		{
+			&Notification{Title: "Holding", Agent: "Codex", Message: "Waiting for approval of sensitive tool Bash", Priority: PriorityHigh},
+			"err Holding [Codex]: Waiting for approval of sensitive tool Bash (event=holding)",
+		},
+		{
+			&Notification{Title: "Possible loop", Agent: "Codex", Message: "Bash requested 5 times"},
+			"warning Possible loop [Codex]: Bash requested 5 times (event=loop)",
+		},
+		{
+			&Notification{Title: "Process Exited", Agent: "firebell", Message: "Monitored process (PID 42) has terminated"},
+			"notice Process Exited [firebell]: Monitored process (PID 42) has terminated (event=process_exit)",
+		},
+		{
+			&Notification{Title: "Still working", Agent: "Claude Code"},
+			"info Still working [Claude Code] (event=working)",
+		},
+		{
+			&Notification{Title: "Tool call", Agent: "Claude Code", Message: "tool use"},
+			"info Tool call [Claude Code]: tool use (event=activity)",
+		},
+	}
+
+	w := &fakeSyslog{}
+	s := &SyslogNotifier{w: w}
+	if s.Name() != "syslog" {
+		t.Errorf("Name = %q, want syslog", s.Name())
+	}
+	for _, tt := range tests {
+		if err := s.Send(context.Background(), tt.n); err != nil {
+			t.Fatalf("Send(%q) failed: %v", tt.n.Title, err)
+		}
+	}
+	for i, tt := range tests {
+		if i >= len(w.logged) || w.logged[i] != tt.want {
+			t.Errorf("Logged[%d] = %q, want %q", i, w.logged[min(i, len(w.logged)-1)], tt.want)
+		}
+	}
+
+	s.Close()
+	if !w.closed {
+		t.Error("Close did not close the syslog writer")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !plan9
+
+package notify
+
+import "log/syslog"
+
+// NewSyslogNotifier connects to the local syslog daemon, tagging messages
+// with tag.
+func NewSyslogNotifier(tag string) (*SyslogNotifier, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogNotifier{w: w}, nil
+}
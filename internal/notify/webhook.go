@@ -7,6 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"firebell/internal/config"
@@ -16,34 +20,70 @@ import (
 type WebhookNotifier struct {
 	webhooks []webhookEndpoint
 	client   *http.Client
+	workers  int         // Max endpoints sent to at once
+	queue    *RetryQueue // Deliveries that failed every attempt (nil = dropped)
+	logger   Logger      // Where retries are logged (nil = not logged)
+}
+
+// Logger receives warnings the webhook notifier logs, such as the daemon's
+// logger.
+type Logger interface {
+	Warn(msg string, args ...interface{})
 }
 
 type webhookEndpoint struct {
 	url     string
+	method  string
 	events  map[string]bool // nil means all events
 	headers map[string]string
 	timeout time.Duration
+	retries int                // Retries after the first attempt
+	body    *template.Template // nil means render the format preset
+	format  string             // Body preset (config.WebhookFormat*; "" = generic)
+	history int                // Recent events to include (include_history)
+
+	route    string // "webhook[N]", N being its index in notify.webhooks
+	critical bool   // Failures are critical (see DeliveryFailure)
 }
 
 // NewWebhookNotifier creates a notifier that sends to multiple webhook endpoints.
 func NewWebhookNotifier(configs []config.WebhookConfig) *WebhookNotifier {
 	endpoints := make([]webhookEndpoint, 0, len(configs))
 
-	for _, cfg := range configs {
+	for i, cfg := range configs {
 		if cfg.URL == "" {
 			continue
 		}
 
 		endpoint := webhookEndpoint{
-			url:     cfg.URL,
-			headers: cfg.Headers,
-			timeout: 10 * time.Second,
+			route:    fmt.Sprintf("webhook[%d]", i),
+			critical: cfg.Critical,
+			url:      cfg.URL,
+			method:   http.MethodPost,
+			headers:  cfg.Headers,
+			timeout:  10 * time.Second,
+			retries:  cfg.RetryCount(),
+			format:   cfg.Format,
+			history:  cfg.IncludeHistory,
+		}
+
+		if cfg.Method != "" {
+			endpoint.method = strings.ToUpper(cfg.Method)
 		}
 
 		if cfg.Timeout > 0 {
 			endpoint.timeout = time.Duration(cfg.Timeout) * time.Second
 		}
 
+		// Templates are validated at config load; skip the endpoint if one slips through
+		if cfg.BodyTemplate != "" {
+			tmpl, err := template.New("body").Parse(cfg.BodyTemplate)
+			if err != nil {
+				continue
+			}
+			endpoint.body = tmpl
+		}
+
 		// Convert events list to map for fast lookup
 		if len(cfg.Events) > 0 {
 			endpoint.events = make(map[string]bool)
@@ -58,11 +98,78 @@ func NewWebhookNotifier(configs []config.WebhookConfig) *WebhookNotifier {
 	return &WebhookNotifier{
 		webhooks: endpoints,
 		client: &http.Client{
-			Timeout: 30 * time.Second, // Overall client timeout
+			Timeout: requestTimeout(endpoints),
 		},
+		workers: config.DefaultWebhookConcurrency,
 	}
 }
 
+// SetConcurrency sets how many endpoints are sent to at once.
+// Values below 1 restore the default.
+func (w *WebhookNotifier) SetConcurrency(n int) {
+	if n < 1 {
+		n = config.DefaultWebhookConcurrency
+	}
+	w.workers = n
+}
+
+// SetRetryQueue queues deliveries that fail every attempt so RunRetries can
+// send them later.
+func (w *WebhookNotifier) SetRetryQueue(q *RetryQueue) {
+	w.queue = q
+}
+
+// SetLogger logs each retried delivery attempt to l at WARN, so delayed
+// notifications can be traced to a struggling endpoint.
+func (w *WebhookNotifier) SetLogger(l Logger) {
+	w.logger = l
+}
+
+// RetryQueue returns the retry queue, or nil if none is set.
+func (w *WebhookNotifier) RetryQueue() *RetryQueue {
+	return w.queue
+}
+
+// RunRetries resends queued deliveries in the background until ctx is done.
+// It returns immediately if no retry queue is set.
+func (w *WebhookNotifier) RunRetries(ctx context.Context) {
+	if w.queue == nil {
+		return
+	}
+	w.queue.Run(ctx, RetryInterval, w.Resend)
+}
+
+// Resend makes one delivery attempt of event to the endpoint with url.
+// Returns ErrEndpointGone if no endpoint has that URL any more.
+func (w *WebhookNotifier) Resend(ctx context.Context, url string, event *Event) error {
+	for _, endpoint := range w.webhooks {
+		if endpoint.url != url {
+			continue
+		}
+		data, err := endpoint.render(event)
+		if err != nil {
+			return err
+		}
+		return w.doRequest(ctx, endpoint, data)
+	}
+	return ErrEndpointGone
+}
+
+// requestTimeout derives the client timeout, which caps each request, from
+// the slowest endpoint's timeout. It is only a backstop: doRequest applies
+// each endpoint's own timeout, and a delivery takes at most its attempts
+// times that, plus the backoff between them.
+func requestTimeout(endpoints []webhookEndpoint) time.Duration {
+	var slowest time.Duration
+	for _, e := range endpoints {
+		slowest = max(slowest, e.timeout)
+	}
+	if slowest == 0 {
+		slowest = 30 * time.Second
+	}
+	return slowest
+}
+
 // Name returns the notifier type.
 func (w *WebhookNotifier) Name() string {
 	return "webhook"
@@ -75,42 +182,73 @@ func (w *WebhookNotifier) Send(ctx context.Context, n *Notification) error {
 	}
 
 	eventType := DetermineEventType(n)
-	event := NewEventFromNotification(n, eventType)
+	return w.sendAll(ctx, NewEventFromNotification(n, eventType), n.History)
+}
 
-	// Send to each webhook (best effort for each)
-	var lastErr error
+// sendAll delivers an event to every endpoint whose filter accepts it, up to
+// w.workers at a time, with the last entries of history for endpoints that
+// include it. A failing endpoint doesn't stop the others; their failures are
+// returned as a *DeliveryError, in config order.
+func (w *WebhookNotifier) sendAll(ctx context.Context, event *Event, history []HistoryEntry) error {
+	var targets []webhookEndpoint
 	for _, endpoint := range w.webhooks {
 		// Check event filter
-		if endpoint.events != nil && !endpoint.events[string(eventType)] && !endpoint.events["all"] {
+		if endpoint.events != nil && !endpoint.events[string(event.Event)] && !endpoint.events["all"] {
 			continue
 		}
+		targets = append(targets, endpoint)
+	}
 
-		if err := w.sendToEndpoint(ctx, endpoint, event); err != nil {
-			lastErr = err
-			// Continue to other endpoints even if one fails
+	errs := make([]error, len(targets))
+	sem := make(chan struct{}, max(w.workers, 1))
+	var wg sync.WaitGroup
+	for i, endpoint := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = w.sendToEndpoint(ctx, endpoint, endpoint.withHistory(event, history))
+		}()
+	}
+	wg.Wait()
+
+	var failures []DeliveryFailure
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, DeliveryFailure{Route: targets[i].route, Critical: targets[i].critical, Err: err})
 		}
 	}
+	return deliveryError(failures)
+}
 
-	return lastErr
+// withHistory returns event with the last include_history entries of
+// history, or event itself if the endpoint includes none.
+func (e webhookEndpoint) withHistory(event *Event, history []HistoryEntry) *Event {
+	if e.history <= 0 || len(history) == 0 {
+		return event
+	}
+	with := *event
+	with.History = lastHistory(history, e.history)
+	return &with
 }
 
 // sendToEndpoint sends an event to a single webhook endpoint with retry.
 func (w *WebhookNotifier) sendToEndpoint(ctx context.Context, endpoint webhookEndpoint, event *Event) error {
-	data, err := json.Marshal(event)
+	data, err := endpoint.render(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return err
 	}
 
-	// Retry up to 3 times with exponential backoff
+	// Retry with exponential backoff
+	attempts := endpoint.retries + 1
 	var lastErr error
-	for attempt := 0; attempt < 3; attempt++ {
+	for attempt := 0; attempt < attempts; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s
-			backoff := time.Duration(1<<attempt) * time.Second
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(backoff):
+			case <-time.After(attemptBackoff(attempt)):
 			}
 		}
 
@@ -124,9 +262,51 @@ func (w *WebhookNotifier) sendToEndpoint(ctx context.Context, endpoint webhookEn
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if w.logger != nil && attempt+1 < attempts {
+			w.logger.Warn("Webhook %s attempt %d/%d failed, retrying in %s: %v",
+				endpoint.label(), attempt+1, attempts, attemptBackoff(attempt+1), err)
+		}
 	}
 
-	return fmt.Errorf("webhook failed after 3 attempts: %w", lastErr)
+	err = fmt.Errorf("webhook failed after %d attempt(s): %w", attempts, lastErr)
+	if w.queue != nil {
+		if qerr := w.queue.Add(endpoint.url, event); qerr != nil {
+			return fmt.Errorf("%w (queued for retry, not saved: %v)", err, qerr)
+		}
+		return fmt.Errorf("%w (queued for retry)", err)
+	}
+	return err
+}
+
+// attemptBackoff returns the wait before delivery attempt n, the first
+// being 0: exponential backoff of 2s, 4s, 8s, ...
+func attemptBackoff(n int) time.Duration {
+	return time.Duration(1<<n) * time.Second
+}
+
+// label names the endpoint in logs: its route and host, leaving out the
+// path and query, which often hold a token.
+func (e webhookEndpoint) label() string {
+	u, err := url.Parse(e.url)
+	if err != nil || u.Host == "" {
+		return e.route
+	}
+	return e.route + " (" + u.Host + ")"
+}
+
+// render builds the request body for an event.
+// Uses the endpoint's body template if set, otherwise its format preset
+// (the Event JSON by default).
+func (e webhookEndpoint) render(event *Event) ([]byte, error) {
+	if e.body == nil {
+		return renderFormat(e.format, event)
+	}
+
+	var buf bytes.Buffer
+	if err := e.body.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("failed to render body template: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 // doRequest performs a single HTTP request to the webhook.
@@ -135,7 +315,7 @@ func (w *WebhookNotifier) doRequest(ctx context.Context, endpoint webhookEndpoin
 	reqCtx, cancel := context.WithTimeout(ctx, endpoint.timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(reqCtx, "POST", endpoint.url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(reqCtx, endpoint.method, endpoint.url, bytes.NewReader(data))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -166,20 +346,7 @@ func (w *WebhookNotifier) SendEvent(ctx context.Context, event *Event) error {
 	if len(w.webhooks) == 0 {
 		return nil
 	}
-
-	var lastErr error
-	for _, endpoint := range w.webhooks {
-		// Check event filter
-		if endpoint.events != nil && !endpoint.events[string(event.Event)] && !endpoint.events["all"] {
-			continue
-		}
-
-		if err := w.sendToEndpoint(ctx, endpoint, event); err != nil {
-			lastErr = err
-		}
-	}
-
-	return lastErr
+	return w.sendAll(ctx, event, nil)
 }
 
 // TestWebhook sends a test event to a specific URL and returns the result.
@@ -188,13 +355,8 @@ func TestWebhook(ctx context.Context, url string, headers map[string]string, tim
 		timeout = 10 * time.Second
 	}
 
-	event := &Event{
-		Event:     "test",
-		Timestamp: time.Now(),
-		Agent:     "firebell",
-		Title:     "Test Notification",
-		Message:   "Webhook configuration is working!",
-	}
+	event := NewEvent("test").WithAgent("firebell").WithMessage("Webhook configuration is working!")
+	event.Title = "Test Notification"
 
 	data, err := json.Marshal(event)
 	if err != nil {
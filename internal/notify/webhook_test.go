@@ -3,8 +3,13 @@ package notify
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -62,6 +67,9 @@ func TestWebhookNotifier_Send(t *testing.T) {
 	if lastEvent.Agent != "Claude Code" {
 		t.Errorf("Agent = %q, want %q", lastEvent.Agent, "Claude Code")
 	}
+	if lastEvent.Host != eventHost() || lastEvent.Version != config.Version {
+		t.Errorf("Host/Version = %q/%q, want %q/%q", lastEvent.Host, lastEvent.Version, eventHost(), config.Version)
+	}
 }
 
 func TestWebhookNotifier_CustomHeaders(t *testing.T) {
@@ -179,6 +187,57 @@ func TestWebhookNotifier_MultipleEndpoints(t *testing.T) {
 	}
 }
 
+func TestWebhookNotifier_IncludeHistory(t *testing.T) {
+	received := func(events chan<- Event) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event Event
+			if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+				t.Errorf("Failed to decode payload: %v", err)
+			}
+			events <- event
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+	withHistory, without := make(chan Event, 1), make(chan Event, 1)
+	server1, server2 := received(withHistory), received(without)
+	defer server1.Close()
+	defer server2.Close()
+
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: server1.URL, IncludeHistory: 2},
+		{URL: server2.URL},
+	})
+
+	start := time.Date(2025, 1, 9, 14, 3, 0, 0, time.UTC)
+	notification := &Notification{
+		Title: "Cooling",
+		Agent: "Claude Code",
+		Time:  start.Add(time.Minute),
+		History: []HistoryEntry{
+			{Timestamp: start, Type: "activity", Reason: "user message"},
+			{Timestamp: start.Add(10 * time.Second), Type: "holding", Reason: "tool_use"},
+			{Timestamp: start.Add(20 * time.Second), Type: "complete", Reason: "stop_reason: end_turn"},
+		},
+	}
+	if err := notifier.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	got := <-withHistory
+	want := notification.History[1:]
+	if len(got.History) != len(want) {
+		t.Fatalf("History = %+v, want the last %d events", got.History, len(want))
+	}
+	for i, e := range got.History {
+		if !e.Timestamp.Equal(want[i].Timestamp) || e.Type != want[i].Type || e.Reason != want[i].Reason {
+			t.Errorf("History[%d] = %+v, want %+v", i, e, want[i])
+		}
+	}
+	if got := <-without; got.History != nil {
+		t.Errorf("History = %+v, want none without include_history", got.History)
+	}
+}
+
 func TestWebhookNotifier_Retry(t *testing.T) {
 	var attempts atomic.Int32
 
@@ -213,6 +272,50 @@ func TestWebhookNotifier_Retry(t *testing.T) {
 	}
 }
 
+// recordingLogger captures the warnings a WebhookNotifier logs.
+type recordingLogger struct {
+	mu    sync.Mutex
+	warns []string
+}
+
+func (l *recordingLogger) Warn(msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(msg, args...))
+}
+
+func TestWebhookNotifier_LogsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+
+	retries := 1
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: ok.URL, Timeout: 1, Retries: &retries},
+		{URL: server.URL + "/hooks/secret-token", Timeout: 1, Retries: &retries},
+	})
+	logger := &recordingLogger{}
+	notifier.SetLogger(logger)
+
+	if err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err == nil {
+		t.Fatal("Expected error from failing endpoint")
+	}
+
+	// One line per retry, none for the final failure (returned instead) or
+	// the endpoint that succeeded
+	host := strings.TrimPrefix(server.URL, "http://")
+	want := []string{"Webhook webhook[1] (" + host + ") attempt 1/2 failed, retrying in 2s: webhook returned status 503"}
+	if !reflect.DeepEqual(logger.warns, want) {
+		t.Errorf("logged %q, want %q", logger.warns, want)
+	}
+	if strings.Contains(strings.Join(logger.warns, "\n"), "secret-token") {
+		t.Error("Retry log includes the webhook URL's path")
+	}
+}
+
 func TestWebhookNotifier_AllEventsFilter(t *testing.T) {
 	var received atomic.Int32
 
@@ -315,3 +418,307 @@ func TestTestWebhook_WithHeaders(t *testing.T) {
 		t.Errorf("Custom header = %q, want 'custom-value'", authHeader)
 	}
 }
+
+func TestWebhookNotifier_MethodAndBodyTemplate(t *testing.T) {
+	var method string
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{
+			URL:          server.URL,
+			Method:       "put",
+			BodyTemplate: `{"status":"{{.Event}}","who":"{{.Agent}}","text":"{{.Message}}"}`,
+		},
+	})
+
+	notification := &Notification{
+		Title:   "Cooling",
+		Agent:   "Claude Code",
+		Message: "Turn finished",
+		Time:    time.Now(),
+	}
+
+	if err := notifier.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("Method = %q, want PUT", method)
+	}
+
+	want := `{"status":"cooling","who":"Claude Code","text":"Turn finished"}`
+	if string(body) != want {
+		t.Errorf("Body = %q, want %q", body, want)
+	}
+}
+
+func TestWebhookNotifier_DefaultMethod(t *testing.T) {
+	var method string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier([]config.WebhookConfig{{URL: server.URL}})
+	notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+
+	if method != http.MethodPost {
+		t.Errorf("Method = %q, want POST", method)
+	}
+}
+
+func TestWebhookNotifier_ZeroRetries(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	retries := 0
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: server.URL, Timeout: 1, Retries: &retries},
+	})
+
+	err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	if err == nil {
+		t.Fatal("Expected error from failing endpoint")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+func TestWebhookNotifier_CustomRetries(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	retries := 1
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: server.URL, Timeout: 1, Retries: &retries},
+	})
+
+	err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	if err == nil {
+		t.Fatal("Expected error from failing endpoint")
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts.Load())
+	}
+	if got := notifier.client.Timeout; got != time.Second {
+		t.Errorf("Client timeout = %v, want the 1s request timeout", got)
+	}
+}
+
+// slowServer responds after delay and counts requests.
+func slowServer(t *testing.T, delay time.Duration, status int, count *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		time.Sleep(delay)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookNotifier_ConcurrentSends(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	var count atomic.Int32
+	zero := 0
+
+	var configs []config.WebhookConfig
+	for i := 0; i < 4; i++ {
+		configs = append(configs, config.WebhookConfig{URL: slowServer(t, delay, http.StatusOK, &count).URL, Retries: &zero})
+	}
+	notifier := NewWebhookNotifier(configs)
+
+	start := time.Now()
+	if err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if count.Load() != 4 {
+		t.Errorf("Expected 4 requests, got %d", count.Load())
+	}
+	// Sequential sends would take 4*delay; concurrent ones about delay
+	if elapsed >= 2*delay {
+		t.Errorf("Send took %v, want about %v (the slowest endpoint), not the sum", elapsed, delay)
+	}
+}
+
+func TestWebhookNotifier_ConcurrencyLimit(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	var count atomic.Int32
+	zero := 0
+
+	var configs []config.WebhookConfig
+	for i := 0; i < 4; i++ {
+		configs = append(configs, config.WebhookConfig{URL: slowServer(t, delay, http.StatusOK, &count).URL, Retries: &zero})
+	}
+	notifier := NewWebhookNotifier(configs)
+	notifier.SetConcurrency(2)
+
+	start := time.Now()
+	if err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	// Two workers over four endpoints take two rounds
+	if elapsed < 2*delay || elapsed >= 4*delay {
+		t.Errorf("Send took %v, want about %v with 2 workers", elapsed, 2*delay)
+	}
+	if count.Load() != 4 {
+		t.Errorf("Expected 4 requests, got %d", count.Load())
+	}
+}
+
+func TestWebhookNotifier_ConcurrentBestEffort(t *testing.T) {
+	var okCount, failCount atomic.Int32
+	zero := 0
+
+	failing := slowServer(t, 100*time.Millisecond, http.StatusInternalServerError, &failCount)
+	ok := slowServer(t, 0, http.StatusOK, &okCount)
+
+	notifier := NewWebhookNotifier([]config.WebhookConfig{
+		{URL: failing.URL, Retries: &zero},
+		{URL: ok.URL, Retries: &zero},
+	})
+
+	err := notifier.Send(context.Background(), &Notification{Title: "Cooling", Time: time.Now()})
+	if err == nil {
+		t.Fatal("Expected error from failing endpoint")
+	}
+	if okCount.Load() != 1 || failCount.Load() != 1 {
+		t.Errorf("Expected one request per endpoint, got ok=%d fail=%d", okCount.Load(), failCount.Load())
+	}
+}
+
+func TestWebhookNotifier_Formats(t *testing.T) {
+	ts := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	n := &Notification{
+		Title:    "Holding",
+		Agent:    "Claude Code",
+		Message:  "Waiting for approval of sensitive tool Bash",
+		Snippet:  "rm -rf build",
+		Time:     ts,
+		Priority: PriorityHigh,
+	}
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, body map[string]any)
+	}{
+		{"", func(t *testing.T, body map[string]any) {
+			if body["event"] != "holding" || body["agent"] != "Claude Code" || body["snippet"] != "rm -rf build" {
+				t.Errorf("Generic body = %v, want the Event JSON", body)
+			}
+		}},
+		{config.WebhookFormatGeneric, func(t *testing.T, body map[string]any) {
+			if body["event"] != "holding" || body["priority"] != "high" {
+				t.Errorf("Generic body = %v, want the Event JSON", body)
+			}
+		}},
+		{config.WebhookFormatSlack, func(t *testing.T, body map[string]any) {
+			want := "*Claude Code* | Holding\nWaiting for approval of sensitive tool Bash\n```\nrm -rf build\n```"
+			if len(body) != 1 || body["text"] != want {
+				t.Errorf("Slack body = %v, want text %q", body, want)
+			}
+		}},
+		{config.WebhookFormatDiscord, func(t *testing.T, body map[string]any) {
+			embeds, _ := body["embeds"].([]any)
+			if len(embeds) != 1 {
+				t.Fatalf("Discord body = %v, want one embed", body)
+			}
+			embed := embeds[0].(map[string]any)
+			if embed["title"] != "Claude Code | Holding" {
+				t.Errorf("Embed title = %v", embed["title"])
+			}
+			if embed["description"] != "Waiting for approval of sensitive tool Bash\n```\nrm -rf build\n```" {
+				t.Errorf("Embed description = %q", embed["description"])
+			}
+			if embed["color"] != float64(0xE01E5A) || embed["timestamp"] != "2025-01-15T10:30:00Z" {
+				t.Errorf("Embed color/timestamp = %v / %v, want high-priority red at the event time", embed["color"], embed["timestamp"])
+			}
+		}},
+		{config.WebhookFormatTeams, func(t *testing.T, body map[string]any) {
+			attachments, _ := body["attachments"].([]any)
+			if body["type"] != "message" || len(attachments) != 1 {
+				t.Fatalf("Teams body = %v, want a message with one attachment", body)
+			}
+			att := attachments[0].(map[string]any)
+			if att["contentType"] != "application/vnd.microsoft.card.adaptive" {
+				t.Errorf("contentType = %v", att["contentType"])
+			}
+			card := att["content"].(map[string]any)
+			blocks, _ := card["body"].([]any)
+			if card["type"] != "AdaptiveCard" || len(blocks) != 3 {
+				t.Fatalf("Card = %v, want title, message, and snippet blocks", card)
+			}
+			title := blocks[0].(map[string]any)
+			if title["text"] != "Claude Code | Holding" || title["color"] != "attention" {
+				t.Errorf("Title block = %v", title)
+			}
+			if blocks[2].(map[string]any)["text"] != "rm -rf build" {
+				t.Errorf("Snippet block = %v", blocks[2])
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run("format="+tt.format, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			notifier := NewWebhookNotifier([]config.WebhookConfig{{URL: server.URL, Format: tt.format}})
+			if err := notifier.Send(context.Background(), n); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			var decoded map[string]any
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				t.Fatalf("Body is not JSON: %q", body)
+			}
+			tt.check(t, decoded)
+		})
+	}
+}
+
+func TestEventTone(t *testing.T) {
+	tests := []struct {
+		event    EventType
+		priority Priority
+		want     string
+	}{
+		{EventCooling, "", "good"},
+		{EventAwaiting, "", "warning"},
+		{EventLoop, "", "warning"},
+		{EventCooling, PriorityHigh, "attention"},
+		{EventActivity, "", "default"},
+	}
+	for _, tt := range tests {
+		if got := eventTone(&Event{Event: tt.event, Priority: tt.priority}); got != tt.want {
+			t.Errorf("eventTone(%s, %q) = %q, want %q", tt.event, tt.priority, got, tt.want)
+		}
+	}
+}
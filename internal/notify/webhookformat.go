@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"firebell/internal/config"
+)
+
+// Message size limits of the services a webhook can be formatted for.
+const (
+	discordDescriptionMax = 4096
+	teamsTextMax          = 4000
+)
+
+// eventTone classifies an event by its severity for services that color
+// their cards: "attention" for high, "warning" when an agent needs the
+// user, "good" when a turn or process finished, and "default" otherwise.
+// Events without a severity (e.g. queued by an older firebell) get the
+// default one for their type.
+func eventTone(e *Event) string {
+	severity := e.Severity
+	if severity == "" {
+		severity = SeverityMap(nil).Of(e.Priority, e.Event)
+	}
+	switch severity {
+	case SeverityHigh:
+		return "attention"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNotice:
+		return "good"
+	default:
+		return "default"
+	}
+}
+
+// discordColors maps an eventTone to a Discord embed color.
+var discordColors = map[string]int{
+	"attention": 0xE01E5A,
+	"warning":   0xECB22E,
+	"good":      0x2EB67D,
+	"default":   0x9E9E9E,
+}
+
+// renderFormat builds the request body for an event in a webhook format
+// preset (see config.WebhookConfig.Format).
+func renderFormat(format string, e *Event) ([]byte, error) {
+	var payload any
+	switch format {
+	case config.WebhookFormatSlack:
+		payload = map[string]any{"text": formatSlackText(e.Notification())}
+	case config.WebhookFormatDiscord:
+		payload = discordPayload(e)
+	case config.WebhookFormatTeams:
+		payload = teamsPayload(e)
+	default:
+		payload = e
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return data, nil
+}
+
+// discordPayload renders an event as a Discord webhook message with one embed.
+func discordPayload(e *Event) map[string]any {
+	title := e.Title
+	if e.Agent != "" {
+		title = e.Agent + " | " + e.Title
+	}
+	desc := e.Message
+	if e.Snippet != "" {
+		desc += "\n" + formatSnippetForSlack(e.Snippet)
+	}
+	embed := map[string]any{
+		"title":       title,
+		"description": truncate(desc, discordDescriptionMax),
+		"color":       discordColors[eventTone(e)],
+		"footer":      map[string]string{"text": string(e.Event)},
+	}
+	if !e.Timestamp.IsZero() {
+		embed["timestamp"] = e.Timestamp.UTC().Format(time.RFC3339)
+	}
+	return map[string]any{"embeds": []any{embed}}
+}
+
+// teamsPayload renders an event as a Microsoft Teams message holding an
+// Adaptive Card, as accepted by Teams workflow webhooks.
+func teamsPayload(e *Event) map[string]any {
+	title := e.Title
+	if e.Agent != "" {
+		title = e.Agent + " | " + e.Title
+	}
+	body := []any{
+		map[string]any{
+			"type":   "TextBlock",
+			"text":   title,
+			"weight": "Bolder",
+			"size":   "Medium",
+			"color":  eventTone(e),
+			"wrap":   true,
+		},
+	}
+	if e.Message != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": truncate(e.Message, teamsTextMax), "wrap": true})
+	}
+	if e.Snippet != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": truncate(e.Snippet, slackSnippetMax), "fontType": "Monospace", "wrap": true})
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
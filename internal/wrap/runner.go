@@ -22,6 +22,8 @@ type Runner struct {
 	notifier  notify.Notifier
 	matcher   detect.Matcher
 	agentName string
+	agent     string            // Known agent detected from the command ("" = generic)
+	commands  map[string]string // Command names of known agents (see SetCommandAgents)
 
 	// Deduplication state
 	lastNotifyTime time.Time
@@ -45,6 +47,13 @@ func NewRunner(cfg *config.Config, notifier notify.Notifier, agentName string) *
 	}
 }
 
+// SetCommandAgents sets the command names recognized as known agents, mapped
+// to their agent names (see monitor.CommandAgents). A wrapped command that
+// names one is matched with that agent's matcher.
+func (r *Runner) SetCommandAgents(commands map[string]string) {
+	r.commands = commands
+}
+
 // Run executes the command and monitors its output.
 // Returns the command's exit code.
 func (r *Runner) Run(ctx context.Context, args []string) (int, error) {
@@ -52,6 +61,15 @@ func (r *Runner) Run(ctx context.Context, args []string) (int, error) {
 		return 1, fmt.Errorf("no command specified")
 	}
 
+	// Use the agent's own matcher when wrapping a known agent
+	if agent := detect.AgentForCommand(args[0], r.commands); agent != "" {
+		r.agent = agent
+		r.matcher = detect.NewComboMatcher(
+			detect.MatcherForCommand(args[0], r.commands),
+			detect.MustRegexMatcher(agent, detect.DefaultPattern),
+		)
+	}
+
 	// Create PTY wrapper
 	p := NewPTY(args[0], args[1:]...)
 
@@ -147,8 +165,13 @@ func (r *Runner) sendNotification(ctx context.Context, match *detect.Match, rece
 		displayName = "Wrapped Command"
 	}
 
+	agent := r.agent
+	if agent == "" {
+		agent = "wrapped"
+	}
+
 	n := notify.NewNotificationFromMatch(
-		"wrapped",
+		agent,
 		displayName,
 		match.Reason,
 		match.Line,
@@ -164,10 +187,10 @@ func (r *Runner) sendNotification(ctx context.Context, match *detect.Match, rece
 		if start < 0 {
 			start = 0
 		}
-		n.Snippet = strings.Join(recentLines[start:], "\n")
+		n.Snippet = notify.DedupeSnippet(strings.Join(recentLines[start:], "\n"), match.Line, n.Message)
 	}
 
-	if err := r.notifier.Send(ctx, n); err != nil {
+	if err := notify.CriticalOnly(r.notifier.Send(ctx, n)); err != nil {
 		fmt.Fprintf(os.Stderr, "\n[firebell] Failed to send notification: %v\n", err)
 	}
 }
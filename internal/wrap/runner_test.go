@@ -2,6 +2,8 @@ package wrap
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -99,3 +101,38 @@ func TestRunnerWithMatch(t *testing.T) {
 	// Note: Due to async nature, notification may or may not be captured
 	// This is a basic smoke test
 }
+
+func TestRunnerDetectsWrappedAgent(t *testing.T) {
+	// A stand-in "claude" binary that prints a Claude end_turn entry
+	dir := t.TempDir()
+	script := filepath.Join(dir, "claude")
+	line := `{"type":"assistant","message":{"stop_reason":"end_turn"}}`
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho '"+line+"'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Notify.Type = "stdout"
+	cfg.Output.Verbosity = "verbose"
+
+	notifier := &mockNotifier{}
+	runner := NewRunner(cfg, notifier, "Main Claude")
+	runner.SetCommandAgents(map[string]string{"claude": "claude"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := runner.Run(ctx, []string{script}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if runner.agent != "claude" {
+		t.Errorf("agent = %q, want claude", runner.agent)
+	}
+	if len(notifier.notifications) == 0 {
+		t.Fatal("expected a notification for the end_turn line")
+	}
+	if got := notifier.notifications[0].Message; got != "end turn" {
+		t.Errorf("Message = %q, want ClaudeMatcher's %q", got, "end turn")
+	}
+}